@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+	"strings"
+)
+
+// callerKey is the context key under which the authenticated user of a request is stored.
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx that carries the authenticated user of a request.
+func WithCaller(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, callerKey{}, user)
+}
+
+// Caller returns the authenticated user stored in ctx by WithCaller, or nil if the request is anonymous.
+func Caller(ctx context.Context) *models.User {
+	user, _ := ctx.Value(callerKey{}).(*models.User)
+	return user
+}
+
+// callerName returns the name of the authenticated user of the request, or an empty string.
+func callerName(r *http.Request) string {
+	if user := Caller(r.Context()); user != nil {
+		return user.Name
+	}
+	return ""
+}
+
+// RequestToken returns the API token of a request, taken from an "Authorization: Bearer" header
+// or, for clients that cannot set it, from the X-API-Key header. It returns an empty string if there is none.
+func RequestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// BatchHandler applies batches of create, update and delete operations in one transaction.
+type BatchHandler struct {
+	Control *handle.BatchControl
+}
+
+// control returns the control that serves a request: for an authenticated caller, it applies the batch
+// with the caller's access to the planners.
+func (h *BatchHandler) control(r *http.Request) *handle.BatchControl {
+	user := callerName(r)
+	if user == "" {
+		return h.Control
+	}
+	return h.Control.As(user)
+}
+
+// Batch applies the operations of the request body in order and responds with their results.
+// Later operations can refer to the id of a record an earlier one created by its ref, as "${ref}".
+// The operations are applied all together or not at all: if one fails, nothing is stored and the error
+// names the failed operation, with the status the failure would have on its own endpoint.
+//
+// Example:
+//
+//	POST /api/v1/batch
+//	{"operations": [
+//	  {"op": "create", "resource": "plan", "ref": "release", "data": {"plan_name": "Release", "plan_date": "2024-06-01", "plan_time": "09:00"}},
+//	  {"op": "create", "resource": "task", "data": {"title": "Tag the release", "plan_id": "${release}"}}
+//	]}
+func (h *BatchHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req handle.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).Batch(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/chat"
+	"github.com/ooyeku/flow/pkg/services"
+	"net/http"
+)
+
+// ChatHandler serves the chat history of the daemon to the chat, which reads and writes it through
+// client.ChatStore. The history is not shared between users, so the daemon only serves it on its Unix socket,
+// to the user who runs it. If Search is set, the responses saved through the handler are added to its index.
+type ChatHandler struct {
+	Service *chat.ChatService
+	Search  *services.SearchService
+}
+
+// RegisterChatRoutes registers the routes of h under /chat on r.
+//
+// Example:
+//
+//	GET /chat/topics
+//	POST /chat/responses
+//	DELETE /chat/responses?topic=General
+func RegisterChatRoutes(r *mux.Router, h *ChatHandler) {
+	r.HandleFunc("/chat/threads", h.ListThreads).Methods("GET")
+	r.HandleFunc("/chat/threads", h.CreateThread).Methods("POST")
+	r.HandleFunc("/chat/threads/{id}", h.GetThread).Methods("GET")
+	r.HandleFunc("/chat/threads/{id}", h.UpdateThread).Methods("PUT")
+	r.HandleFunc("/chat/threads/{id}", h.DeleteThread).Methods("DELETE")
+	r.HandleFunc("/chat/topics", h.ListTopics).Methods("GET")
+	r.HandleFunc("/chat/topics", h.CreateTopic).Methods("POST")
+	r.HandleFunc("/chat/topics/{id}", h.GetTopic).Methods("GET")
+	r.HandleFunc("/chat/topics/{id}", h.UpdateTopic).Methods("PUT")
+	r.HandleFunc("/chat/topics/{id}", h.DeleteTopic).Methods("DELETE")
+	r.HandleFunc("/chat/responses", h.ListChatResponses).Methods("GET")
+	r.HandleFunc("/chat/responses", h.SaveChatResponse).Methods("POST")
+	r.HandleFunc("/chat/responses", h.ClearEntries).Methods("DELETE")
+	r.HandleFunc("/chat/responses/{id}", h.GetChatResponse).Methods("GET")
+}
+
+// chatID parses the uuid in the URL of a thread or topic request.
+func chatID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+// writeChat encodes v as the JSON response, or writes the error with its status.
+func writeChat(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(v)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// readChat decodes the JSON body of a request into v and reports whether it succeeded.
+func readChat(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// ListThreads responds with every chat thread.
+func (h *ChatHandler) ListThreads(w http.ResponseWriter, r *http.Request) {
+	threads, err := h.Service.ListThreads()
+	writeChat(w, threads, err)
+}
+
+// CreateThread stores the thread in the body.
+func (h *ChatHandler) CreateThread(w http.ResponseWriter, r *http.Request) {
+	var thread chat.Thread
+	if readChat(w, r, &thread) {
+		writeChat(w, thread, h.Service.CreateThread(&thread))
+	}
+}
+
+// GetThread responds with the thread with the id in the URL.
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	if id, ok := chatID(w, r); ok {
+		thread, err := h.Service.GetThread(id)
+		writeChat(w, thread, err)
+	}
+}
+
+// UpdateThread replaces the thread with the id in the URL with the thread in the body.
+func (h *ChatHandler) UpdateThread(w http.ResponseWriter, r *http.Request) {
+	var thread chat.Thread
+	if id, ok := chatID(w, r); ok && readChat(w, r, &thread) {
+		writeChat(w, thread, h.Service.UpdateThread(id, &thread))
+	}
+}
+
+// DeleteThread deletes the thread with the id in the URL.
+func (h *ChatHandler) DeleteThread(w http.ResponseWriter, r *http.Request) {
+	if id, ok := chatID(w, r); ok {
+		writeChat(w, struct{}{}, h.Service.DeleteThread(id))
+	}
+}
+
+// ListTopics responds with every chat topic.
+func (h *ChatHandler) ListTopics(w http.ResponseWriter, r *http.Request) {
+	topics, err := h.Service.ListTopics()
+	writeChat(w, topics, err)
+}
+
+// CreateTopic stores the topic in the body.
+func (h *ChatHandler) CreateTopic(w http.ResponseWriter, r *http.Request) {
+	var topic chat.ChatTopic
+	if readChat(w, r, &topic) {
+		writeChat(w, topic, h.Service.CreateTopic(&topic))
+	}
+}
+
+// GetTopic responds with the topic with the id in the URL.
+func (h *ChatHandler) GetTopic(w http.ResponseWriter, r *http.Request) {
+	if id, ok := chatID(w, r); ok {
+		topic, err := h.Service.GetTopic(id)
+		writeChat(w, topic, err)
+	}
+}
+
+// UpdateTopic replaces the topic with the id in the URL with the topic in the body.
+func (h *ChatHandler) UpdateTopic(w http.ResponseWriter, r *http.Request) {
+	var topic chat.ChatTopic
+	if id, ok := chatID(w, r); ok && readChat(w, r, &topic) {
+		writeChat(w, topic, h.Service.UpdateTopic(id, &topic))
+	}
+}
+
+// DeleteTopic deletes the topic with the id in the URL.
+func (h *ChatHandler) DeleteTopic(w http.ResponseWriter, r *http.Request) {
+	if id, ok := chatID(w, r); ok {
+		writeChat(w, struct{}{}, h.Service.DeleteTopic(id))
+	}
+}
+
+// ListChatResponses responds with every stored chat response.
+func (h *ChatHandler) ListChatResponses(w http.ResponseWriter, r *http.Request) {
+	responses, err := h.Service.ListChatResponses()
+	writeChat(w, responses, err)
+}
+
+// SaveChatResponse stores the chat response in the body, and adds it to the search index.
+func (h *ChatHandler) SaveChatResponse(w http.ResponseWriter, r *http.Request) {
+	var response chat.ChatResponse
+	if !readChat(w, r, &response) {
+		return
+	}
+	err := h.Service.SaveChatResponse(&response)
+	if err == nil && h.Search != nil {
+		h.Search.AddChatResponse(&response)
+	}
+	writeChat(w, response, err)
+}
+
+// GetChatResponse responds with the chat response with the id in the URL.
+func (h *ChatHandler) GetChatResponse(w http.ResponseWriter, r *http.Request) {
+	response, err := h.Service.GetChatResponse(mux.Vars(r)["id"])
+	writeChat(w, response, err)
+}
+
+// ClearEntries clears the chat history of the topic named in the topic parameter, or the whole history without it,
+// and rebuilds the search index without the cleared responses.
+func (h *ChatHandler) ClearEntries(w http.ResponseWriter, r *http.Request) {
+	var err error
+	if topic := r.URL.Query().Get("topic"); topic != "" {
+		err = h.Service.ClearEntriesByTopic(topic)
+	} else {
+		err = h.Service.ClearEntries()
+	}
+	if err == nil && h.Search != nil {
+		err = h.Search.Rebuild()
+	}
+	writeChat(w, struct{}{}, err)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventStreamContentType is the media type of a Server-Sent Events stream.
+const eventStreamContentType = "text/event-stream"
+
+// heartbeatInterval is how often a comment is sent on an idle event stream, so that proxies keep it open.
+const heartbeatInterval = 15 * time.Second
+
+// EventHandler serves the stream of changes to tasks, goals, plans, planners and versions.
+type EventHandler struct {
+	Control *handle.EventControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it only streams the events
+// of the planners shared with the authenticated caller.
+func (h *EventHandler) control(r *http.Request) *handle.EventControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
+}
+
+// streamEventsRequest builds a StreamEventsRequest from the query string and the Last-Event-ID header.
+// Browsers send Last-Event-ID when an EventSource reconnects; other clients may pass last_event_id in the query instead.
+func streamEventsRequest(r *http.Request) (*handle.StreamEventsRequest, error) {
+	query := r.URL.Query()
+	req := &handle.StreamEventsRequest{
+		PlannerId: query.Get("planner_id"),
+	}
+	for _, t := range strings.Split(query.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			req.Types = append(req.Types, t)
+		}
+	}
+	lastId := r.Header.Get("Last-Event-ID")
+	if lastId == "" {
+		lastId = query.Get("last_event_id")
+	}
+	if lastId != "" {
+		after, err := strconv.Atoi(lastId)
+		if err != nil || after < 0 {
+			return nil, fmt.Errorf("invalid Last-Event-ID: %s", lastId)
+		}
+		req.After = after
+	}
+	return req, nil
+}
+
+// writeEvent writes an event in the Server-Sent Events format, with its id, type and the event as JSON data.
+func writeEvent(w http.ResponseWriter, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
+
+// StreamEvents streams the changes to tasks, goals, plans, planners and versions as Server-Sent Events.
+// The query parameters planner_id and type (a comma separated list of event types such as task.created,
+// or resources such as goal) select the events. A client that reconnects with Last-Event-ID first receives
+// the logged events it missed. The stream ends when the client disconnects or falls too far behind,
+// in which case it should reconnect with the id of the last event it received.
+//
+// Example:
+//
+//	GET /api/v1/events?planner_id=123&type=task,goal.deleted
+//	Last-Event-ID: 42
+func (h *EventHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		handleError(w, errors.New("streaming is not supported"), http.StatusInternalServerError)
+		return
+	}
+	req, err := streamEventsRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	logged, sub, err := h.control(r).StreamEvents(req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer sub.Close()
+	// the stream stays open for as long as the client listens, past the write timeout of the server
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	last := req.After
+	for _, event := range logged {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+		last = event.Id
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if event.Id <= last {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			last = event.Id
+			flusher.Flush()
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
 	"net/http"
 )
 
@@ -96,6 +97,17 @@ import (
 //	}
 type GoalHandler struct {
 	Control *handle.GoalControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it acts for the authenticated caller
+// and only reaches the goals of the planners shared with them.
+func (h *GoalHandler) control(r *http.Request) *handle.GoalControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
 }
 
 // CreateGoal is a method of the GoalHandler struct that handles the creation of a new goal.
@@ -110,7 +122,7 @@ func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
 	var req handle.CreateGoalRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	handleError(w, err, http.StatusBadRequest)
-	res, err := h.Control.CreateGoal(&req)
+	res, err := h.control(r).CreateGoal(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -125,7 +137,7 @@ func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
 // The result of the GetGoal method is stored in `res`, and any error that occurred is stored in `err`.
 // If an error occurred while executing the GetGoal method, it is handled by calling the handleError function,
 // which writes an error response with the corresponding HTTP status code to the client and logs the error.
-// If no error occurred, the result `res` is encoded as JSON and written as the response to the client.
+// If no error occurred, the ETag header is set to the revision of the goal and the result `res` is encoded as JSON and written as the response to the client.
 // Any error encountered during encoding is also handled by calling the handleError function.
 // Example Usage:
 //
@@ -138,8 +150,12 @@ func (h *GoalHandler) GetGoal(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	req := handle.GetGoalRequest{Id: id}
-	res, err := h.Control.GetGoal(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	res, err := h.control(r).GetGoal(&req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setETag(w, res.Goal.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
@@ -166,7 +182,7 @@ func (h *GoalHandler) GetGoalByObjective(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	objective := vars["objective"]
 	req := handle.GetGoalByObjectiveRequest{Objective: objective}
-	res, err := h.Control.GetGoalByObjective(&req)
+	res, err := h.control(r).GetGoalByObjective(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -194,7 +210,7 @@ func (h *GoalHandler) GetGoalsByPlannerIdRequest(w http.ResponseWriter, r *http.
 	vars := mux.Vars(r)
 	plannerId := vars["planner_id"]
 	req := handle.GetGoalsByPlannerIdRequest{PlannerId: plannerId}
-	res, err := h.Control.GetGoalsByPlannerId(&req)
+	res, err := h.control(r).GetGoalsByPlannerId(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -204,7 +220,8 @@ func (h *GoalHandler) GetGoalsByPlannerIdRequest(w http.ResponseWriter, r *http.
 // It first decodes the request body into an update goal request object.
 // Then it ensures that the ID from the URL is used.
 // It then calls the UpdateGoal method of the GoalControl service.
-// If there is an error during the update, it returns a 500 Internal Server Error response.
+// If the If-Match header does not match the revision of the goal, it returns a 412 Precondition Failed response.
+// If there is any other error during the update, it returns a 500 Internal Server Error response.
 // If the update is successful, it returns a 200 OK response.
 // If there is an error decoding the request body, it returns a 400 Bad Request response.
 //
@@ -223,12 +240,22 @@ func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 
 	var req handle.UpdateGoalRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
-	handleError(w, err, http.StatusBadRequest)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
 
 	req.Id = id // Ensure the ID from the URL is used
+	if err := ifMatch(r, &req.Revision); err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
-	err = h.Control.UpdateGoal(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	err = h.control(r).UpdateGoal(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -244,6 +271,7 @@ func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 //
 // This handler function expects a DELETE HTTP request to the "/goals/{id}" URL pattern,
 // where "{id}" is the ID of the goal to be deleted.
+// An If-Match header makes the deletion conditional on the revision of the goal; a mismatch returns 412 Precondition Failed.
 //
 // The function retrieves the ID from the request URL, creates a DeleteGoalRequest with the
 // ID, and calls the DeleteGoal method of the GoalControl struct passed in the GoalHandler.
@@ -258,24 +286,82 @@ func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request) {
 func (h *GoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	req := handle.DeleteGoalRequest{Id: id}
-	err := h.Control.DeleteGoal(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.DeleteGoalRequest{Id: id, Revision: revision}
+	err = h.control(r).DeleteGoal(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
-// ListGoals retrieves a list of goals.
+// ListGoals retrieves a page of goals.
 //
-// It calls the ListGoals method of GoalControl and encodes the response as JSON.
+// It converts the query string into a ListRequest, calls the FindGoals method of GoalControl and encodes the response as JSON.
+// Goals can be filtered by status, deadline_before and planner_id, ordered with sort and paged with limit and cursor.
+// If the list parameters are invalid, it returns an HTTP 400 Bad Request.
 // If there is an error retrieving the goals, it returns an HTTP 500 Internal Server Error.
 //
 // Example usage:
 //
 //	goalHandler := &GoalHandler{Control: goalControl}
 //	http.HandleFunc("/goals", goalHandler.ListGoals)
+//
+//	GET /goals?status=Not%20Started&sort=deadline&limit=10
 func (h *GoalHandler) ListGoals(w http.ResponseWriter, r *http.Request) {
-	res, err := h.Control.ListGoals()
+	req, err := listRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).FindGoals(req)
+	if err != nil {
+		handleError(w, err, listErrorStatus(err))
+		return
+	}
+	setNextCursor(w, res.NextCursor)
+	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
+}
+
+// PatchGoal partially updates a goal with the JSON Merge Patch (RFC 7396) in the request body.
+//
+// Only the fields present in the patch are changed; the status and creation time of the goal are kept
+// unless the patch sets them. The deadline may be given as "YYYY-MM-DD".
+// It responds with the updated goal, 400 if the patch is invalid, 404 if the goal does not exist
+// 412 if the If-Match header does not match its revision and 415 if the body is not a merge patch.
+// The new revision of the goal is sent in the ETag header.
+//
+// Example:
+//
+//	PATCH /api/v1/goals/123
+//	Content-Type: application/merge-patch+json
+//
+//	{"goal_status": "In Progress", "deadline": "2024-12-31"}
+func (h *GoalHandler) PatchGoal(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	patch, err := readPatch(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.PatchGoalRequest{Id: mux.Vars(r)["id"], Patch: patch, Revision: revision}
+	res, err := h.control(r).PatchGoal(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	setETag(w, res.Goal.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
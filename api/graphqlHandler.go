@@ -0,0 +1,691 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ooyeku/flow/pkg/graphql"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// GraphQLHandler serves the GraphQL API, which reads and changes tasks, goals, plans and planners
+// through the controls of the resource handlers, with the access of the caller.
+// Related records are loaded for every record of a level of the response at once, so a query for
+// a planner with its goals, their plans and the plans' tasks makes one store lookup per level.
+type GraphQLHandler struct {
+	Task    *TaskHandler
+	Goal    *GoalHandler
+	Plan    *PlanHandler
+	Planner *PlannerHandler
+
+	once   sync.Once
+	schema *graphql.Schema
+}
+
+// graphqlControls are the controls that serve one GraphQL request, scoped to its caller.
+type graphqlControls struct {
+	caller  string
+	task    *handle.TaskControl
+	goal    *handle.GoalControl
+	plan    *handle.PlanControl
+	planner *handle.PlannerControl
+}
+
+// graphqlControlsKey is the context key under which the controls of a GraphQL request are stored.
+type graphqlControlsKey struct{}
+
+// controlsOf returns the controls stored in ctx by GraphQL.
+func controlsOf(ctx context.Context) *graphqlControls {
+	return ctx.Value(graphqlControlsKey{}).(*graphqlControls)
+}
+
+// Schema returns the GraphQL schema served by the handler.
+func (h *GraphQLHandler) Schema() *graphql.Schema {
+	h.once.Do(func() {
+		h.schema = newGraphQLSchema()
+	})
+	return h.schema
+}
+
+// GraphQL executes the GraphQL request in the body and responds with its result.
+// As usual for GraphQL, errors of the query are reported in the errors of a 200 response;
+// only a body that is not a GraphQL request is rejected with 400 Bad Request.
+//
+// Example:
+//
+//	POST /graphql
+//	{"query": "{ planner(id: \"p1\") { title goals { objective plans { name tasks { title completed } } } } }"}
+func (h *GraphQLHandler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	ctx := context.WithValue(r.Context(), graphqlControlsKey{}, &graphqlControls{
+		caller:  callerName(r),
+		task:    h.Task.control(r),
+		goal:    h.Goal.control(r),
+		plan:    h.Plan.control(r),
+		planner: h.Planner.control(r),
+	})
+	res := h.Schema().Execute(ctx, &req)
+	err := json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// GraphQLSchema responds with the schema of the GraphQL API in the schema definition language.
+func (h *GraphQLHandler) GraphQLSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err := w.Write([]byte(h.Schema().SDL()))
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// newGraphQLSchema builds the GraphQL schema. The types refer to each other, so their fields are added once they all exist.
+func newGraphQLSchema() *graphql.Schema {
+	timeType := &graphql.Scalar{Name: "Time", Description: "An RFC 3339 date and time."}
+	taskType := &graphql.Object{Name: "Task", Description: "A unit of work, optionally part of a plan."}
+	goalType := &graphql.Object{Name: "Goal", Description: "An objective with a deadline, optionally part of a planner."}
+	planType := &graphql.Object{Name: "Plan", Description: "A scheduled course of action towards a goal."}
+	plannerType := &graphql.Object{Name: "Planner", Description: "A collection of goals shared with its members."}
+	memberType := &graphql.Object{Name: "Member", Description: "A user who has a role in a planner."}
+
+	taskType.Fields = map[string]*graphql.Field{
+		"id":          {Type: graphql.NonNullOf(graphql.ID), Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.ID })},
+		"title":       {Type: graphql.String, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Title })},
+		"description": {Type: graphql.String, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Description })},
+		"owner":       {Type: graphql.String, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Owner })},
+		"started":     {Type: graphql.Boolean, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Started })},
+		"completed":   {Type: graphql.Boolean, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Completed })},
+		"failed":      {Type: graphql.Boolean, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Failed })},
+		"createdAt":   {Type: timeType, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.CreatedAt })},
+		"updatedAt":   {Type: timeType, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.UpdatedAt })},
+		"revision":    {Type: graphql.Int, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return t.Revision })},
+		"planId":      {Type: graphql.ID, Resolve: taskProperty(func(t *handle.GetTaskResponse) interface{} { return optional(t.PlanId) })},
+		"plan": {Type: planType, Description: "The plan the task belongs to.",
+			Resolve: toOne(func(t *handle.GetTaskResponse) string { return t.PlanId }, loadPlans, planId)},
+	}
+	goalType.Fields = map[string]*graphql.Field{
+		"id":        {Type: graphql.NonNullOf(graphql.ID), Resolve: goalProperty(func(g *models.Goal) interface{} { return g.Id })},
+		"objective": {Type: graphql.String, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.Objective })},
+		"status":    {Type: graphql.String, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.GoalStatus })},
+		"deadline":  {Type: timeType, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.Deadline })},
+		"createdAt": {Type: timeType, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.GoalCreatedAt })},
+		"updatedAt": {Type: timeType, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.GoalUpdatedAt })},
+		"revision":  {Type: graphql.Int, Resolve: goalProperty(func(g *models.Goal) interface{} { return g.Revision })},
+		"plannerId": {Type: graphql.ID, Resolve: goalProperty(func(g *models.Goal) interface{} { return optional(g.PlannerId) })},
+		"planner": {Type: plannerType, Description: "The planner the goal belongs to.",
+			Resolve: toOne(func(g *models.Goal) string { return g.PlannerId }, loadPlanners, plannerId)},
+		"plans": {Type: graphql.ListOf(planType), Description: "The plans towards the goal.",
+			Resolve: toMany(goalId, loadGoalPlans, func(p *models.Plan) string { return p.GoalId })},
+	}
+	planType.Fields = map[string]*graphql.Field{
+		"id":          {Type: graphql.NonNullOf(graphql.ID), Resolve: planProperty(func(p *models.Plan) interface{} { return p.Id })},
+		"name":        {Type: graphql.String, Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanName })},
+		"description": {Type: graphql.String, Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanDescription })},
+		"date": {Type: graphql.String, Description: "The date of the plan, in the format YYYY-MM-DD.",
+			Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanDate.Format("2006-01-02") })},
+		"time": {Type: graphql.String, Description: "The time of the plan, in the format HH:MM.",
+			Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanTime.Format("15:04") })},
+		"status":    {Type: graphql.String, Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanStatus })},
+		"createdAt": {Type: timeType, Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanCreatedAt })},
+		"updatedAt": {Type: timeType, Resolve: planProperty(func(p *models.Plan) interface{} { return p.PlanUpdatedAt })},
+		"revision":  {Type: graphql.Int, Resolve: planProperty(func(p *models.Plan) interface{} { return p.Revision })},
+		"goalId":    {Type: graphql.ID, Resolve: planProperty(func(p *models.Plan) interface{} { return optional(p.GoalId) })},
+		"goal": {Type: goalType, Description: "The goal the plan works towards.",
+			Resolve: toOne(func(p *models.Plan) string { return p.GoalId }, loadGoals, goalId)},
+		"tasks": {Type: graphql.ListOf(taskType), Description: "The tasks of the plan.",
+			Resolve: toMany(planId, loadPlanTasks, func(t *handle.GetTaskResponse) string { return t.PlanId })},
+	}
+	plannerType.Fields = map[string]*graphql.Field{
+		"id":       {Type: graphql.NonNullOf(graphql.ID), Resolve: plannerProperty(func(p *handle.GetPlannerResponse) interface{} { return p.Id })},
+		"title":    {Type: graphql.String, Resolve: plannerProperty(func(p *handle.GetPlannerResponse) interface{} { return p.Title })},
+		"userId":   {Type: graphql.String, Description: "The owner of the planner.", Resolve: plannerProperty(func(p *handle.GetPlannerResponse) interface{} { return p.UserId })},
+		"revision": {Type: graphql.Int, Resolve: plannerProperty(func(p *handle.GetPlannerResponse) interface{} { return p.Revision })},
+		"members": {Type: graphql.ListOf(memberType), Description: "The users the planner is shared with.",
+			Resolve: plannerProperty(func(p *handle.GetPlannerResponse) interface{} {
+				members := make([]*plannerMember, 0, len(p.Members))
+				for _, user := range sortedUsers(p.Members) {
+					members = append(members, &plannerMember{User: user, Role: p.Members[user]})
+				}
+				return members
+			})},
+		"goals": {Type: graphql.ListOf(goalType), Description: "The goals of the planner.",
+			Resolve: toMany(plannerId, loadPlannerGoals, func(g *models.Goal) string { return g.PlannerId })},
+	}
+	memberType.Fields = map[string]*graphql.Field{
+		"user": {Type: graphql.String, Resolve: graphql.Property(func(source interface{}) interface{} { return source.(*plannerMember).User })},
+		"role": {Type: graphql.String, Description: "owner, editor or viewer.",
+			Resolve: graphql.Property(func(source interface{}) interface{} { return source.(*plannerMember).Role })},
+	}
+
+	return &graphql.Schema{
+		Query:    newGraphQLQuery(taskType, goalType, planType, plannerType),
+		Mutation: newGraphQLMutation(taskType, goalType, planType, plannerType),
+	}
+}
+
+// pageArgs are the arguments of the list queries, as in handle.ListRequest.
+func pageArgs(filters ...*graphql.Argument) []*graphql.Argument {
+	return append([]*graphql.Argument{
+		{Name: "limit", Type: graphql.Int, Description: "The maximum number of records in the page."},
+		{Name: "cursor", Type: graphql.String, Description: "The nextCursor of the previous page."},
+		{Name: "sort", Type: graphql.String, Description: "The json name of the field to order by, prefixed with - for descending order."},
+	}, filters...)
+}
+
+// pageType returns the type of a page of a list query: its items and the cursor of the next page.
+func pageType(name string, item *graphql.Object) *graphql.Object {
+	return &graphql.Object{Name: name, Fields: map[string]*graphql.Field{
+		"items": {Type: graphql.ListOf(item), Resolve: graphql.Property(func(source interface{}) interface{} { return source.(*page).items })},
+		"nextCursor": {Type: graphql.String, Description: "The cursor of the next page, or null on the last page.",
+			Resolve: graphql.Property(func(source interface{}) interface{} { return optional(source.(*page).nextCursor) })},
+	}}
+}
+
+// page is a page of records returned by a list query.
+type page struct {
+	items      interface{}
+	nextCursor string
+}
+
+// pageRequest returns the list request of the arguments of a list query.
+func pageRequest(args map[string]interface{}) *handle.ListRequest {
+	limit, _ := args["limit"].(int)
+	return &handle.ListRequest{
+		Limit:          limit,
+		Cursor:         stringArg(args, "cursor"),
+		Sort:           stringArg(args, "sort"),
+		Owner:          stringArg(args, "owner"),
+		Status:         stringArg(args, "status"),
+		DeadlineBefore: stringArg(args, "deadlineBefore"),
+		PlannerId:      stringArg(args, "plannerId"),
+		GoalId:         stringArg(args, "goalId"),
+	}
+}
+
+func newGraphQLQuery(taskType, goalType, planType, plannerType *graphql.Object) *graphql.Object {
+	idArg := []*graphql.Argument{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}}
+	owner := &graphql.Argument{Name: "owner", Type: graphql.String, Description: "Only list records owned by this user."}
+	status := &graphql.Argument{Name: "status", Type: graphql.String, Description: "Only list records with this status."}
+	return &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"task": {Type: taskType, Args: idArg, Description: "The task with the given id.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				return c.task.GetTask(&handle.GetTaskRequest{ID: args["id"].(string)})
+			})},
+		"tasks": {Type: pageType("TaskPage", taskType), Args: pageArgs(owner, status), Description: "A page of tasks.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.task.FindTasks(pageRequest(args))
+				if err != nil {
+					return nil, err
+				}
+				return &page{items: res.Tasks, nextCursor: res.NextCursor}, nil
+			})},
+		"goal": {Type: goalType, Args: idArg, Description: "The goal with the given id.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.goal.GetGoal(&handle.GetGoalRequest{Id: args["id"].(string)})
+				if err != nil {
+					return nil, err
+				}
+				return res.Goal, nil
+			})},
+		"goals": {Type: pageType("GoalPage", goalType), Description: "A page of goals.",
+			Args: pageArgs(status,
+				&graphql.Argument{Name: "plannerId", Type: graphql.ID, Description: "Only list goals of this planner."},
+				&graphql.Argument{Name: "deadlineBefore", Type: graphql.String, Description: "Only list goals due before this date, in the format YYYY-MM-DD."}),
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.goal.FindGoals(pageRequest(args))
+				if err != nil {
+					return nil, err
+				}
+				return &page{items: res.Goals, nextCursor: res.NextCursor}, nil
+			})},
+		"plan": {Type: planType, Args: idArg, Description: "The plan with the given id.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.plan.GetPlan(&handle.GetPlanRequest{Id: args["id"].(string)})
+				if err != nil {
+					return nil, err
+				}
+				return res.Plan, nil
+			})},
+		"plans": {Type: pageType("PlanPage", planType), Description: "A page of plans.",
+			Args: pageArgs(status, &graphql.Argument{Name: "goalId", Type: graphql.ID, Description: "Only list plans of this goal."}),
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.plan.FindPlans(pageRequest(args))
+				if err != nil {
+					return nil, err
+				}
+				return &page{items: res.Plans, nextCursor: res.NextCursor}, nil
+			})},
+		"planner": {Type: plannerType, Args: idArg, Description: "The planner with the given id.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				return c.planner.GetPlanner(&handle.GetPlannerRequest{Id: args["id"].(string)})
+			})},
+		"planners": {Type: pageType("PlannerPage", plannerType), Args: pageArgs(owner), Description: "A page of planners.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.planner.FindPlanners(pageRequest(args))
+				if err != nil {
+					return nil, err
+				}
+				return &page{items: res.Planners, nextCursor: res.NextCursor}, nil
+			})},
+	}}
+}
+
+// newGraphQLMutation returns the mutation type. The update mutations change only the fields given as arguments,
+// with a merge patch, and the revision argument makes them fail if the record has changed since it was read.
+func newGraphQLMutation(taskType, goalType, planType, plannerType *graphql.Object) *graphql.Object {
+	id := &graphql.Argument{Name: "id", Type: graphql.NonNullOf(graphql.ID)}
+	revision := &graphql.Argument{Name: "revision", Type: graphql.Int, Description: "The revision the change is based on."}
+	deleteArgs := []*graphql.Argument{id, revision}
+	return &graphql.Object{Name: "Mutation", Fields: map[string]*graphql.Field{
+		"createTask": {Type: taskType, Description: "Creates a task, owned by the caller unless an owner is given.",
+			Args: []*graphql.Argument{
+				{Name: "title", Type: graphql.NonNullOf(graphql.String)},
+				{Name: "description", Type: graphql.String},
+				{Name: "owner", Type: graphql.String},
+				{Name: "planId", Type: graphql.ID},
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				req := handle.CreateTaskRequest{
+					Title:       stringArg(args, "title"),
+					Description: stringArg(args, "description"),
+					Owner:       stringArg(args, "owner"),
+					PlanId:      stringArg(args, "planId"),
+				}
+				if req.Owner == "" {
+					req.Owner = c.caller
+				}
+				res, err := c.task.CreateTask(req)
+				if err != nil {
+					return nil, err
+				}
+				return c.task.GetTask(&handle.GetTaskRequest{ID: res.ID})
+			})},
+		"updateTask": {Type: taskType, Description: "Changes the given fields of a task.",
+			Args: []*graphql.Argument{id,
+				{Name: "title", Type: graphql.String},
+				{Name: "description", Type: graphql.String},
+				{Name: "owner", Type: graphql.String},
+				{Name: "planId", Type: graphql.ID},
+				{Name: "started", Type: graphql.Boolean},
+				{Name: "completed", Type: graphql.Boolean},
+				{Name: "failed", Type: graphql.Boolean},
+				revision,
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				patch, err := mergePatch(args, map[string]string{"title": "title", "description": "description",
+					"owner": "owner", "planId": "plan_id", "started": "started", "completed": "completed", "failed": "failed"})
+				if err != nil {
+					return nil, err
+				}
+				return c.task.PatchTask(&handle.PatchTaskRequest{ID: args["id"].(string), Patch: patch, Revision: intArg(args, "revision")})
+			})},
+		"deleteTask": {Type: graphql.Boolean, Args: deleteArgs, Description: "Deletes a task.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				err := c.task.DeleteTask(&handle.DeleteTaskRequest{ID: args["id"].(string), Revision: intArg(args, "revision")})
+				return err == nil, err
+			})},
+
+		"createGoal": {Type: goalType, Description: "Creates a goal.",
+			Args: []*graphql.Argument{
+				{Name: "objective", Type: graphql.NonNullOf(graphql.String)},
+				{Name: "deadline", Type: graphql.NonNullOf(graphql.String), Description: "In the format YYYY-MM-DD."},
+				{Name: "plannerId", Type: graphql.ID},
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.goal.CreateGoal(&handle.CreateGoalRequest{
+					Objective: stringArg(args, "objective"),
+					Deadline:  stringArg(args, "deadline"),
+					PlannerId: stringArg(args, "plannerId"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				goal, err := c.goal.GetGoal(&handle.GetGoalRequest{Id: res.ID})
+				if err != nil {
+					return nil, err
+				}
+				return goal.Goal, nil
+			})},
+		"updateGoal": {Type: goalType, Description: "Changes the given fields of a goal.",
+			Args: []*graphql.Argument{id,
+				{Name: "objective", Type: graphql.String},
+				{Name: "status", Type: graphql.String},
+				{Name: "deadline", Type: graphql.String, Description: "In the format YYYY-MM-DD."},
+				{Name: "plannerId", Type: graphql.ID},
+				revision,
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				patch, err := mergePatch(args, map[string]string{"objective": "objective", "status": "goal_status",
+					"deadline": "deadline", "plannerId": "planner_id"})
+				if err != nil {
+					return nil, err
+				}
+				res, err := c.goal.PatchGoal(&handle.PatchGoalRequest{Id: args["id"].(string), Patch: patch, Revision: intArg(args, "revision")})
+				if err != nil {
+					return nil, err
+				}
+				return res.Goal, nil
+			})},
+		"deleteGoal": {Type: graphql.Boolean, Args: deleteArgs, Description: "Deletes a goal.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				err := c.goal.DeleteGoal(&handle.DeleteGoalRequest{Id: args["id"].(string), Revision: intArg(args, "revision")})
+				return err == nil, err
+			})},
+
+		"createPlan": {Type: planType, Description: "Creates a plan.",
+			Args: []*graphql.Argument{
+				{Name: "name", Type: graphql.NonNullOf(graphql.String)},
+				{Name: "description", Type: graphql.String},
+				{Name: "date", Type: graphql.NonNullOf(graphql.String), Description: "In the format YYYY-MM-DD."},
+				{Name: "time", Type: graphql.NonNullOf(graphql.String), Description: "In the format HH:MM."},
+				{Name: "goalId", Type: graphql.ID},
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				res, err := c.plan.CreatePlan(&handle.CreatePlanRequest{
+					PlanName:        stringArg(args, "name"),
+					PlanDescription: stringArg(args, "description"),
+					PlanDate:        stringArg(args, "date"),
+					PlanTime:        stringArg(args, "time"),
+					GoalId:          stringArg(args, "goalId"),
+				})
+				if err != nil {
+					return nil, err
+				}
+				plan, err := c.plan.GetPlan(&handle.GetPlanRequest{Id: res.ID})
+				if err != nil {
+					return nil, err
+				}
+				return plan.Plan, nil
+			})},
+		"updatePlan": {Type: planType, Description: "Changes the given fields of a plan.",
+			Args: []*graphql.Argument{id,
+				{Name: "name", Type: graphql.String},
+				{Name: "description", Type: graphql.String},
+				{Name: "date", Type: graphql.String, Description: "In the format YYYY-MM-DD."},
+				{Name: "time", Type: graphql.String, Description: "In the format HH:MM."},
+				{Name: "status", Type: graphql.String},
+				{Name: "goalId", Type: graphql.ID},
+				revision,
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				patch, err := mergePatch(args, map[string]string{"name": "plan_name", "description": "plan_description",
+					"date": "plan_date", "time": "plan_time", "status": "plan_status", "goalId": "goal_id"})
+				if err != nil {
+					return nil, err
+				}
+				res, err := c.plan.PatchPlan(&handle.PatchPlanRequest{Id: args["id"].(string), Patch: patch, Revision: intArg(args, "revision")})
+				if err != nil {
+					return nil, err
+				}
+				return res.Plan, nil
+			})},
+		"deletePlan": {Type: graphql.Boolean, Args: deleteArgs, Description: "Deletes a plan.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				err := c.plan.DeletePlan(&handle.DeletePlanRequest{Id: args["id"].(string), Revision: intArg(args, "revision")})
+				return err == nil, err
+			})},
+
+		"createPlanner": {Type: plannerType, Description: "Creates a planner, owned by the caller unless a userId is given.",
+			Args: []*graphql.Argument{
+				{Name: "title", Type: graphql.NonNullOf(graphql.String)},
+				{Name: "userId", Type: graphql.String},
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				req := &handle.CreatePlannerRequest{Title: stringArg(args, "title"), UserId: stringArg(args, "userId")}
+				if req.UserId == "" {
+					req.UserId = c.caller
+				}
+				res, err := c.planner.CreatePlanner(req)
+				if err != nil {
+					return nil, err
+				}
+				return c.planner.GetPlanner(&handle.GetPlannerRequest{Id: res.Id})
+			})},
+		"updatePlanner": {Type: plannerType, Description: "Changes the given fields of a planner.",
+			Args: []*graphql.Argument{id,
+				{Name: "title", Type: graphql.String},
+				{Name: "userId", Type: graphql.String},
+				revision,
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				patch, err := mergePatch(args, map[string]string{"title": "title", "userId": "user_id"})
+				if err != nil {
+					return nil, err
+				}
+				return c.planner.PatchPlanner(&handle.PatchPlannerRequest{Id: args["id"].(string), Patch: patch, Revision: intArg(args, "revision")})
+			})},
+		"deletePlanner": {Type: graphql.Boolean, Args: deleteArgs, Description: "Deletes a planner.",
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				err := c.planner.DeletePlanner(&handle.DeletePlannerRequest{Id: args["id"].(string), Revision: intArg(args, "revision")})
+				return err == nil, err
+			})},
+		"sharePlanner": {Type: plannerType, Description: "Gives a user a role in a planner, or removes the user from its members without a role.",
+			Args: []*graphql.Argument{id,
+				{Name: "user", Type: graphql.NonNullOf(graphql.String)},
+				{Name: "role", Type: graphql.String, Description: "owner, editor or viewer."},
+				revision,
+			},
+			Resolve: root(func(c *graphqlControls, args map[string]interface{}) (interface{}, error) {
+				return c.planner.SharePlanner(&handle.SharePlannerRequest{
+					Id:       args["id"].(string),
+					User:     stringArg(args, "user"),
+					Role:     stringArg(args, "role"),
+					Revision: intArg(args, "revision"),
+				})
+			})},
+	}}
+}
+
+// root returns the resolver of a field of the query or mutation type, which has a single nil source.
+func root(fn func(c *graphqlControls, args map[string]interface{}) (interface{}, error)) graphql.ResolveFunc {
+	return func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		value, err := fn(controlsOf(ctx), args)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	}
+}
+
+// toOne returns the resolver of a relationship to the record whose id is foreignKey of each source.
+// The records of every source are loaded with one call to load; sources whose record is missing or hidden resolve to null.
+func toOne[S, T any](foreignKey func(S) string, load func(c *graphqlControls, ids []string) ([]T, error), key func(T) string) graphql.ResolveFunc {
+	return func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		var ids []string
+		for _, source := range sources {
+			ids = append(ids, foreignKey(source.(S)))
+		}
+		records, err := load(controlsOf(ctx), uniqueIds(ids))
+		if err != nil {
+			return nil, err
+		}
+		byId := make(map[string]T, len(records))
+		for _, record := range records {
+			byId[key(record)] = record
+		}
+		values := make([]interface{}, len(sources))
+		for i, id := range ids {
+			if record, ok := byId[id]; ok && id != "" {
+				values[i] = record
+			}
+		}
+		return values, nil
+	}
+}
+
+// toMany returns the resolver of a relationship to the records whose foreignKey is the id of each source.
+// The records of every source are loaded with one call to load.
+func toMany[S, T any](id func(S) string, load func(c *graphqlControls, ids []string) ([]T, error), foreignKey func(T) string) graphql.ResolveFunc {
+	return func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		ids := make([]string, len(sources))
+		for i, source := range sources {
+			ids[i] = id(source.(S))
+		}
+		records, err := load(controlsOf(ctx), uniqueIds(ids))
+		if err != nil {
+			return nil, err
+		}
+		byKey := map[string][]T{}
+		for _, record := range records {
+			byKey[foreignKey(record)] = append(byKey[foreignKey(record)], record)
+		}
+		values := make([]interface{}, len(sources))
+		for i, id := range ids {
+			values[i] = append([]T{}, byKey[id]...)
+		}
+		return values, nil
+	}
+}
+
+// The loaders of the relationships. Each loads the records related to a batch of ids with one call to a control;
+// the lists of related records are in the order the records were created.
+
+func loadPlanners(c *graphqlControls, ids []string) ([]*handle.GetPlannerResponse, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	res, err := c.planner.FindPlanners(&handle.ListRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	return res.Planners, nil
+}
+
+func loadGoals(c *graphqlControls, ids []string) ([]*models.Goal, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	res, err := c.goal.FindGoals(&handle.ListRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	return res.Goals, nil
+}
+
+func loadPlannerGoals(c *graphqlControls, plannerIds []string) ([]*models.Goal, error) {
+	if len(plannerIds) == 0 {
+		return nil, nil
+	}
+	res, err := c.goal.FindGoals(&handle.ListRequest{PlannerIds: plannerIds, Sort: "goal_created_at"})
+	if err != nil {
+		return nil, err
+	}
+	return res.Goals, nil
+}
+
+func loadPlans(c *graphqlControls, ids []string) ([]*models.Plan, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	res, err := c.plan.FindPlans(&handle.ListRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plans, nil
+}
+
+func loadGoalPlans(c *graphqlControls, goalIds []string) ([]*models.Plan, error) {
+	if len(goalIds) == 0 {
+		return nil, nil
+	}
+	res, err := c.plan.FindPlans(&handle.ListRequest{GoalIds: goalIds, Sort: "plan_created_at"})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plans, nil
+}
+
+func loadPlanTasks(c *graphqlControls, planIds []string) ([]*handle.GetTaskResponse, error) {
+	if len(planIds) == 0 {
+		return nil, nil
+	}
+	res, err := c.task.FindTasks(&handle.ListRequest{PlanIds: planIds, Sort: "createdAt"})
+	if err != nil {
+		return nil, err
+	}
+	return res.Tasks, nil
+}
+
+func plannerId(p *handle.GetPlannerResponse) string { return p.Id }
+func goalId(g *models.Goal) string                  { return g.Id }
+func planId(p *models.Plan) string                  { return p.Id }
+
+func taskProperty(fn func(*handle.GetTaskResponse) interface{}) graphql.ResolveFunc {
+	return graphql.Property(func(source interface{}) interface{} { return fn(source.(*handle.GetTaskResponse)) })
+}
+
+func goalProperty(fn func(*models.Goal) interface{}) graphql.ResolveFunc {
+	return graphql.Property(func(source interface{}) interface{} { return fn(source.(*models.Goal)) })
+}
+
+func planProperty(fn func(*models.Plan) interface{}) graphql.ResolveFunc {
+	return graphql.Property(func(source interface{}) interface{} { return fn(source.(*models.Plan)) })
+}
+
+func plannerProperty(fn func(*handle.GetPlannerResponse) interface{}) graphql.ResolveFunc {
+	return graphql.Property(func(source interface{}) interface{} { return fn(source.(*handle.GetPlannerResponse)) })
+}
+
+// plannerMember is a member of a planner as returned by the members field.
+type plannerMember struct {
+	User string
+	Role string
+}
+
+// sortedUsers returns the users of a planner's members in alphabetical order.
+func sortedUsers(members map[string]string) []string {
+	users := make([]string, 0, len(members))
+	for user := range members {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// mergePatch builds a JSON Merge Patch from the arguments of an update mutation,
+// renaming them to the json names of the record with fields. Arguments that are not given are left out.
+func mergePatch(args map[string]interface{}, fields map[string]string) (json.RawMessage, error) {
+	patch := map[string]interface{}{}
+	for arg, name := range fields {
+		if value, ok := args[arg]; ok {
+			patch[name] = value
+		}
+	}
+	return json.Marshal(patch)
+}
+
+// uniqueIds returns the non-empty ids of a list without duplicates.
+func uniqueIds(ids []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// optional returns nil for an empty string, so that it is null in the response.
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+func intArg(args map[string]interface{}, name string) int {
+	n, _ := args[name].(int)
+	return n
+}
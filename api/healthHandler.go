@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// HealthHandler serves the liveness and readiness probes of the server.
+// Ready reports whether the server can serve requests, such as whether its database is open and writable.
+type HealthHandler struct {
+	Ready func() error
+}
+
+// Healthz responds with 200 OK as long as the server is running and handling requests.
+//
+// Example:
+//
+//	GET /healthz
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintln(w, "ok")
+}
+
+// Readyz responds with 200 OK if the server is ready to serve requests, and with 503 Service Unavailable
+// and the reason otherwise, so that a load balancer stops sending it requests.
+//
+// Example:
+//
+//	GET /readyz
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.Ready != nil {
+		if err := h.Ready(); err != nil {
+			http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+			log.Printf("Error due to: not ready: %s", err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintln(w, "ok")
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/store"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxListLimit caps the page size a client may request from a list endpoint.
+const maxListLimit = 500
+
+// listRequest builds a handle.ListRequest from the query string of a list endpoint.
+// Supported parameters are limit, cursor, sort, owner, status, deadline_before, planner_id and goal_id.
+// It returns an error if limit is not a positive number.
+//
+// Example:
+//
+//	GET /listgoals?limit=20&status=In%20Progress&deadline_before=2024-06-01&sort=-deadline
+func listRequest(r *http.Request) (*handle.ListRequest, error) {
+	query := r.URL.Query()
+	req := &handle.ListRequest{
+		Cursor:         query.Get("cursor"),
+		Sort:           query.Get("sort"),
+		Owner:          query.Get("owner"),
+		Status:         query.Get("status"),
+		DeadlineBefore: query.Get("deadline_before"),
+		PlannerId:      query.Get("planner_id"),
+		GoalId:         query.Get("goal_id"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+		if n > maxListLimit {
+			n = maxListLimit
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+// listErrorStatus returns the HTTP status code for an error returned by a Find* control method.
+// Errors caused by the list parameters are reported as 400 Bad Request, anything else as 500 Internal Server Error.
+func listErrorStatus(err error) int {
+	var parseErr *time.ParseError
+	if errors.Is(err, handle.ErrInvalidCursor) || errors.Is(err, store.ErrInvalidSort) || errors.As(err, &parseErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// setNextCursor exposes the cursor of the next page in the X-Next-Cursor response header.
+func setNextCursor(w http.ResponseWriter, cursor string) {
+	if cursor != "" {
+		w.Header().Set("X-Next-Cursor", cursor)
+	}
+}
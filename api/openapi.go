@@ -0,0 +1,290 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/ref"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document conforms to.
+const openAPIVersion = "3.1.0"
+
+// apiVersion is the version of the API described by the document.
+const apiVersion = "1.0.0"
+
+// docsPage is a self-contained page that renders the document served at /openapi.json.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// pathParamPattern matches the {name} parameters of a mux path template.
+var pathParamPattern = regexp.MustCompile(`{([^}:]+)(?::[^}]+)?}`)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// object is a JSON object of the OpenAPI document.
+type object = map[string]interface{}
+
+// OpenAPI builds an OpenAPI 3.1 document describing the routes.
+// The schemas of the request and response bodies are derived from the Go types in the route table,
+// using the same json tags encoding/json uses, so they follow any change to the types in pkg/handle.
+func OpenAPI(routes []Route) object {
+	schemas := object{}
+	paths := object{}
+	for _, route := range routes {
+		path := pathParamPattern.ReplaceAllString(route.Path, "{$1}")
+		item, ok := paths[path].(object)
+		if !ok {
+			item = object{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation(route, schemas)
+	}
+	return object{
+		"openapi": openAPIVersion,
+		"info": object{
+			"title":       "Flow API",
+			"description": "Tasks, goals, plans and planners of a flow server.",
+			"version":     apiVersion,
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas,
+			"securitySchemes": object{
+				"bearerToken": object{"type": "http", "scheme": "bearer", "description": "An API token created with flow token create."},
+				"apiKey":      object{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+		"security": []object{{"bearerToken": []string{}}, {"apiKey": []string{}}},
+	}
+}
+
+// operation builds the OpenAPI operation object of a route, adding the schemas it refers to.
+func operation(route Route, schemas object) object {
+	var params []object
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		param := object{"name": match[1], "in": "path", "required": true, "schema": object{"type": "string"}}
+		if match[1] == "id" && route.Resolve != nil {
+			param["description"] = fmt.Sprintf(`The id of the record, a unique prefix of it at least %d characters long, or @"name".`, ref.MinPrefix)
+		}
+		params = append(params, param)
+	}
+	if route.List {
+		params = append(params, listParameters()...)
+	}
+	if route.Stream {
+		params = append(params, streamParameters()...)
+	}
+	if route.Search {
+		params = append(params, searchParameters()...)
+	}
+	if route.Conditional {
+		params = append(params, object{
+			"name":        "If-Match",
+			"in":          "header",
+			"description": "Only change the resource if it is still at this revision, as returned in the ETag header.",
+			"schema":      object{"type": "string"},
+		})
+	}
+
+	ok := object{"description": "OK"}
+	headers := object{}
+	if route.ETag {
+		headers["ETag"] = object{"description": "The revision of the resource.", "schema": object{"type": "string"}}
+	}
+	if route.List {
+		headers["X-Next-Cursor"] = object{"description": "The cursor of the next page, if there is one.", "schema": object{"type": "string"}}
+	}
+	if len(headers) > 0 {
+		ok["headers"] = headers
+	}
+	if route.Response != nil {
+		contentType := "application/json"
+		if route.Stream {
+			contentType = eventStreamContentType
+		}
+		ok["content"] = object{contentType: object{"schema": schemaOf(reflect.TypeOf(route.Response), schemas)}}
+	}
+	responses := object{
+		"200": ok,
+		"default": object{
+			"description": "The error message.",
+			"content":     object{"text/plain": object{"schema": object{"type": "string"}}},
+		},
+	}
+	responses["401"] = object{"description": "The request has no valid API token."}
+	responses["403"] = object{"description": "The caller lacks the role the request requires in the planner of the resource."}
+	if route.Conditional {
+		responses["412"] = object{"description": "The resource is no longer at the revision given in If-Match."}
+	}
+
+	op := object{
+		"operationId": route.OperationID,
+		"summary":     route.Summary,
+		"tags":        []string{route.Tag},
+		"responses":   responses,
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if route.Request != nil {
+		contentType := "application/json"
+		schema := schemaOf(reflect.TypeOf(route.Request), schemas)
+		if route.MergePatch {
+			// every member of a merge patch is optional, so the schema of the resource is inlined without required
+			contentType = mergePatchContentType
+			schema = structSchema(reflect.TypeOf(route.Request), schemas)
+			delete(schema, "required")
+		}
+		op["requestBody"] = object{
+			"required": true,
+			"content":  object{contentType: object{"schema": schema}},
+		}
+	}
+	return op
+}
+
+// listParameters describes the query parameters read by listRequest.
+func listParameters() []object {
+	query := func(name, typ, description string) object {
+		return object{"name": name, "in": "query", "description": description, "schema": object{"type": typ}}
+	}
+	limit := query("limit", "integer", "The maximum number of records in the page.")
+	limit["schema"] = object{"type": "integer", "minimum": 1, "maximum": maxListLimit}
+	deadline := query("deadline_before", "string", "Only list goals due before this date.")
+	deadline["schema"] = object{"type": "string", "format": "date"}
+	return []object{
+		limit,
+		query("cursor", "string", "The X-Next-Cursor of the previous page."),
+		query("sort", "string", "The field to order by, prefixed with - for descending order."),
+		query("owner", "string", "Only list records owned by this user."),
+		query("status", "string", "Only list records with this status."),
+		deadline,
+		query("planner_id", "string", "Only list goals of this planner."),
+		query("goal_id", "string", "Only list plans of this goal."),
+	}
+}
+
+// streamParameters describes the parameters read by streamEventsRequest.
+func streamParameters() []object {
+	parameter := func(name, in, description string) object {
+		return object{"name": name, "in": in, "description": description, "schema": object{"type": "string"}}
+	}
+	return []object{
+		parameter("planner_id", "query", "Only stream events about records of this planner."),
+		parameter("type", "query", "A comma separated list of event types, such as task.created, or resources, such as goal."),
+		parameter("Last-Event-ID", "header", "Resume after the event with this id, sending the logged events that were missed."),
+		parameter("last_event_id", "query", "The same as the Last-Event-ID header, for clients that cannot set it."),
+	}
+}
+
+// searchParameters describes the query parameters read by searchRequest.
+func searchParameters() []object {
+	q := object{"name": "q", "in": "query", "required": true, "description": "The words to search for.", "schema": object{"type": "string"}}
+	types := object{"name": "type", "in": "query", "description": "A comma separated list of the types to search: task, goal, plan and chat.", "schema": object{"type": "string"}}
+	limit := object{"name": "limit", "in": "query", "description": "The maximum number of results.", "schema": object{"type": "integer", "minimum": 1, "maximum": maxListLimit}}
+	return []object{q, types, limit}
+}
+
+// schemaOf returns the JSON schema of values of type t as encoded by encoding/json.
+// Named struct types are added to schemas and referenced by name.
+func schemaOf(t reflect.Type, schemas object) object {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return object{"type": "string", "format": "date-time"}
+	case t == rawType:
+		return object{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return object{"type": "string", "contentEncoding": "base64"}
+		}
+		return object{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		ref := object{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; !ok {
+			// register the name first so that recursive types terminate
+			schemas[t.Name()] = object{}
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return ref
+	}
+	return object{}
+}
+
+// structSchema returns the object schema of a struct type from the json tags of its exported fields.
+// Fields without omitempty are always encoded and are therefore listed as required.
+func structSchema(t reflect.Type, schemas object) object {
+	properties := object{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// OpenAPIHandler serves the OpenAPI document of the routes as JSON.
+func OpenAPIHandler(routes []Route) http.HandlerFunc {
+	doc, err := json.MarshalIndent(OpenAPI(routes), "", "  ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			handleError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(doc)
+		handleError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// DocsHandler serves an interactive page documenting the API from /openapi.json.
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write(docsPage)
+	handleError(w, err, http.StatusInternalServerError)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// mergePatchContentType is the media type of a JSON Merge Patch document (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+// maxPatchSize limits the size of a merge patch request body.
+const maxPatchSize = 1 << 20
+
+// errUnsupportedPatchType is returned by readPatch when the request body is not a merge patch.
+var errUnsupportedPatchType = errors.New("content type must be " + mergePatchContentType + " or application/json")
+
+// readPatch reads the merge patch from the body of a PATCH request.
+// The Content-Type must be application/merge-patch+json or application/json; a missing Content-Type is accepted.
+func readPatch(r *http.Request) ([]byte, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return nil, errUnsupportedPatchType
+		}
+	}
+	return io.ReadAll(io.LimitReader(r.Body, maxPatchSize))
+}
+
+// writeErrorStatus returns the HTTP status code for an error returned while updating, patching or deleting a resource.
+// A failed If-Match precondition is reported as 412 Precondition Failed, an invalid planner member as 400 Bad Request
+// and a restore from the trash under a parent that is still in it as 409 Conflict.
+func writeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrRevisionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, store.ErrParentInTrash):
+		return http.StatusConflict
+	case errors.Is(err, errUnsupportedPatchType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, handle.ErrInvalidPatch), errors.Is(err, services.ErrInvalidMember),
+		errors.Is(err, services.ErrInvalidWebhook), errors.Is(err, handle.ErrInvalidBatch),
+		errors.Is(err, handle.ErrInvalidUndo), errors.Is(err, handle.ErrInvalidTrash):
+		return http.StatusBadRequest
+	case errors.Is(err, storm.ErrNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
 	"net/http"
 )
 
@@ -38,6 +39,17 @@ import (
 // It calls the ListPlans method on PlanControl and returns the retrieved plans or any errors that occur during the process.
 type PlanHandler struct {
 	Control *handle.PlanControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it acts for the authenticated caller
+// and only reaches the plans of the planners shared with them.
+func (h *PlanHandler) control(r *http.Request) *handle.PlanControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
 }
 
 // CreatePlan takes an HTTP response writer and request as input.
@@ -55,7 +67,7 @@ func (h *PlanHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
 	var req handle.CreatePlanRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	handleError(w, err, http.StatusBadRequest)
-	res, err := h.Control.CreatePlan(&req)
+	res, err := h.control(r).CreatePlan(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -64,14 +76,19 @@ func (h *PlanHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
 // GetPlan takes an HTTP response writer and request as input.
 // It retrieves the "id" parameter from the URL, creates a GetPlanRequest,
 // and sends it to the PlanControl's GetPlan method to retrieve the plan information.
+// The revision of the plan is sent in the ETag header.
 // The function then encodes the response using JSON and writes it to the HTTP response writer.
 // If any error occurs during the process, it is handled by the handleError function.
 func (h *PlanHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	req := handle.GetPlanRequest{Id: id}
-	res, err := h.Control.GetPlan(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	res, err := h.control(r).GetPlan(&req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setETag(w, res.Plan.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
@@ -81,7 +98,7 @@ func (h *PlanHandler) GetPlanByName(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	planName := vars["plan_name"]
 	req := handle.GetPlanByNameRequest{PlanName: planName}
-	res, err := h.Control.GetPlanByName(&req)
+	res, err := h.control(r).GetPlanByName(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -98,45 +115,99 @@ func (h *PlanHandler) GetPlansByGoal(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	goalId := vars["goal_id"]
 	req := handle.GetPlansByGoalRequest{GoalId: goalId}
-	res, err := h.Control.GetPlansByGoal(&req)
+	res, err := h.control(r).GetPlansByGoal(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
 
-// UpdatePlan updates an existing plan
+// UpdatePlan updates an existing plan, responding 412 if the If-Match header does not match its revision
 func (h *PlanHandler) UpdatePlan(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	var req handle.UpdatePlanRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
-	handleError(w, err, http.StatusBadRequest)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
 
 	req.Id = id // Ensure the ID from the URL is used
+	if err := ifMatch(r, &req.Revision); err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
-	err = h.Control.UpdatePlan(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	err = h.control(r).UpdatePlan(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-// DeletePlan deletes a plan by its ID
+// DeletePlan deletes a plan by its ID, responding 412 if the If-Match header does not match its revision
 func (h *PlanHandler) DeletePlan(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	req := handle.DeletePlanRequest{Id: id}
-	err := h.Control.DeletePlan(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.DeletePlanRequest{Id: id, Revision: revision}
+	err = h.control(r).DeletePlan(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
-// ListPlans fetches a list of plans
+// ListPlans fetches a page of plans filtered by the status and goal_id query parameters,
+// ordered by sort and paged by limit and cursor,
 // and encodes them as JSON before returning the response
 // to the HTTP client
 func (h *PlanHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
-	res, err := h.Control.ListPlans()
+	req, err := listRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).FindPlans(req)
+	if err != nil {
+		handleError(w, err, listErrorStatus(err))
+		return
+	}
+	setNextCursor(w, res.NextCursor)
+	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
+}
+
+// PatchPlan partially updates a plan with the JSON Merge Patch in the request body
+// and responds with the updated plan and its new revision in the ETag header.
+// An If-Match header that does not match the revision of the plan results in 412 Precondition Failed
+func (h *PlanHandler) PatchPlan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	patch, err := readPatch(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.PatchPlanRequest{Id: mux.Vars(r)["id"], Patch: patch, Revision: revision}
+	res, err := h.control(r).PatchPlan(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	setETag(w, res.Plan.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
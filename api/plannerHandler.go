@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
 	"net/http"
 )
 
@@ -18,11 +20,23 @@ import (
 // - DeletePlanner: handles the deletion of a planner.
 type PlannerHandler struct {
 	Control *handle.PlannerControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it acts for the authenticated caller
+// and only reaches the planners of the planners shared with them.
+func (h *PlannerHandler) control(r *http.Request) *handle.PlannerControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
 }
 
 // CreatePlanner takes an HTTP response writer and request as input.
 // It decodes the request body into a CreatePlannerRequest object.
 // If any error occurs during the decoding, it is handled by the handleError function.
+// A planner without a user ID belongs to the authenticated caller.
 // It then sends the CreatePlannerRequest to the PlannerControl's CreatePlanner method to create a new planner.
 // If any error occurs during the creation process, it is handled by the handleError function.
 // Finally, it encodes the response using JSON and writes it to the HTTP response writer.
@@ -32,7 +46,10 @@ func (h *PlannerHandler) CreatePlanner(w http.ResponseWriter, r *http.Request) {
 	var req handle.CreatePlannerRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	handleError(w, err, http.StatusBadRequest)
-	res, err := h.Control.CreatePlanner(&req)
+	if req.UserId == "" {
+		req.UserId = callerName(r)
+	}
+	res, err := h.control(r).CreatePlanner(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -43,6 +60,7 @@ func (h *PlannerHandler) CreatePlanner(w http.ResponseWriter, r *http.Request) {
 // It extracts the "id" variable from the request's route parameters.
 // It creates a GetPlannerRequest with the extracted id.
 // It sends the GetPlannerRequest to the PlannerControl's GetPlanner method to retrieve the planner.
+// It sets the ETag header to the revision of the planner.
 // If any error occurs during the retrieval process, it is handled by the handleError function.
 // It encodes the response using JSON and writes it to the HTTP response writer.
 // If any error occurs during the encoding process, it is handled by the handleError function.
@@ -53,8 +71,12 @@ func (h *PlannerHandler) GetPlanner(w http.ResponseWriter, r *http.Request) {
 	req := &handle.GetPlannerRequest{
 		Id: id,
 	}
-	res, err := h.Control.GetPlanner(req)
-	handleError(w, err, http.StatusInternalServerError)
+	res, err := h.control(r).GetPlanner(req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setETag(w, res.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
@@ -73,7 +95,7 @@ func (h *PlannerHandler) GetPlannerByTitle(w http.ResponseWriter, r *http.Reques
 	req := &handle.GetPlannerByTitleRequest{
 		Title: title,
 	}
-	res, err := h.Control.GetPlannerByTitle(req)
+	res, err := h.control(r).GetPlannerByTitle(req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -94,7 +116,7 @@ func (h *PlannerHandler) GetPlannerByOwner(w http.ResponseWriter, r *http.Reques
 	req := &handle.GetPlannerByOwnerRequest{
 		UserId: owner,
 	}
-	res, err := h.Control.GetPlannerByOwner(req)
+	res, err := h.control(r).GetPlannerByOwner(req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -106,7 +128,9 @@ func (h *PlannerHandler) GetPlannerByOwner(w http.ResponseWriter, r *http.Reques
 // It creates an UpdatePlannerRequest object with the "id" parameter set.
 // It decodes the request body into the UpdatePlannerRequest object.
 // If any error occurs during the decoding, it is handled by the handleError function.
+// An If-Match header overrides the revision in the body.
 // It sends the UpdatePlannerRequest to the PlannerControl's UpdatePlanner method to update the planner.
+// A revision mismatch is reported as 412 (Precondition Failed).
 // If any error occurs during the update process, it is handled by the handleError function.
 // It sets the HTTP status code to 200 (OK).
 func (h *PlannerHandler) UpdatePlanner(w http.ResponseWriter, r *http.Request) {
@@ -117,42 +141,156 @@ func (h *PlannerHandler) UpdatePlanner(w http.ResponseWriter, r *http.Request) {
 		Id: id,
 	}
 	err := json.NewDecoder(r.Body).Decode(&req)
-	handleError(w, err, http.StatusBadRequest)
-	err = h.Control.UpdatePlanner(req)
-	handleError(w, err, http.StatusInternalServerError)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := ifMatch(r, &req.Revision); err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = h.control(r).UpdatePlanner(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
 // DeletePlanner takes an HTTP response writer and request as input.
 // It sets the "Content-Type" header of the response to "application/json".
 // It gets the "id" parameter from the request URL using the mux.Vars() function.
-// It creates a DeletePlannerRequest object with the extracted id and passes it to h.Control.DeletePlanner().
+// It creates a DeletePlannerRequest object with the extracted id and the revision from the If-Match header, if any,
+// and passes it to h.Control.DeletePlanner(). A revision mismatch is reported as 412 (Precondition Failed).
 // If any error occurs during the deletion process, it is handled by the handleError function.
 // It sets the HTTP response writer status code to 200 (OK).
 func (h *PlannerHandler) DeletePlanner(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
 	id := vars["id"]
-	err := h.Control.DeletePlanner(&handle.DeletePlannerRequest{
-		Id: id,
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = h.control(r).DeletePlanner(&handle.DeletePlannerRequest{
+		Id:       id,
+		Revision: revision,
 	})
-	handleError(w, err, http.StatusInternalServerError)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
 // ListPlanners takes an HTTP response writer and request as input.
 // It sets the "Content-Type" header of the response to "application/json".
-// It then calls the ListPlanners method of the PlannerControl to retrieve a list of planners.
+// It converts the query string (limit, cursor, sort and owner) into a ListRequest.
+// It then calls the FindPlanners method of the PlannerControl to retrieve a page of planners.
 // If any error occurs during the retrieval process, it is handled by the handleError function.
 // Finally, it encodes the response using JSON and writes it to the HTTP response writer.
 // If any error occurs during the encoding process, it is handled by the handleError function.
 func (h *PlannerHandler) ListPlanners(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	res, err := h.Control.ListPlanners()
+	req, err := listRequest(r)
 	if err != nil {
-		handleError(w, err, http.StatusInternalServerError)
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).FindPlanners(req)
+	if err != nil {
+		handleError(w, err, listErrorStatus(err))
+		return
+	}
+	setNextCursor(w, res.NextCursor)
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// PatchPlanner takes an HTTP response writer and request as input.
+// It sets the "Content-Type" header of the response to "application/json".
+// It reads the JSON Merge Patch (RFC 7396) from the request body and sends it, together with the "id" parameter
+// from the request URL, to the PlannerControl's PatchPlanner method.
+// Only the fields present in the patch are changed.
+// Errors are handled by the handleError function with the status code given by writeErrorStatus.
+// It checks the If-Match header against the revision of the planner and sets the ETag header to the new revision.
+// Finally, it encodes the updated planner using JSON and writes it to the HTTP response writer.
+func (h *PlannerHandler) PatchPlanner(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	patch, err := readPatch(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := &handle.PatchPlannerRequest{
+		Id:       mux.Vars(r)["id"],
+		Patch:    patch,
+		Revision: revision,
+	}
+	res, err := h.control(r).PatchPlanner(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	setETag(w, res.Revision)
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// SharePlanner gives the user named in the URL the role in the request body in the planner with the given id.
+// The revision is taken from the If-Match header, if any, and the updated planner is returned with its ETag.
+// Only owners of the planner may share it; other callers get 403 (Forbidden).
+//
+// Example:
+//
+//	PUT /api/v1/planners/123/members/bob
+//	{"role": "editor"}
+func (h *PlannerHandler) SharePlanner(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	var req handle.SharePlannerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		handleError(w, errors.New("missing role"), http.StatusBadRequest)
+		return
+	}
+	req.Id = vars["id"]
+	req.User = vars["user"]
+	h.share(w, r, &req)
+}
+
+// UnsharePlanner removes the user named in the URL from the members of the planner with the given id.
+// The revision is taken from the If-Match header, if any, and the updated planner is returned with its ETag.
+func (h *PlannerHandler) UnsharePlanner(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	h.share(w, r, &handle.SharePlannerRequest{
+		Id:   vars["id"],
+		User: vars["user"],
+	})
+}
+
+// share applies a SharePlannerRequest for SharePlanner and UnsharePlanner.
+func (h *PlannerHandler) share(w http.ResponseWriter, r *http.Request, req *handle.SharePlannerRequest) {
+	if err := ifMatch(r, &req.Revision); err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	res, err := h.control(r).SharePlanner(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
 		return
 	}
+	setETag(w, res.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
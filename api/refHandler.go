@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/ref"
+	"net/http"
+)
+
+// resolveID returns a handler that replaces the {id} of the path with the id resolve returns for it,
+// such as for a unique prefix of the id or @"name", before next serves the request.
+// A reference that matches several records is answered with 400 Bad Request and the list of the records,
+// one that matches none with 404 Not Found.
+func resolveID(next http.HandlerFunc, resolve func(r *http.Request, ref string) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := resolve(r, vars["id"])
+		if err != nil {
+			var ambiguous *ref.AmbiguousError
+			if errors.As(err, &ambiguous) {
+				handleError(w, err, http.StatusBadRequest)
+				return
+			}
+			handleError(w, err, http.StatusInternalServerError)
+			return
+		}
+		vars["id"] = id
+		next(w, mux.SetURLVars(r, vars))
+	}
+}
+
+// resolveID returns the id of the task ref refers to, among those the caller can see.
+func (h *TaskHandler) resolveID(r *http.Request, ref string) (string, error) {
+	return h.control(r).ResolveTask(ref)
+}
+
+// resolveID returns the id of the goal ref refers to, among those the caller can see.
+func (h *GoalHandler) resolveID(r *http.Request, ref string) (string, error) {
+	return h.control(r).ResolveGoal(ref)
+}
+
+// resolveID returns the id of the plan ref refers to, among those the caller can see.
+func (h *PlanHandler) resolveID(r *http.Request, ref string) (string, error) {
+	return h.control(r).ResolvePlan(ref)
+}
+
+// resolveID returns the id of the planner ref refers to, among those the caller can see.
+func (h *PlannerHandler) resolveID(r *http.Request, ref string) (string, error) {
+	return h.control(r).ResolvePlanner(ref)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"github.com/ooyeku/flow/pkg/store"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setETag exposes the revision of a resource as a strong entity tag in the ETag response header.
+func setETag(w http.ResponseWriter, revision int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(revision)))
+}
+
+// ifMatchRevision returns the revision a PUT, PATCH or DELETE request expects the resource to be at,
+// taken from its If-Match header. A missing header or "*" returns store.AnyRevision.
+// An If-Match that is not an ETag written by setETag can never match, so store.ErrRevisionMismatch is returned.
+//
+// Example:
+//
+//	PATCH /api/v1/goals/123
+//	If-Match: "4"
+func ifMatchRevision(r *http.Request) (int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return store.AnyRevision, nil
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	value, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, store.ErrRevisionMismatch
+	}
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		return 0, store.ErrRevisionMismatch
+	}
+	return revision, nil
+}
+
+// ifMatch overrides the revision of a PUT request body with the one given in the If-Match header, if any.
+// A body without a revision and a request without If-Match update the resource unconditionally.
+func ifMatch(r *http.Request, revision *int) error {
+	expected, err := ifMatchRevision(r)
+	if err != nil {
+		return err
+	}
+	if expected != store.AnyRevision {
+		*revision = expected
+	}
+	return nil
+}
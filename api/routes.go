@@ -0,0 +1,185 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/graphql"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+)
+
+// Route describes one endpoint of the server.
+// The same table is used to register the endpoints with the router and to build the OpenAPI document,
+// so the document always lists exactly the routes that are served.
+//
+// Fields:
+// - Method, Path: the HTTP method and the mux path template of the endpoint.
+// - OperationID: the name of the operation, which is also the name of the matching pkg/client method.
+// - Summary, Tag: a one-line description and the resource the endpoint belongs to.
+// - Handler: the handler that serves the endpoint.
+// - Request: a zero value of the JSON request body, or nil if the endpoint has no body.
+// - Response: a zero value of the JSON response body, or nil if the endpoint responds without a body.
+// - List: the endpoint accepts the list query parameters and may set the X-Next-Cursor header.
+// - Conditional: the endpoint honors the If-Match header.
+// - ETag: the endpoint sets the ETag header to the revision of the resource.
+// - MergePatch: the request body is a JSON Merge Patch of Request.
+// - Stream: the endpoint responds with a stream of Server-Sent Events whose data is Response.
+// - Search: the endpoint accepts the search query parameters.
+// - Resolve: if set, the {id} of the path may also be a reference to the record, a unique prefix of its id
+// or @"name" (see pkg/ref), which Resolve turns into the id before Handler serves the request.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Handler     http.HandlerFunc
+	Request     interface{}
+	Response    interface{}
+	List        bool
+	Conditional bool
+	ETag        bool
+	MergePatch  bool
+	Stream      bool
+	Search      bool
+	Resolve     func(r *http.Request, ref string) (string, error)
+}
+
+// Handlers groups the handlers of every resource served by the API.
+type Handlers struct {
+	Task    *TaskHandler
+	Goal    *GoalHandler
+	Plan    *PlanHandler
+	Planner *PlannerHandler
+	Event   *EventHandler
+	Webhook *WebhookHandler
+	GraphQL *GraphQLHandler
+	Batch   *BatchHandler
+	Search  *SearchHandler
+	Undo    *UndoHandler
+	Trash   *TrashHandler
+}
+
+// Routes returns the route table of the API for the given handlers.
+func Routes(h *Handlers) []Route {
+	return []Route{
+		{Method: "GET", Path: "/listtasks", OperationID: "ListTasks", Summary: "List tasks", Tag: "tasks",
+			Handler: h.Task.ListTasks, Response: []*handle.GetTaskResponse{}, List: true},
+		{Method: "POST", Path: "/task/new", OperationID: "CreateTask", Summary: "Create a task", Tag: "tasks",
+			Handler: h.Task.CreateTask, Request: handle.CreateTaskRequest{}, Response: handle.CreateTaskResponse{}},
+		{Method: "GET", Path: "/task/{id}", OperationID: "GetTask", Summary: "Get a task by id", Tag: "tasks",
+			Handler: h.Task.GetTask, Resolve: h.Task.resolveID, Response: handle.GetTaskResponse{}, ETag: true},
+		{Method: "GET", Path: "/task/title/{title}", OperationID: "GetTaskByTitle", Summary: "Get a task by title", Tag: "tasks",
+			Handler: h.Task.GetTaskByTitle, Response: handle.GetTaskResponse{}},
+		{Method: "GET", Path: "/task/owner/{owner}", OperationID: "GetTaskByOwner", Summary: "Get the tasks of an owner", Tag: "tasks",
+			Handler: h.Task.GetTaskByOwner, Response: []*handle.GetTaskResponse{}},
+		{Method: "PUT", Path: "/task/{id}", OperationID: "UpdateTask", Summary: "Replace a task", Tag: "tasks",
+			Handler: h.Task.UpdateTask, Resolve: h.Task.resolveID, Request: handle.UpdateTaskRequest{}, Conditional: true},
+		{Method: "DELETE", Path: "/task/{id}", OperationID: "DeleteTask", Summary: "Delete a task", Tag: "tasks",
+			Handler: h.Task.DeleteTask, Resolve: h.Task.resolveID, Conditional: true},
+
+		{Method: "GET", Path: "/listgoals", OperationID: "ListGoals", Summary: "List goals", Tag: "goals",
+			Handler: h.Goal.ListGoals, Response: handle.ListGoalsResponse{}, List: true},
+		{Method: "POST", Path: "/goal/new", OperationID: "CreateGoal", Summary: "Create a goal", Tag: "goals",
+			Handler: h.Goal.CreateGoal, Request: handle.CreateGoalRequest{}, Response: handle.CreateGoalResponse{}},
+		{Method: "GET", Path: "/goal/{id}", OperationID: "GetGoal", Summary: "Get a goal by id", Tag: "goals",
+			Handler: h.Goal.GetGoal, Resolve: h.Goal.resolveID, Response: handle.GetGoalResponse{}, ETag: true},
+		{Method: "GET", Path: "/goal/obj/{objective}", OperationID: "GetGoalByObjective", Summary: "Get a goal by objective", Tag: "goals",
+			Handler: h.Goal.GetGoalByObjective, Response: handle.GetGoalByObjectiveResponse{}},
+		{Method: "GET", Path: "/goal/pid/{planner_id}", OperationID: "GetGoalsByPlannerId", Summary: "Get the goals of a planner", Tag: "goals",
+			Handler: h.Goal.GetGoalsByPlannerIdRequest, Response: handle.GetGoalsByPlannerIdResponse{}},
+		{Method: "PUT", Path: "/goal/{id}", OperationID: "UpdateGoal", Summary: "Replace a goal", Tag: "goals",
+			Handler: h.Goal.UpdateGoal, Resolve: h.Goal.resolveID, Request: handle.UpdateGoalRequest{}, Conditional: true},
+		{Method: "DELETE", Path: "/goal/{id}", OperationID: "DeleteGoal", Summary: "Delete a goal", Tag: "goals",
+			Handler: h.Goal.DeleteGoal, Resolve: h.Goal.resolveID, Conditional: true},
+
+		{Method: "GET", Path: "/listplans", OperationID: "ListPlans", Summary: "List plans", Tag: "plans",
+			Handler: h.Plan.ListPlans, Response: handle.ListPlansResponse{}, List: true},
+		{Method: "POST", Path: "/plan/new", OperationID: "CreatePlan", Summary: "Create a plan", Tag: "plans",
+			Handler: h.Plan.CreatePlan, Request: handle.CreatePlanRequest{}, Response: handle.CreatePlanResponse{}},
+		{Method: "GET", Path: "/plan/{id}", OperationID: "GetPlan", Summary: "Get a plan by id", Tag: "plans",
+			Handler: h.Plan.GetPlan, Resolve: h.Plan.resolveID, Response: handle.GetPlanResponse{}, ETag: true},
+		{Method: "GET", Path: "/plan/name/{plan_name}", OperationID: "GetPlanByName", Summary: "Get a plan by name", Tag: "plans",
+			Handler: h.Plan.GetPlanByName, Response: handle.GetPlanByNameResponse{}},
+		{Method: "GET", Path: "/plan/goal/{goal_id}", OperationID: "GetPlansByGoal", Summary: "Get the plans of a goal", Tag: "plans",
+			Handler: h.Plan.GetPlansByGoal, Response: handle.GetPlansByGoalResponse{}},
+		{Method: "PUT", Path: "/plan/{id}", OperationID: "UpdatePlan", Summary: "Replace a plan", Tag: "plans",
+			Handler: h.Plan.UpdatePlan, Resolve: h.Plan.resolveID, Request: handle.UpdatePlanRequest{}, Conditional: true},
+		{Method: "DELETE", Path: "/plan/{id}", OperationID: "DeletePlan", Summary: "Delete a plan", Tag: "plans",
+			Handler: h.Plan.DeletePlan, Resolve: h.Plan.resolveID, Conditional: true},
+
+		{Method: "GET", Path: "/listplanners", OperationID: "ListPlanners", Summary: "List planners", Tag: "planners",
+			Handler: h.Planner.ListPlanners, Response: handle.ListPlannersResponse{}, List: true},
+		{Method: "POST", Path: "/planner/new", OperationID: "CreatePlanner", Summary: "Create a planner", Tag: "planners",
+			Handler: h.Planner.CreatePlanner, Request: handle.CreatePlannerRequest{}, Response: handle.CreatePlannerResponse{}},
+		{Method: "GET", Path: "/planner/{id}", OperationID: "GetPlanner", Summary: "Get a planner by id", Tag: "planners",
+			Handler: h.Planner.GetPlanner, Resolve: h.Planner.resolveID, Response: handle.GetPlannerResponse{}, ETag: true},
+		{Method: "GET", Path: "/planner/title/{title}", OperationID: "GetPlannerByTitle", Summary: "Get a planner by title", Tag: "planners",
+			Handler: h.Planner.GetPlannerByTitle, Response: handle.GetPlannerByTitleResponse{}},
+		{Method: "GET", Path: "/planner/owner/{owner}", OperationID: "GetPlannerByOwner", Summary: "Get the planners of an owner", Tag: "planners",
+			Handler: h.Planner.GetPlannerByOwner, Response: []*handle.GetPlannerByOwnerResponse{}},
+		{Method: "PUT", Path: "/planner/{id}", OperationID: "UpdatePlanner", Summary: "Replace a planner", Tag: "planners",
+			Handler: h.Planner.UpdatePlanner, Resolve: h.Planner.resolveID, Request: handle.UpdatePlannerRequest{}, Conditional: true},
+		{Method: "DELETE", Path: "/planner/{id}", OperationID: "DeletePlanner", Summary: "Delete a planner", Tag: "planners",
+			Handler: h.Planner.DeletePlanner, Resolve: h.Planner.resolveID, Conditional: true},
+
+		{Method: "PATCH", Path: "/api/v1/tasks/{id}", OperationID: "PatchTask", Summary: "Partially update a task", Tag: "tasks",
+			Handler: h.Task.PatchTask, Resolve: h.Task.resolveID, Request: models.Task{}, Response: handle.GetTaskResponse{}, Conditional: true, ETag: true, MergePatch: true},
+		{Method: "PATCH", Path: "/api/v1/goals/{id}", OperationID: "PatchGoal", Summary: "Partially update a goal", Tag: "goals",
+			Handler: h.Goal.PatchGoal, Resolve: h.Goal.resolveID, Request: models.Goal{}, Response: handle.GetGoalResponse{}, Conditional: true, ETag: true, MergePatch: true},
+		{Method: "PATCH", Path: "/api/v1/plans/{id}", OperationID: "PatchPlan", Summary: "Partially update a plan", Tag: "plans",
+			Handler: h.Plan.PatchPlan, Resolve: h.Plan.resolveID, Request: models.Plan{}, Response: handle.GetPlanResponse{}, Conditional: true, ETag: true, MergePatch: true},
+		{Method: "PATCH", Path: "/api/v1/planners/{id}", OperationID: "PatchPlanner", Summary: "Partially update a planner", Tag: "planners",
+			Handler: h.Planner.PatchPlanner, Resolve: h.Planner.resolveID, Request: models.Planner{}, Response: handle.GetPlannerResponse{}, Conditional: true, ETag: true, MergePatch: true},
+		{Method: "PUT", Path: "/api/v1/planners/{id}/members/{user}", OperationID: "SharePlanner", Summary: "Share a planner with a user", Tag: "planners",
+			Handler: h.Planner.SharePlanner, Resolve: h.Planner.resolveID, Request: handle.SharePlannerRequest{}, Response: handle.GetPlannerResponse{}, Conditional: true, ETag: true},
+		{Method: "DELETE", Path: "/api/v1/planners/{id}/members/{user}", OperationID: "UnsharePlanner", Summary: "Stop sharing a planner with a user", Tag: "planners",
+			Handler: h.Planner.UnsharePlanner, Resolve: h.Planner.resolveID, Response: handle.GetPlannerResponse{}, Conditional: true, ETag: true},
+
+		{Method: "GET", Path: "/api/v1/events", OperationID: "StreamEvents", Summary: "Stream changes as Server-Sent Events", Tag: "events",
+			Handler: h.Event.StreamEvents, Response: models.Event{}, Stream: true},
+
+		{Method: "GET", Path: "/api/v1/webhooks", OperationID: "ListWebhooks", Summary: "List your webhooks", Tag: "webhooks",
+			Handler: h.Webhook.ListWebhooks, Response: []*models.Webhook{}},
+		{Method: "POST", Path: "/api/v1/webhooks", OperationID: "CreateWebhook", Summary: "Create a webhook", Tag: "webhooks",
+			Handler: h.Webhook.CreateWebhook, Request: handle.CreateWebhookRequest{}, Response: handle.CreateWebhookResponse{}},
+		{Method: "GET", Path: "/api/v1/webhooks/{id}", OperationID: "GetWebhook", Summary: "Get a webhook by id", Tag: "webhooks",
+			Handler: h.Webhook.GetWebhook, Response: models.Webhook{}},
+		{Method: "DELETE", Path: "/api/v1/webhooks/{id}", OperationID: "DeleteWebhook", Summary: "Delete a webhook", Tag: "webhooks",
+			Handler: h.Webhook.DeleteWebhook},
+		{Method: "GET", Path: "/api/v1/webhooks/{id}/deliveries", OperationID: "ListWebhookDeliveries", Summary: "List the delivery attempts of a webhook", Tag: "webhooks",
+			Handler: h.Webhook.ListWebhookDeliveries, Response: []*models.Delivery{}},
+
+		{Method: "POST", Path: "/api/v1/batch", OperationID: "Batch", Summary: "Apply create, update and delete operations in one transaction", Tag: "batch",
+			Handler: h.Batch.Batch, Request: handle.BatchRequest{}, Response: handle.BatchResponse{}},
+
+		{Method: "POST", Path: "/api/v1/undo", OperationID: "Undo", Summary: "Undo your last changes", Tag: "undo",
+			Handler: h.Undo.Undo, Request: handle.UndoRequest{}, Response: handle.UndoResponse{}},
+		{Method: "POST", Path: "/api/v1/redo", OperationID: "Redo", Summary: "Redo your last undone changes", Tag: "undo",
+			Handler: h.Undo.Redo, Request: handle.UndoRequest{}, Response: handle.UndoResponse{}},
+
+		{Method: "GET", Path: "/api/v1/trash", OperationID: "ListTrash", Summary: "List the deleted records in the trash", Tag: "trash",
+			Handler: h.Trash.ListTrash, Response: handle.TrashResponse{}},
+		{Method: "POST", Path: "/api/v1/trash/empty", OperationID: "EmptyTrash", Summary: "Purge the records deleted before an age", Tag: "trash",
+			Handler: h.Trash.EmptyTrash, Request: handle.EmptyTrashRequest{}, Response: handle.TrashResponse{}},
+		{Method: "POST", Path: "/api/v1/trash/{id}/restore", OperationID: "RestoreTrash", Summary: "Restore a deleted record with the records deleted along with it", Tag: "trash",
+			Handler: h.Trash.RestoreTrash, Response: handle.TrashResponse{}},
+
+		{Method: "GET", Path: "/api/v1/search", OperationID: "Search", Summary: "Search tasks, goals, plans and the chat history", Tag: "search",
+			Handler: h.Search.Search, Response: handle.SearchResponse{}, Search: true},
+
+		{Method: "POST", Path: "/graphql", OperationID: "GraphQL", Summary: "Run a GraphQL query or mutation", Tag: "graphql",
+			Handler: h.GraphQL.GraphQL, Request: graphql.Request{}, Response: graphql.Response{}},
+	}
+}
+
+// Register registers every route of the table with the router.
+func Register(r *mux.Router, routes []Route) {
+	for _, route := range routes {
+		handler := route.Handler
+		if route.Resolve != nil {
+			handler = resolveID(handler, route.Resolve)
+		}
+		r.HandleFunc(route.Path, handler).Methods(route.Method)
+	}
+}
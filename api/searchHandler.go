@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SearchHandler serves full-text searches across tasks, goals, plans and the chat history.
+type SearchHandler struct {
+	Control *handle.SearchControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it only finds the records
+// of the planners shared with the authenticated caller.
+func (h *SearchHandler) control(r *http.Request) *handle.SearchControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
+}
+
+// searchRequest builds a SearchRequest from the query parameters q, type (a comma separated list of
+// task, goal, plan and chat) and limit. It returns an error if q is missing or limit is not a positive number.
+func searchRequest(r *http.Request) (*handle.SearchRequest, error) {
+	query := r.URL.Query()
+	req := &handle.SearchRequest{Query: query.Get("q")}
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, fmt.Errorf("missing query: q")
+	}
+	for _, t := range strings.Split(query.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			req.Types = append(req.Types, t)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+		if n > maxListLimit {
+			n = maxListLimit
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+// Search responds with the records that match every word of the query parameter q, best match first.
+// Words match exactly, as a prefix or with a typo.
+//
+// Example:
+//
+//	GET /api/v1/search?q=relase%20notes&type=task,plan&limit=10
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	req, err := searchRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).Search(req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/asdine/storm"
 	"github.com/gorilla/mux"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
 	"log"
 	"net/http"
 )
@@ -19,11 +22,30 @@ import (
 // - ListTasks: handles the listing of all tasks.
 type TaskHandler struct {
 	Control *handle.TaskControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it acts for the authenticated caller
+// and only reaches the tasks of the planners shared with them.
+func (h *TaskHandler) control(r *http.Request) *handle.TaskControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
 }
 
 // handleError checks if there is an error and if so, it writes the error message to the response writer
 // with the specified status code and logs the error message.
+// Errors of a caller who lacks the role the request requires are always reported as 403 Forbidden,
+// and those of a record that does not exist as 404 Not Found.
 func handleError(w http.ResponseWriter, err error, statusCode int) {
+	if errors.Is(err, services.ErrForbidden) {
+		statusCode = http.StatusForbidden
+	}
+	if errors.Is(err, storm.ErrNotFound) {
+		statusCode = http.StatusNotFound
+	}
 	if err != nil {
 		http.Error(w, err.Error(), statusCode)
 		log.Printf("Error due to: %s", err)
@@ -32,6 +54,7 @@ func handleError(w http.ResponseWriter, err error, statusCode int) {
 
 // CreateTask creates a new task based on the request data.
 // It decodes the JSON request body to a CreateTaskRequest struct.
+// A task without an owner is owned by the authenticated caller.
 // Then, it generates a unique ID for the task, creates a task instance using the provided data, and calls the CreateTask method of the TaskControl.
 // If the task creation is successful, it returns a CreateTaskResponse with the ID of the created task.
 // If any error occurs during the process, it handles the error by writing an HTTP error response with the corresponding status code and logging the error message.
@@ -40,19 +63,27 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 	var req handle.CreateTaskRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	handleError(w, err, http.StatusBadRequest)
-	res, err := h.Control.CreateTask(req)
+	if req.Owner == "" {
+		req.Owner = callerName(r)
+	}
+	res, err := h.control(r).CreateTask(req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
 
 // GetTask retrieves a task by its ID.
+// The revision of the task is sent in the ETag header.
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	req := handle.GetTaskRequest{ID: id}
-	res, err := h.Control.GetTask(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	res, err := h.control(r).GetTask(&req)
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setETag(w, res.Revision)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
@@ -67,7 +98,7 @@ func (h *TaskHandler) GetTaskByTitle(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	title := vars["title"]
 	req := handle.GetTaskByTitleRequest{Title: title}
-	res, err := h.Control.GetTaskByTitle(&req)
+	res, err := h.control(r).GetTaskByTitle(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
@@ -81,21 +112,30 @@ func (h *TaskHandler) GetTaskByOwner(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	owner := vars["owner"]
 	req := handle.GetTaskByOwnerRequest{Owner: owner}
-	res, err := h.Control.GetTaskByOwner(&req)
+	res, err := h.control(r).GetTaskByOwner(&req)
 	handleError(w, err, http.StatusInternalServerError)
 	err = json.NewEncoder(w).Encode(res)
 	handleError(w, err, http.StatusInternalServerError)
 }
 
-// ListTasks retrieves a list of tasks.
-// It calls the ListTasks method of the TaskControl to retrieve the tasks.
-// If there is any error during the retrieval, it returns an Internal Server Error status.
-// Finally, it encodes the retrieved tasks into JSON and writes it to the response writer.
-// If there is any error during encoding, it returns an Internal Server Error status.
+// ListTasks retrieves a page of tasks.
+// The query string is converted into a ListRequest (limit, cursor, sort, owner and status) and passed
+// to the FindTasks method of the TaskControl.
+// Invalid list parameters result in a Bad Request status, any other error in an Internal Server Error status.
+// The tasks are encoded as a JSON array and the cursor of the next page, if any, is set in the X-Next-Cursor header.
 func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
-	res, err := h.Control.ListTasks()
-	handleError(w, err, http.StatusInternalServerError)
-	err = json.NewEncoder(w).Encode(res)
+	req, err := listRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).FindTasks(req)
+	if err != nil {
+		handleError(w, err, listErrorStatus(err))
+		return
+	}
+	setNextCursor(w, res.NextCursor)
+	err = json.NewEncoder(w).Encode(res.Tasks)
 	handleError(w, err, http.StatusInternalServerError)
 }
 
@@ -103,6 +143,7 @@ func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
 // It expects the request to include an ID path variable and a request body containing the updated task information.
 // The ID from the URL is used to ensure the correct task is updated.
 // If there is an error during the process, it returns a JSON error response with the corresponding status code.
+// If the If-Match header does not match the revision of the task, it returns 412 Precondition Failed.
 // If the update is successful, it returns a 200 OK status code.
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -110,12 +151,22 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 
 	var req handle.UpdateTaskRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
-	handleError(w, err, http.StatusBadRequest)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
 
 	req.ID = id // Ensure the ID from the URL is used
+	if err := ifMatch(r, &req.Revision); err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
-	err = h.Control.UpdateTask(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	err = h.control(r).UpdateTask(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -123,13 +174,57 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 // DeleteTask deletes a task with the given ID.
 // It extracts the task ID from the URL parameters and creates a DeleteTaskRequest with that ID.
 // Then, it calls the DeleteTask method of TaskControl to delete the task.
-// If there is any error during task deletion, it returns an Internal Server Error status.
+// If the If-Match header does not match the revision of the task, it returns a Precondition Failed status;
+// if there is any other error during task deletion, it returns an Internal Server Error status.
 // Finally, it sets the response writer's status code to OK.
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	req := handle.DeleteTaskRequest{ID: id}
-	err := h.Control.DeleteTask(&req)
-	handleError(w, err, http.StatusInternalServerError)
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.DeleteTaskRequest{ID: id, Revision: revision}
+	err = h.control(r).DeleteTask(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// PatchTask is a method of TaskHandler that handles the PATCH request to partially update a task.
+// The request body is a JSON Merge Patch (RFC 7396); only the fields present in it are changed.
+// It responds with the updated task, 400 if the patch is invalid, 404 if the task does not exist
+// 412 if the If-Match header does not match its revision and 415 if the body is not a merge patch.
+// The new revision of the task is sent in the ETag header.
+//
+// Example:
+//
+//	PATCH /api/v1/tasks/123
+//	Content-Type: application/merge-patch+json
+//
+//	{"completed": true}
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	patch, err := readPatch(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	revision, err := ifMatchRevision(r)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	req := handle.PatchTaskRequest{ID: mux.Vars(r)["id"], Patch: patch, Revision: revision}
+	res, err := h.control(r).PatchTask(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	setETag(w, res.Revision)
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
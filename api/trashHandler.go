@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"io"
+	"net/http"
+)
+
+// TrashHandler serves the trash of deleted tasks, goals, plans and planners.
+type TrashHandler struct {
+	Control *handle.TrashControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it only serves the records the caller
+// could have deleted.
+func (h *TrashHandler) control(r *http.Request) *handle.TrashControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
+}
+
+// ListTrash responds with the records in the trash, most recently deleted first.
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res, err := h.control(r).ListTrash()
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// RestoreTrash brings back the record with the id in the URL, together with the records deleted along with it,
+// and responds with the restored records.
+//
+// Example:
+//
+//	POST /api/v1/trash/5f0c.../restore
+func (h *TrashHandler) RestoreTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res, err := h.control(r).RestoreTrash(&handle.RestoreTrashRequest{Id: mux.Vars(r)["id"]})
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// EmptyTrash purges the records deleted longer ago than the older_than of the request body for good,
+// and responds with the purged records. An empty body empties the whole trash.
+//
+// Example:
+//
+//	POST /api/v1/trash/empty
+//	{"older_than": "30d"}
+func (h *TrashHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	req := &handle.EmptyTrashRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).EmptyTrash(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"io"
+	"net/http"
+)
+
+// UndoHandler undoes and redoes the changes the caller made to tasks, goals, plans and planners.
+type UndoHandler struct {
+	Control *handle.UndoControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: the changes of the authenticated caller, with the caller's access
+// to the planners, or without Access or a caller, the changes of the local user of the daemon.
+func (h *UndoHandler) control(r *http.Request) *handle.UndoControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
+}
+
+// undoRequest decodes the UndoRequest of the request body; an empty body undoes or redoes one change.
+func undoRequest(r *http.Request) (*handle.UndoRequest, error) {
+	req := &handle.UndoRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Undo reverts the caller's last changes, as many as the steps of the request body, and responds with the changes
+// it reverted and those it skipped because someone else has changed the records since.
+//
+// Example:
+//
+//	POST /api/v1/undo
+//	{"steps": 2}
+func (h *UndoHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	req, err := undoRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).Undo(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// Redo applies again the caller's last undone changes, as many as the steps of the request body, and responds
+// as Undo does. Undone changes can only be redone until the caller makes a new change.
+func (h *UndoHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	req, err := undoRequest(r)
+	if err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).Redo(req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
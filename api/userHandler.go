@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+	"strings"
+)
+
+// UserHandler serves the users of the server and their API tokens to "flow user" and "flow token".
+// Whoever manages them can act as any user, so the daemon only serves them on its Unix socket,
+// to the user who runs it.
+type UserHandler struct {
+	Control *handle.UserControl
+}
+
+// RegisterUserRoutes registers the routes of h under /users and /tokens on r.
+//
+// Example:
+//
+//	GET /users
+//	POST /users/alice/tokens
+//	DELETE /tokens/2f1c7a9e-...
+func RegisterUserRoutes(r *mux.Router, h *UserHandler) {
+	r.HandleFunc("/users", h.ListUsers).Methods("GET")
+	r.HandleFunc("/users", h.AddUser).Methods("POST")
+	r.HandleFunc("/users/{name}", h.GetUser).Methods("GET")
+	r.HandleFunc("/users/{name}/tokens", h.ListTokens).Methods("GET")
+	r.HandleFunc("/users/{name}/tokens", h.CreateToken).Methods("POST")
+	r.HandleFunc("/tokens/{id}", h.RevokeToken).Methods("DELETE")
+}
+
+// writeUser encodes v as the JSON response, or writes the error with its status: 409 Conflict for a name
+// that is taken, 404 Not Found for an unknown user or token, and 500 Internal Server Error otherwise.
+func writeUser(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, handle.ErrUserExists) {
+			status = http.StatusConflict
+		}
+		handleError(w, err, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(v)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// AddUser adds the user named in the request body and responds with its id.
+//
+// Example:
+//
+//	POST /users
+//	{"name": "alice"}
+func (h *UserHandler) AddUser(w http.ResponseWriter, r *http.Request) {
+	var req handle.AddUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		handleError(w, errors.New("user name is required"), http.StatusBadRequest)
+		return
+	}
+	res, err := h.Control.AddUser(&req)
+	writeUser(w, res, err)
+}
+
+// ListUsers responds with every user.
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.Control.ListUsers()
+	if users == nil {
+		users = []*models.User{}
+	}
+	writeUser(w, users, err)
+}
+
+// GetUser responds with the user named in the URL.
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.Control.GetUser(&handle.GetUserRequest{Name: mux.Vars(r)["name"]})
+	writeUser(w, user, err)
+}
+
+// CreateToken creates an API token for the user named in the URL, with the label in the request body,
+// and responds with its id and secret, which is not returned again.
+//
+// Example:
+//
+//	POST /users/alice/tokens
+//	{"name": "laptop"}
+func (h *UserHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req handle.CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	req.UserName = mux.Vars(r)["name"]
+	res, err := h.Control.CreateToken(&req)
+	writeUser(w, res, err)
+}
+
+// ListTokens responds with the API tokens of the user named in the URL, without their secrets.
+func (h *UserHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.Control.ListTokens(&handle.ListTokensRequest{UserName: mux.Vars(r)["name"]})
+	if tokens == nil {
+		tokens = []*models.Token{}
+	}
+	writeUser(w, tokens, err)
+}
+
+// RevokeToken revokes the API token with the id in the URL.
+func (h *UserHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	err := h.Control.RevokeToken(&handle.RevokeTokenRequest{Id: mux.Vars(r)["id"]})
+	writeUser(w, struct{}{}, err)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"net/http"
+)
+
+// WebhookHandler serves the webhooks of the authenticated caller and their delivery logs.
+type WebhookHandler struct {
+	Control *handle.WebhookControl
+	Access  *services.AccessService
+}
+
+// control returns the control that serves a request: with Access set, it only manages the webhooks of the caller.
+func (h *WebhookHandler) control(r *http.Request) *handle.WebhookControl {
+	user := callerName(r)
+	if h.Access == nil || user == "" {
+		return h.Control
+	}
+	return h.Control.As(h.Access, user)
+}
+
+// CreateWebhook creates a webhook owned by the caller and responds with its id and secret,
+// which is not returned again. Without a secret in the request, a random one is generated.
+//
+// Example:
+//
+//	POST /api/v1/webhooks
+//	{"url": "https://ci.example.com/flow", "types": ["task.completed", "goal.overdue"]}
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var req handle.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(w, err, http.StatusBadRequest)
+		return
+	}
+	res, err := h.control(r).CreateWebhook(&req)
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(res)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// ListWebhooks responds with the webhooks of the caller, without their secrets.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	webhooks, err := h.control(r).ListWebhooks()
+	if err != nil {
+		handleError(w, err, http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(webhooks)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// GetWebhook responds with the webhook with the id in the URL, without its secret.
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	webhook, err := h.control(r).GetWebhook(&handle.GetWebhookRequest{Id: mux.Vars(r)["id"]})
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(webhook)
+	handleError(w, err, http.StatusInternalServerError)
+}
+
+// DeleteWebhook deletes the webhook with the id in the URL and its delivery log.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := h.control(r).DeleteWebhook(&handle.DeleteWebhookRequest{Id: mux.Vars(r)["id"]})
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListWebhookDeliveries responds with the logged delivery attempts of the webhook with the id in the URL,
+// the most recent first.
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	deliveries, err := h.control(r).ListWebhookDeliveries(&handle.ListWebhookDeliveriesRequest{Id: mux.Vars(r)["id"]})
+	if err != nil {
+		handleError(w, err, writeErrorStatus(err))
+		return
+	}
+	err = json.NewEncoder(w).Encode(deliveries)
+	handleError(w, err, http.StatusInternalServerError)
+}
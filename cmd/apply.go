@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var (
+	applyFile string
+	applyAs   string
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	addRemoteFlags(applyCmd)
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", `the batch to apply, as JSON; "-" reads it from stdin`)
+	applyCmd.Flags().StringVar(&applyAs, "as", "", "apply the batch with the access of this user, who also owns the records created without an owner")
+	_ = applyCmd.MarkFlagRequired("file")
+}
+
+// readBatch reads a batch from the named file, or from r if the name is "-".
+func readBatch(name string, r io.Reader) (*handle.BatchRequest, error) {
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	req := &handle.BatchRequest{}
+	if err := json.NewDecoder(r).Decode(req); err != nil {
+		return nil, fmt.Errorf("error reading batch %s: %w", name, err)
+	}
+	return req, nil
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <batch.json>",
+	Short: "apply a batch of create, update and delete operations",
+	Long: `apply a batch of create, update and delete operations on tasks, goals, plans and planners.
+The operations are applied in order in one transaction: if one fails, none of them are stored.
+A create operation may name the record it makes with "ref"; later operations use "${ref}" for its id.
+The batch has the format of POST /api/v1/batch:
+
+{"operations": [
+  {"op": "create", "resource": "plan", "ref": "release", "data": {"plan_name": "Release", "plan_date": "2024-06-01", "plan_time": "09:00"}},
+  {"op": "create", "resource": "task", "data": {"title": "Tag the release", "plan_id": "${release}"}},
+  {"op": "update", "resource": "goal", "id": "<goal id>", "data": {"goal_status": "In Progress"}},
+  {"op": "delete", "resource": "task", "id": "<task id>"}
+]}
+
+With --remote or --profile, the server applies the batch with the access of the token's user.
+
+Example usage:
+flow apply -f batch.json --as alice`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if applyAs != "" && (remote != "" || profile != "") {
+			return usageError{errors.New("--as only applies to the local database; a server applies the batch as the user of the token")}
+		}
+		req, err := readBatch(applyFile, cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		// the daemon applies and journals the batch as the user of --as, if any, as a server does for a token
+		c.User = applyAs
+		res, err := c.Batch(req)
+		if err != nil {
+			return fmt.Errorf("nothing was applied: %w", err)
+		}
+		for _, result := range res.Results {
+			line := fmt.Sprintf("%s\t%s\t%s", result.Op, result.Resource, result.Id)
+			if result.Ref != "" {
+				line += "\t" + result.Ref
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+		}
+		return nil
+	},
+}
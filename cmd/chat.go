@@ -1,8 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/chatapp"
 	"github.com/spf13/cobra"
-	"os/exec"
 )
 
 var chatVersion string
@@ -12,34 +13,32 @@ var chatCmd = &cobra.Command{
 	Short: "Launch a chat with the AI",
 	Long: `Launch a chat with the AI using the pv1(perplexity).
 
-chat history will be stored in a local chat.db file and can be accessed by running scripts/getchathistory.go
+chat history is stored in the flow data directory, ~/.flow or $FLOW_HOME. The pv2 chat keeps it through
+the daemon, which it starts in the background if it is not running.
 
 The chat will run until you type 'exit' and press enter.
 
 Example usage:
-go run main.go chat --version pv1
-go run main.go chat -v pv1`,
-	Run: func(cmd *cobra.Command, args []string) {
-		var chat *exec.Cmd
-		if chatVersion == "pv1" {
-			chat = exec.Command("bash", "-c", "./pv1")
-		} else if chatVersion == "pv2" {
-			chat = exec.Command("bash", "-c", "./pv2")
-		} else {
-			cmd.PrintErrf("invalid chat version: %s", chatVersion)
-			return
-		}
-		chat.Stdin = cmd.InOrStdin()
-		chat.Stdout = cmd.OutOrStdout()
-		chat.Stderr = cmd.ErrOrStderr()
-		err := chat.Run()
-		if err != nil {
-			cmd.PrintErrf("error running chat: %s", err)
+flow chat --version pv1
+flow chat -v pv2`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch chatVersion {
+		case "pv1":
+			return chatapp.RunPV1()
+		case "pv2":
+			c, err := ensureDaemon()
+			if err != nil {
+				return err
+			}
+			return chatapp.RunPV2(c.ChatStore())
 		}
+		return fmt.Errorf("invalid chat version: %s", chatVersion)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(chatCmd)
-	chatCmd.Flags().StringVarP(&chatVersion, "version", "v", "pv1", "specify chat version to run (pv1)")
+	chatCmd.Flags().StringVarP(&chatVersion, "version", "v", "pv1", "specify chat version to run (pv1 or pv2)")
 }
@@ -1,4 +1,4 @@
-package main
+package chatapp
 
 import (
 	"bufio"
@@ -7,7 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/logrusorgru/aurora"
-	"github.com/ooyeku/flow/cmd/chat/helpers"
+	"github.com/ooyeku/flow/cmd/chatapp/helpers"
+	"github.com/ooyeku/flow/internal/conf"
 	"github.com/ooyeku/flow/pkg/chat"
 	"github.com/theckman/yacspin"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -272,7 +274,7 @@ func (app *ChatAppP) RunP() error {
 //
 // Example usage:
 //
-//	  dbPath := "internal/inmemory/pv1.db"
+//	  dbPath := filepath.Join(conf.DataDir(), "pv1.db")
 //		app, err := NewChatAppP(dbPath)
 //		if err != nil {
 //		    log.Fatalf("Error creating chat app: %s", err)
@@ -288,18 +290,20 @@ func (app *ChatAppP) CloseP() {
 	}
 }
 
-// main is the entry point of the program.
-func main() {
-	dbPath := "internal/inmemory/pv1.db"
+// RunPV1 runs the pv1 chat, which keeps its history in pv1.db in conf.DataDir(), until the user exits
+// or the process receives SIGINT or SIGTERM.
+func RunPV1() error {
+	dbPath := filepath.Join(conf.DataDir(), "pv1.db")
 
 	app, err := NewChatAppP(dbPath)
 	if err != nil {
-		log.Fatalf("Error creating chat app: %s", err)
+		return fmt.Errorf("error creating chat app: %s", err)
 	}
 	defer app.CloseP()
 
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
 
 	go func() {
 		if err := app.RunP(); err != nil {
@@ -309,4 +313,5 @@ func main() {
 	}()
 
 	<-signals
+	return nil
 }
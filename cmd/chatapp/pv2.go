@@ -1,13 +1,12 @@
-package main
+package chatapp
 
 import (
 	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/asdine/storm"
 	"github.com/logrusorgru/aurora"
-	"github.com/ooyeku/flow/cmd/chat/helpers"
+	"github.com/ooyeku/flow/cmd/chatapp/helpers"
 	"github.com/ooyeku/flow/pkg/chat"
 	"github.com/theckman/yacspin"
 	"io"
@@ -18,18 +17,11 @@ import (
 	"time"
 )
 
-// cliSetup initializes the chat app by creating a database, chat service, and chat app instances.
+// cliSetup initializes the chat app by creating the chat service and chat app instances on store.
 // It also sets up the necessary dependencies for the chat app to function properly.
-// The function returns the chat app, chat service, and database instances.
-func cliSetup() (*chat.ChatApp, *chat.ChatService, *storm.DB) {
-	dbPath := "internal/inmemory/pv2.db"
-	db, err := storm.Open(dbPath, storm.BoltOptions(0600, nil))
-	if err != nil {
-		log.Fatalf("error opening db: %s", err)
-	}
-
-	cs := chat.NewStromRepo(db)
-	chatservice := chat.NewChatService(cs)
+// The function returns the chat app and chat service instances.
+func cliSetup(store chat.PPLXChatStore) (*chat.ChatApp, *chat.ChatService, error) {
+	chatservice := chat.NewChatService(store)
 
 	client := &http.Client{}
 	scanner := bufio.NewScanner(os.Stdin)
@@ -38,7 +30,7 @@ func cliSetup() (*chat.ChatApp, *chat.ChatService, *storm.DB) {
 
 	app, err := chat.NewChatApp(client, chatservice, scanner, apikey, models)
 	if err != nil {
-		log.Fatalf("error creating chat app: %s", err)
+		return nil, nil, fmt.Errorf("error creating chat app: %s", err)
 	}
 
 	// Get topics from db
@@ -46,13 +38,13 @@ func cliSetup() (*chat.ChatApp, *chat.ChatService, *storm.DB) {
 	// set current topic to General$h
 	app.CurrentTopic = *chat.NewChatTopic("General", "Standard chat topic")
 	if err != nil {
-		log.Fatalf("error getting topics: %s", err)
+		return nil, nil, fmt.Errorf("error getting topics: %s", err)
 	}
 	app.Topics = topics
 
 	app.CurrentModel = "pplx-70b-online"
 
-	return app, chatservice, db
+	return app, chatservice, nil
 }
 
 // run is the main function responsible for running the chat application.
@@ -467,17 +459,16 @@ func clearHisRun(app *chat.ChatApp) error {
 	return nil
 }
 
-func main() {
-	app, _, db := cliSetup()
-	defer func(db *storm.DB) {
-		err := db.Close()
-		if err != nil {
-			log.Fatalf("error closing db: %s", err)
-		}
-	}(db)
-
-	err := run(app)
+// RunPV2 runs the pv2 chat, which keeps its history by topic in store, until the user exits.
+// The flow command passes the daemon's chat store, so that several chats can run at the same time.
+func RunPV2(store chat.PPLXChatStore) error {
+	app, _, err := cliSetup(store)
 	if err != nil {
-		log.Fatalf("error running app: %s", err)
+		return err
+	}
+
+	if err := run(app); err != nil {
+		return fmt.Errorf("error running app: %s", err)
 	}
+	return nil
 }
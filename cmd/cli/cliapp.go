@@ -1,15 +1,16 @@
-package main
+package cli
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/asdine/storm"
 	"github.com/logrusorgru/aurora"
-	"github.com/ooyeku/flow/internal/conf"
-	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/client"
 	"github.com/ooyeku/flow/pkg/handle"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
+	"github.com/peterh/liner"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -52,93 +53,155 @@ func commandBox() {
 	fmt.Println(au.Cyan("update-planner or upl"), " - Update a planner")
 	fmt.Println(au.Cyan("delete-planner or dpl"), " - Delete a planner")
 	fmt.Println(au.Cyan("list-planners or lpl"), " - List all planners")
+	fmt.Println(au.Yellow(`Records can be given by their id, a unique prefix of it such as 3fa8, or @"name" such as @"Ship v2".`))
+	fmt.Println(au.Yellow("Tab completes the commands and, at the prompts, the ids and names of the records; ↑/↓ go through the history."))
+	fmt.Println(au.Cyan("help"), " - Show these commands", " ", au.Cyan("exit"), " - Leave the shell")
 	fmt.Println(au.Bold(au.BgMagenta("__________________________________________________________")))
 }
 
-// main is the entry point function for the CLI application.
+// errExit is returned by runCommand when the user enters "exit".
+var errExit = errors.New("exit")
+
+// Run is the entry point function for the CLI application.
 // It displays a welcome message and continuously prompts the user for a command.
 // The entered command string is passed to the runCommand function for execution.
 // Any error that occurs during the process is printed to stderr.
-func main() {
+// The commands run on controls over the stores of c, the daemon that owns the database, so that any number of
+// terminals can work on the same data, or a remote flow server. They share one connection to it for the whole session.
+// Commands and prompts are read with line editing; tab completes the commands and, at the prompts, the ids and
+// names of the records. The commands entered are kept in the history file at historyPath.
+// It returns when the user enters "exit" or stdin ends.
+func Run(c *client.Client, historyPath string) (err error) {
+	ctl := NewControls(c)
+	closeLine, err := openLine(ctl, historyPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closeLine(); err == nil {
+			err = cerr
+		}
+	}()
 
-	reader := bufio.NewReader(os.Stdin)
 	fmt.Println(au.Bold(au.Cyan("Welcome to flow CLI app! 😼")))
 	commandBox()
 	for {
 		fmt.Println(au.Green("Enter a command: "))
-		cmdString, err := reader.ReadString('\n')
+		cmdString, err := readCommand()
+		if errors.Is(err, liner.ErrPromptAborted) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
-			_, err := fmt.Fprintln(os.Stderr, err)
-			if err != nil {
-				return
-			}
+			return err
+		}
+		err = runCommand(ctl, cmdString)
+		if errors.Is(err, errExit) {
+			return nil
 		}
-		err = runCommand(cmdString)
 		if err != nil {
 			_, err := fmt.Fprintln(os.Stderr, err)
 			if err != nil {
-				return
+				return err
 			}
 		}
 	}
 }
 
-// cliSetup is a function that initializes the CLI setup by creating instances of different controls and the database.
-// It opens the database using the path obtained from the configuration.
-// It then creates in-memory stores, services, and routers for tasks, goals, plans, and planners using the database.
-// Finally, it returns the taskRouter, goalRouter, planRouter, plannerRouter, and the database instance.
-// The returned taskRouter is of type *handle.TaskControl and has the following methods:
-// - CreateTask: Creates a new task with the provided request and returns the response.
-// - UpdateTask: Updates an existing task using the provided request.
-// - DeleteTask: Deletes a task with the specified ID.
-// - GetTask: Retrieves a task with the specified ID and returns the response.
-// - GetTaskByTitle: Retrieves a task with the specified title and returns the response.
-// - GetTaskByOwner: Retrieves tasks with the specified owner and returns a slice of responses.
-// - ListTasks: Retrieves all tasks and returns a slice of responses.
-// The returned goalRouter is of type *handle.GoalControl and has the following methods:
-// - CreateGoal: Creates a new goal with the provided request and returns the response.
-// - UpdateGoal: Updates an existing goal using the provided request.
-// - DeleteGoal: Deletes a goal with the specified ID.
-// - GetGoal: Retrieves a goal with the specified
-func cliSetup() (*handle.TaskControl, *handle.GoalControl, *handle.PlanControl, *handle.PlannerControl, *storm.DB) {
-	dbPath := conf.GetDBPath()
-	db, err := storm.Open(dbPath, storm.BoltOptions(0600, nil))
-	if err != nil {
-		log.Fatalf("error opening db: %s", err)
-	}
-
-	// Intialize router, service and inmemory store
-	taskStore := inmemory.NewInMemoryTaskStore(db)
-	taskService := services.NewTaskService(taskStore)
-	taskRouter := handle.NewTaskControl(taskService)
-
-	goalStore := inmemory.NewInMemoryGoalStore(db)
-	goalService := services.NewGoalService(goalStore)
-	goalRouter := handle.NewGoalControl(goalService)
-
-	planStore := inmemory.NewInMemoryPlanStore(db)
-	planService := services.NewPlanService(planStore)
-	planRouter := handle.NewPlanControl(planService)
-
-	plannerStore := inmemory.NewInMemoryPlannerStore(db)
-	plannerService := services.NewPlannerService(plannerStore)
-	plannerRouter := handle.NewPlannerControl(plannerService)
-
-	return taskRouter, goalRouter, planRouter, plannerRouter, db
+// taskAPI, goalAPI, planAPI and plannerAPI are the operations the commands of the cli use,
+// as the controls of pkg/handle provide them.
+type taskAPI interface {
+	ResolveTask(ref string) (string, error)
+	CreateTask(req handle.CreateTaskRequest) (*handle.CreateTaskResponse, error)
+	GetTask(req *handle.GetTaskRequest) (*handle.GetTaskResponse, error)
+	GetTaskByTitle(req *handle.GetTaskByTitleRequest) (*handle.GetTaskResponse, error)
+	GetTaskByOwner(req *handle.GetTaskByOwnerRequest) ([]*handle.GetTaskResponse, error)
+	PatchTask(req *handle.PatchTaskRequest) (*handle.GetTaskResponse, error)
+	DeleteTask(req *handle.DeleteTaskRequest) error
+	FindTasks(req *handle.ListRequest) (*handle.ListTasksResponse, error)
+}
+
+type goalAPI interface {
+	ResolveGoal(ref string) (string, error)
+	CreateGoal(req *handle.CreateGoalRequest) (*handle.CreateGoalResponse, error)
+	GetGoal(req *handle.GetGoalRequest) (*handle.GetGoalResponse, error)
+	GetGoalByObjective(req *handle.GetGoalByObjectiveRequest) (*handle.GetGoalByObjectiveResponse, error)
+	GetGoalsByPlannerId(req *handle.GetGoalsByPlannerIdRequest) (*handle.GetGoalsByPlannerIdResponse, error)
+	PatchGoal(req *handle.PatchGoalRequest) (*handle.GetGoalResponse, error)
+	DeleteGoal(req *handle.DeleteGoalRequest) error
+	FindGoals(req *handle.ListRequest) (*handle.ListGoalsResponse, error)
+}
+
+type planAPI interface {
+	ResolvePlan(ref string) (string, error)
+	CreatePlan(req *handle.CreatePlanRequest) (*handle.CreatePlanResponse, error)
+	GetPlan(req *handle.GetPlanRequest) (*handle.GetPlanResponse, error)
+	GetPlanByName(req *handle.GetPlanByNameRequest) (*handle.GetPlanByNameResponse, error)
+	GetPlansByGoal(req *handle.GetPlansByGoalRequest) (*handle.GetPlansByGoalResponse, error)
+	PatchPlan(req *handle.PatchPlanRequest) (*handle.GetPlanResponse, error)
+	DeletePlan(req *handle.DeletePlanRequest) error
+	FindPlans(req *handle.ListRequest) (*handle.ListPlansResponse, error)
 }
 
-func promptUser(reader *bufio.Reader, prompt string) (string, error) {
+type plannerAPI interface {
+	ResolvePlanner(ref string) (string, error)
+	CreatePlanner(req *handle.CreatePlannerRequest) (*handle.CreatePlannerResponse, error)
+	GetPlanner(req *handle.GetPlannerRequest) (*handle.GetPlannerResponse, error)
+	GetPlannerByTitle(req *handle.GetPlannerByTitleRequest) (*handle.GetPlannerByTitleResponse, error)
+	GetPlannerByOwner(req *handle.GetPlannerByOwnerRequest) ([]*handle.GetPlannerByOwnerResponse, error)
+	PatchPlanner(req *handle.PatchPlannerRequest) (*handle.GetPlannerResponse, error)
+	DeletePlanner(req *handle.DeletePlannerRequest) error
+	FindPlanners(req *handle.ListRequest) (*handle.ListPlannersResponse, error)
+}
+
+// Controls are the controls the commands of the shell, and of flow task, goal, plan and planner, run on.
+type Controls struct {
+	Task    *handle.TaskControl
+	Goal    *handle.GoalControl
+	Plan    *handle.PlanControl
+	Planner *handle.PlannerControl
+}
+
+// NewControls returns the controls over the stores of the server c talks to. The server checks the access
+// of the user of c's token and records the events, so the services are neither scoped nor given an event bus.
+func NewControls(c *client.Client) *Controls {
+	return &Controls{
+		Task:    handle.NewTaskControl(services.NewTaskService(c.TaskStore())),
+		Goal:    handle.NewGoalControl(services.NewGoalService(c.GoalStore())),
+		Plan:    handle.NewPlanControl(services.NewPlanService(c.PlanStore())),
+		Planner: handle.NewPlannerControl(services.NewPlannerService(c.PlannerStore())),
+	}
+}
+
+// promptUser shows prompt and reads the answer, without the surrounding spaces. Ctrl-C fails with liner.ErrPromptAborted.
+func promptUser(prompt string) (string, error) {
 	fmt.Println(prompt)
-	response, err := reader.ReadString('\n')
+	response, err := readLine()
 	if err != nil {
-		return "", fmt.Errorf("could not read from stdin: %s", err)
+		return "", fmt.Errorf("could not read from stdin: %w", err)
 	}
 	return strings.TrimSpace(response), nil
 }
 
+// promptPatch prompts the user for a new value of a field and records it in patch under the given json name.
+// The current value is shown in the prompt; pressing enter without typing anything keeps it,
+// in which case the field is left out of the patch.
+func promptPatch(prompt string, current string, field string, patch map[string]interface{}) error {
+	value, err := promptUser(fmt.Sprintf("%s [%s]: ", prompt, current))
+	if err != nil {
+		return err
+	}
+	if value != "" {
+		patch[field] = value
+	}
+	return nil
+}
+
 // taskCommands is a map that contains various commands related to task operations.
 // The key represents the command name and the value represents the corresponding function to be executed.
-var taskCommands = map[string]func(*handle.TaskControl){
+var taskCommands = map[string]func(taskAPI){
 	"create-task":       createTask,
 	"ct":                createTask,
 	"get-task":          getTask,
@@ -156,7 +219,7 @@ var taskCommands = map[string]func(*handle.TaskControl){
 }
 
 // goalCommands is a map that contains various commands related to goal operations. The key represents the command name and the value represents the corresponding function to be executed.
-var goalCommands = map[string]func(*handle.GoalControl){
+var goalCommands = map[string]func(goalAPI){
 	"create-goal":         createGoal,
 	"cg":                  createGoal,
 	"get-goal":            getGoal,
@@ -174,7 +237,7 @@ var goalCommands = map[string]func(*handle.GoalControl){
 }
 
 // planCommands is a map that contains various commands related to plan operations. The key represents the command name and the value represents the corresponding function to be executed
-var planCommands = map[string]func(*handle.PlanControl){
+var planCommands = map[string]func(planAPI){
 	"create-plan":      createPlan,
 	"cp":               createPlan,
 	"get-plan":         getPlan,
@@ -192,7 +255,7 @@ var planCommands = map[string]func(*handle.PlanControl){
 }
 
 // plannerCommands is a map that contains various commands related to planner operations. The key represents the command name and the value represents the corresponding function to be executed
-var plannerCommands = map[string]func(*handle.PlannerControl){
+var plannerCommands = map[string]func(plannerAPI){
 	"create-planner":       createPlanner,
 	"cpl":                  createPlanner,
 	"get-planner":          getPlanner,
@@ -215,15 +278,15 @@ var plannerCommands = map[string]func(*handle.PlannerControl){
 // If there is an error creating the task, it prints the error message to the console.
 // Finally, it prints the ID of the created task to the console.
 // The function makes use of the promptUser function to read user input.
-// The promptUser function takes a *bufio.Reader instance and a prompt string as arguments,
+// The promptUser function takes a prompt string as argument,
 // and returns the trimmed user input or an error.
 // The promptUser function is defined as follows:
 //
-//	func promptUser(reader *bufio.Reader, prompt string) (string, error) {
+//	func promptUser(prompt string) (string, error) {
 //		fmt.Println(prompt)
-//		response, err := reader.ReadString('\n')
+//		response, err := readLine()
 //		if err != nil {
-//			return "", fmt.Errorf("could not read from stdin: %s", err)
+//			return "", fmt.Errorf("could not read from stdin: %w", err)
 //		}
 //		return strings.TrimSpace(response), nil
 //	}
@@ -237,21 +300,23 @@ var plannerCommands = map[string]func(*handle.PlannerControl){
 // Here is an example of how to use the createTask function:
 // t := &handle.TaskControl{service: &services.TaskService{Store: &store.TaskStore{}}} // Instantiate TaskControl
 // createTask(t) // Call createTask function with the TaskControl instance as argument
-func createTask(t *handle.TaskControl) {
+func createTask(t taskAPI) {
 	fmt.Println("Creating task...")
-	reader := bufio.NewReader(os.Stdin)
-	title, err := promptUser(reader, "Enter task title: ")
+	title, err := promptUser("Enter task title: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	description, err := promptUser(reader, "Enter task description: ")
+	description, err := promptUser("Enter task description: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 
-	owner, err := promptUser(reader, "Enter task owner: ")
+	owner, err := promptUser("Enter task owner: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.CreateTaskRequest{
 		Title:       title,
@@ -270,12 +335,16 @@ func createTask(t *handle.TaskControl) {
 // It prompts the user to enter the task ID and retrieves the task from TaskControl service.
 // If there is an error while reading from standard input or retrieving the task, it prints an error message.
 // It prints the task's title and description if the task is retrieved successfully.
-func getTask(t *handle.TaskControl) {
+func getTask(t taskAPI) {
 	fmt.Println("Getting task...")
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter task id: ")
+	id, err := promptUser("Enter task id: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = t.ResolveTask(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetTaskRequest{
 		ID: id,
@@ -293,12 +362,12 @@ func getTask(t *handle.TaskControl) {
 // It prompts the user to enter the task title, and then calls the GetTaskByTitle function of the TaskControl instance.
 // If the task is found, it prints the task title and description.
 // If there is an error retrieving the task or prompting the user, it logs the error message.
-func getTaskByTitle(t *handle.TaskControl) {
+func getTaskByTitle(t taskAPI) {
 	fmt.Println("Getting task...")
-	reader := bufio.NewReader(os.Stdin)
-	title, err := promptUser(reader, "Enter task title: ")
+	title, err := promptUser("Enter task title: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetTaskByTitleRequest{
 		Title: title,
@@ -319,12 +388,11 @@ func getTaskByTitle(t *handle.TaskControl) {
 // If there is an error reading the user's input or retrieving the tasks, an error message is logged.
 // Parameters:
 // - t: a pointer to a handle.TaskControl struct representing the task control service
-func getTaskByOwner(t *handle.TaskControl) {
+func getTaskByOwner(t taskAPI) {
 	fmt.Println("Getting task...")
-	reader := bufio.NewReader(os.Stdin)
-	owner, err := promptUser(reader, "Enter task owner: ")
+	owner, err := promptUser("Enter task owner: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
 		return
 	}
 	req := handle.GetTaskByOwnerRequest{
@@ -347,15 +415,19 @@ func getTaskByOwner(t *handle.TaskControl) {
 // It then retrieves the task from the task control system using the provided ID.
 // If an error occurs during the retrieval, the function prints an error message and returns.
 // The function displays the retrieved task's title and description to the user.
-// The user is prompted to enter a new task title, description, and owner.
-// The function then updates the task with the new values and prints a message indicating that the task is being updated.
+// The user is prompted to enter a new task title, description, and owner; pressing enter keeps the current value.
+// The function then sends only the changed values to PatchTask and prints a message indicating that the task is being updated.
 // If an error occurs during the update, the function prints an error message and returns.
 // Finally, the function prints a message indicating that the task has been updated with its ID.
-func updateTasks(t *handle.TaskControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter task id of task to be updated: ")
+func updateTasks(t taskAPI) {
+	id, err := promptUser("Enter task id of task to be updated: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = t.ResolveTask(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetTaskRequest{
 		ID: id,
@@ -367,28 +439,28 @@ func updateTasks(t *handle.TaskControl) {
 	}
 	fmt.Println("Got task: ", task.Title)
 	fmt.Println("Description: ", task.Description)
-	fmt.Println("Enter New task title: ")
+	fmt.Println("Enter New task values (press enter to keep the current value): ")
 
-	title, err := promptUser(reader, "Enter task title: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	patch := map[string]interface{}{}
+	if err := promptPatch("Enter task title", task.Title, "title", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	description, err := promptUser(reader, "Enter task description: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter task description", task.Description, "description", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	owner, err := promptUser(reader, "Enter task owner: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter task owner", task.Owner, "owner", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	update := handle.UpdateTaskRequest{
-		ID:          id,
-		Title:       title,
-		Description: description,
-		Owner:       owner,
+	body, err := json.Marshal(patch)
+	if err != nil {
+		fmt.Printf("Error updating task with id %s: %s\n", id, err)
+		return
 	}
 	fmt.Println("Updating task...")
-	err = t.UpdateTask(&update)
+	_, err = t.PatchTask(&handle.PatchTaskRequest{ID: id, Patch: body, Revision: task.Revision})
 	if err != nil {
 		fmt.Printf("Error updating task with id %s: %s\n", id, err)
 		return
@@ -403,11 +475,15 @@ func updateTasks(t *handle.TaskControl) {
 // If the user confirms the deletion, the task is deleted using the DeleteTask method of the TaskControl service.
 // If the deletion is successful, a message is printed to confirm the deletion.
 // If the user cancels the deletion or provides invalid input, appropriate messages are printed.
-func deleteTask(t *handle.TaskControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter task id to be deleted: ")
+func deleteTask(t taskAPI) {
+	id, err := promptUser("Enter task id to be deleted: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = t.ResolveTask(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	// Get task first to show user what task is being deleted
 	req := handle.GetTaskRequest{
@@ -420,9 +496,10 @@ func deleteTask(t *handle.TaskControl) {
 	}
 	fmt.Println("Got task: ", task.Title)
 	fmt.Println("are you sure you want to delete this task? (y/n)")
-	confirm, err := reader.ReadString('\n')
+	confirm, err := readLine()
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	confirm = strings.TrimSpace(confirm)
 	if confirm == "n" {
@@ -430,7 +507,8 @@ func deleteTask(t *handle.TaskControl) {
 	} else if confirm == "y" {
 		fmt.Println("Deleting task...")
 		req := handle.DeleteTaskRequest{
-			ID: id,
+			ID:       id,
+			Revision: task.Revision,
 		}
 		err = t.DeleteTask(&req)
 		if err != nil {
@@ -454,49 +532,53 @@ func deleteTask(t *handle.TaskControl) {
 //
 //	t := &handle.TaskControl{}
 //	listTasks(t)
-func listTasks(t *handle.TaskControl) {
+func listTasks(t taskAPI) {
 	fmt.Println("Listing tasks...")
-	// using channel to get tasks
+	// using channel to get tasks, a page at a time
 	taskChan := make(chan *handle.GetTaskResponse)
 
 	go func() {
-		tasks, err := t.ListTasks()
-		if err != nil {
-			fmt.Println("Error listing tasks: ", err)
-			taskChan <- nil
-		}
-		for _, task := range tasks {
-			taskChan <- &handle.GetTaskResponse{
-				ID:          task.ID,
-				Title:       task.Title,
-				Description: task.Description,
+		defer close(taskChan)
+		req := &handle.ListRequest{}
+		for {
+			page, err := t.FindTasks(req)
+			if err != nil {
+				fmt.Println("Error listing tasks: ", err)
+				return
+			}
+			for _, task := range page.Tasks {
+				taskChan <- task
 			}
+			if page.NextCursor == "" {
+				return
+			}
+			req.Cursor = page.NextCursor
 		}
-		close(taskChan)
 	}()
 
 	for task := range taskChan {
 		fmt.Printf("| Task ID: %s | Task Title: %s | Task Description: %s |\n", task.ID, task.Title, task.Description)
 	}
-
 }
 
 // Prompt user to enter goal objective
-func createGoal(g *handle.GoalControl) {
+func createGoal(g goalAPI) {
 	fmt.Println("Creating goal...")
-	reader := bufio.NewReader(os.Stdin)
-	objective, err := promptUser(reader, "Enter goal objective: ")
+	objective, err := promptUser("Enter goal objective: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	deadline, err := promptUser(reader, "Enter goal deadline date in YYYY-MM-DD format: ")
+	deadline, err := promptUser("Enter goal deadline date in YYYY-MM-DD format: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	deadlineTime, err := promptUser(reader, "Enter goal deadline time in HH:MM format: ")
-	plannerid, err := promptUser(reader, "Enter goal plannerid: ")
+	deadlineTime, err := promptUser("Enter goal deadline time in HH:MM format: ")
+	plannerid, err := promptUser("Enter goal plannerid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 
 	// Convert deadline to time.Time
@@ -525,12 +607,16 @@ func createGoal(g *handle.GoalControl) {
 //
 //	g := &handle.GoalControl{Service: &services.GoalService{}}
 //	getGoal(g)
-func getGoal(g *handle.GoalControl) {
+func getGoal(g goalAPI) {
 	fmt.Println("Getting goal...")
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter goal id: ")
+	id, err := promptUser("Enter goal id: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = g.ResolveGoal(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetGoalRequest{
 		Id: id,
@@ -550,12 +636,12 @@ func getGoal(g *handle.GoalControl) {
 // and calls the GetGoalByObjective method of the GoalControl service to fetch the goal.
 // If there is an error fetching the goal, the error message is logged in the GetGoalByObjective method.
 // If the goal is fetched successfully, it prints the goal's objective, deadline, and plannerID to the console.
-func getGoalByObjective(g *handle.GoalControl) {
+func getGoalByObjective(g goalAPI) {
 	fmt.Println("Getting goal...")
-	reader := bufio.NewReader(os.Stdin)
-	objective, err := promptUser(reader, "Enter goal title(objective): ")
+	objective, err := promptUser("Enter goal title(objective): ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetGoalByObjectiveRequest{
 		Objective: objective,
@@ -587,17 +673,17 @@ func getGoalByObjective(g *handle.GoalControl) {
 // - g: a handle.GoalControl instance that provides access to the GoalControl service methods.
 //
 // Dependencies:
-// - bufio.NewReader: used for reading user input from standard input.
+// - promptUser: used for reading user input from the line editor of the shell.
 // - promptUser: a function that prompts the user to enter a value and returns the entered value as a string.
 // - handle.GetGoalsByPlannerIdRequest: a struct representing the request to retrieve goals by planner ID.
 //
 // Returns: None
-func getGoalsByPlannerId(g *handle.GoalControl) {
+func getGoalsByPlannerId(g goalAPI) {
 	fmt.Println("Getting goal...")
-	reader := bufio.NewReader(os.Stdin)
-	plannerid, err := promptUser(reader, "Enter goal plannerid: ")
+	plannerid, err := promptUser("Enter goal plannerid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetGoalsByPlannerIdRequest{
 		PlannerId: plannerid,
@@ -620,15 +706,20 @@ func getGoalsByPlannerId(g *handle.GoalControl) {
 // Then it retrieves the goal from the GoalControl service using the given ID.
 // If there is an error retrieving the goal, it prints an error message and returns.
 // Otherwise, it prompts the user to enter the new goal objective, deadline, and planner ID.
-// After collecting the new values, it creates an UpdateGoalRequest with the collected values.
-// It then calls the UpdateGoal method of the GoalControl service to update the goal.
+// Pressing enter at a prompt keeps the current value.
+// After collecting the new values, it creates a PatchGoalRequest containing only the changed values.
+// It then calls the PatchGoal method of the GoalControl service to update the goal, which keeps its status and creation time.
 // If there is an error updating the goal, it prints an error message and returns.
 // Otherwise, it prints a success message with the updated goal ID.
-func updateGoals(g *handle.GoalControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter goal id of goal to be updated: ")
+func updateGoals(g goalAPI) {
+	id, err := promptUser("Enter goal id of goal to be updated: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = g.ResolveGoal(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetGoalRequest{
 		Id: id,
@@ -641,28 +732,28 @@ func updateGoals(g *handle.GoalControl) {
 	fmt.Println("Got goal: ", goal.Goal.Objective)
 	fmt.Println("Deadline: ", goal.Goal.Deadline)
 	fmt.Println("PlannerID: ", goal.Goal.PlannerId)
-	fmt.Println("Enter New goal objective: ")
+	fmt.Println("Enter New goal values (press enter to keep the current value): ")
 
-	objective, err := promptUser(reader, "Enter goal objective: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	patch := map[string]interface{}{}
+	if err := promptPatch("Enter goal objective", goal.Goal.Objective, "objective", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	deadline, err := promptUser(reader, "Enter goal deadline in YYYY-MM-DD format: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter goal deadline in YYYY-MM-DD format", goal.Goal.Deadline.Format("2006-01-02"), "deadline", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	plannerid, err := promptUser(reader, "Enter goal plannerid: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter goal plannerid", goal.Goal.PlannerId, "planner_id", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	update := handle.UpdateGoalRequest{
-		Id:        id,
-		Objective: objective,
-		Deadline:  deadline,
-		PlannerId: plannerid,
+	body, err := json.Marshal(patch)
+	if err != nil {
+		fmt.Printf("Error updating goal with id %s: %s\n", id, err)
+		return
 	}
 	fmt.Println("Updating goal...")
-	err = g.UpdateGoal(&update)
+	_, err = g.PatchGoal(&handle.PatchGoalRequest{Id: id, Patch: body, Revision: goal.Goal.Revision})
 	if err != nil {
 		fmt.Printf("Error updating goal with id %s: %s\n", id, err)
 		return
@@ -676,11 +767,15 @@ func updateGoals(g *handle.GoalControl) {
 // If the goal is found, it asks the user for confirmation.
 // If the user confirms the deletion, it calls the DeleteGoal method on the GoalControl service to delete the goal.
 // If there is an error during any step, it prints an error message accordingly.
-func deleteGoal(g *handle.GoalControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter goal id to be deleted: ")
+func deleteGoal(g goalAPI) {
+	id, err := promptUser("Enter goal id to be deleted: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = g.ResolveGoal(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	// Get goal first to show user what goal is being deleted
 	req := handle.GetGoalRequest{
@@ -693,9 +788,10 @@ func deleteGoal(g *handle.GoalControl) {
 	}
 	fmt.Println("Got goal: ", goal.Goal.Objective)
 	fmt.Println("are you sure you want to delete this goal? (y/n)")
-	confirm, err := reader.ReadString('\n')
+	confirm, err := readLine()
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	confirm = strings.TrimSpace(confirm)
 	if confirm == "n" {
@@ -703,7 +799,8 @@ func deleteGoal(g *handle.GoalControl) {
 	} else if confirm == "y" {
 		fmt.Println("Deleting goal...")
 		req := handle.DeleteGoalRequest{
-			Id: id,
+			Id:       id,
+			Revision: goal.Goal.Revision,
 		}
 		err = g.DeleteGoal(&req)
 		if err != nil {
@@ -733,22 +830,28 @@ func deleteGoal(g *handle.GoalControl) {
 //	Listing goals...
 //	Goal id: 1, Objective: Finish project, Deadline: 2022-12-31T23:59:00, PlannerID: 12345
 //	Goal id: 2, Objective: Exercise daily, Deadline: 2023-01-01T08:00:00, PlannerID: 67890
-func listGoals(g *handle.GoalControl) {
+func listGoals(g goalAPI) {
 	fmt.Println("Listing goals...")
 
 	goalChan := make(chan *models.Goal)
 
 	go func() {
-		goals, err := g.Service.ListGoals()
-		if err != nil {
-			log.Printf("Error listing goals: %s", err)
-			close(goalChan)
-			return
-		}
-		for _, goal := range goals {
-			goalChan <- goal
+		defer close(goalChan)
+		req := &handle.ListRequest{}
+		for {
+			page, err := g.FindGoals(req)
+			if err != nil {
+				log.Printf("Error listing goals: %s", err)
+				return
+			}
+			for _, goal := range page.Goals {
+				goalChan <- goal
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			req.Cursor = page.NextCursor
 		}
-		close(goalChan)
 	}()
 
 	for goal := range goalChan {
@@ -765,28 +868,32 @@ func listGoals(g *handle.GoalControl) {
 // Note that this function runs in a Goroutine, allowing for concurrent plan creation.
 // Parameters:
 // - p: A pointer to an instance of the handle.PlanControl struct which contains a PlanService instance for plan CRUD operations.
-func createPlan(p *handle.PlanControl) {
+func createPlan(p planAPI) {
 	fmt.Println("Creating plan...")
-	reader := bufio.NewReader(os.Stdin)
-	name, err := promptUser(reader, "Enter plan name: ")
+	name, err := promptUser("Enter plan name: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	goalid, err := promptUser(reader, "Enter plan goalid: ")
+	goalid, err := promptUser("Enter plan goalid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	description, err := promptUser(reader, "Enter plan description: ")
+	description, err := promptUser("Enter plan description: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	date, err := promptUser(reader, "Enter plan date in YYYY-MM-DD format: ")
+	date, err := promptUser("Enter plan date in YYYY-MM-DD format: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	time, err := promptUser(reader, "Enter plan time in HH:MM format: ")
+	time, err := promptUser("Enter plan time in HH:MM format: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 
 	req := handle.CreatePlanRequest{
@@ -815,12 +922,16 @@ func createPlan(p *handle.PlanControl) {
 //	  Service: <PlanServiceInstance>,
 //	}
 //	getPlan(p)
-func getPlan(p *handle.PlanControl) {
+func getPlan(p planAPI) {
 	fmt.Println("Getting plan...")
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter plan id: ")
+	id, err := promptUser("Enter plan id: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlan(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetPlanRequest{
 		Id: id,
@@ -839,12 +950,12 @@ func getPlan(p *handle.PlanControl) {
 // It prompts the user to enter the plan name and uses the PlanControl service to get the plan.
 // If there is an error retrieving the plan, the error message is logged in GetPlanByName.
 // The function prints the plan name and description if the plan is found.
-func getPlanByName(p *handle.PlanControl) {
+func getPlanByName(p planAPI) {
 	fmt.Println("Getting plan...")
-	reader := bufio.NewReader(os.Stdin)
-	name, err := promptUser(reader, "Enter plan name: ")
+	name, err := promptUser("Enter plan name: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetPlanByNameRequest{
 		PlanName: name,
@@ -871,12 +982,12 @@ func getPlanByName(p *handle.PlanControl) {
 //	getPlanByGoal(p)
 //
 // Returns: None
-func getPlanByGoal(p *handle.PlanControl) {
+func getPlanByGoal(p planAPI) {
 	fmt.Println("Getting plan...")
-	reader := bufio.NewReader(os.Stdin)
-	goalid, err := promptUser(reader, "Enter plan goalid: ")
+	goalid, err := promptUser("Enter plan goalid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetPlansByGoalRequest{
 		GoalId: goalid,
@@ -896,14 +1007,18 @@ func getPlanByGoal(p *handle.PlanControl) {
 // updatePlans is a function that allows the user to update a specific plan.
 // It prompts the user to enter the plan ID of the plan to be updated.
 // It then retrieves the plan information using the plan ID.
-// It prompts the user to enter a new plan name, description, date, and time.
-// After receiving the new values, it creates an UpdatePlanRequest object and sends it to the PlanControl's UpdatePlan method.
+// It prompts the user to enter a new plan name, description, date, and time; pressing enter keeps the current value.
+// After receiving the new values, it creates a PatchPlanRequest with the changed values and sends it to the PlanControl's PatchPlan method.
 // If an error occurs during the process, it prints an error message.
-func updatePlans(p *handle.PlanControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter plan id of plan to be updated: ")
+func updatePlans(p planAPI) {
+	id, err := promptUser("Enter plan id of plan to be updated: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlan(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetPlanRequest{
 		Id: id,
@@ -915,33 +1030,32 @@ func updatePlans(p *handle.PlanControl) {
 	}
 	fmt.Println("Got plan: ", plan.Plan.PlanName)
 	fmt.Println("Description: ", plan.Plan.PlanDescription)
-	fmt.Println("Enter New plan name: ")
+	fmt.Println("Enter New plan values (press enter to keep the current value): ")
 
-	name, err := promptUser(reader, "Enter plan name: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	patch := map[string]interface{}{}
+	if err := promptPatch("Enter plan name", plan.Plan.PlanName, "plan_name", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	description, err := promptUser(reader, "Enter plan description: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter plan description", plan.Plan.PlanDescription, "plan_description", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	date, err := promptUser(reader, "Enter plan date in YYYY-MM-DD format: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter plan date in YYYY-MM-DD format", plan.Plan.PlanDate.Format("2006-01-02"), "plan_date", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	time, err := promptUser(reader, "Enter plan time in HH:MM format: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter plan time in HH:MM format", plan.Plan.PlanTime.Format("15:04"), "plan_time", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	update := handle.UpdatePlanRequest{
-		Id:              id,
-		PlanName:        name,
-		PlanDescription: description,
-		PlanDate:        date,
-		PlanTime:        time,
+	body, err := json.Marshal(patch)
+	if err != nil {
+		fmt.Printf("Error updating plan with id %s: %s\n", id, err)
+		return
 	}
 	fmt.Println("Updating plan...")
-	err = p.UpdatePlan(&update)
+	_, err = p.PatchPlan(&handle.PatchPlanRequest{Id: id, Patch: body, Revision: plan.Plan.Revision})
 	if err != nil {
 		fmt.Printf("Error updating plan with id %s: %s\n", id, err)
 		return
@@ -958,11 +1072,15 @@ func updatePlans(p *handle.PlanControl) {
 // If the user enters 'n' or any other input, it cancels the deletion process.
 // Parameters:
 // - p: a pointer to the PlanControl structure that provides access to plan-related operations.
-func deletePlan(p *handle.PlanControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter plan id to be deleted: ")
+func deletePlan(p planAPI) {
+	id, err := promptUser("Enter plan id to be deleted: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlan(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	// Get plan first to show user what plan is being deleted
 	req := handle.GetPlanRequest{
@@ -975,9 +1093,10 @@ func deletePlan(p *handle.PlanControl) {
 	}
 	fmt.Println("Got plan: ", plan.Plan.PlanName)
 	fmt.Println("are you sure you want to delete this plan? (y/n)")
-	confirm, err := reader.ReadString('\n')
+	confirm, err := readLine()
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	confirm = strings.TrimSpace(confirm)
 	if confirm == "n" {
@@ -985,7 +1104,8 @@ func deletePlan(p *handle.PlanControl) {
 	} else if confirm == "y" {
 		fmt.Println("Deleting plan...")
 		req := handle.DeletePlanRequest{
-			Id: id,
+			Id:       id,
+			Revision: plan.Plan.Revision,
 		}
 		err = p.DeletePlan(&req)
 		if err != nil {
@@ -1001,22 +1121,28 @@ func deletePlan(p *handle.PlanControl) {
 // listPlans retrieves a list of plans from the PlanControl and prints them to the console.
 // It first prints a message indicating that the plans are being listed, then calls the ListPlans method of the PlanControl
 // to get the list of plans. It then iterates over the list and prints the ID, name, and description of each plan.
-func listPlans(p *handle.PlanControl) {
+func listPlans(p planAPI) {
 	fmt.Println("Listing plans...")
 
 	planChan := make(chan *models.Plan)
 
 	go func() {
-		plans, err := p.Service.ListPlans()
-		if err != nil {
-			log.Printf("Error listing plans: %s", err)
-			close(planChan)
-			return
-		}
-		for _, plan := range plans {
-			planChan <- plan
+		defer close(planChan)
+		req := &handle.ListRequest{}
+		for {
+			page, err := p.FindPlans(req)
+			if err != nil {
+				log.Printf("Error listing plans: %s", err)
+				return
+			}
+			for _, plan := range page.Plans {
+				planChan <- plan
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			req.Cursor = page.NextCursor
 		}
-		close(planChan)
 	}()
 
 	for plan := range planChan {
@@ -1028,16 +1154,17 @@ func listPlans(p *handle.PlanControl) {
 // It uses the PlannerControl struct to make a request to create the planner using the PlannerService.
 // If the creation is successful, it prints the planner's ID.
 // If there is an error during the creation process, it prints the error message.
-func createPlanner(p *handle.PlannerControl) {
+func createPlanner(p plannerAPI) {
 	fmt.Println("Creating planner...")
-	reader := bufio.NewReader(os.Stdin)
-	title, err := promptUser(reader, "Enter planner title: ")
+	title, err := promptUser("Enter planner title: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	userid, err := promptUser(reader, "Enter planner userid: ")
+	userid, err := promptUser("Enter planner userid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 
 	req := handle.CreatePlannerRequest{
@@ -1056,20 +1183,23 @@ func createPlanner(p *handle.PlannerControl) {
 // If there is an error reading from stdin, it will log a fatal error and exit.
 // If there is an error retrieving the planner, it will print an error message and return.
 // Otherwise, it will print the planner's title and owner's user ID.
-// Function signature: func getPlanner(p *handle.PlannerControl)
+// Function signature: func getPlanner(p plannerAPI)
 // Parameter:
 // - p: a pointer to a PlannerControl instance
 // Example usage:
 //
-//	reader := bufio.NewReader(os.Stdin)
-//	plannerControl := handle.PlannerControl{Service: &services.PlannerService{}}
+////	plannerControl := handle.PlannerControl{Service: &services.PlannerService{}}
 //	getPlanner(&plannerControl)
-func getPlanner(p *handle.PlannerControl) {
+func getPlanner(p plannerAPI) {
 	fmt.Println("Getting planner...")
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter planner id: ")
+	id, err := promptUser("Enter planner id: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlanner(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetPlannerRequest{
 		Id: id,
@@ -1083,12 +1213,12 @@ func getPlanner(p *handle.PlannerControl) {
 	fmt.Println("User ID: ", planner.UserId)
 }
 
-func getPlannerByGoal(p *handle.PlannerControl) {
+func getPlannerByGoal(p plannerAPI) {
 	fmt.Println("Getting planner...")
-	reader := bufio.NewReader(os.Stdin)
-	title, err := promptUser(reader, "Enter planner title: ")
+	title, err := promptUser("Enter planner title: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetPlannerByTitleRequest{
 		Title: title,
@@ -1104,12 +1234,12 @@ func getPlannerByGoal(p *handle.PlannerControl) {
 }
 
 // getPlannerByOwner retrieves a list of planners owned by a specific user.
-func getPlannerByOwner(p *handle.PlannerControl) {
+func getPlannerByOwner(p plannerAPI) {
 	fmt.Println("Getting planner...")
-	reader := bufio.NewReader(os.Stdin)
-	userid, err := promptUser(reader, "Enter planner userid: ")
+	userid, err := promptUser("Enter planner userid: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	req := handle.GetPlannerByOwnerRequest{
 		UserId: userid,
@@ -1129,15 +1259,20 @@ func getPlannerByOwner(p *handle.PlannerControl) {
 // updatePlanners is a function that updates a planner in the PlannerControl object.
 // It prompts the user to enter the planner ID of the planner to be updated.
 // It then retrieves the planner from the PlannerControl object using the GetPlanner method.
-// If the planner is found, it prompts the user to enter the new title and user ID for the planner.
-// It then creates an UpdatePlannerRequest object with the updated details.
-// Finally, it calls the UpdatePlanner method of the PlannerControl object to update the planner.
+// If the planner is found, it prompts the user to enter the new title and user ID for the planner;
+// pressing enter keeps the current value.
+// It then creates a PatchPlannerRequest object with the changed details.
+// Finally, it calls the PatchPlanner method of the PlannerControl object to update the planner.
 // If there is an error retrieving or updating the planner, it prints an error message.
-func updatePlanners(p *handle.PlannerControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter planner id of planner to be updated: ")
+func updatePlanners(p plannerAPI) {
+	id, err := promptUser("Enter planner id of planner to be updated: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlanner(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	req := handle.GetPlannerRequest{
 		Id: id,
@@ -1149,23 +1284,24 @@ func updatePlanners(p *handle.PlannerControl) {
 	}
 	fmt.Println("Got planner: ", planner.Title)
 	fmt.Println("User ID: ", planner.UserId)
-	fmt.Println("Enter New planner title: ")
+	fmt.Println("Enter New planner values (press enter to keep the current value): ")
 
-	title, err := promptUser(reader, "Enter planner title: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	patch := map[string]interface{}{}
+	if err := promptPatch("Enter planner title", planner.Title, "title", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	userid, err := promptUser(reader, "Enter planner userid: ")
-	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+	if err := promptPatch("Enter planner userid", planner.UserId, "user_id", patch); err != nil {
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
-	update := handle.UpdatePlannerRequest{
-		Id:     id,
-		Title:  title,
-		UserId: userid,
+	body, err := json.Marshal(patch)
+	if err != nil {
+		fmt.Printf("Error updating planner with id %s: %s\n", id, err)
+		return
 	}
 	fmt.Println("Updating planner...")
-	err = p.UpdatePlanner(&update)
+	_, err = p.PatchPlanner(&handle.PatchPlannerRequest{Id: id, Patch: body, Revision: planner.Revision})
 	if err != nil {
 		fmt.Printf("Error updating planner with id %s: %s\n", id, err)
 		return
@@ -1179,11 +1315,15 @@ func updatePlanners(p *handle.PlannerControl) {
 // If there is any error during the process, it prints an error message.
 // Parameters:
 // - p: a pointer to a PlannerControl struct that contains the planner service.
-func deletePlanner(p *handle.PlannerControl) {
-	reader := bufio.NewReader(os.Stdin)
-	id, err := promptUser(reader, "Enter planner id to be deleted: ")
+func deletePlanner(p plannerAPI) {
+	id, err := promptUser("Enter planner id to be deleted: ")
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
+	}
+	if id, err = p.ResolvePlanner(id); err != nil {
+		fmt.Printf("Error: %s\n", err)
+		return
 	}
 	// Get planner first to show user what planner is being deleted
 	req := handle.GetPlannerRequest{
@@ -1196,9 +1336,10 @@ func deletePlanner(p *handle.PlannerControl) {
 	}
 	fmt.Println("Got planner: ", planner.Title)
 	fmt.Println("are you sure you want to delete this planner? (y/n)")
-	confirm, err := reader.ReadString('\n')
+	confirm, err := readLine()
 	if err != nil {
-		log.Fatalf("Could not read from stdin: %s\n", err)
+		fmt.Printf("Could not read from stdin: %s\n", err)
+		return
 	}
 	confirm = strings.TrimSpace(confirm)
 	if confirm == "n" {
@@ -1206,7 +1347,8 @@ func deletePlanner(p *handle.PlannerControl) {
 	} else if confirm == "y" {
 		fmt.Println("Deleting planner...")
 		req := handle.DeletePlannerRequest{
-			Id: id,
+			Id:       id,
+			Revision: planner.Revision,
 		}
 		err = p.DeletePlanner(&req)
 		if err != nil {
@@ -1225,26 +1367,28 @@ func deletePlanner(p *handle.PlannerControl) {
 // Then, it calls the ListPlanners method of the PlannerControl service to get the list of planners.
 // If there is an error during the retrieval, it prints an error message and returns.
 // Otherwise, it iterates over the list of planners and prints the ID, Title, and User ID of each planner.
-func listPlanners(p *handle.PlannerControl) {
+func listPlanners(p plannerAPI) {
 	fmt.Println("Listing planners...")
 
 	plannerChan := make(chan *handle.GetPlannerResponse)
 
 	go func() {
-		planners, err := p.ListPlanners()
-		if err != nil {
-			fmt.Println("Error listing planners: ", err)
-			close(plannerChan)
-			return
-		}
-		for _, planner := range planners.Planners {
-			plannerChan <- &handle.GetPlannerResponse{
-				Id:     planner.Id,
-				Title:  planner.Title,
-				UserId: planner.UserId,
+		defer close(plannerChan)
+		req := &handle.ListRequest{}
+		for {
+			page, err := p.FindPlanners(req)
+			if err != nil {
+				fmt.Println("Error listing planners: ", err)
+				return
+			}
+			for _, planner := range page.Planners {
+				plannerChan <- planner
 			}
+			if page.NextCursor == "" {
+				return
+			}
+			req.Cursor = page.NextCursor
 		}
-		close(plannerChan)
 	}()
 
 	for planner := range plannerChan {
@@ -1252,30 +1396,16 @@ func listPlanners(p *handle.PlannerControl) {
 	}
 }
 
-// runCommand takes a command string as input and executes the corresponding command.
-// It initializes the necessary routers and database connection using cliSetup.
+// runCommand takes a command string as input and executes the corresponding command with ctl.
 // If the command string is empty, it prints a message and returns.
 // It trims any newline characters from the command string.
 // It splits the command string into individual words.
-// If the first word is "exit", it exits the application.
-// If the first word matches a command in the taskCommands map,
-// it executes the corresponding command using the taskRouter.
-// If the first word matches a command in the goalCommands map,
-// it executes the corresponding command using the goalRouter.
-// If the first word matches a command in the planCommands map,
-// it executes the corresponding command using the planRouter.
-// If the first word matches a command in the plannerCommands map,
-// it executes the corresponding command using the plannerRouter.
+// If the first word is "exit", it returns errExit.
+// If the first word matches a command in the taskCommands, goalCommands, planCommands or
+// plannerCommands map, it executes the corresponding command.
 // If no command matches the first word, it prints a message.
 // It returns nil to indicate success.
-func runCommand(commandStr string) error {
-	taskRouter, goalRouter, planRouter, plannerRouter, db := cliSetup()
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Fatalf("error closing db: %s", err)
-		}
-	}()
-
+func runCommand(ctl *Controls, commandStr string) error {
 	commandStr = strings.TrimSuffix(commandStr, "\n")
 	arrCommandStr := strings.Fields(commandStr)
 	if len(arrCommandStr) == 0 {
@@ -1286,17 +1416,21 @@ func runCommand(commandStr string) error {
 	commandName := arrCommandStr[0]
 
 	if commandName == "exit" {
-		os.Exit(0)
+		return errExit
+	}
+	if commandName == "help" {
+		commandBox()
+		return nil
 	}
 
 	if command, ok := taskCommands[commandName]; ok {
-		command(taskRouter)
+		command(ctl.Task)
 	} else if command, ok := goalCommands[commandName]; ok {
-		command(goalRouter)
+		command(ctl.Goal)
 	} else if command, ok := planCommands[commandName]; ok {
-		command(planRouter)
+		command(ctl.Plan)
 	} else if command, ok := plannerCommands[commandName]; ok {
-		command(plannerRouter)
+		command(ctl.Planner)
 	} else {
 		fmt.Println("Command not found")
 	}
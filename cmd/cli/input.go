@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"errors"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/peterh/liner"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// line is the line editor the shell reads the commands, and the answers to the prompts of the commands, from.
+// It keeps the terminal in raw mode while the shell runs, so everything the shell reads must go through it.
+// When stdin is not a terminal, it reads plain lines.
+var line *liner.State
+
+// completion is what tab completes in line.
+var completion *completer
+
+// openLine opens line, completing with the records of ctl, and loads the history from historyPath,
+// if there is one yet. It returns a function that saves the history and restores the terminal.
+func openLine(ctl *Controls, historyPath string) (func() error, error) {
+	line = liner.NewLiner()
+	line.SetCtrlCAborts(true)
+	line.SetTabCompletionStyle(liner.TabPrints)
+	completion = &completer{ctl: ctl}
+	line.SetCompleter(completion.complete)
+	if f, err := os.Open(historyPath); err == nil {
+		_, err = line.ReadHistory(f)
+		f.Close()
+		if err != nil {
+			line.Close()
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		line.Close()
+		return nil, err
+	}
+	return func() error {
+		defer line.Close()
+		f, err := os.OpenFile(historyPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := line.WriteHistory(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}, nil
+}
+
+// readCommand reads a command, which tab completes to the names of the commands, and adds it to the history.
+func readCommand() (string, error) {
+	completion.reset(true)
+	command, err := line.Prompt("")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(command) != "" {
+		line.AppendHistory(command)
+	}
+	return command, nil
+}
+
+// readLine reads the answer to a prompt of a command, which tab completes to the records.
+func readLine() (string, error) {
+	completion.reset(false)
+	return line.Prompt("")
+}
+
+// completer completes the names of the commands and their aliases at the command prompt, and the ids,
+// @"names" and names of the tasks, goals, plans and planners at the other prompts.
+type completer struct {
+	ctl *Controls
+	// command is set while a command is read
+	command bool
+	// records holds the completions of the records, loaded on the first tab of a prompt
+	records []string
+}
+
+// reset prepares c for the next line, a command or not.
+func (c *completer) reset(command bool) {
+	c.command = command
+	c.records = nil
+}
+
+// complete returns the completions of the text before the cursor, whose case does not matter.
+func (c *completer) complete(text string) []string {
+	var candidates []string
+	if c.command {
+		candidates = commandNames()
+	} else {
+		if c.records == nil {
+			c.records = c.loadRecords()
+		}
+		candidates = c.records
+	}
+	prefix := strings.ToLower(text)
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			completions = append(completions, candidate)
+		}
+	}
+	return completions
+}
+
+// loadRecords returns the ids, @"names" and names of the records the user can see. A list that
+// fails is left out, since a failed completion has nothing to show.
+func (c *completer) loadRecords() []string {
+	var records []string
+	add := func(id, name string) {
+		records = append(records, id)
+		if name != "" {
+			records = append(records, "@"+strconv.Quote(name), name)
+		}
+	}
+	if tasks, err := c.ctl.Task.FindTasks(&handle.ListRequest{}); err == nil {
+		for _, task := range tasks.Tasks {
+			add(task.ID, task.Title)
+		}
+	}
+	if goals, err := c.ctl.Goal.FindGoals(&handle.ListRequest{}); err == nil {
+		for _, goal := range goals.Goals {
+			add(goal.Id, goal.Objective)
+		}
+	}
+	if plans, err := c.ctl.Plan.FindPlans(&handle.ListRequest{}); err == nil {
+		for _, plan := range plans.Plans {
+			add(plan.Id, plan.PlanName)
+		}
+	}
+	if planners, err := c.ctl.Planner.FindPlanners(&handle.ListRequest{}); err == nil {
+		for _, planner := range planners.Planners {
+			add(planner.Id, planner.Title)
+		}
+	}
+	sort.Strings(records)
+	return records
+}
+
+// commandNames returns the names and aliases of the commands of the shell, sorted.
+func commandNames() []string {
+	names := []string{"exit", "help"}
+	for name := range taskCommands {
+		names = append(names, name)
+	}
+	for name := range goalCommands {
+		names = append(names, name)
+	}
+	for name := range planCommands {
+		names = append(names, name)
+	}
+	for name := range plannerCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
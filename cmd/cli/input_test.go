@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// setupControls returns the controls over a temporary database with a task, a goal, a plan and a planner.
+func setupControls(t *testing.T) (*Controls, *storm.DB) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	tasks := inmemory.NewInMemoryTaskStore(db)
+	goals := inmemory.NewInMemoryGoalStore(db)
+	plans := inmemory.NewInMemoryPlanStore(db)
+	planners := inmemory.NewInMemoryPlannerStore(db)
+	if err := tasks.CreateTask(&models.Task{ID: "task1", Title: "Write the notes"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := goals.CreateGoal(&models.Goal{Id: "goal1", Objective: "Ship 1.2"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if err := plans.CreatePlan(&models.Plan{Id: "plan1", PlanName: "release"}); err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if err := planners.CreatePlanner(&models.Planner{Id: "planner1"}); err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	return &Controls{
+		Task:    handle.NewTaskControl(services.NewTaskService(tasks)),
+		Goal:    handle.NewGoalControl(services.NewGoalService(goals)),
+		Plan:    handle.NewPlanControl(services.NewPlanService(plans)),
+		Planner: handle.NewPlannerControl(services.NewPlannerService(planners)),
+	}, db
+}
+
+func TestCompleter_Complete(t *testing.T) {
+	ctl, _ := setupControls(t)
+	c := &completer{ctl: ctl}
+
+	tests := []struct {
+		name    string
+		command bool
+		text    string
+		want    []string
+	}{
+		{name: "command", command: true, text: "create-p", want: []string{"create-plan", "create-planner"}},
+		{name: "command in any case", command: true, text: "GET-TASK-BY-", want: []string{"get-task-by-owner", "get-task-by-title"}},
+		{name: "alias", command: true, text: "gt", want: []string{"gt", "gtk", "gto"}},
+		{name: "no command", command: true, text: "task", want: nil},
+		{name: "records are not commands", command: true, text: "write", want: nil},
+		{name: "name", text: "write", want: []string{"Write the notes"}},
+		{name: "quoted name", text: "@\"w", want: []string{"@\"Write the notes\""}},
+		{name: "id", text: "PLAN", want: []string{"plan1", "planner1"}},
+		{name: "names after an @", text: "@", want: []string{"@\"Ship 1.2\"", "@\"Write the notes\"", "@\"release\""}},
+		{name: "commands are not records", text: "create", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.reset(tt.command)
+			assert.Equal(t, tt.want, c.complete(tt.text))
+		})
+	}
+}
+
+func TestCompleter_LoadRecords(t *testing.T) {
+	ctl, db := setupControls(t)
+	c := &completer{ctl: ctl}
+
+	// a planner without a title only completes to its id
+	want := []string{"@\"Ship 1.2\"", "@\"Write the notes\"", "@\"release\"", "Ship 1.2", "Write the notes",
+		"goal1", "plan1", "planner1", "release", "task1"}
+	assert.Equal(t, want, c.loadRecords())
+
+	// the records are loaded once per prompt
+	c.reset(false)
+	c.complete("")
+	if _, err := ctl.Task.CreateTask(handle.CreateTaskRequest{Title: "Tag"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	assert.Empty(t, c.complete("tag"))
+	c.reset(false)
+	assert.Equal(t, []string{"Tag"}, c.complete("tag"))
+
+	// the lists that fail are left out
+	_ = db.Close()
+	assert.Empty(t, c.loadRecords())
+}
+
+func TestCommandNames(t *testing.T) {
+	names := commandNames()
+	assert.True(t, sort.StringsAreSorted(names))
+	assert.Len(t, names, 2+len(taskCommands)+len(goalCommands)+len(planCommands)+len(plannerCommands))
+	for _, name := range []string{"exit", "help", "create-task", "ct", "get-goal", "up", "delete-planner", "dpl"} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestOpenLine_History(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	// no history yet
+	closeLine, err := openLine(&Controls{}, path)
+	if err != nil {
+		t.Fatalf("failed to open line: %v", err)
+	}
+	line.AppendHistory("ct")
+	line.AppendHistory("get-task task1")
+	if err := closeLine(); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	assert.Equal(t, "ct\nget-task task1\n", string(data))
+	if info, err := os.Stat(path); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// the history is loaded and saved back with the new commands
+	closeLine, err = openLine(&Controls{}, path)
+	if err != nil {
+		t.Fatalf("failed to open line: %v", err)
+	}
+	line.AppendHistory("exit")
+	if err := closeLine(); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	assert.Equal(t, "ct\nget-task task1\nexit\n", string(data))
+
+	// a history that cannot be read is an error
+	if _, err := openLine(&Controls{}, t.TempDir()); err == nil {
+		t.Fatalf("expected a directory not to be read as the history")
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/asdine/storm"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/api"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var markUsageErrorsOnce sync.Once
+
+// flowServer is a flow server over a temporary database for the commands under test to work against with --remote,
+// as the daemon cannot be started from a test.
+type flowServer struct {
+	url    string
+	dbPath string
+}
+
+// newFlowServer serves the routes of tasks, goals, plans and planners over a temporary database.
+func newFlowServer(t *testing.T) *flowServer {
+	dir := t.TempDir()
+	server := httptest.NewServer(newFlowRouter(t, filepath.Join(dir, "test.db")))
+	t.Cleanup(server.Close)
+	return &flowServer{url: server.URL, dbPath: filepath.Join(dir, "flow.db")}
+}
+
+// newFlowRouter opens the database at path and routes the health probe and the requests of tasks, goals,
+// plans and planners to it, as the daemon does.
+func newFlowRouter(t *testing.T, path string) *mux.Router {
+	db, err := storm.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	handlers := &api.Handlers{
+		Task:    &api.TaskHandler{Control: handle.NewTaskControl(services.NewTaskService(inmemory.NewInMemoryTaskStore(db)))},
+		Goal:    &api.GoalHandler{Control: handle.NewGoalControl(services.NewGoalService(inmemory.NewInMemoryGoalStore(db)))},
+		Plan:    &api.PlanHandler{Control: handle.NewPlanControl(services.NewPlanService(inmemory.NewInMemoryPlanStore(db)))},
+		Planner: &api.PlannerHandler{Control: handle.NewPlannerControl(services.NewPlannerService(inmemory.NewInMemoryPlannerStore(db)))},
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/healthz", (&api.HealthHandler{}).Healthz).Methods("GET")
+	api.Register(r, api.Routes(handlers))
+	return r
+}
+
+// run runs flow with args against s and returns what it printed and its exit code.
+func (s *flowServer) run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	return runFlow(t, append(args, "--remote", s.url, "--token", "flow_test", "--db", s.dbPath)...)
+}
+
+// runJSON runs flow with args and --output json against s, expecting it to succeed, and decodes what it printed into v.
+func (s *flowServer) runJSON(t *testing.T, v interface{}, args ...string) {
+	t.Helper()
+	out, code := s.run(t, append(args, "-o", "json")...)
+	if code != 0 {
+		t.Fatalf("flow %s exited with %d: %s", strings.Join(args, " "), code, out)
+	}
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("failed to decode the output of flow %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// runFlow runs flow with args as Execute does and returns its output, errors included, and its exit code.
+func runFlow(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	markUsageErrorsOnce.Do(func() { markUsageErrors(rootCmd) })
+	resetFlags(rootCmd)
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	})
+	err := rootCmd.Execute()
+	return out.String(), ExitCode(err)
+}
+
+// resetFlags sets the flags of cmd and its subcommands back to their defaults, which cobra keeps from one run to the next.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
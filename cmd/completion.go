@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/ref"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+// completeFunc completes an argument or the value of a flag, as cobra's ValidArgsFunction.
+type completeFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completionControls returns the controls the completions look the records up with, and a function that
+// releases them. Pressing tab should not start a daemon, so without --remote or --profile it asks the daemon
+// only if one is running, and otherwise reads the database itself.
+func completionControls(cmd *cobra.Command) (*cli.Controls, func(), error) {
+	// cobra does not run the PersistentPreRunE hooks when it completes
+	if err := rootCmd.PersistentPreRunE(cmd, nil); err != nil {
+		return nil, nil, err
+	}
+	if remote != "" || profile != "" {
+		c, err := connect()
+		if err != nil {
+			return nil, nil, err
+		}
+		return cli.NewControls(c), func() {}, nil
+	}
+	if c := client.NewSocketClient(conf.GetSocketPath()); c.Ping() == nil {
+		return cli.NewControls(c), func() {}, nil
+	}
+	db, err := openDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cli.Controls{
+		Task:    handle.NewTaskControl(services.NewTaskService(inmemory.NewInMemoryTaskStore(db))),
+		Goal:    handle.NewGoalControl(services.NewGoalService(inmemory.NewInMemoryGoalStore(db))),
+		Plan:    handle.NewPlanControl(services.NewPlanService(inmemory.NewInMemoryPlanStore(db))),
+		Planner: handle.NewPlannerControl(services.NewPlannerService(inmemory.NewInMemoryPlannerStore(db))),
+	}, func() { db.Close() }, nil
+}
+
+// completeRefs returns a completeFunc that offers the records list returns: their ids, described by their names,
+// or, once an @ is typed, their names.
+func completeRefs(list func(ctl *cli.Controls) ([]ref.Candidate, error)) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctl, release, err := completionControls(cmd)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveError
+		}
+		defer release()
+		candidates, err := list(ctl)
+		if err != nil {
+			cobra.CompDebugln(err.Error(), false)
+			return nil, cobra.ShellCompDirectiveError
+		}
+		completions := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			if !strings.HasPrefix(toComplete, "@") {
+				completions = append(completions, candidate.Id+"\t"+candidate.Name)
+			} else if candidate.Name != "" {
+				completions = append(completions, "@"+candidate.Name+"\t"+candidate.Id)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFirstArg returns a ValidArgsFunction that completes the first argument with complete, and no other.
+func completeFirstArg(complete completeFunc) completeFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completeStatus offers the statuses of goals and plans, as the command line writes them.
+func completeStatus(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		completions = append(completions, statusArg(status))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// taskRefs, goalRefs, planRefs and plannerRefs list the records the user can see, as candidates of references.
+
+func taskRefs(ctl *cli.Controls) ([]ref.Candidate, error) {
+	res, err := ctl.Task.FindTasks(&handle.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]ref.Candidate, 0, len(res.Tasks))
+	for _, task := range res.Tasks {
+		candidates = append(candidates, ref.Candidate{Id: task.ID, Name: task.Title})
+	}
+	return candidates, nil
+}
+
+func goalRefs(ctl *cli.Controls) ([]ref.Candidate, error) {
+	res, err := ctl.Goal.FindGoals(&handle.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]ref.Candidate, 0, len(res.Goals))
+	for _, goal := range res.Goals {
+		candidates = append(candidates, ref.Candidate{Id: goal.Id, Name: goal.Objective})
+	}
+	return candidates, nil
+}
+
+func planRefs(ctl *cli.Controls) ([]ref.Candidate, error) {
+	res, err := ctl.Plan.FindPlans(&handle.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]ref.Candidate, 0, len(res.Plans))
+	for _, plan := range res.Plans {
+		candidates = append(candidates, ref.Candidate{Id: plan.Id, Name: plan.PlanName})
+	}
+	return candidates, nil
+}
+
+func plannerRefs(ctl *cli.Controls) ([]ref.Candidate, error) {
+	res, err := ctl.Planner.FindPlanners(&handle.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]ref.Candidate, 0, len(res.Planners))
+	for _, planner := range res.Planners {
+		candidates = append(candidates, ref.Candidate{Id: planner.Id, Name: planner.Title})
+	}
+	return candidates, nil
+}
+
+// roles are the roles of the members of a planner, which flow planner share completes.
+var roles = []string{models.RoleViewer, models.RoleEditor, models.RoleOwner}
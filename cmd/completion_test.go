@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// seedDB creates a database at path with a task, a goal, a plan and a planner.
+func seedDB(t *testing.T, path string) {
+	db, err := storm.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	if err := inmemory.NewInMemoryTaskStore(db).CreateTask(&models.Task{ID: "task1", Title: "Write the notes", PlanId: "plan1"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := inmemory.NewInMemoryGoalStore(db).CreateGoal(&models.Goal{Id: "goal1", Objective: "Ship 1.2", PlannerId: "planner1"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if err := inmemory.NewInMemoryPlanStore(db).CreatePlan(&models.Plan{Id: "plan1", PlanName: "Release", GoalId: "goal1"}); err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if err := inmemory.NewInMemoryPlannerStore(db).CreatePlanner(&models.Planner{Id: "planner1", Title: "Work"}); err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+}
+
+// complete runs cobra's __complete with args, the last of which is the word to complete, and returns
+// the completions flow offers and its directive.
+func complete(t *testing.T, args ...string) ([]string, cobra.ShellCompDirective) {
+	t.Helper()
+	out, code := runFlow(t, append([]string{"__complete"}, args...)...)
+	if code != 0 {
+		t.Fatalf("flow __complete %s exited with %d: %s", strings.Join(args, " "), code, out)
+	}
+	var completions []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, ":") {
+			directive, err := strconv.Atoi(line[1:])
+			if err != nil {
+				t.Fatalf("failed to read the directive of %q: %v", line, err)
+			}
+			return completions, cobra.ShellCompDirective(directive)
+		}
+		completions = append(completions, line)
+	}
+	t.Fatalf("no directive in the output of flow __complete %s: %s", strings.Join(args, " "), out)
+	return nil, 0
+}
+
+// testCompletions checks the completions flow offers for the database at dbPath, and with extra after it, if any.
+func testCompletions(t *testing.T, dbPath string, extra ...string) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "task", args: []string{"task", "get", ""}, want: []string{"task1\tWrite the notes"}},
+		{name: "task by name", args: []string{"task", "delete", "@"}, want: []string{"@Write the notes\ttask1"}},
+		{name: "second argument", args: []string{"task", "get", "task1", ""}},
+		{name: "goal", args: []string{"goal", "update", ""}, want: []string{"goal1\tShip 1.2"}},
+		{name: "plan", args: []string{"plan", "get", "@R"}, want: []string{"@Release\tplan1"}},
+		{name: "planner", args: []string{"planner", "share", ""}, want: []string{"planner1\tWork"}},
+		{name: "role", args: []string{"planner", "share", "planner1", "bob", ""}, want: []string{"viewer", "editor", "owner"}},
+		{name: "plan flag", args: []string{"task", "create", "--plan", ""}, want: []string{"plan1\tRelease"}},
+		{name: "goal flag", args: []string{"plan", "list", "--goal", "@"}, want: []string{"@Ship 1.2\tgoal1"}},
+		{name: "planner flag", args: []string{"goal", "create", "--planner", ""}, want: []string{"planner1\tWork"}},
+		{name: "status flag", args: []string{"goal", "list", "--status", ""}, want: []string{"not-started", "in-progress", "completed", "fail"}},
+		{name: "output flag", args: []string{"plan", "list", "-o", ""}, want: []string{"table", "json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--db", dbPath}, extra...)
+			completions, directive := complete(t, append(args, tt.args...)...)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+			assert.Equal(t, tt.want, completions)
+		})
+	}
+}
+
+func TestCompletion_Database(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "flow.db")
+	seedDB(t, dbPath)
+	// no daemon listens on the socket of the database, so the completions read it
+	testCompletions(t, dbPath)
+}
+
+func TestCompletion_Daemon(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "flow.db")
+	seedDB(t, dbPath)
+	listener, err := net.Listen("unix", dbPath+".sock")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: newFlowRouter(t, dbPath)}
+	go server.Serve(listener)
+	t.Cleanup(func() { _ = server.Close() })
+	// the daemon holds the database, so the completions ask it
+	testCompletions(t, dbPath)
+}
+
+func TestCompletion_Remote(t *testing.T) {
+	s := newFlowServer(t)
+	s.runJSON(t, &map[string]string{}, "planner", "create", "--title", "Work")
+	completeRemote := func(args ...string) []string {
+		completions, directive := complete(t, append([]string{"--db", s.dbPath, "--remote", s.url, "--token", "flow_test"}, args...)...)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+		return completions
+	}
+	completions := completeRemote("planner", "get", "@")
+	if assert.Len(t, completions, 1) {
+		assert.True(t, strings.HasPrefix(completions[0], "@Work\t"), completions[0])
+	}
+	assert.Empty(t, completeRemote("task", "get", ""))
+}
+
+func TestCompletion_Unavailable(t *testing.T) {
+	dir := t.TempDir()
+	// the database is a directory, which neither a daemon nor the completions can open
+	completions, directive := complete(t, "--db", dir, "task", "get", "")
+	assert.Equal(t, cobra.ShellCompDirectiveError, directive)
+	assert.Empty(t, completions)
+
+	// a server that refuses the connection is an error too, rather than the local database
+	completions, directive = complete(t, "--db", filepath.Join(dir, "flow.db"), "--remote", "http://127.0.0.1:1", "--token", "flow_test", "task", "get", "")
+	assert.Equal(t, cobra.ShellCompDirectiveError, directive)
+	assert.Empty(t, completions)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/server"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/spf13/cobra"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// daemonStartTimeout is how long ensureDaemon waits for a daemon it started to answer on its socket.
+const daemonStartTimeout = 5 * time.Second
+
+// daemonConfig holds the settings of the daemon command, set from its flags.
+// The daemon only listens on its socket unless it is given a port.
+var daemonConfig = func() *server.Config {
+	cfg := server.DefaultConfig()
+	cfg.Port = 0
+	cfg.GRPCPort = 0
+	return cfg
+}()
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStopCmd, daemonStatusCmd)
+	addServerFlags(daemonCmd, daemonConfig)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "run the flow daemon, which owns the database",
+	Long: `run the flow daemon, which owns the database and serves it on a Unix socket next to it,
+and on TCP with --port. The cli and the chat are clients of the daemon, and start it in the background
+when it is not running, so that any number of them can work on the same data. "flow server" becomes an
+HTTP front end to a running daemon.
+
+Requests over the socket come from the user who runs the daemon and need no API token.
+
+Example usage:
+flow daemon
+flow daemon --port 8080 --grpc-port 9090
+flow daemon stop`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		daemonConfig.Socket = conf.GetSocketPath()
+		return daemonConfig.Validate()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return server.Run(daemonConfig)
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "stop the daemon of the database",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := conf.GetSocketPath()
+		c := client.NewSocketClient(socket)
+		if c.Ping() != nil {
+			return fmt.Errorf("no daemon is listening on %s", socket)
+		}
+		if err := c.StopDaemon(); err != nil {
+			return err
+		}
+		// the database is unlocked once the daemon stops answering
+		for deadline := time.Now().Add(daemonConfig.ShutdownTimeout + time.Second); c.Ping() == nil; {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("the daemon on %s is still running", socket)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Stopped the daemon")
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "report whether the daemon of the database is running",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := conf.GetSocketPath()
+		if err := client.NewSocketClient(socket).Ping(); err != nil {
+			return fmt.Errorf("no daemon is listening on %s", socket)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "The daemon is listening on %s\n", socket)
+		return nil
+	},
+}
+
+// ensureDaemon returns a client of the daemon of the database, starting it in the background if it is not running.
+// The output of a daemon started this way goes to conf.GetDaemonLogPath().
+func ensureDaemon() (*client.Client, error) {
+	c := client.NewSocketClient(conf.GetSocketPath())
+	if c.Ping() == nil {
+		return c, nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error starting the daemon: %w", err)
+	}
+	logFile, err := os.OpenFile(conf.GetDaemonLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the daemon: %w", err)
+	}
+	defer logFile.Close()
+	daemon := exec.Command(executable, "daemon", "--db", conf.GetDBPath())
+	daemon.Stdout = logFile
+	daemon.Stderr = logFile
+	daemon.SysProcAttr = detached()
+	if err := daemon.Start(); err != nil {
+		return nil, fmt.Errorf("error starting the daemon: %w", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = daemon.Wait()
+		close(exited)
+	}()
+	for deadline := time.Now().Add(daemonStartTimeout); ; time.Sleep(50 * time.Millisecond) {
+		if c.Ping() == nil {
+			return c, nil
+		}
+		select {
+		case <-exited:
+			// another process may have started a daemon first
+			if c.Ping() == nil {
+				return c, nil
+			}
+			return nil, fmt.Errorf("the daemon did not start; see %s", conf.GetDaemonLogPath())
+		default:
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("the daemon did not answer within %v; see %s", daemonStartTimeout, conf.GetDaemonLogPath())
+		}
+	}
+}
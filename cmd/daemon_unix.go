@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+// detached returns the attributes that start the daemon in a session of its own,
+// so that it keeps running when the terminal of the cli that started it closes.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
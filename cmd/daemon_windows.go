@@ -0,0 +1,9 @@
+package cmd
+
+import "syscall"
+
+// detached returns the attributes that start the daemon in a process group of its own,
+// so that it keeps running when the console of the cli that started it closes.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/conf"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+// openDB opens the flow database for the completions, which read it directly rather than start the daemon.
+// Bolt allows a single process to open the file, so it gives up after a second if the daemon or the server holds it.
+func openDB() (*storm.DB, error) {
+	db, err := storm.Open(conf.GetDBPath(), storm.BoltOptions(0600, &bolt.Options{Timeout: time.Second}))
+	if err != nil {
+		return nil, fmt.Errorf("error opening db %s (stop the daemon with `flow daemon stop` or the server first): %w", conf.GetDBPath(), err)
+	}
+	return db, nil
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/cmd/editor"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+	editCmd.AddCommand(editTaskCmd, editGoalCmd, editPlanCmd, editPlannerCmd)
+	addRemoteFlags(editCmd)
+	addOutputFlag(editCmd)
+
+	editTaskCmd.ValidArgsFunction = completeFirstArg(completeRefs(taskRefs))
+	editGoalCmd.ValidArgsFunction = completeFirstArg(completeRefs(goalRefs))
+	editPlanCmd.ValidArgsFunction = completeFirstArg(completeRefs(planRefs))
+	editPlannerCmd.ValidArgsFunction = completeFirstArg(completeRefs(plannerRefs))
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "change a task, goal, plan or planner in your editor",
+	Long: `change a task, goal, plan or planner in your editor, $VISUAL or $EDITOR, as a commented YAML document.
+When the editor exits, the fields you changed are checked and updated at once, and the record is printed.
+If a field is invalid, the editor opens again with the error at the top; save the document unchanged to give up.
+If the record changed in the meantime, nothing is updated and the command exits with 5.
+
+The record is given by its id, a unique prefix of it such as 3fa8, or its name after an @, such as @"Ship v2".
+
+Example usage:
+flow edit task 3fa8
+EDITOR="code --wait" flow edit goal @"Ship v2"`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var editTaskCmd = &cobra.Command{
+	Use:          "task <task id>",
+	Short:        "change a task in your editor",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Task.ResolveTask(args[0])
+			if err != nil {
+				return err
+			}
+			task, err := ctl.Task.GetTask(&handle.GetTaskRequest{ID: id})
+			if err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			fields := []editField{
+				{Field: editor.Field{Key: "title", Value: task.Title}, patch: "title", parse: required},
+				{Field: editor.Field{Key: "description", Value: task.Description}, patch: "description"},
+				{Field: editor.Field{Key: "owner", Value: task.Owner}, patch: "owner"},
+				{Field: editor.Field{Key: "plan", Value: task.PlanId, Note: planName(ctl, task.PlanId),
+					Comment: "the plan of the task: its id, a unique prefix of it or @\"name\"; empty for none"},
+					patch: "plan_id", parse: optionalRef(ctl.Plan.ResolvePlan)},
+				{Field: editor.Field{Key: "started", Value: task.Started}, patch: "started"},
+				{Field: editor.Field{Key: "completed", Value: task.Completed}, patch: "completed"},
+				{Field: editor.Field{Key: "failed", Value: task.Failed}, patch: "failed"},
+			}
+			header := editHeader("task", task.ID, task.Revision, task.CreatedAt, task.UpdatedAt)
+			return editRecord(cmd, header, fields, func(patch json.RawMessage) error {
+				task, err := ctl.Task.PatchTask(&handle.PatchTaskRequest{ID: id, Patch: patch, Revision: task.Revision})
+				if err != nil {
+					return fmt.Errorf("task %s: %w", id, err)
+				}
+				return printTasks(cmd, task, []*handle.GetTaskResponse{task})
+			})
+		})
+	},
+}
+
+var editGoalCmd = &cobra.Command{
+	Use:          "goal <goal id>",
+	Short:        "change a goal in your editor",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Goal.ResolveGoal(args[0])
+			if err != nil {
+				return err
+			}
+			res, err := ctl.Goal.GetGoal(&handle.GetGoalRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("goal %s: %w", id, err)
+			}
+			goal := res.Goal
+			fields := []editField{
+				{Field: editor.Field{Key: "objective", Value: goal.Objective}, patch: "objective", parse: required},
+				{Field: editor.Field{Key: "deadline", Value: formatDate(goal.Deadline, "2006-01-02"), Comment: "as YYYY-MM-DD"},
+					patch: "deadline", parse: layout("2006-01-02")},
+				{Field: editor.Field{Key: "planner", Value: goal.PlannerId, Note: plannerTitle(ctl, goal.PlannerId),
+					Comment: "the planner of the goal: its id, a unique prefix of it or @\"title\"; empty for none"},
+					patch: "planner_id", parse: optionalRef(ctl.Planner.ResolvePlanner)},
+				{Field: editor.Field{Key: "status", Value: goal.GoalStatus, Comment: statusComment()},
+					patch: "goal_status", parse: parseStatus},
+			}
+			header := editHeader("goal", goal.Id, goal.Revision, goal.GoalCreatedAt, goal.GoalUpdatedAt)
+			return editRecord(cmd, header, fields, func(patch json.RawMessage) error {
+				res, err := ctl.Goal.PatchGoal(&handle.PatchGoalRequest{Id: id, Patch: patch, Revision: goal.Revision})
+				if err != nil {
+					return fmt.Errorf("goal %s: %w", id, err)
+				}
+				return printGoals(cmd, res.Goal, []*models.Goal{res.Goal})
+			})
+		})
+	},
+}
+
+var editPlanCmd = &cobra.Command{
+	Use:          "plan <plan id>",
+	Short:        "change a plan in your editor",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Plan.ResolvePlan(args[0])
+			if err != nil {
+				return err
+			}
+			res, err := ctl.Plan.GetPlan(&handle.GetPlanRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("plan %s: %w", id, err)
+			}
+			plan := res.Plan
+			fields := []editField{
+				{Field: editor.Field{Key: "name", Value: plan.PlanName}, patch: "plan_name", parse: required},
+				{Field: editor.Field{Key: "description", Value: plan.PlanDescription}, patch: "plan_description"},
+				{Field: editor.Field{Key: "date", Value: formatDate(plan.PlanDate, "2006-01-02"), Comment: "as YYYY-MM-DD"},
+					patch: "plan_date", parse: layout("2006-01-02")},
+				{Field: editor.Field{Key: "time", Value: formatDate(plan.PlanTime, "15:04"), Comment: "as HH:MM"},
+					patch: "plan_time", parse: layout("15:04")},
+				{Field: editor.Field{Key: "goal", Value: plan.GoalId, Note: goalObjective(ctl, plan.GoalId),
+					Comment: "the goal of the plan: its id, a unique prefix of it or @\"objective\"; empty for none"},
+					patch: "goal_id", parse: optionalRef(ctl.Goal.ResolveGoal)},
+				{Field: editor.Field{Key: "status", Value: plan.PlanStatus, Comment: statusComment()},
+					patch: "plan_status", parse: parseStatus},
+			}
+			header := editHeader("plan", plan.Id, plan.Revision, plan.PlanCreatedAt, plan.PlanUpdatedAt)
+			return editRecord(cmd, header, fields, func(patch json.RawMessage) error {
+				res, err := ctl.Plan.PatchPlan(&handle.PatchPlanRequest{Id: id, Patch: patch, Revision: plan.Revision})
+				if err != nil {
+					return fmt.Errorf("plan %s: %w", id, err)
+				}
+				return printPlans(cmd, res.Plan, []*models.Plan{res.Plan})
+			})
+		})
+	},
+}
+
+var editPlannerCmd = &cobra.Command{
+	Use:          "planner <planner id>",
+	Short:        "change a planner in your editor",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			planner, err := ctl.Planner.GetPlanner(&handle.GetPlannerRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			fields := []editField{
+				{Field: editor.Field{Key: "title", Value: planner.Title}, patch: "title", parse: required},
+				{Field: editor.Field{Key: "owner", Value: planner.UserId}, patch: "user_id", parse: required},
+			}
+			header := editHeader("planner", planner.Id, planner.Revision, time.Time{}, time.Time{}) +
+				"\nMembers are changed with flow planner share and unshare."
+			return editRecord(cmd, header, fields, func(patch json.RawMessage) error {
+				planner, err := ctl.Planner.PatchPlanner(&handle.PatchPlannerRequest{Id: id, Patch: patch, Revision: planner.Revision})
+				if err != nil {
+					return fmt.Errorf("planner %s: %w", id, err)
+				}
+				return printPlanners(cmd, planner, []*handle.GetPlannerResponse{planner})
+			})
+		})
+	},
+}
+
+// editField is a field of a record flow edit shows, with the json name it is patched under and
+// parse, which checks a new text and returns the value to patch; without parse, texts are patched as they are.
+type editField struct {
+	editor.Field
+	patch string
+	parse func(value string) (string, error)
+}
+
+// errNoChanges is returned by the check of editRecord when the fields were edited back to their values.
+var errNoChanges = errors.New("no changes")
+
+// editRecord lets the user change fields in their editor, under header, and passes the merge patch of the fields
+// they changed to apply. An invalid field, or a patch apply fails with handle.ErrInvalidPatch, opens the editor again.
+func editRecord(cmd *cobra.Command, header string, fields []editField, apply func(patch json.RawMessage) error) error {
+	shown := make([]editor.Field, len(fields))
+	for i, field := range fields {
+		shown[i] = field.Field
+	}
+	doc, err := editor.Render(header, shown)
+	if err != nil {
+		return err
+	}
+	err = editor.Edit(doc, func(edited []byte) error {
+		values, err := editor.Parse(edited, shown)
+		if err != nil {
+			return editor.Invalid(err)
+		}
+		patch := map[string]interface{}{}
+		for _, field := range fields {
+			value := values[field.Key]
+			if value == field.Value {
+				continue
+			}
+			if text, ok := value.(string); ok && field.parse != nil {
+				if value, err = field.parse(text); err != nil {
+					return editor.Invalid(fmt.Errorf("%s: %w", field.Key, err))
+				}
+			}
+			patch[field.patch] = value
+		}
+		if len(patch) == 0 {
+			return errNoChanges
+		}
+		body, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		err = apply(body)
+		if errors.Is(err, handle.ErrInvalidPatch) {
+			return editor.Invalid(err)
+		}
+		return err
+	})
+	if errors.Is(err, editor.ErrUnchanged) || errors.Is(err, errNoChanges) {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Edit cancelled, no changes made.")
+		return nil
+	}
+	return err
+}
+
+// editHeader returns the comment at the top of the document of a record, with what cannot be edited.
+func editHeader(kind, id string, revision int, created, updated time.Time) string {
+	header := fmt.Sprintf("Edit the %s below; lines starting with # are ignored. Save it unchanged to cancel.\n\n%s %s, revision %d",
+		kind, kind, id, revision)
+	if !created.IsZero() {
+		header += fmt.Sprintf(", created %s, updated %s", created.Format(time.RFC3339), updated.Format(time.RFC3339))
+	}
+	return header
+}
+
+// statusComment returns the comment of the status field, with the statuses.
+func statusComment() string {
+	args := make([]string, len(statuses))
+	for i, status := range statuses {
+		args[i] = statusArg(status)
+	}
+	return "one of " + strings.Join(args, ", ")
+}
+
+// required accepts any text but an empty one.
+func required(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", errors.New("must not be empty")
+	}
+	return value, nil
+}
+
+// layout returns a parse function that accepts the times of a layout of package time.
+func layout(layout string) func(string) (string, error) {
+	return func(value string) (string, error) {
+		if _, err := time.Parse(layout, value); err != nil {
+			return "", fmt.Errorf("invalid value %q: expected %s", value, layout)
+		}
+		return value, nil
+	}
+}
+
+// optionalRef returns a parse function that resolves a reference to a record, unless it is empty.
+func optionalRef(resolve func(string) (string, error)) func(string) (string, error) {
+	return func(value string) (string, error) {
+		err := resolveRef(&value, resolve)
+		return value, err
+	}
+}
+
+// planName returns the name of the plan with the given id for a note in the document, or nothing if it cannot be read.
+func planName(ctl *cli.Controls, id string) string {
+	if id == "" {
+		return ""
+	}
+	if res, err := ctl.Plan.GetPlan(&handle.GetPlanRequest{Id: id}); err == nil {
+		return res.Plan.PlanName
+	}
+	return ""
+}
+
+// goalObjective returns the objective of the goal with the given id for a note, or nothing if it cannot be read.
+func goalObjective(ctl *cli.Controls, id string) string {
+	if id == "" {
+		return ""
+	}
+	if res, err := ctl.Goal.GetGoal(&handle.GetGoalRequest{Id: id}); err == nil {
+		return res.Goal.Objective
+	}
+	return ""
+}
+
+// plannerTitle returns the title of the planner with the given id for a note, or nothing if it cannot be read.
+func plannerTitle(ctl *cli.Controls, id string) string {
+	if id == "" {
+		return ""
+	}
+	if planner, err := ctl.Planner.GetPlanner(&handle.GetPlannerRequest{Id: id}); err == nil {
+		return planner.Title
+	}
+	return ""
+}
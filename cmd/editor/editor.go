@@ -0,0 +1,218 @@
+// Package editor lets the user change the fields of a record in their text editor, as a commented YAML document.
+package editor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnchanged is returned by Edit when the user leaves the document as it was.
+var ErrUnchanged = errors.New("the document was not changed")
+
+// Field is a field of a record in the document: a text or a boolean, under a key.
+type Field struct {
+	Key string
+	// Comment is written on the lines above the field
+	Comment string
+	// Note is written after the value, such as the name of the record an id refers to
+	Note  string
+	Value interface{}
+}
+
+// InvalidError is an error of the check of Edit that the user can fix: the editor is opened again with it.
+type InvalidError struct {
+	Err error
+}
+
+// Error returns the message of the wrapped error, which Edit writes above the document.
+func (e *InvalidError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error, so that errors.Is and errors.As see through an InvalidError.
+func (e *InvalidError) Unwrap() error {
+	return e.Err
+}
+
+// Invalid marks err, unless it is nil, as an error the user can fix in the editor.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &InvalidError{Err: err}
+}
+
+// Render returns the fields as a YAML document, in their order, under header, a comment.
+func Render(header string, fields []Field) ([]byte, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range fields {
+		value := &yaml.Node{}
+		if err := value.Encode(field.Value); err != nil {
+			return nil, err
+		}
+		value.LineComment = field.Note
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: field.Key, HeadComment: field.Comment}, value)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, HeadComment: header, Content: []*yaml.Node{mapping}}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse returns the values of the fields in doc, by key. A field left out of doc keeps the value it has in fields;
+// a key that is not one of fields, or a value of the wrong type, fails. An empty value is the empty text.
+func Parse(doc []byte, fields []Field) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(fields))
+	byKey := make(map[string]Field, len(fields))
+	for _, field := range fields {
+		values[field.Key] = field.Value
+		byKey[field.Key] = field
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return values, nil
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, errors.New("expected the fields of the record, as key: value")
+	}
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		field, ok := byKey[key.Value]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", key.Value)
+		}
+		if seen[key.Value] {
+			return nil, fmt.Errorf("%s is given twice", key.Value)
+		}
+		seen[key.Value] = true
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%s must be a single value", key.Value)
+		}
+		switch field.Value.(type) {
+		case bool:
+			var b bool
+			if err := value.Decode(&b); err != nil {
+				return nil, fmt.Errorf("%s must be true or false", key.Value)
+			}
+			values[key.Value] = b
+		default:
+			if value.Tag == "!!null" {
+				values[key.Value] = ""
+			} else {
+				values[key.Value] = value.Value
+			}
+		}
+	}
+	return values, nil
+}
+
+// Edit opens doc in the user's editor and passes what they save to check, until check accepts it.
+// If check fails with an InvalidError, the editor is opened again with what the user saved and the error
+// written above it, which check is not passed; other errors of check are returned. Edit fails with ErrUnchanged
+// if the user saves the document as it was shown, or with the last error of check if they do so after an error.
+func Edit(doc []byte, check func(edited []byte) error) error {
+	file, err := os.CreateTemp("", "flow-edit-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	shown := doc
+	var lastErr error
+	for {
+		if err := os.WriteFile(file.Name(), shown, 0600); err != nil {
+			return err
+		}
+		if err := open(file.Name()); err != nil {
+			return err
+		}
+		edited, err := os.ReadFile(file.Name())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, shown) {
+			if lastErr != nil {
+				return lastErr
+			}
+			return ErrUnchanged
+		}
+		err = check(withoutError(edited))
+		var invalid *InvalidError
+		if !errors.As(err, &invalid) {
+			return err
+		}
+		lastErr = invalid.Err
+		shown = annotate(edited, invalid.Err)
+	}
+}
+
+// errorHeader starts the comment Edit writes above the document for the error of its check.
+const errorHeader = "# Error: "
+
+// annotate returns doc with a comment of err above it, instead of the one of an earlier error.
+func annotate(doc []byte, err error) []byte {
+	var buf bytes.Buffer
+	lines := strings.Split(err.Error(), "\n")
+	buf.WriteString(errorHeader + lines[0] + "\n")
+	for _, line := range lines[1:] {
+		buf.WriteString("#        " + line + "\n")
+	}
+	buf.WriteString("#\n")
+	buf.Write(withoutError(doc))
+	return buf.Bytes()
+}
+
+// withoutError returns doc without the comment annotate wrote above it, which ends with an empty comment line.
+func withoutError(doc []byte) []byte {
+	if bytes.HasPrefix(doc, []byte(errorHeader)) {
+		if end := bytes.Index(doc, []byte("\n#\n")); end >= 0 {
+			return doc[end+3:]
+		}
+	}
+	return doc
+}
+
+// open runs the editor of $VISUAL or $EDITOR, which may have arguments, on path and waits for it to exit.
+// Without either, it runs vi, or notepad on Windows.
+func open(path string) error {
+	command := []string{"vi"}
+	if runtime.GOOS == "windows" {
+		command = []string{"notepad"}
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			command = fields
+			break
+		}
+	}
+	editor := exec.Command(command[0], append(command[1:], path)...)
+	editor.Stdin = os.Stdin
+	editor.Stdout = os.Stdout
+	editor.Stderr = os.Stderr
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("error running the editor %s: %w", command[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var testFields = []Field{
+	{Key: "title", Comment: "The title of the task", Value: "Write"},
+	{Key: "plan", Note: "Week 1", Value: "plan1"},
+	{Key: "started", Value: false},
+}
+
+func TestRenderParse(t *testing.T) {
+	doc, err := Render("Edit the task", testFields)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	text := string(doc)
+	assert.True(t, strings.HasPrefix(text, "# Edit the task\n"))
+	assert.Contains(t, text, "# The title of the task\ntitle: Write\n")
+	assert.Contains(t, text, "plan: plan1 # Week 1\n")
+
+	values, err := Parse(doc, testFields)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	assert.Equal(t, map[string]interface{}{"title": "Write", "plan": "plan1", "started": false}, values)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want map[string]interface{}
+		err  string
+	}{
+		{name: "changed", doc: "title: Rewrite\nstarted: true\n",
+			want: map[string]interface{}{"title": "Rewrite", "plan": "plan1", "started": true}},
+		{name: "empty value", doc: "plan:\n",
+			want: map[string]interface{}{"title": "Write", "plan": "", "started": false}},
+		{name: "empty document", doc: "# nothing\n",
+			want: map[string]interface{}{"title": "Write", "plan": "plan1", "started": false}},
+		{name: "unknown key", doc: "title: Write\nowner: alice\n", err: `unknown field "owner"`},
+		{name: "duplicate key", doc: "title: Write\ntitle: Rewrite\n", err: "title is given twice"},
+		{name: "invalid yaml", doc: "title: [Write\n", err: "yaml:"},
+		{name: "not a mapping", doc: "- title\n", err: "expected the fields of the record"},
+		{name: "list value", doc: "title: [a, b]\n", err: "title must be a single value"},
+		{name: "not a boolean", doc: "started: maybe\n", err: "started must be true or false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := Parse([]byte(tt.doc), testFields)
+			if tt.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	doc := []byte("# Edit the task\ntitle: Write\n")
+	annotated := annotate(doc, errors.New("title is required\nand must be unique"))
+	assert.Equal(t, "# Error: title is required\n#        and must be unique\n#\n# Edit the task\ntitle: Write\n", string(annotated))
+	assert.Equal(t, doc, withoutError(annotated))
+
+	// a second error replaces the first one
+	again := annotate(annotated, errors.New("title is too long"))
+	assert.Equal(t, "# Error: title is too long\n#\n# Edit the task\ntitle: Write\n", string(again))
+	assert.Equal(t, doc, withoutError(doc))
+}
+
+func TestInvalidError(t *testing.T) {
+	assert.NoError(t, Invalid(nil))
+	cause := errors.New("title is required")
+	err := Invalid(cause)
+	assert.Equal(t, "title is required", err.Error())
+	assert.ErrorIs(t, err, cause)
+}
+
+// scriptEditor sets $VISUAL to an editor that, on its nth run, keeps a copy of the file it is given as seen<n>
+// and replaces the file with saves[n-1], or leaves it as it is for an empty save. It returns the directory of the copies.
+func scriptEditor(t *testing.T, saves ...string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the scripted editor needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	for i, save := range saves {
+		if save != "" {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("save%d", i+1)), []byte(save), 0600); err != nil {
+				t.Fatalf("failed to write save: %v", err)
+			}
+		}
+	}
+	script := fmt.Sprintf(`#!/bin/sh
+n=$(cat "%[1]s/n" 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > "%[1]s/n"
+cp "$1" "%[1]s/seen$n"
+if [ -f "%[1]s/save$n" ]; then cp "%[1]s/save$n" "$1"; fi
+`, dir)
+	path := filepath.Join(dir, "editor.sh")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatalf("failed to write editor: %v", err)
+	}
+	t.Setenv("VISUAL", path)
+	return dir
+}
+
+// seen returns what the scripted editor was shown on its nth run.
+func seen(t *testing.T, dir string, n int) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("seen%d", n)))
+	if err != nil {
+		t.Fatalf("failed to read what the editor was shown: %v", err)
+	}
+	return string(b)
+}
+
+func TestEdit(t *testing.T) {
+	doc := "# Edit the task\ntitle: Write\n"
+	dir := scriptEditor(t, "# Edit the task\ntitle: \n", "# Error: title is required\n#\n# Edit the task\ntitle: Rewrite\n")
+
+	var checked []string
+	err := Edit([]byte(doc), func(edited []byte) error {
+		checked = append(checked, string(edited))
+		values, err := Parse(edited, []Field{{Key: "title", Value: ""}})
+		if err != nil {
+			return Invalid(err)
+		}
+		if values["title"] == "" {
+			return Invalid(errors.New("title is required"))
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, doc, seen(t, dir, 1))
+	// the editor opens again on what was saved, with the error inline, and check never sees the error
+	assert.Equal(t, "# Error: title is required\n#\n# Edit the task\ntitle: \n", seen(t, dir, 2))
+	assert.Equal(t, []string{"# Edit the task\ntitle: \n", "# Edit the task\ntitle: Rewrite\n"}, checked)
+}
+
+func TestEdit_GiveUp(t *testing.T) {
+	doc := []byte("title: Write\n")
+
+	scriptEditor(t)
+	err := Edit(doc, func(edited []byte) error {
+		t.Fatalf("check should not run for an unchanged document")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrUnchanged)
+
+	// saving the annotated document unchanged gives up with the error
+	scriptEditor(t, "title: \n", "")
+	invalid := errors.New("title is required")
+	err = Edit(doc, func(edited []byte) error { return Invalid(invalid) })
+	assert.Equal(t, invalid, err)
+
+	// other errors of check are returned at once
+	scriptEditor(t, "title: Rewrite\n")
+	failed := errors.New("revision mismatch")
+	err = Edit(doc, func(edited []byte) error { return failed })
+	assert.Equal(t, failed, err)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/ref"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+// The exit codes of flow, so that scripts can tell why a command failed.
+const (
+	exitError     = 1 // any other error
+	exitUsage     = 2 // invalid arguments or flags, or a reference that matches several records
+	exitNotFound  = 3 // the record does not exist
+	exitForbidden = 4 // the token is invalid, or its user lacks the role the command requires
+	exitConflict  = 5 // the record changed since the revision given with --revision
+)
+
+// usageError marks the errors of invalid arguments and flags.
+type usageError struct {
+	error
+}
+
+func (e usageError) Unwrap() error {
+	return e.error
+}
+
+// ExitCode returns the exit code for the error a command returned, or 0 for nil.
+func ExitCode(err error) int {
+	var usage usageError
+	var ambiguous *ref.AmbiguousError
+	var response *client.Error
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usage), errors.As(err, &ambiguous):
+		return exitUsage
+	case errors.Is(err, storm.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, services.ErrForbidden), errors.As(err, &response) && response.StatusCode == http.StatusUnauthorized:
+		return exitForbidden
+	case errors.Is(err, store.ErrRevisionMismatch):
+		return exitConflict
+	}
+	return exitError
+}
+
+// markUsageErrors makes the argument checks of cmd and its subcommands, the parsing of their flags
+// and the check of their required flags return usageError.
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+	if check := cmd.Args; check != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := check(cmd, args); err != nil {
+				return usageError{err}
+			}
+			// cobra checks the required flags later, and returns their error as is
+			if err := cmd.ValidateRequiredFlags(); err != nil {
+				return usageError{err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
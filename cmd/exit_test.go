@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/ref"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: 0},
+		{name: "error", err: errors.New("boom"), want: exitError},
+		{name: "usage", err: usageError{errors.New("accepts 1 arg(s), received 2")}, want: exitUsage},
+		{name: "ambiguous", err: fmt.Errorf("task: %w", &ref.AmbiguousError{Kind: "task", Ref: "@Ship"}), want: exitUsage},
+		{name: "not found", err: fmt.Errorf("task 3fa8: %w", storm.ErrNotFound), want: exitNotFound},
+		{name: "not found on the server", err: &client.Error{StatusCode: http.StatusNotFound}, want: exitNotFound},
+		{name: "forbidden", err: services.ErrForbidden, want: exitForbidden},
+		{name: "forbidden on the server", err: &client.Error{StatusCode: http.StatusForbidden}, want: exitForbidden},
+		{name: "invalid token", err: fmt.Errorf("error connecting: %w", &client.Error{StatusCode: http.StatusUnauthorized}), want: exitForbidden},
+		{name: "revision mismatch", err: store.ErrRevisionMismatch, want: exitConflict},
+		{name: "revision mismatch on the server", err: &client.Error{StatusCode: http.StatusPreconditionFailed}, want: exitConflict},
+		{name: "server error", err: &client.Error{StatusCode: http.StatusInternalServerError}, want: exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown flag", args: []string{"task", "list", "--colour"}},
+		{name: "invalid flag value", args: []string{"task", "list", "--limit", "ten"}},
+		{name: "extra argument", args: []string{"goal", "get", "a", "b"}},
+		{name: "required flag", args: []string{"plan", "create", "--name", "Release"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runFlow(t, append(tt.args, "--db", t.TempDir()+"/flow.db")...)
+			assert.Equal(t, exitUsage, code, out)
+		})
+	}
+}
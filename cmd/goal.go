@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+// goalFlags hold the flags of the goal commands.
+var goalFlags struct {
+	objective, deadline, planner string
+	status, deadlineBefore, sort string
+	limit, revision              int
+}
+
+func init() {
+	rootCmd.AddCommand(goalCmd)
+	goalCmd.AddCommand(goalCreateCmd, goalGetCmd, goalListCmd, goalUpdateCmd, goalDeleteCmd)
+	addRemoteFlags(goalCmd)
+	addOutputFlag(goalCmd)
+
+	flags := goalCreateCmd.Flags()
+	flags.StringVar(&goalFlags.objective, "objective", "", "the objective of the goal")
+	flags.StringVar(&goalFlags.deadline, "deadline", "", "the deadline of the goal, as YYYY-MM-DD")
+	flags.StringVar(&goalFlags.planner, "planner", "", "the planner the goal belongs to")
+	_ = goalCreateCmd.MarkFlagRequired("objective")
+	_ = goalCreateCmd.MarkFlagRequired("deadline")
+
+	flags = goalListCmd.Flags()
+	flags.StringVar(&goalFlags.planner, "planner", "", "only list the goals of this planner")
+	flags.StringVar(&goalFlags.status, "status", "", "only list the goals with this status, such as in-progress")
+	flags.StringVar(&goalFlags.deadlineBefore, "deadline-before", "", "only list the goals due before this date, as YYYY-MM-DD")
+	flags.StringVar(&goalFlags.sort, "sort", "", "the field to sort by, such as deadline or -createdAt")
+	flags.IntVar(&goalFlags.limit, "limit", 0, "the maximum number of goals to list; all if 0")
+
+	flags = goalUpdateCmd.Flags()
+	flags.String("objective", "", "the new objective")
+	flags.String("deadline", "", "the new deadline, as YYYY-MM-DD")
+	flags.String("planner", "", "the new planner")
+	flags.String("status", "", "the new status: not-started, in-progress, completed or fail")
+	flags.IntVar(&goalFlags.revision, "revision", 0, "fail unless the goal is still at this revision")
+
+	goalDeleteCmd.Flags().IntVar(&goalFlags.revision, "revision", 0, "fail unless the goal is still at this revision")
+
+	for _, cmd := range []*cobra.Command{goalGetCmd, goalUpdateCmd, goalDeleteCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeRefs(goalRefs))
+	}
+	for _, cmd := range []*cobra.Command{goalCreateCmd, goalListCmd, goalUpdateCmd} {
+		_ = cmd.RegisterFlagCompletionFunc("planner", completeRefs(plannerRefs))
+	}
+	for _, cmd := range []*cobra.Command{goalListCmd, goalUpdateCmd} {
+		_ = cmd.RegisterFlagCompletionFunc("status", completeStatus)
+	}
+}
+
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "create, show, list, update and delete goals",
+	Long: `create, show, list, update and delete goals, without prompting, for scripts. Every command prints
+a table, or JSON with --output json, and exits with a non-zero code if it fails: 2 for invalid arguments,
+3 if the goal does not exist, 4 if access is denied and 5 if the goal changed since --revision.
+
+get, update and delete take the id of the goal, a unique prefix of it such as 3fa8, or its objective after an @,
+such as @"Ship v2". --planner takes a planner the same way.`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var goalCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a goal and print its id",
+	Long: `create a goal and print its id.
+
+Example usage:
+flow goal create --objective "Ship 1.2" --deadline 2026-12-01 --planner 1b4e28ba-2fa1-11d2-883f-0016d3cca427`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			if err := resolveRef(&goalFlags.planner, ctl.Planner.ResolvePlanner); err != nil {
+				return err
+			}
+			res, err := ctl.Goal.CreateGoal(&handle.CreateGoalRequest{
+				Objective: goalFlags.objective,
+				Deadline:  goalFlags.deadline,
+				PlannerId: goalFlags.planner,
+			})
+			if err != nil {
+				return err
+			}
+			return printID(cmd, res.ID)
+		})
+	},
+}
+
+var goalGetCmd = &cobra.Command{
+	Use:          "get <goal id>",
+	Short:        "show a goal",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Goal.ResolveGoal(args[0])
+			if err != nil {
+				return err
+			}
+			res, err := ctl.Goal.GetGoal(&handle.GetGoalRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("goal %s: %w", id, err)
+			}
+			return printGoals(cmd, res.Goal, []*models.Goal{res.Goal})
+		})
+	},
+}
+
+var goalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list goals",
+	Long: `list the goals you can see, filtered by planner, status and deadline and sorted by a field.
+
+Example usage:
+flow goal list --planner 1b4e28ba-2fa1-11d2-883f-0016d3cca427 -o json
+flow goal list --status in-progress --deadline-before 2026-12-01 --sort deadline`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &handle.ListRequest{PlannerId: goalFlags.planner, DeadlineBefore: goalFlags.deadlineBefore,
+			Sort: goalFlags.sort, Limit: goalFlags.limit}
+		if goalFlags.status != "" {
+			status, err := parseStatus(goalFlags.status)
+			if err != nil {
+				return err
+			}
+			req.Status = status
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			if err := resolveRef(&req.PlannerId, ctl.Planner.ResolvePlanner); err != nil {
+				return err
+			}
+			res, err := ctl.Goal.FindGoals(req)
+			if err != nil {
+				return err
+			}
+			if res.Goals == nil {
+				res.Goals = []*models.Goal{}
+			}
+			return printGoals(cmd, res.Goals, res.Goals)
+		})
+	},
+}
+
+var goalUpdateCmd = &cobra.Command{
+	Use:   "update <goal id>",
+	Short: "change the fields of a goal",
+	Long: `change the fields of a goal given with flags; the others keep their values. The updated goal is printed.
+
+Example usage:
+flow goal update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --status completed
+flow goal update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --deadline 2027-01-15 --revision 2`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patch, err := patchFlags(cmd, map[string]string{
+			"objective": "objective", "deadline": "deadline", "planner": "planner_id", "status": "goal_status",
+		})
+		if err != nil {
+			return err
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Goal.ResolveGoal(args[0])
+			if err != nil {
+				return err
+			}
+			if patch, err = resolvePatchRef(patch, "planner_id", ctl.Planner.ResolvePlanner); err != nil {
+				return err
+			}
+			res, err := ctl.Goal.PatchGoal(&handle.PatchGoalRequest{Id: id, Patch: patch, Revision: goalFlags.revision})
+			if err != nil {
+				return fmt.Errorf("goal %s: %w", id, err)
+			}
+			return printGoals(cmd, res.Goal, []*models.Goal{res.Goal})
+		})
+	},
+}
+
+var goalDeleteCmd = &cobra.Command{
+	Use:          "delete <goal id>",
+	Short:        "delete a goal",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Goal.ResolveGoal(args[0])
+			if err != nil {
+				return err
+			}
+			if err := ctl.Goal.DeleteGoal(&handle.DeleteGoalRequest{Id: id, Revision: goalFlags.revision}); err != nil {
+				return fmt.Errorf("goal %s: %w", id, err)
+			}
+			return printID(cmd, id)
+		})
+	},
+}
+
+// printGoals prints v, a goal or a list of them, as printResult does, with a row for each of goals.
+func printGoals(cmd *cobra.Command, v interface{}, goals []*models.Goal) error {
+	return printResult(cmd, v, []string{"ID", "OBJECTIVE", "STATUS", "DEADLINE", "PLANNER", "REVISION"}, func() [][]string {
+		rows := make([][]string, 0, len(goals))
+		for _, goal := range goals {
+			rows = append(rows, []string{goal.Id, goal.Objective, goal.GoalStatus, formatDate(goal.Deadline, "2006-01-02"),
+				goal.PlannerId, strconv.Itoa(goal.Revision)})
+		}
+		return rows
+	})
+}
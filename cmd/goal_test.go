@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGoalCommands(t *testing.T) {
+	s := newFlowServer(t)
+
+	var created map[string]string
+	s.runJSON(t, &created, "goal", "create", "--objective", "Ship 1.2", "--deadline", "2026-12-01")
+	id := created["id"]
+	assert.Len(t, id, 36)
+
+	var goal map[string]interface{}
+	s.runJSON(t, &goal, "goal", "get", "@ship 1.2")
+	for _, key := range []string{"id", "objective", "goal_status", "deadline", "planner_id", "revision"} {
+		assert.Contains(t, goal, key)
+	}
+	assert.Equal(t, id, goal["id"])
+	assert.Equal(t, "Ship 1.2", goal["objective"])
+	assert.Equal(t, "2026-12-01", goal["deadline"].(string)[:10])
+
+	var updated map[string]interface{}
+	s.runJSON(t, &updated, "goal", "update", id[:6], "--status", "in-progress")
+	assert.Equal(t, "In Progress", updated["goal_status"])
+
+	var goals []map[string]interface{}
+	s.runJSON(t, &goals, "goal", "list", "--status", "IN_PROGRESS")
+	assert.Len(t, goals, 1)
+	s.runJSON(t, &goals, "goal", "list", "--status", "completed")
+	assert.Len(t, goals, 0)
+
+	s.runJSON(t, &created, "goal", "create", "--objective", "Ship 1.2", "--deadline", "2027-01-01")
+	second := created["id"]
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "missing", args: []string{"goal", "get", "00000000-0000-0000-0000-000000000000"}, code: exitNotFound},
+		{name: "ambiguous", args: []string{"goal", "get", "@Ship 1.2"}, code: exitUsage},
+		{name: "ambiguous update", args: []string{"goal", "update", "@Ship 1.2", "--objective", "Ship 1.3"}, code: exitUsage},
+		{name: "no deadline", args: []string{"goal", "create", "--objective", "Ship 2"}, code: exitUsage},
+		{name: "unknown status", args: []string{"goal", "update", id, "--status", "paused"}, code: exitUsage},
+		{name: "stale revision", args: []string{"goal", "delete", id, "--revision", "1"}, code: exitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := s.run(t, tt.args...)
+			assert.Equal(t, tt.code, code, out)
+		})
+	}
+
+	var deleted map[string]string
+	s.runJSON(t, &deleted, "goal", "delete", second)
+	assert.Equal(t, map[string]string{"id": second}, deleted)
+	_, code := s.run(t, "goal", "get", second)
+	assert.Equal(t, exitNotFound, code)
+}
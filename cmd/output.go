@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// outputFormat is the format the commands given addOutputFlag print their results in: table or json.
+var outputFormat string
+
+// addOutputFlag adds the --output flag to cmd and its subcommands.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "the output format: table or json")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// preRunWithOutput is the PersistentPreRunE of the commands given addOutputFlag. It runs the one of the root
+// command, which cobra would skip, and rejects an unknown --output before the command changes anything.
+func preRunWithOutput(cmd *cobra.Command, args []string) error {
+	if err := rootCmd.PersistentPreRunE(cmd, args); err != nil {
+		return err
+	}
+	if outputFormat != "table" && outputFormat != "json" {
+		return usageError{fmt.Errorf("invalid output format %q: expected table or json", outputFormat)}
+	}
+	return nil
+}
+
+// printResult prints v as indented JSON with --output json, and otherwise as a table with a row per record,
+// as rows returns them, under header.
+func printResult(cmd *cobra.Command, v interface{}, header []string, rows func() [][]string) error {
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows() {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printID prints the id of a record a command created, as {"id": ...} with --output json.
+func printID(cmd *cobra.Command, id string) error {
+	if outputFormat == "json" {
+		return printResult(cmd, map[string]string{"id": id}, nil, nil)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), id)
+	return nil
+}
+
+// formatDate formats the date of a record, leaving it empty if it is not set.
+func formatDate(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// statuses are the statuses of goals and plans, in their stored form.
+var statuses = []string{models.NotStarted, models.InProgress, models.Completed, models.Fail}
+
+// statusArg returns how a status is written on the command line, such as in-progress.
+func statusArg(status string) string {
+	return strings.ReplaceAll(strings.ToLower(status), " ", "-")
+}
+
+// parseStatus accepts a status of a goal or plan in any case, with dashes, underscores or spaces between
+// its words, such as "in-progress", and returns its stored form, "In Progress".
+func parseStatus(status string) (string, error) {
+	normalize := func(s string) string {
+		return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(s))
+	}
+	for _, known := range statuses {
+		if normalize(status) == normalize(known) {
+			return known, nil
+		}
+	}
+	return "", usageError{fmt.Errorf("invalid status %q: expected not-started, in-progress, completed or fail", status)}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// withOutput runs fn with --output set to format and returns what it printed.
+func withOutput(t *testing.T, format string, fn func(cmd *cobra.Command) error) string {
+	t.Helper()
+	saved := outputFormat
+	outputFormat = format
+	t.Cleanup(func() { outputFormat = saved })
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := fn(cmd); err != nil {
+		t.Fatalf("failed to print: %v", err)
+	}
+	return out.String()
+}
+
+func TestPrintResult(t *testing.T) {
+	v := []map[string]string{{"id": "1", "title": "Write"}, {"id": "22", "title": "Test"}}
+	rows := func() [][]string { return [][]string{{"1", "Write"}, {"22", "Test"}} }
+	show := func(cmd *cobra.Command) error { return printResult(cmd, v, []string{"ID", "TITLE"}, rows) }
+
+	assert.Equal(t, "ID  TITLE\n1   Write\n22  Test\n", withOutput(t, "table", show))
+	assert.Equal(t, `[
+  {
+    "id": "1",
+    "title": "Write"
+  },
+  {
+    "id": "22",
+    "title": "Test"
+  }
+]
+`, withOutput(t, "json", show))
+}
+
+func TestPrintID(t *testing.T) {
+	show := func(cmd *cobra.Command) error { return printID(cmd, "3fa8") }
+	assert.Equal(t, "3fa8\n", withOutput(t, "table", show))
+	assert.Equal(t, "{\n  \"id\": \"3fa8\"\n}\n", withOutput(t, "json", show))
+}
+
+func TestFormatDate(t *testing.T) {
+	assert.Equal(t, "", formatDate(time.Time{}, "2006-01-02"))
+	assert.Equal(t, "2026-12-01", formatDate(time.Date(2026, 12, 1, 9, 30, 0, 0, time.UTC), "2006-01-02"))
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		status  string
+		want    string
+		wantErr bool
+	}{
+		{status: "not-started", want: models.NotStarted},
+		{status: "In Progress", want: models.InProgress},
+		{status: "in_progress", want: models.InProgress},
+		{status: "COMPLETED", want: models.Completed},
+		{status: "fail", want: models.Fail},
+		{status: "paused", wantErr: true},
+		{status: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got, err := parseStatus(tt.status)
+			if tt.wantErr {
+				assert.Equal(t, exitUsage, ExitCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			// the status a command line takes parses back to itself
+			parsed, err := parseStatus(statusArg(got))
+			assert.NoError(t, err)
+			assert.Equal(t, got, parsed)
+		})
+	}
+}
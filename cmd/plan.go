@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+// planFlags hold the flags of the plan commands.
+var planFlags struct {
+	name, description, date, time, goal string
+	status, sort                        string
+	limit, revision                     int
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planCreateCmd, planGetCmd, planListCmd, planUpdateCmd, planDeleteCmd)
+	addRemoteFlags(planCmd)
+	addOutputFlag(planCmd)
+
+	flags := planCreateCmd.Flags()
+	flags.StringVar(&planFlags.name, "name", "", "the name of the plan")
+	flags.StringVar(&planFlags.description, "desc", "", "the description of the plan")
+	flags.StringVar(&planFlags.date, "date", "", "the date of the plan, as YYYY-MM-DD")
+	flags.StringVar(&planFlags.time, "time", "", "the time of the plan, as HH:MM")
+	flags.StringVar(&planFlags.goal, "goal", "", "the goal the plan belongs to")
+	_ = planCreateCmd.MarkFlagRequired("name")
+	_ = planCreateCmd.MarkFlagRequired("date")
+	_ = planCreateCmd.MarkFlagRequired("time")
+
+	flags = planListCmd.Flags()
+	flags.StringVar(&planFlags.goal, "goal", "", "only list the plans of this goal")
+	flags.StringVar(&planFlags.status, "status", "", "only list the plans with this status, such as in-progress")
+	flags.StringVar(&planFlags.sort, "sort", "", "the field to sort by, such as name or -createdAt")
+	flags.IntVar(&planFlags.limit, "limit", 0, "the maximum number of plans to list; all if 0")
+
+	flags = planUpdateCmd.Flags()
+	flags.String("name", "", "the new name")
+	flags.String("desc", "", "the new description")
+	flags.String("date", "", "the new date, as YYYY-MM-DD")
+	flags.String("time", "", "the new time, as HH:MM")
+	flags.String("goal", "", "the new goal; empty to take the plan out of its goal")
+	flags.String("status", "", "the new status: not-started, in-progress, completed or fail")
+	flags.IntVar(&planFlags.revision, "revision", 0, "fail unless the plan is still at this revision")
+
+	planDeleteCmd.Flags().IntVar(&planFlags.revision, "revision", 0, "fail unless the plan is still at this revision")
+
+	for _, cmd := range []*cobra.Command{planGetCmd, planUpdateCmd, planDeleteCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeRefs(planRefs))
+	}
+	for _, cmd := range []*cobra.Command{planCreateCmd, planListCmd, planUpdateCmd} {
+		_ = cmd.RegisterFlagCompletionFunc("goal", completeRefs(goalRefs))
+	}
+	for _, cmd := range []*cobra.Command{planListCmd, planUpdateCmd} {
+		_ = cmd.RegisterFlagCompletionFunc("status", completeStatus)
+	}
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "create, show, list, update and delete plans",
+	Long: `create, show, list, update and delete plans, without prompting, for scripts. Every command prints
+a table, or JSON with --output json, and exits with a non-zero code if it fails: 2 for invalid arguments,
+3 if the plan does not exist, 4 if access is denied and 5 if the plan changed since --revision.
+
+get, update and delete take the id of the plan, a unique prefix of it such as 3fa8, or its name after an @,
+such as @"Ship v2". --goal takes a goal the same way.`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var planCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a plan and print its id",
+	Long: `create a plan and print its id.
+
+Example usage:
+flow plan create --name "Release week" --date 2026-11-30 --time 09:00 --goal 1b4e28ba-2fa1-11d2-883f-0016d3cca427`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			if err := resolveRef(&planFlags.goal, ctl.Goal.ResolveGoal); err != nil {
+				return err
+			}
+			res, err := ctl.Plan.CreatePlan(&handle.CreatePlanRequest{
+				PlanName:        planFlags.name,
+				PlanDescription: planFlags.description,
+				PlanDate:        planFlags.date,
+				PlanTime:        planFlags.time,
+				GoalId:          planFlags.goal,
+			})
+			if err != nil {
+				return err
+			}
+			return printID(cmd, res.ID)
+		})
+	},
+}
+
+var planGetCmd = &cobra.Command{
+	Use:          "get <plan id>",
+	Short:        "show a plan",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Plan.ResolvePlan(args[0])
+			if err != nil {
+				return err
+			}
+			res, err := ctl.Plan.GetPlan(&handle.GetPlanRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("plan %s: %w", id, err)
+			}
+			return printPlans(cmd, res.Plan, []*models.Plan{res.Plan})
+		})
+	},
+}
+
+var planListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list plans",
+	Long: `list the plans you can see, filtered by goal and status and sorted by a field.
+
+Example usage:
+flow plan list --goal 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --status not-started -o json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &handle.ListRequest{GoalId: planFlags.goal, Sort: planFlags.sort, Limit: planFlags.limit}
+		if planFlags.status != "" {
+			status, err := parseStatus(planFlags.status)
+			if err != nil {
+				return err
+			}
+			req.Status = status
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			if err := resolveRef(&req.GoalId, ctl.Goal.ResolveGoal); err != nil {
+				return err
+			}
+			res, err := ctl.Plan.FindPlans(req)
+			if err != nil {
+				return err
+			}
+			if res.Plans == nil {
+				res.Plans = []*models.Plan{}
+			}
+			return printPlans(cmd, res.Plans, res.Plans)
+		})
+	},
+}
+
+var planUpdateCmd = &cobra.Command{
+	Use:   "update <plan id>",
+	Short: "change the fields of a plan",
+	Long: `change the fields of a plan given with flags; the others keep their values. The updated plan is printed.
+
+Example usage:
+flow plan update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --status in-progress
+flow plan update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --date 2026-12-02 --time 14:30 --revision 4`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patch, err := patchFlags(cmd, map[string]string{
+			"name": "plan_name", "desc": "plan_description", "date": "plan_date", "time": "plan_time",
+			"goal": "goal_id", "status": "plan_status",
+		})
+		if err != nil {
+			return err
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Plan.ResolvePlan(args[0])
+			if err != nil {
+				return err
+			}
+			if patch, err = resolvePatchRef(patch, "goal_id", ctl.Goal.ResolveGoal); err != nil {
+				return err
+			}
+			res, err := ctl.Plan.PatchPlan(&handle.PatchPlanRequest{Id: id, Patch: patch, Revision: planFlags.revision})
+			if err != nil {
+				return fmt.Errorf("plan %s: %w", id, err)
+			}
+			return printPlans(cmd, res.Plan, []*models.Plan{res.Plan})
+		})
+	},
+}
+
+var planDeleteCmd = &cobra.Command{
+	Use:          "delete <plan id>",
+	Short:        "delete a plan",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Plan.ResolvePlan(args[0])
+			if err != nil {
+				return err
+			}
+			if err := ctl.Plan.DeletePlan(&handle.DeletePlanRequest{Id: id, Revision: planFlags.revision}); err != nil {
+				return fmt.Errorf("plan %s: %w", id, err)
+			}
+			return printID(cmd, id)
+		})
+	},
+}
+
+// printPlans prints v, a plan or a list of them, as printResult does, with a row for each of plans.
+func printPlans(cmd *cobra.Command, v interface{}, plans []*models.Plan) error {
+	return printResult(cmd, v, []string{"ID", "NAME", "STATUS", "DATE", "TIME", "GOAL", "REVISION"}, func() [][]string {
+		rows := make([][]string, 0, len(plans))
+		for _, plan := range plans {
+			rows = append(rows, []string{plan.Id, plan.PlanName, plan.PlanStatus, formatDate(plan.PlanDate, "2006-01-02"),
+				formatDate(plan.PlanTime, "15:04"), plan.GoalId, strconv.Itoa(plan.Revision)})
+		}
+		return rows
+	})
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlanCommands(t *testing.T) {
+	s := newFlowServer(t)
+
+	var created map[string]string
+	s.runJSON(t, &created, "goal", "create", "--objective", "Ship 1.2", "--deadline", "2026-12-01")
+	goalID := created["id"]
+	s.runJSON(t, &created, "plan", "create", "--name", "Release", "--date", "2026-11-30", "--time", "09:30", "--goal", "@Ship 1.2")
+	id := created["id"]
+	assert.Len(t, id, 36)
+
+	var plan map[string]interface{}
+	s.runJSON(t, &plan, "plan", "get", "@release")
+	for _, key := range []string{"id", "plan_name", "plan_description", "plan_date", "plan_time", "plan_status", "goal_id", "revision"} {
+		assert.Contains(t, plan, key)
+	}
+	assert.Equal(t, id, plan["id"])
+	assert.Equal(t, goalID, plan["goal_id"])
+
+	out, code := s.run(t, "plan", "get", id)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "2026-11-30")
+	assert.Contains(t, out, "09:30")
+
+	var plans []map[string]interface{}
+	s.runJSON(t, &plans, "plan", "list", "--goal", goalID[:8])
+	assert.Len(t, plans, 1)
+
+	var updated map[string]interface{}
+	s.runJSON(t, &updated, "plan", "update", id, "--goal", "")
+	assert.Equal(t, "", updated["goal_id"])
+	assert.Equal(t, "Release", updated["plan_name"])
+
+	s.runJSON(t, &created, "plan", "create", "--name", "Release", "--date", "2026-12-30", "--time", "10:00")
+	second := created["id"]
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "missing", args: []string{"plan", "get", "00000000-0000-0000-0000-000000000000"}, code: exitNotFound},
+		{name: "missing goal", args: []string{"plan", "create", "--name", "Tag", "--date", "2026-11-30", "--time", "09:30", "--goal", "@Ship 2"}, code: exitNotFound},
+		{name: "ambiguous", args: []string{"plan", "delete", "@Release"}, code: exitUsage},
+		{name: "no time", args: []string{"plan", "create", "--name", "Tag", "--date", "2026-11-30"}, code: exitUsage},
+		{name: "stale revision", args: []string{"plan", "update", id, "--name", "Tag", "--revision", "1"}, code: exitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := s.run(t, tt.args...)
+			assert.Equal(t, tt.code, code, out)
+		})
+	}
+
+	var deleted map[string]string
+	s.runJSON(t, &deleted, "plan", "delete", second)
+	assert.Equal(t, map[string]string{"id": second}, deleted)
+	_, code = s.run(t, "plan", "get", second)
+	assert.Equal(t, exitNotFound, code)
+}
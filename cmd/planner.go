@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"sort"
+	"strconv"
+)
+
+// plannerFlags hold the flags of the planner commands.
+var plannerFlags struct {
+	title, owner    string
+	sort            string
+	limit, revision int
+}
+
+func init() {
+	rootCmd.AddCommand(plannerCmd)
+	plannerCmd.AddCommand(plannerCreateCmd, plannerGetCmd, plannerListCmd, plannerUpdateCmd, plannerDeleteCmd,
+		plannerShareCmd, plannerUnshareCmd)
+	addRemoteFlags(plannerCmd)
+	addOutputFlag(plannerCmd)
+
+	flags := plannerCreateCmd.Flags()
+	flags.StringVar(&plannerFlags.title, "title", "", "the title of the planner")
+	flags.StringVar(&plannerFlags.owner, "owner", "", "the owner of the planner; the user of the token if empty")
+	_ = plannerCreateCmd.MarkFlagRequired("title")
+
+	flags = plannerListCmd.Flags()
+	flags.StringVar(&plannerFlags.owner, "owner", "", "only list the planners of this owner")
+	flags.StringVar(&plannerFlags.sort, "sort", "", "the field to sort by, such as title or -createdAt")
+	flags.IntVar(&plannerFlags.limit, "limit", 0, "the maximum number of planners to list; all if 0")
+
+	flags = plannerUpdateCmd.Flags()
+	flags.String("title", "", "the new title")
+	flags.String("owner", "", "the new owner")
+	flags.IntVar(&plannerFlags.revision, "revision", 0, "fail unless the planner is still at this revision")
+
+	plannerDeleteCmd.Flags().IntVar(&plannerFlags.revision, "revision", 0, "fail unless the planner is still at this revision")
+
+	for _, cmd := range []*cobra.Command{plannerGetCmd, plannerUpdateCmd, plannerDeleteCmd, plannerUnshareCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeRefs(plannerRefs))
+	}
+	// share takes a planner, a user and a role
+	plannerShareCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return completeRefs(plannerRefs)(cmd, args, toComplete)
+		case 2:
+			return roles, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// withPlannerClient connects as withControls does and passes fn the client, whose members endpoints share planners,
+// and the controls over it, which resolve the references to them.
+func withPlannerClient(fn func(c *client.Client, ctl *cli.Controls) error) error {
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	return fn(c, cli.NewControls(c))
+}
+
+// printPlanners prints v, a planner or a list of them, as printResult does, with a row for each of planners.
+func printPlanners(cmd *cobra.Command, v interface{}, planners []*handle.GetPlannerResponse) error {
+	return printResult(cmd, v, []string{"ID", "TITLE", "OWNER", "MEMBERS", "REVISION"}, func() [][]string {
+		rows := make([][]string, 0, len(planners))
+		for _, planner := range planners {
+			rows = append(rows, []string{planner.Id, planner.Title, planner.UserId, strconv.Itoa(len(planner.Members)),
+				strconv.Itoa(planner.Revision)})
+		}
+		return rows
+	})
+}
+
+// printMembers prints the owner and members of a planner, or the planner as JSON with --output json.
+func printMembers(cmd *cobra.Command, planner *handle.GetPlannerResponse) error {
+	if outputFormat == "json" {
+		return printResult(cmd, planner, nil, nil)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\towner\n", planner.UserId)
+	names := make([]string, 0, len(planner.Members))
+	for name := range planner.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", name, planner.Members[name])
+	}
+	return nil
+}
+
+var plannerCmd = &cobra.Command{
+	Use:   "planner",
+	Short: "create, show, list, update, delete and share planners",
+	Long: `create, show, list, update and delete planners, without prompting, for scripts, and manage who can
+access them. Every command prints a table, or JSON with --output json, and exits with a non-zero code
+if it fails: 2 for invalid arguments, 3 if the planner does not exist, 4 if access is denied and 5 if
+the planner changed since --revision.
+
+get, update, delete, share and unshare take the id of the planner, a unique prefix of it such as 3fa8, or its title after an @,
+such as @"Release 1.2".
+
+Users see and change a planner, and its goals, plans and tasks, through the server according to their
+role in it: viewers can read, editors can also make changes, and owners can also delete the planner
+and share it.`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var plannerCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a planner and print its id",
+	Long: `create a planner and print its id.
+
+Example usage:
+flow planner create --title "Release 1.2" --owner alice`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			res, err := ctl.Planner.CreatePlanner(&handle.CreatePlannerRequest{Title: plannerFlags.title, UserId: plannerFlags.owner})
+			if err != nil {
+				return err
+			}
+			return printID(cmd, res.Id)
+		})
+	},
+}
+
+var plannerGetCmd = &cobra.Command{
+	Use:          "get <planner id>",
+	Short:        "show a planner",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			planner, err := ctl.Planner.GetPlanner(&handle.GetPlannerRequest{Id: id})
+			if err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			return printPlanners(cmd, planner, []*handle.GetPlannerResponse{planner})
+		})
+	},
+}
+
+var plannerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list planners",
+	Long: `list the planners you can see, filtered by owner and sorted by a field.
+
+Example usage:
+flow planner list --owner alice -o json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			res, err := ctl.Planner.FindPlanners(&handle.ListRequest{Owner: plannerFlags.owner, Sort: plannerFlags.sort, Limit: plannerFlags.limit})
+			if err != nil {
+				return err
+			}
+			if res.Planners == nil {
+				res.Planners = []*handle.GetPlannerResponse{}
+			}
+			return printPlanners(cmd, res.Planners, res.Planners)
+		})
+	},
+}
+
+var plannerUpdateCmd = &cobra.Command{
+	Use:   "update <planner id>",
+	Short: "change the title or owner of a planner",
+	Long: `change the title or owner of a planner; a field without its flag keeps its value. The updated planner is printed.
+
+Example usage:
+flow planner update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --title "Release 1.3"`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patch, err := patchFlags(cmd, map[string]string{"title": "title", "owner": "user_id"})
+		if err != nil {
+			return err
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			planner, err := ctl.Planner.PatchPlanner(&handle.PatchPlannerRequest{Id: id, Patch: patch, Revision: plannerFlags.revision})
+			if err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			return printPlanners(cmd, planner, []*handle.GetPlannerResponse{planner})
+		})
+	},
+}
+
+var plannerDeleteCmd = &cobra.Command{
+	Use:          "delete <planner id>",
+	Short:        "delete a planner",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			if err := ctl.Planner.DeletePlanner(&handle.DeletePlannerRequest{Id: id, Revision: plannerFlags.revision}); err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			return printID(cmd, id)
+		})
+	},
+}
+
+var plannerShareCmd = &cobra.Command{
+	Use:   "share <planner id> <user> <role>",
+	Short: "give a user a role in a planner",
+	Long: `give a user a role in a planner. The role is owner, editor or viewer.
+Sharing the planner again with the same user changes the user's role.
+
+Example usage:
+flow planner share 1b4e28ba-2fa1-11d2-883f-0016d3cca427 bob editor`,
+	Args:         cobra.ExactArgs(3),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withPlannerClient(func(c *client.Client, ctl *cli.Controls) error {
+			// only users added with "flow user add" can be members of a planner; a server does not list its users
+			// to its clients, so the user is only checked against the local database
+			if remote == "" && profile == "" {
+				if _, err := c.GetUser(&handle.GetUserRequest{Name: args[1]}); err != nil {
+					return err
+				}
+			}
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			planner, err := c.SharePlanner(&handle.SharePlannerRequest{Id: id, User: args[1], Role: args[2]})
+			if err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			return printMembers(cmd, planner)
+		})
+	},
+}
+
+var plannerUnshareCmd = &cobra.Command{
+	Use:          "unshare <planner id> <user>",
+	Short:        "remove a user from the members of a planner",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withPlannerClient(func(c *client.Client, ctl *cli.Controls) error {
+			id, err := ctl.Planner.ResolvePlanner(args[0])
+			if err != nil {
+				return err
+			}
+			planner, err := c.UnsharePlanner(&handle.SharePlannerRequest{Id: id, User: args[1]})
+			if err != nil {
+				return fmt.Errorf("planner %s: %w", id, err)
+			}
+			return printMembers(cmd, planner)
+		})
+	},
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlannerCommands(t *testing.T) {
+	s := newFlowServer(t)
+
+	var created map[string]string
+	s.runJSON(t, &created, "planner", "create", "--title", "Work", "--owner", "alice")
+	id := created["id"]
+	assert.Len(t, id, 36)
+
+	var planner map[string]interface{}
+	s.runJSON(t, &planner, "planner", "get", "@work")
+	for _, key := range []string{"id", "title", "user_id", "revision"} {
+		assert.Contains(t, planner, key)
+	}
+	assert.Equal(t, id, planner["id"])
+	assert.Equal(t, "alice", planner["user_id"])
+
+	var shared map[string]interface{}
+	s.runJSON(t, &shared, "planner", "share", id[:8], "bob", "editor")
+	assert.Equal(t, map[string]interface{}{"bob": "editor"}, shared["members"])
+	var unshared map[string]interface{}
+	s.runJSON(t, &unshared, "planner", "unshare", id[:8], "bob")
+	assert.NotContains(t, unshared, "members")
+
+	var planners []map[string]interface{}
+	s.runJSON(t, &planners, "planner", "list", "--owner", "alice")
+	assert.Len(t, planners, 1)
+
+	s.runJSON(t, &created, "planner", "create", "--title", "Work")
+	second := created["id"]
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "missing", args: []string{"planner", "get", "00000000-0000-0000-0000-000000000000"}, code: exitNotFound},
+		{name: "ambiguous", args: []string{"planner", "get", "@Work"}, code: exitUsage},
+		{name: "ambiguous share", args: []string{"planner", "share", "@Work", "bob", "viewer"}, code: exitUsage},
+		{name: "share without a role", args: []string{"planner", "share", id, "bob"}, code: exitUsage},
+		{name: "stale revision", args: []string{"planner", "update", id, "--title", "Home", "--revision", "1"}, code: exitConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := s.run(t, tt.args...)
+			assert.Equal(t, tt.code, code, out)
+		})
+	}
+
+	var deleted map[string]string
+	s.runJSON(t, &deleted, "planner", "delete", second)
+	assert.Equal(t, map[string]string{"id": second}, deleted)
+	_, code := s.run(t, "planner", "get", second)
+	assert.Equal(t, exitNotFound, code)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"net/url"
+	"os"
+)
+
+// remote, remoteToken and profile select the flow server the commands given addRemoteFlags work against.
+var (
+	remote      string
+	remoteToken string
+	profile     string
+)
+
+// addRemoteFlags adds the flags that point cmd, and its subcommands, at a flow server instead of the local daemon.
+func addRemoteFlags(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&remote, "remote", "", "the address of a flow server to work against instead of the local database")
+	flags.StringVar(&remoteToken, "token", "", "the API token for --remote; defaults to $FLOW_TOKEN")
+	flags.StringVar(&profile, "profile", os.Getenv("FLOW_PROFILE"), "a profile of "+conf.GetProfilesPath()+" with the server and token to use")
+}
+
+// connect returns a client of the server the command works against: the server of --remote or of --profile,
+// with the token of --token, $FLOW_TOKEN or the profile, or else the daemon of the database,
+// which it starts if it is not running.
+func connect() (*client.Client, error) {
+	address, token := remote, remoteToken
+	if profile != "" {
+		p, err := conf.LoadProfile(profile)
+		if err != nil {
+			return nil, err
+		}
+		if address == "" {
+			address = p.Remote
+		}
+		if token == "" {
+			token = p.Token
+		}
+	}
+	if address == "" {
+		return ensureDaemon()
+	}
+	if token == "" {
+		token = os.Getenv("FLOW_TOKEN")
+	}
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote %q: expected an address such as https://flow.team.local", address)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("%s requires an API token: set --token or $FLOW_TOKEN", address)
+	}
+	c := client.NewClient(address, token)
+	// the health probe is served without a token, so the smallest list checks the token as well
+	if _, err := c.ListTasks(&handle.ListRequest{Limit: 1}); err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", address, err)
+	}
+	return c, nil
+}
+
+// withControls connects as connect does and passes the controls over the stores of the server to fn.
+func withControls(fn func(ctl *cli.Controls) error) error {
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	return fn(cli.NewControls(c))
+}
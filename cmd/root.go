@@ -1,19 +1,34 @@
 package cmd
 
 import (
+	"github.com/ooyeku/flow/internal/conf"
 	"github.com/spf13/cobra"
 )
 
 var (
+	// dbPath is the database the commands use, set with the --db flag.
+	dbPath string
+
 	// 	root entry
 	rootCmd = &cobra.Command{
 		Use:   "flow",
 		Short: "A flow and process management tool",
 		Long: `Work-flow is a CLI tool for managing your flow and processes.
 				Complete documentation is available at...`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			conf.SetDBPath(dbPath)
+			return conf.EnsureDirs()
+		},
 	}
 )
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", conf.GetDBPath(), "the database to use")
+}
+
+// Execute runs the command of the arguments of the process. Its error, if any, has been printed;
+// ExitCode tells the exit code for it.
 func Execute() error {
+	markUsageErrors(rootCmd)
 	return rootCmd.Execute()
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var (
+	searchTypes []string
+	searchLimit int
+)
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	addRemoteFlags(searchCmd)
+	searchCmd.Flags().StringSliceVarP(&searchTypes, "type", "t", nil, "only search records of this type: task, goal, plan or chat; repeat for more")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", handle.DefaultSearchLimit, "the maximum number of results")
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <words>...",
+	Short: "search tasks, goals, plans and the chat history",
+	Long: `search the titles and descriptions of tasks, the objectives of goals, the names and descriptions of plans,
+and the questions and answers of the chat history. A result must match every word, exactly, as the start of
+a longer word, or with a typo. The best matches are listed first, with their type, id and title.
+With --remote or --profile, a server is searched for the records of the planners shared with the token's user,
+without the chat history.
+
+Example usage:
+flow search release notes --type task --type plan`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		res, err := c.Search(&handle.SearchRequest{
+			Query: strings.Join(args, " "),
+			Types: searchTypes,
+			Limit: searchLimit,
+		})
+		if err != nil {
+			return err
+		}
+		if len(res.Results) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No results")
+			return nil
+		}
+		for _, hit := range res.Results {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", hit.Type, hit.ID, hit.Title)
+		}
+		return nil
+	},
+}
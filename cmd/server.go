@@ -1,48 +1,65 @@
 package cmd
 
 import (
+	"github.com/ooyeku/flow/cmd/server"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/ooyeku/flow/pkg/client"
 	"github.com/spf13/cobra"
-	"os/exec"
 )
 
+// serverConfig holds the settings of the server command, set from its flags.
+var serverConfig = server.DefaultConfig()
+
 func init() {
 	rootCmd.AddCommand(serverCommand)
+	addServerFlags(serverCommand, serverConfig)
 }
 
-/*
-Right now if the server is running, cli command will work but not connect to db.  If the cli
-is running, the server command will not work.  Need to figure out how to run both at the same time, regardless
-of which one is started first.
-*/
-
-func executeServer(cmd *cobra.Command) {
-	// run server at cmd/server/serverapp.go
-	server := exec.Command("go", "run", "cmd/server/serverapp.go")
-	// pass stdin, stdout, and stderr to child process
-	server.Stdin = cmd.InOrStdin()
-	server.Stdout = cmd.OutOrStdout()
-	server.Stderr = cmd.ErrOrStderr()
-
-	handleError(cmd, runServer(server))
-}
-
-func runServer(server *exec.Cmd) error {
-	return server.Run()
-}
-
-func handleError(cmd *cobra.Command, err error) {
-	if err != nil {
-		cmd.PrintErrf("error running server: %s", err)
-	}
+// addServerFlags adds the flags of the listen address, the timeouts and TLS of cfg to cmd,
+// with the values cfg has as their defaults.
+func addServerFlags(cmd *cobra.Command, cfg *server.Config) {
+	flags := cmd.Flags()
+	flags.StringVar(&cfg.Addr, "addr", cfg.Addr, "the address to bind to; all interfaces if empty")
+	flags.IntVar(&cfg.Port, "port", cfg.Port, "the port of the HTTP API")
+	flags.IntVar(&cfg.GRPCPort, "grpc-port", cfg.GRPCPort, "the port of the gRPC service; 0 disables it")
+	flags.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", cfg.ReadHeaderTimeout, "the time allowed to read the headers of a request")
+	flags.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "the time allowed to read a whole request")
+	flags.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "the time allowed to write a response, except for the event stream")
+	flags.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "how long an idle keep-alive connection stays open")
+	flags.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "how long a shutdown waits for the requests in flight")
+	flags.StringVar(&cfg.TLSCert, "tls-cert", "", "the PEM certificate to serve TLS with; requires --tls-key")
+	flags.StringVar(&cfg.TLSKey, "tls-key", "", "the PEM private key of --tls-cert")
+	flags.BoolVar(&cfg.TLSSelfSigned, "tls-self-signed", false, "serve TLS with a self-signed certificate generated at startup, for development")
 }
 
 var (
 	serverCommand = &cobra.Command{
 		Use:   "server",
 		Short: "run flow in server mode",
-		Long:  "run flow in server mode",
-		Run: func(cmd *cobra.Command, args []string) {
-			executeServer(cmd)
+		Long: `run flow in server mode
+
+The server serves the HTTP API and the gRPC service until it receives SIGINT or SIGTERM,
+then waits for the requests in flight and closes the database.
+
+If a daemon already owns the database, the server is an HTTP front end to it instead: it forwards
+every request to the daemon, which authenticates it with its API token. Otherwise the server also
+listens on the socket of the daemon, so that the cli and the chat can work on the same data.
+
+Example usage:
+flow server --addr 127.0.0.1 --port 8443 --tls-self-signed
+flow server --db /var/lib/flow/flow.db --grpc-port 0`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return serverConfig.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			socket := conf.GetSocketPath()
+			if client.NewSocketClient(socket).Ping() == nil {
+				return server.RunFrontEnd(serverConfig, socket)
+			}
+			serverConfig.Socket = socket
+			return server.Run(serverConfig)
 		},
 	}
 )
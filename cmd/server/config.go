@@ -0,0 +1,88 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Config holds the settings of the server, which the server command reads from its flags.
+//
+// Fields:
+// - Addr: the address to bind to; all interfaces if empty.
+// - Port, GRPCPort: the ports of the HTTP API and of the gRPC service, which are disabled with port 0.
+// - Socket: the path of a Unix socket to serve the HTTP API on as well, for the clients run by the same user;
+// no socket if empty. The HTTP API may only be disabled if there is a socket.
+// - ReadHeaderTimeout, ReadTimeout, WriteTimeout, IdleTimeout: the timeouts of http.Server.
+// The event stream is exempt from WriteTimeout, since it stays open for as long as the client listens.
+// - ShutdownTimeout: how long a shutdown waits for the requests in flight before closing their connections.
+// - TLSCert, TLSKey: the paths of a PEM certificate and key to serve HTTPS and gRPC over TLS with.
+// - TLSSelfSigned: serve over TLS with a self-signed certificate generated at startup, for development.
+type Config struct {
+	Addr              string
+	Port              int
+	GRPCPort          int
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	TLSCert           string
+	TLSKey            string
+	TLSSelfSigned     bool
+	Socket            string
+}
+
+// DefaultConfig returns the settings the server runs with unless they are changed:
+// the HTTP API on port 8080 and gRPC on port 9090 of all interfaces, without TLS.
+//
+// Example:
+//
+//	cfg := server.DefaultConfig()
+//	cfg.Port = 8443
+//	cfg.TLSSelfSigned = true
+//	err := server.Run(cfg)
+func DefaultConfig() *Config {
+	return &Config{
+		Port:              8080,
+		GRPCPort:          9090,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
+// Validate reports settings that do not go together, such as a certificate without its key.
+func (cfg *Config) Validate() error {
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return errors.New("--tls-cert and --tls-key must be set together")
+	}
+	if cfg.TLSSelfSigned && cfg.TLSCert != "" {
+		return errors.New("--tls-self-signed cannot be combined with --tls-cert")
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 || cfg.GRPCPort < 0 || cfg.GRPCPort > 65535 {
+		return errors.New("ports must be between 1 and 65535")
+	}
+	if cfg.Port == 0 && cfg.Socket == "" {
+		return errors.New("--port must be set unless the server listens on a socket")
+	}
+	return nil
+}
+
+// TLS reports whether the server is configured to serve over TLS.
+func (cfg *Config) TLS() bool {
+	return cfg.TLSCert != "" || cfg.TLSSelfSigned
+}
+
+// HTTPAddr returns the address the HTTP API listens on.
+func (cfg *Config) HTTPAddr() string {
+	return net.JoinHostPort(cfg.Addr, strconv.Itoa(cfg.Port))
+}
+
+// GRPCAddr returns the address the gRPC service listens on.
+func (cfg *Config) GRPCAddr() string {
+	return net.JoinHostPort(cfg.Addr, strconv.Itoa(cfg.GRPCPort))
+}
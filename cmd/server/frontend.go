@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/client"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+)
+
+// RunFrontEnd serves the HTTP API of the daemon listening on the Unix socket at socket, on the address of cfg
+// and with its timeouts and TLS, until the process receives SIGINT or SIGTERM. Requests are forwarded to the daemon,
+// which authenticates them with their API tokens like its own. The front end does not serve gRPC; run the daemon
+// with --grpc-port for it.
+func RunFrontEnd(cfg *Config, socket string) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	tlsConf, err := tlsConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error setting up TLS: %s", err)
+	}
+	daemon := &url.URL{Scheme: "http", Host: "flow"}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(daemon)
+			r.SetXForwarded()
+		},
+		Transport: client.SocketTransport(socket),
+		// the events are passed on as soon as the daemon sends them
+		FlushInterval: -1,
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/events" {
+			// the stream stays open for as long as the client listens, past the write timeout of the server
+			_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		}
+		proxy.ServeHTTP(w, r)
+	})
+
+	s := newServers(cfg)
+	ln, err := net.Listen("tcp", cfg.HTTPAddr())
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %s", cfg.HTTPAddr(), err)
+	}
+	s.addHTTP(handler, ln, tlsConf, nil)
+	log.Printf("Forwarding requests to the daemon on %s", socket)
+	return s.run()
+}
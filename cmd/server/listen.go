@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// servers are the HTTP servers and the gRPC server of a process, each with its listener, which run and shut
+// down together. Every request and gRPC stream is served with a context that ends when the shutdown begins,
+// which ends the event streams; they would otherwise hold the shutdown up until the timeout.
+type servers struct {
+	cfg          *Config
+	http         []*http.Server
+	listeners    []net.Listener
+	grpc         *grpc.Server
+	grpcListener net.Listener
+	streams      context.Context
+	endStreams   context.CancelFunc
+	stop         chan struct{}
+	stopOnce     sync.Once
+}
+
+// newServers returns an empty group of servers that shut down within cfg.ShutdownTimeout.
+func newServers(cfg *Config) *servers {
+	streams, endStreams := context.WithCancel(context.Background())
+	return &servers{cfg: cfg, streams: streams, endStreams: endStreams, stop: make(chan struct{})}
+}
+
+// addHTTP adds a server of handler on ln, with the timeouts of the configuration and over TLS if tlsConf is set.
+// connContext, if set, derives the context of the requests of each connection, as http.Server.ConnContext does.
+func (s *servers) addHTTP(handler http.Handler, ln net.Listener, tlsConf *tls.Config, connContext func(context.Context, net.Conn) context.Context) {
+	server := &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConf,
+		ReadHeaderTimeout: s.cfg.ReadHeaderTimeout,
+		ReadTimeout:       s.cfg.ReadTimeout,
+		WriteTimeout:      s.cfg.WriteTimeout,
+		IdleTimeout:       s.cfg.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return s.streams },
+		ConnContext:       connContext,
+	}
+	server.RegisterOnShutdown(s.endStreams)
+	s.http = append(s.http, server)
+	s.listeners = append(s.listeners, ln)
+}
+
+// setGRPC sets the gRPC server of the group, which serves on ln.
+func (s *servers) setGRPC(server *grpc.Server, ln net.Listener) {
+	s.grpc = server
+	s.grpcListener = ln
+}
+
+// close closes the listeners of a group that is not run, such as when a later listener cannot be opened.
+func (s *servers) close() {
+	for _, ln := range s.listeners {
+		_ = ln.Close()
+	}
+	if s.grpcListener != nil {
+		_ = s.grpcListener.Close()
+	}
+	s.endStreams()
+}
+
+// Stop asks run to shut the servers down, as SIGINT and SIGTERM do.
+func (s *servers) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// run serves until the process receives SIGINT or SIGTERM, Stop is called or a server fails.
+// It then stops accepting connections, ends the event streams and waits up to the shutdown timeout
+// for the requests in flight before closing the connections still open.
+func (s *servers) run() error {
+	errs := make(chan error, len(s.http)+1)
+	for i, server := range s.http {
+		server, ln := server, s.listeners[i]
+		go func() {
+			var err error
+			if server.TLSConfig != nil {
+				log.Printf("Listening on %s (https)", ln.Addr())
+				err = server.ServeTLS(ln, "", "")
+			} else {
+				log.Printf("Listening on %s", ln.Addr())
+				err = server.Serve(ln)
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+	if s.grpc != nil {
+		go func() {
+			log.Printf("Serving gRPC on %s", s.grpcListener.Addr())
+			if err := s.grpc.Serve(s.grpcListener); err != nil {
+				errs <- fmt.Errorf("error serving gRPC: %s", err)
+			}
+		}()
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	var serveErr error
+	select {
+	case <-signals.Done():
+		log.Println("Shutting down")
+	case <-s.stop:
+		log.Println("Shutting down")
+	case serveErr = <-errs:
+		log.Printf("Shutting down: %s", serveErr)
+	}
+	// a second signal stops the process at once
+	stopSignals()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+	s.endStreams()
+	for _, server := range s.http {
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Closing the connections still open after %v: %s", s.cfg.ShutdownTimeout, err)
+			_ = server.Close()
+		}
+	}
+	if s.grpc != nil {
+		stopGRPC(ctx, s.grpc)
+	}
+	return serveErr
+}
+
+// stopGRPC stops server gracefully, letting the calls in flight finish, unless ctx ends first.
+// The calls still running then are cancelled.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+		<-stopped
+	}
+}
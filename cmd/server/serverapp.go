@@ -1,118 +1,383 @@
-package main
+package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/asdine/storm"
 	"github.com/gorilla/mux"
 	"github.com/ooyeku/flow/api"
 	"github.com/ooyeku/flow/internal/conf"
 	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/chat"
+	"github.com/ooyeku/flow/pkg/client"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/metrics"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/rpc"
 	"github.com/ooyeku/flow/pkg/services"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 )
 
-// cliSetup initializes and sets up the CLI by performing the following steps:
-// - Retrieves the database path using conf.GetDBPath()
-// - Opens the database using the path obtained above, with specific permissions and options
-// - Creates an in-memory task store using the opened database
-// - Creates a task service using the in-memory task store
-// - Creates a new task control using the task service
-// - Returns the taskRouter, db, and error as the result of the setup process.
-func cliSetup() (*handle.TaskControl, *storm.DB, error) {
+// cliSetup opens the database at conf.GetDBPath() with specific permissions and options.
+// The stores, services and controls of the server are built on it in Run.
+func cliSetup() (*storm.DB, error) {
 	dbPath := conf.GetDBPath()
-	db, err := storm.Open(dbPath, storm.BoltOptions(0600, nil))
+	db, err := storm.Open(dbPath, storm.BoltOptions(0600, &bolt.Options{Timeout: time.Second}))
 	if err != nil {
-		return nil, nil, fmt.Errorf("error opening db: %s", err)
+		return nil, fmt.Errorf("error opening db %s (is another flow command using it?): %s", dbPath, err)
 	}
-	// Intialize router, service and inmemory store
-	taskStore := inmemory.NewInMemoryTaskStore(db)
-	taskService := services.NewTaskService(taskStore)
-	taskRouter := handle.NewTaskControl(taskService)
-	return taskRouter, db, nil
+	return db, nil
 }
 
-// loggingMiddleware logs the HTTP request method, URL path, and the time it took to process the request.
-// It takes an http.Handler as input and returns an http.Handler.
-// The input http.Handler is the next handler in the middleware chain.
-// The returned http.Handler is a function that wraps the next handler with logging functionality.
-// It measures the time it takes to execute the next handler and logs the information using log.Printf.
+// requestMetrics are the metrics loggingMiddleware records for every request, by method and route.
+type requestMetrics struct {
+	requests  *metrics.Counter
+	durations *metrics.Histogram
+}
+
+// newRequestMetrics registers the request metrics with the registry.
+func newRequestMetrics(registry *metrics.Registry) *requestMetrics {
+	return &requestMetrics{
+		requests:  registry.NewCounter("flow_http_requests_total", "HTTP requests served, by method, route and status code.", "method", "route", "code"),
+		durations: registry.NewHistogram("flow_http_request_duration_seconds", "Time taken to serve HTTP requests, by method and route.", metrics.DefaultBuckets, "method", "route"),
+	}
+}
+
+// statusRecorder is an http.ResponseWriter that remembers the status code written through it.
+// It passes Flush on, so that the event stream still works behind it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// loggingMiddleware logs the HTTP request method, URL path, status code and the time it took to process the request,
+// and records the request in m. The requests are counted by their route template, such as /task/{id},
+// so that the metrics have one series per endpoint rather than per id.
 // Example usage:
 // r := mux.NewRouter()
-// r.Use(loggingMiddleware)
+// r.Use(loggingMiddleware(newRequestMetrics(registry)))
 // err := http.ListenAndServe(":8080", r)
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
-	})
+func loggingMiddleware(m *requestMetrics) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			elapsed := time.Since(start)
+			log.Printf("%s %s %d %v", r.Method, r.URL.Path, rec.status, elapsed)
+			route := "unmatched"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+			m.requests.Inc(r.Method, route, strconv.Itoa(rec.status))
+			m.durations.Observe(elapsed.Seconds(), r.Method, route)
+		})
+	}
+}
+
+// publicPaths are served without authentication so that clients can discover the API.
+var publicPaths = map[string]bool{
+	"/healthz":        true,
+	"/readyz":         true,
+	"/openapi.json":   true,
+	"/docs":           true,
+	"/graphql/schema": true,
+}
+
+// authMiddleware authenticates each request with the API token in its Authorization (Bearer) or X-API-Key header.
+// The user the token belongs to is stored in the request context with api.WithCaller,
+// where the handlers use it as the default owner of the records the request creates.
+// Requests without a valid token are rejected with 401 Unauthorized, except those for publicPaths and those
+// of the user who runs the daemon over its socket, which are served without a caller, with access to every record,
+// or as the user named in their client.UserHeader header. Tokens are created with "flow token create".
+func authMiddleware(users *handle.UserControl) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if publicPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			token := api.RequestToken(r)
+			if token == "" && isLocal(r) {
+				if name := r.Header.Get(client.UserHeader); name != "" {
+					r = r.WithContext(api.WithCaller(r.Context(), &models.User{Name: name}))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="flow"`)
+				http.Error(w, "missing API token", http.StatusUnauthorized)
+				return
+			}
+			user, err := users.Authenticate(token)
+			if errors.Is(err, handle.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="flow", error="invalid_token"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				log.Printf("Error due to: %s", err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(api.WithCaller(r.Context(), user)))
+		})
+	}
 }
 
-func main() {
+// Run serves the HTTP API, on its port and its Unix socket, and the gRPC service as cfg sets them up,
+// until the process receives SIGINT or SIGTERM or a local client stops the daemon with POST /daemon/stop.
+// It then stops accepting connections, ends the event streams, waits up to cfg.ShutdownTimeout for the requests
+// in flight, stops the background work and closes the database, so that the next start finds it unlocked.
+func Run(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	tlsConf, err := tlsConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error setting up TLS: %s", err)
+	}
 	r := mux.NewRouter()
-	taskRouter, db, err := cliSetup()
+	db, err := cliSetup()
 	if err != nil {
-		log.Fatalf("error setting up cli: %s", err)
+		return fmt.Errorf("error setting up cli: %s", err)
 	}
 
 	defer func(db *storm.DB) {
-		_ = db.Close()
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing the database: %s", err)
+			return
+		}
+		log.Println("Closed the database")
 	}(db)
 
-	// Initialize handlers
+	// the stores time every operation for /metrics
+	registry := metrics.NewRegistry()
+	storeDurations := registry.NewHistogram("flow_store_operation_duration_seconds", "Time taken by store operations, by store and operation.", metrics.DefaultBuckets, "store", "operation")
+	taskStore := metrics.InstrumentTaskStore(inmemory.NewInMemoryTaskStore(db), storeDurations)
+	goalStore := metrics.InstrumentGoalStore(inmemory.NewInMemoryGoalStore(db), storeDurations)
+	planStore := metrics.InstrumentPlanStore(inmemory.NewInMemoryPlanStore(db), storeDurations)
+	plannerStore := metrics.InstrumentPlannerStore(inmemory.NewInMemoryPlannerStore(db), storeDurations)
+	registry.NewGaugeFunc("flow_db_size_bytes", "Size of the database file.", func() ([]metrics.Sample, error) {
+		info, err := os.Stat(db.Bolt.Path())
+		if err != nil {
+			return nil, err
+		}
+		return []metrics.Sample{{Value: float64(info.Size())}}, nil
+	})
+	registry.NewGaugeFunc("flow_entities", "Tasks, goals and plans, by type and status.",
+		metrics.EntityCounts(inmemory.NewInMemoryTaskStore(db), inmemory.NewInMemoryGoalStore(db), inmemory.NewInMemoryPlanStore(db)), "type", "status")
+
+	// Initialize handlers; each request is served for its caller, with the access the caller has in the planners
+	access := services.NewAccessService(plannerStore, goalStore, planStore, taskStore)
+	// every change made through the services is published on the event bus
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	taskService := services.NewTaskService(taskStore).WithEvents(events)
+	goalService := services.NewGoalService(goalStore).WithEvents(events)
+	planService := services.NewPlanService(planStore).WithEvents(events)
+	plannerService := services.NewPlannerService(plannerStore).WithEvents(events)
+	// deleted records go to the trash, with the records below them, until they are restored or purged
+	trashService := services.NewTrashService(inmemory.NewInMemoryTrashStore(db)).WithEvents(events)
+	// and journaled for the user who made it, who can undo it; the journal reverts changes without journaling them again
+	journal := services.NewJournalService(inmemory.NewInMemoryJournalStore(db), services.DefaultJournalSize,
+		taskService, goalService, planService, plannerService).WithTrash(trashService)
 	taskHandler := &api.TaskHandler{
-		Control: taskRouter,
+		Control: handle.NewTaskControl(taskService.WithJournal(journal)),
+		Access:  access,
 	}
 	goalHandler := &api.GoalHandler{
-		Control: handle.NewGoalControl(services.NewGoalService(inmemory.NewInMemoryGoalStore(db))),
+		Control: handle.NewGoalControl(goalService.WithJournal(journal)),
+		Access:  access,
 	}
 	planHandler := &api.PlanHandler{
-		Control: handle.NewPlanControl(services.NewPlanService(inmemory.NewInMemoryPlanStore(db))),
+		Control: handle.NewPlanControl(planService.WithJournal(journal)),
+		Access:  access,
 	}
 	plannerHandler := &api.PlannerHandler{
-		Control: handle.NewPlannerControl(services.NewPlannerService(inmemory.NewInMemoryPlannerStore(db))),
+		Control: handle.NewPlannerControl(plannerService.WithJournal(journal)),
+		Access:  access,
+	}
+	undoHandler := &api.UndoHandler{
+		Control: handle.NewUndoControl(journal),
+		Access:  access,
+	}
+	trashHandler := &api.TrashHandler{
+		Control: handle.NewTrashControl(trashService.WithJournal(journal)),
+		Access:  access,
+	}
+	versionControl := handle.NewVersionControl(services.NewVersionService(inmemory.NewInMemoryVersionStore(db)).WithEvents(events))
+	eventHandler := &api.EventHandler{
+		Control: handle.NewEventControl(events),
+		Access:  access,
+	}
+	webhookService := services.NewWebhookService(inmemory.NewInMemoryWebhookStore(db), events, access)
+	webhookHandler := &api.WebhookHandler{
+		Control: handle.NewWebhookControl(webhookService),
+		Access:  access,
+	}
+	// batches run in one transaction over the same stores and publish their events once committed
+	batchHandler := &api.BatchHandler{
+		Control: handle.NewBatchControl(metrics.InstrumentTransactor(inmemory.NewInMemoryTransactor(db), storeDurations), events).WithJournal(journal),
+	}
+	// a daemon keeps the chat history for the chats that run as its clients; it is left out if another process holds it
+	var chats services.ChatHistory = chat.HistoryFile(conf.GetChatDBPath())
+	var chatHandler *api.ChatHandler
+	if cfg.Socket != "" {
+		chatDB, err := storm.Open(conf.GetChatDBPath(), storm.BoltOptions(0600, &bolt.Options{Timeout: time.Second}))
+		if err != nil {
+			log.Printf("Serving without the chat history: %s", err)
+		} else {
+			defer func() { _ = chatDB.Close() }()
+			chatService := chat.NewChatService(chat.NewStromRepo(chatDB))
+			chats = chatService
+			chatHandler = &api.ChatHandler{Service: chatService}
+		}
+	}
+	// the search index is built once from the stores and the chat history, then kept up to date from the event bus
+	// and the chat responses saved through the socket
+	searchService := services.NewSearchService(taskStore, goalStore, planStore, chats)
+	if err := searchService.Rebuild(); err != nil {
+		return fmt.Errorf("error building search index: %s", err)
+	}
+	searchService.Watch(events)
+	if chatHandler != nil {
+		chatHandler.Search = searchService
+	}
+	searchHandler := &api.SearchHandler{
+		Control: handle.NewSearchControl(searchService),
+		Access:  access,
+	}
+	graphqlHandler := &api.GraphQLHandler{
+		Task:    taskHandler,
+		Goal:    goalHandler,
+		Plan:    planHandler,
+		Planner: plannerHandler,
 	}
 	// Register handlers and routes
-	r.HandleFunc("/listtasks", taskHandler.ListTasks).Methods("GET")
-	r.HandleFunc("/task/new", taskHandler.CreateTask).Methods("POST")
-	r.HandleFunc("/task/{id}", taskHandler.GetTask).Methods("GET")
-	r.HandleFunc("/task/title/{title}", taskHandler.GetTaskByTitle).Methods("GET")
-	r.HandleFunc("/task/owner/{owner}", taskHandler.GetTaskByOwner).Methods("GET")
-	r.HandleFunc("/task/{id}", taskHandler.UpdateTask).Methods("PUT")
-	r.HandleFunc("/task/{id}", taskHandler.DeleteTask).Methods("DELETE")
-
-	r.HandleFunc("/listgoals", goalHandler.ListGoals).Methods("GET")
-	r.HandleFunc("/goal/new", goalHandler.CreateGoal).Methods("POST")
-	r.HandleFunc("/goal/{id}", goalHandler.GetGoal).Methods("GET")
-	r.HandleFunc("/goal/obj/{objective}", goalHandler.GetGoalByObjective).Methods("GET")
-	r.HandleFunc("/goal/pid/{planner_id}", goalHandler.GetGoalsByPlannerIdRequest).Methods("GET")
-	r.HandleFunc("/goal/{id}", goalHandler.UpdateGoal).Methods("PUT")
-	r.HandleFunc("/goal/{id}", goalHandler.DeleteGoal).Methods("DELETE")
-
-	r.HandleFunc("/listplans", planHandler.ListPlans).Methods("GET")
-	r.HandleFunc("/plan/new", planHandler.CreatePlan).Methods("POST")
-	r.HandleFunc("/plan/{id}", planHandler.GetPlan).Methods("GET")
-	r.HandleFunc("/plan/name/{plan_name}", planHandler.GetPlanByName).Methods("GET")
-	r.HandleFunc("/plan/goal/{goal_id}", planHandler.GetPlansByGoal).Methods("GET")
-	r.HandleFunc("/plan/{id}", planHandler.UpdatePlan).Methods("PUT")
-	r.HandleFunc("/plan/{id}", planHandler.DeletePlan).Methods("DELETE")
-
-	r.HandleFunc("/listplanners", plannerHandler.ListPlanners).Methods("GET")
-	r.HandleFunc("/planner/new", plannerHandler.CreatePlanner).Methods("POST")
-	r.HandleFunc("/planner/{id}", plannerHandler.GetPlanner).Methods("GET")
-	r.HandleFunc("/planner/title/{title}", plannerHandler.GetPlannerByTitle).Methods("GET")
-	r.HandleFunc("/planner/owner/{owner}", plannerHandler.GetPlannerByOwner).Methods("GET")
-	r.HandleFunc("/planner/{id}", plannerHandler.UpdatePlanner).Methods("PUT")
-	r.HandleFunc("/planner/{id}", plannerHandler.DeletePlanner).Methods("DELETE")
+	routes := api.Routes(&api.Handlers{
+		Task:    taskHandler,
+		Goal:    goalHandler,
+		Plan:    planHandler,
+		Planner: plannerHandler,
+		Event:   eventHandler,
+		Webhook: webhookHandler,
+		GraphQL: graphqlHandler,
+		Batch:   batchHandler,
+		Search:  searchHandler,
+		Undo:    undoHandler,
+		Trash:   trashHandler,
+	})
+	api.Register(r, routes)
+	r.HandleFunc("/openapi.json", api.OpenAPIHandler(routes)).Methods("GET")
+	r.HandleFunc("/docs", api.DocsHandler).Methods("GET")
+	r.HandleFunc("/graphql/schema", graphqlHandler.GraphQLSchema).Methods("GET")
+	// probes for the container runtime, and the metrics for Prometheus, which scrapes them with an API token
+	healthHandler := &api.HealthHandler{Ready: func() error { return inmemory.CheckWritable(db) }}
+	r.HandleFunc("/healthz", healthHandler.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+	r.Handle("/metrics", registry.Handler()).Methods("GET")
 	// Apply the middleware to the router
-	r.Use(loggingMiddleware)
+	userControl := handle.NewUserControl(services.NewUserService(inmemory.NewInMemoryUserStore(db)))
+	requests := newRequestMetrics(registry)
+	r.Use(loggingMiddleware(requests), authMiddleware(userControl))
 
-	log.Println("Listening on port 8080")
-	err = http.ListenAndServe(":8080", r)
-	if err != nil {
-		log.Fatalf("error serving: %s", err)
+	s := newServers(cfg)
+	// the gRPC service runs on its own port, on the same controls as the HTTP handlers
+	if cfg.GRPCPort != 0 {
+		opts := []grpc.ServerOption{rpc.EndStreamsOn(s.streams)}
+		if tlsConf != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConf)))
+		}
+		grpcServer := (&rpc.Server{
+			Task:    taskHandler.Control,
+			Goal:    goalHandler.Control,
+			Plan:    planHandler.Control,
+			Planner: plannerHandler.Control,
+			Version: versionControl,
+			Event:   eventHandler.Control,
+			Access:  access,
+			Users:   userControl,
+		}).GRPCServer(opts...)
+		ln, err := net.Listen("tcp", cfg.GRPCAddr())
+		if err != nil {
+			return fmt.Errorf("error listening on %s: %s", cfg.GRPCAddr(), err)
+		}
+		s.setGRPC(grpcServer, ln)
+	}
+	if cfg.Port != 0 {
+		ln, err := net.Listen("tcp", cfg.HTTPAddr())
+		if err != nil {
+			s.close()
+			return fmt.Errorf("error listening on %s: %s", cfg.HTTPAddr(), err)
+		}
+		s.addHTTP(r, ln, tlsConf, nil)
 	}
+	// the socket serves the same API to the cli and the chat of the user who runs the server, without a token,
+	// the chat history, the users and their tokens, and a route to stop the daemon
+	if cfg.Socket != "" {
+		ln, err := listenSocket(cfg.Socket)
+		if err != nil {
+			s.close()
+			return fmt.Errorf("error listening on %s: %s", cfg.Socket, err)
+		}
+		local := mux.NewRouter()
+		local.Use(loggingMiddleware(requests), localOnly)
+		local.HandleFunc("/daemon/stop", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			s.Stop()
+		}).Methods("POST")
+		if chatHandler != nil {
+			api.RegisterChatRoutes(local, chatHandler)
+		}
+		api.RegisterUserRoutes(local, &api.UserHandler{Control: userControl})
+		local.NotFoundHandler = r
+		s.addHTTP(local, ln, nil, localConn)
+	}
+
+	// deliver events to the webhooks and report goals whose deadline passes in the background, once every listener
+	// is up, and stop them again before the database is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		webhookService.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		goalService.WatchDeadlines(ctx, time.Minute)
+	}()
+	err = s.run()
+	cancel()
+	background.Wait()
+	return err
 }
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// localKey is the context key that marks the requests received over the Unix socket of the daemon.
+type localKey struct{}
+
+// localConn marks the requests of a connection to the Unix socket; it is the ConnContext of the socket's server.
+func localConn(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, localKey{}, true)
+}
+
+// isLocal reports whether a request comes from the user who runs the daemon: it was received over the Unix socket,
+// which only that user can open, and was not forwarded by the HTTP front end, which adds X-Forwarded-For
+// to the requests of its clients.
+func isLocal(r *http.Request) bool {
+	local, _ := r.Context().Value(localKey{}).(bool)
+	return local && r.Header.Get("X-Forwarded-For") == ""
+}
+
+// localOnly answers 404 Not Found to the requests that do not come from the user who runs the daemon,
+// for the routes only that user may use, such as the chat history.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLocal(r) {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenSocket listens on the Unix socket at path, which only the user who runs the daemon can open.
+// A socket file left behind by a daemon that did not shut down is removed first; if a daemon still answers
+// on it, listenSocket fails instead.
+func listenSocket(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"net"
+	"time"
+)
+
+// selfSignedValidity is how long a generated development certificate is valid.
+const selfSignedValidity = 30 * 24 * time.Hour
+
+// tlsConfig returns the TLS configuration of the server: the certificate and key of cfg,
+// or a self-signed certificate for cfg.TLSSelfSigned. It returns nil if cfg does not enable TLS.
+func tlsConfig(cfg *Config) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case cfg.TLSCert != "":
+		cert, err = tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	case cfg.TLSSelfSigned:
+		cert, err = selfSignedCertificate(cfg.Addr)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// selfSignedCertificate generates a certificate for localhost, the loopback addresses and addr, if it is set.
+// Clients must be told to trust it, such as with curl --insecure; its SHA-256 fingerprint is logged so that it can be checked.
+func selfSignedCertificate(addr string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"flow development"}, CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if ip := net.ParseIP(addr); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if addr != "" {
+		template.DNSNames = append(template.DNSNames, addr)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	fingerprint := sha256.Sum256(der)
+	log.Printf("Generated a self-signed certificate for development, SHA-256 fingerprint %s", hex.EncodeToString(fingerprint[:]))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(ShellCmd)
+	addRemoteFlags(ShellCmd)
+}
+
+var ShellCmd = &cobra.Command{
+	Use:     "shell",
+	Aliases: []string{"cli"},
+	Short:   "run flow in interactive mode",
+	Long: `run flow in interactive mode
+
+The shell prompts for commands, and for each of their fields, until you type 'exit'. Lines can be edited,
+tab completes the commands and, at the prompts, the ids, @"names" and names of the records, and the
+commands entered are kept in ~/.flow/shell_history, or $FLOW_HOME/shell_history. To script flow,
+use the task, goal, plan and planner commands instead. The shell works on the database through the daemon,
+which it starts in the background if it is not running, so any number of shells can run at once.
+With --remote or --profile, it works on the data of a flow server instead, authenticated with an API token.
+
+Example usage:
+flow shell
+flow shell --db ./work.db
+flow shell --remote https://flow.team.local --token $FLOW_TOKEN
+flow shell --profile team`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		if err := conf.EnsureDirs(); err != nil {
+			return err
+		}
+		// run cli loop
+		return cli.Run(c, conf.GetShellHistoryPath())
+	},
+}
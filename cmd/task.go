@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// taskFlags hold the flags of the task commands.
+var taskFlags struct {
+	title, description, owner, plan string
+	sort                            string
+	limit, revision                 int
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskCreateCmd, taskGetCmd, taskListCmd, taskUpdateCmd, taskDeleteCmd)
+	addRemoteFlags(taskCmd)
+	addOutputFlag(taskCmd)
+
+	flags := taskCreateCmd.Flags()
+	flags.StringVar(&taskFlags.title, "title", "", "the title of the task")
+	flags.StringVar(&taskFlags.description, "desc", "", "the description of the task")
+	flags.StringVar(&taskFlags.owner, "owner", "", "the owner of the task; the user of the token if empty")
+	flags.StringVar(&taskFlags.plan, "plan", "", "the plan the task belongs to")
+	_ = taskCreateCmd.MarkFlagRequired("title")
+
+	flags = taskListCmd.Flags()
+	flags.StringVar(&taskFlags.owner, "owner", "", "only list the tasks of this owner")
+	flags.StringVar(&taskFlags.sort, "sort", "", "the field to sort by, such as title or -createdAt")
+	flags.IntVar(&taskFlags.limit, "limit", 0, "the maximum number of tasks to list; all if 0")
+
+	flags = taskUpdateCmd.Flags()
+	flags.String("title", "", "the new title")
+	flags.String("desc", "", "the new description")
+	flags.String("owner", "", "the new owner")
+	flags.String("plan", "", "the new plan; empty to take the task out of its plan")
+	flags.Bool("started", false, "whether the task is started")
+	flags.Bool("completed", false, "whether the task is completed")
+	flags.Bool("failed", false, "whether the task failed")
+	flags.IntVar(&taskFlags.revision, "revision", 0, "fail unless the task is still at this revision")
+
+	taskDeleteCmd.Flags().IntVar(&taskFlags.revision, "revision", 0, "fail unless the task is still at this revision")
+
+	for _, cmd := range []*cobra.Command{taskGetCmd, taskUpdateCmd, taskDeleteCmd} {
+		cmd.ValidArgsFunction = completeFirstArg(completeRefs(taskRefs))
+	}
+	for _, cmd := range []*cobra.Command{taskCreateCmd, taskUpdateCmd} {
+		_ = cmd.RegisterFlagCompletionFunc("plan", completeRefs(planRefs))
+	}
+}
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "create, show, list, update and delete tasks",
+	Long: `create, show, list, update and delete tasks, without prompting, for scripts. Every command prints
+a table, or JSON with --output json, and exits with a non-zero code if it fails: 2 for invalid arguments,
+3 if the task does not exist, 4 if access is denied and 5 if the task changed since --revision.
+
+get, update and delete take the id of the task, a unique prefix of it such as 3fa8, or its title after an @,
+such as @"Ship v2". --plan takes a plan the same way.`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var taskCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a task and print its id",
+	Long: `create a task and print its id.
+
+Example usage:
+flow task create --title "Write the release notes" --desc "For 1.2" --owner alice
+flow task create --title "Tag the release" --plan 1b4e28ba-2fa1-11d2-883f-0016d3cca427 -o json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			if err := resolveRef(&taskFlags.plan, ctl.Plan.ResolvePlan); err != nil {
+				return err
+			}
+			res, err := ctl.Task.CreateTask(handle.CreateTaskRequest{
+				Title:       taskFlags.title,
+				Description: taskFlags.description,
+				Owner:       taskFlags.owner,
+				PlanId:      taskFlags.plan,
+			})
+			if err != nil {
+				return err
+			}
+			return printID(cmd, res.ID)
+		})
+	},
+}
+
+var taskGetCmd = &cobra.Command{
+	Use:          "get <task id>",
+	Short:        "show a task",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Task.ResolveTask(args[0])
+			if err != nil {
+				return err
+			}
+			task, err := ctl.Task.GetTask(&handle.GetTaskRequest{ID: id})
+			if err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			return printTasks(cmd, task, []*handle.GetTaskResponse{task})
+		})
+	},
+}
+
+var taskListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list tasks",
+	Long: `list the tasks you can see, filtered by owner and sorted by a field.
+
+Example usage:
+flow task list --owner alice --sort -createdAt -o json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			res, err := ctl.Task.FindTasks(&handle.ListRequest{Owner: taskFlags.owner, Sort: taskFlags.sort, Limit: taskFlags.limit})
+			if err != nil {
+				return err
+			}
+			if res.Tasks == nil {
+				res.Tasks = []*handle.GetTaskResponse{}
+			}
+			return printTasks(cmd, res.Tasks, res.Tasks)
+		})
+	},
+}
+
+var taskUpdateCmd = &cobra.Command{
+	Use:   "update <task id>",
+	Short: "change the fields of a task",
+	Long: `change the fields of a task given with flags; the others keep their values. The updated task is printed.
+
+Example usage:
+flow task update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --completed
+flow task update 1b4e28ba-2fa1-11d2-883f-0016d3cca427 --owner bob --revision 3`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		patch, err := patchFlags(cmd, map[string]string{
+			"title": "title", "desc": "description", "owner": "owner", "plan": "plan_id",
+			"started": "started", "completed": "completed", "failed": "failed",
+		})
+		if err != nil {
+			return err
+		}
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Task.ResolveTask(args[0])
+			if err != nil {
+				return err
+			}
+			if patch, err = resolvePatchRef(patch, "plan_id", ctl.Plan.ResolvePlan); err != nil {
+				return err
+			}
+			task, err := ctl.Task.PatchTask(&handle.PatchTaskRequest{ID: id, Patch: patch, Revision: taskFlags.revision})
+			if err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			return printTasks(cmd, task, []*handle.GetTaskResponse{task})
+		})
+	},
+}
+
+var taskDeleteCmd = &cobra.Command{
+	Use:          "delete <task id>",
+	Short:        "delete a task",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			id, err := ctl.Task.ResolveTask(args[0])
+			if err != nil {
+				return err
+			}
+			if err := ctl.Task.DeleteTask(&handle.DeleteTaskRequest{ID: id, Revision: taskFlags.revision}); err != nil {
+				return fmt.Errorf("task %s: %w", id, err)
+			}
+			return printID(cmd, id)
+		})
+	},
+}
+
+// printTasks prints v, a task or a list of them, as printResult does, with a row for each of tasks.
+func printTasks(cmd *cobra.Command, v interface{}, tasks []*handle.GetTaskResponse) error {
+	return printResult(cmd, v, []string{"ID", "TITLE", "OWNER", "PLAN", "STARTED", "COMPLETED", "FAILED", "REVISION"}, func() [][]string {
+		rows := make([][]string, 0, len(tasks))
+		for _, task := range tasks {
+			rows = append(rows, []string{task.ID, task.Title, task.Owner, task.PlanId,
+				strconv.FormatBool(task.Started), strconv.FormatBool(task.Completed), strconv.FormatBool(task.Failed), strconv.Itoa(task.Revision)})
+		}
+		return rows
+	})
+}
+
+// patchFlags returns the merge patch of the flags of cmd that were set, each under the json name fields gives it.
+// Boolean flags are patched as booleans and the others as strings. It fails if none of the flags were set.
+func patchFlags(cmd *cobra.Command, fields map[string]string) (json.RawMessage, error) {
+	patch := map[string]interface{}{}
+	names := make([]string, 0, len(fields))
+	for flag, field := range fields {
+		names = append(names, "--"+flag)
+		f := cmd.Flags().Lookup(flag)
+		if !f.Changed {
+			continue
+		}
+		if f.Value.Type() == "bool" {
+			patch[field] = f.Value.String() == "true"
+		} else {
+			patch[field] = f.Value.String()
+		}
+	}
+	if len(patch) == 0 {
+		sort.Strings(names)
+		return nil, usageError{fmt.Errorf("nothing to update: set %s", strings.Join(names, ", "))}
+	}
+	for _, field := range []string{"goal_status", "plan_status"} {
+		if status, ok := patch[field].(string); ok {
+			parsed, err := parseStatus(status)
+			if err != nil {
+				return nil, err
+			}
+			patch[field] = parsed
+		}
+	}
+	return json.Marshal(patch)
+}
+
+// resolveRef replaces *r, the value of a flag that names a record, with the id resolve returns for it, unless it is empty.
+func resolveRef(r *string, resolve func(string) (string, error)) error {
+	if *r == "" {
+		return nil
+	}
+	id, err := resolve(*r)
+	if err != nil {
+		return err
+	}
+	*r = id
+	return nil
+}
+
+// resolvePatchRef replaces field of patch, which names a record, with the id resolve returns for it,
+// unless the field is not in patch or empty.
+func resolvePatchRef(patch json.RawMessage, field string, resolve func(string) (string, error)) (json.RawMessage, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, err
+	}
+	r, _ := fields[field].(string)
+	if r == "" {
+		return patch, nil
+	}
+	id, err := resolve(r)
+	if err != nil {
+		return nil, err
+	}
+	fields[field] = id
+	return json.Marshal(fields)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestTaskCommands(t *testing.T) {
+	s := newFlowServer(t)
+
+	var created map[string]string
+	s.runJSON(t, &created, "task", "create", "--title", "Write the notes", "--owner", "alice")
+	id := created["id"]
+	assert.Len(t, id, 36)
+
+	var task map[string]interface{}
+	s.runJSON(t, &task, "task", "get", id[:8])
+	for _, key := range []string{"id", "title", "description", "owner", "started", "completed", "failed", "createdAt", "updatedAt", "revision"} {
+		assert.Contains(t, task, key)
+	}
+	assert.Equal(t, id, task["id"])
+	assert.Equal(t, "Write the notes", task["title"])
+	assert.Equal(t, "alice", task["owner"])
+	assert.Equal(t, false, task["started"])
+
+	out, code := s.run(t, "task", "get", id[:8])
+	assert.Equal(t, 0, code)
+	assert.Contains(t, out, "Write the notes")
+	assert.Contains(t, out, "REVISION")
+
+	var tasks []map[string]interface{}
+	s.runJSON(t, &tasks, "task", "list", "--owner", "alice")
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, id, tasks[0]["id"])
+	}
+
+	var updated map[string]interface{}
+	s.runJSON(t, &updated, "task", "update", "@write the notes", "--started", "--revision", strconv.Itoa(int(task["revision"].(float64))))
+	assert.Equal(t, true, updated["started"])
+	assert.Equal(t, "alice", updated["owner"])
+
+	// the same title twice makes @title ambiguous
+	s.runJSON(t, &created, "task", "create", "--title", "Write the notes")
+	second := created["id"]
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "missing", args: []string{"task", "get", "00000000-0000-0000-0000-000000000000"}, code: exitNotFound},
+		{name: "missing prefix", args: []string{"task", "get", "zzzz"}, code: exitNotFound},
+		{name: "ambiguous", args: []string{"task", "get", "@Write the notes"}, code: exitUsage},
+		{name: "stale revision", args: []string{"task", "update", id, "--title", "Rewrite", "--revision", "1"}, code: exitConflict},
+		{name: "no title", args: []string{"task", "create"}, code: exitUsage},
+		{name: "extra argument", args: []string{"task", "list", id}, code: exitUsage},
+		{name: "unknown output", args: []string{"task", "list", "-o", "yaml"}, code: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := s.run(t, tt.args...)
+			assert.Equal(t, tt.code, code, out)
+		})
+	}
+
+	var deleted map[string]string
+	s.runJSON(t, &deleted, "task", "delete", second)
+	assert.Equal(t, map[string]string{"id": second}, deleted)
+	_, code = s.run(t, "task", "get", second)
+	assert.Equal(t, exitNotFound, code)
+	s.runJSON(t, &tasks, "task", "list")
+	assert.Len(t, tasks, 1)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+)
+
+var tokenName string
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenRevokeCmd, tokenListCmd)
+	tokenCreateCmd.Flags().StringVarP(&tokenName, "name", "n", "", "a label for the token, such as the machine it is used on")
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "manage API tokens",
+	Long: `manage the API tokens used to authenticate with the server.
+
+Send a token in the Authorization header of each request:
+curl -H "Authorization: Bearer flow_..." localhost:8080/listtasks`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <user>",
+	Short: "create an API token for a user",
+	Long: `create an API token for a user. The token is printed once and cannot be shown again.
+
+Example usage:
+flow token create alice --name laptop`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserClient(func(c *client.Client) error {
+			res, err := c.CreateToken(&handle.CreateTokenRequest{UserName: args[0], Name: tokenName})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created token %s for %s. Store it now, it will not be shown again:\n%s\n", res.Id, args[0], res.Token)
+			return nil
+		})
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <token id>",
+	Short: "revoke an API token",
+	Long: `revoke an API token so that it can no longer be used. The id is printed by "flow token create" and "flow token list".
+
+Example usage:
+flow token revoke 2f1c7a9e-...`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserClient(func(c *client.Client) error {
+			if err := c.RevokeToken(&handle.RevokeTokenRequest{Id: args[0]}); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Revoked token %s\n", args[0])
+			return nil
+		})
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:          "list <user>",
+	Short:        "list the API tokens of a user",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserClient(func(c *client.Client) error {
+			tokens, err := c.ListTokens(&handle.ListTokensRequest{UserName: args[0]})
+			if err != nil {
+				return err
+			}
+			for _, token := range tokens {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", token.Id, token.Name, token.CreatedAt.Format("2006-01-02"))
+			}
+			return nil
+		})
+	},
+}
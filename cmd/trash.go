@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/internal/conf"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/spf13/cobra"
+)
+
+var trashOlderThan string
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd, trashRestoreCmd, trashEmptyCmd)
+	for _, cmd := range []*cobra.Command{trashListCmd, trashRestoreCmd, trashEmptyCmd} {
+		addRemoteFlags(cmd)
+		addOutputFlag(cmd)
+	}
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "only purge the records deleted longer ago than this, such as 30d or 12h")
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "list, restore and purge deleted tasks, goals, plans and planners",
+	Long: `list, restore and purge deleted tasks, goals, plans and planners. Deleting a record moves it to the trash,
+together with the records below it that are not in the trash yet: deleting a planner takes its goals, their plans
+and their tasks along, deleting a goal its plans and their tasks, and deleting a plan its tasks. Records in the
+trash are left out of every list and lookup until they are restored, and are only removed for good when the
+trash is emptied.
+
+With --remote or --profile, you see the records you could have deleted yourself: the planners you own and the
+goals, plans and tasks of the planners you can edit.`,
+	PersistentPreRunE: preRunWithOutput,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "list the records in the trash, most recently deleted first",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		res, err := c.ListTrash()
+		if err != nil {
+			return err
+		}
+		if outputFormat == "table" && len(res.Items) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "The trash is empty")
+			return nil
+		}
+		return printTrash(cmd, res)
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "restore a record from the trash, with the records deleted along with it",
+	Long: `restore a record from the trash, with the records deleted along with it. Restoring a record that was
+deleted along with another one, such as a task of a deleted plan, restores that other record and everything
+deleted with it. A record whose goal, plan or planner was deleted on its own, and is still in the trash,
+cannot be restored until that one is. The restore can be undone with flow undo.
+
+Example usage:
+flow trash restore 5f0c8f3e-6a51-4c1e-9d4f-2b7f0e9a1c3d`,
+	Args:              cobra.ExactArgs(1),
+	SilenceUsage:      true,
+	ValidArgsFunction: completeFirstArg(completeTrash),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		res, err := c.RestoreTrash(&handle.RestoreTrashRequest{Id: args[0]})
+		if err != nil {
+			return err
+		}
+		return printTrash(cmd, res)
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "purge the records in the trash for good",
+	Long: `purge the records in the trash for good, with the records deleted along with them. With --older-than,
+only the records deleted longer ago than the given number of days (30d) or duration (12h) are purged.
+Purged records cannot be restored, and their deletion can no longer be undone.
+
+Example usage:
+flow trash empty --older-than 30d`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := handle.ParseAge(trashOlderThan); err != nil {
+			return usageError{fmt.Errorf("invalid --older-than: %w", err)}
+		}
+		c, err := connect()
+		if err != nil {
+			return err
+		}
+		res, err := c.EmptyTrash(&handle.EmptyTrashRequest{OlderThan: trashOlderThan})
+		if err != nil {
+			return err
+		}
+		if outputFormat == "table" && len(res.Items) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "Nothing to purge")
+			return nil
+		}
+		return printTrash(cmd, res)
+	},
+}
+
+// printTrash prints the records of a response of the trash, one per row.
+func printTrash(cmd *cobra.Command, res *handle.TrashResponse) error {
+	return printResult(cmd, res, []string{"RECORD", "ID", "NAME", "DELETED", "DELETED WITH"}, func() [][]string {
+		rows := make([][]string, 0, len(res.Items))
+		for _, item := range res.Items {
+			rows = append(rows, []string{item.Resource, item.Id, item.Name,
+				formatDate(item.DeletedAt, "2006-01-02 15:04:05"), item.DeletedWith})
+		}
+		return rows
+	})
+}
+
+// completeTrash offers the ids of the records in the trash, described by their kind and name.
+// Like completionControls, it does not start a daemon to look them up.
+func completeTrash(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	res, err := listTrashForCompletion(cmd)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), false)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	completions := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		completions = append(completions, item.Id+"\t"+item.Resource+" "+item.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listTrashForCompletion lists the trash through the server with --remote or --profile, through the daemon
+// if one is running, and from the database otherwise.
+func listTrashForCompletion(cmd *cobra.Command) (*handle.TrashResponse, error) {
+	// cobra does not run the PersistentPreRunE hooks when it completes
+	if err := rootCmd.PersistentPreRunE(cmd, nil); err != nil {
+		return nil, err
+	}
+	if remote != "" || profile != "" {
+		c, err := connect()
+		if err != nil {
+			return nil, err
+		}
+		return c.ListTrash()
+	}
+	if c := client.NewSocketClient(conf.GetSocketPath()); c.Ping() == nil {
+		return c.ListTrash()
+	}
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return handle.NewTrashControl(services.NewTrashService(inmemory.NewInMemoryTrashStore(db))).ListTrash()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/cmd/tui"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+	addRemoteFlags(tuiCmd)
+	tuiCmd.ValidArgsFunction = completeFirstArg(completeRefs(plannerRefs))
+}
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [planner]",
+	Short: "work on the tasks of a planner on a kanban board",
+	Long: `work on the tasks of a planner on a full-screen kanban board, with a column for each status:
+Not Started, In Progress, Completed and Failed. The sidebar lists the goals of the planner and their plans;
+moving through it narrows the board down to a goal or a plan. The planner is given by its id, a unique prefix
+of it or @"title"; without it, you pick one when there are several.
+
+Keys:
+←/→ or h/l     move between the columns, and from the first one to the sidebar
+↑/↓ or j/k     move between the cards of a column
+< and >        move the selected card to the previous or next column (also shift+←/→)
+enter or e     edit the selected card in the detail pane; esc closes it
+n              add a card to the plan, or the goal, selected in the sidebar
+d              delete the selected card
+/              filter the cards by their title, description and owner as you type; esc clears the filter
+tab            switch between the sidebar and the board
+p              switch to another planner
+r              reload the board
+q              quit
+
+Like the shell, the board works on the database through the daemon, or with --remote or --profile
+on the data of a flow server.
+
+Example usage:
+flow tui
+flow tui @"Release 1.2"
+flow tui --profile team`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withControls(func(ctl *cli.Controls) error {
+			plannerID := ""
+			if len(args) == 1 {
+				id, err := ctl.Planner.ResolvePlanner(args[0])
+				if err != nil {
+					return err
+				}
+				plannerID = id
+			}
+			return tui.Run(ctl, plannerID)
+		})
+	},
+}
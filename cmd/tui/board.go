@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"encoding/json"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"sort"
+	"strings"
+)
+
+// The columns of the board, from left to right.
+const (
+	notStarted = iota
+	inProgress
+	completed
+	failed
+	numColumns
+)
+
+// columnTitles are the titles of the columns, which are the statuses goals and plans use.
+var columnTitles = [numColumns]string{models.NotStarted, models.InProgress, models.Completed, models.Fail}
+
+// columnOf returns the column a task is shown in.
+func columnOf(task *handle.GetTaskResponse) int {
+	switch {
+	case task.Failed:
+		return failed
+	case task.Completed:
+		return completed
+	case task.Started:
+		return inProgress
+	}
+	return notStarted
+}
+
+// movePatch returns the merge patch that moves a task to a column. A task that fails keeps whether it was started.
+func movePatch(column int) map[string]interface{} {
+	switch column {
+	case inProgress:
+		return map[string]interface{}{"started": true, "completed": false, "failed": false}
+	case completed:
+		return map[string]interface{}{"started": true, "completed": true, "failed": false}
+	case failed:
+		return map[string]interface{}{"completed": false, "failed": true}
+	}
+	return map[string]interface{}{"started": false, "completed": false, "failed": false}
+}
+
+// board holds a planner with its goals, the plans of those and the tasks of the plans.
+type board struct {
+	planner *handle.GetPlannerResponse
+	goals   []*models.Goal
+	plans   []*models.Plan
+	tasks   []*handle.GetTaskResponse
+}
+
+// loadBoard loads the planner with the given id and everything in it.
+func loadBoard(ctl *cli.Controls, plannerID string) (*board, error) {
+	planner, err := ctl.Planner.GetPlanner(&handle.GetPlannerRequest{Id: plannerID})
+	if err != nil {
+		return nil, err
+	}
+	b := &board{planner: planner}
+	goals, err := ctl.Goal.FindGoals(&handle.ListRequest{PlannerId: plannerID, Sort: "deadline"})
+	if err != nil {
+		return nil, err
+	}
+	b.goals = goals.Goals
+	// an empty list of ids would not filter at all
+	if len(b.goals) == 0 {
+		return b, nil
+	}
+	goalIDs := make([]string, 0, len(b.goals))
+	for _, goal := range b.goals {
+		goalIDs = append(goalIDs, goal.Id)
+	}
+	plans, err := ctl.Plan.FindPlans(&handle.ListRequest{GoalIds: goalIDs})
+	if err != nil {
+		return nil, err
+	}
+	b.plans = plans.Plans
+	if len(b.plans) == 0 {
+		return b, nil
+	}
+	planIDs := make([]string, 0, len(b.plans))
+	for _, plan := range b.plans {
+		planIDs = append(planIDs, plan.Id)
+	}
+	tasks, err := ctl.Task.FindTasks(&handle.ListRequest{PlanIds: planIDs})
+	if err != nil {
+		return nil, err
+	}
+	b.tasks = tasks.Tasks
+	sort.SliceStable(b.tasks, func(i, j int) bool { return b.tasks[i].CreatedAt.Before(b.tasks[j].CreatedAt) })
+	return b, nil
+}
+
+// scope is the part of the planner the board shows, as selected in the sidebar: the whole planner
+// if both ids are empty, the plans of a goal, or a plan.
+type scope struct {
+	goalID, planID string
+}
+
+// plan returns the plan with the given id, or nil.
+func (b *board) plan(id string) *models.Plan {
+	for _, plan := range b.plans {
+		if plan.Id == id {
+			return plan
+		}
+	}
+	return nil
+}
+
+// plansIn returns the plans in scope s.
+func (b *board) plansIn(s scope) []*models.Plan {
+	var plans []*models.Plan
+	for _, plan := range b.plans {
+		if s.planID != "" && plan.Id != s.planID || s.goalID != "" && plan.GoalId != s.goalID {
+			continue
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
+
+// columns returns the tasks of each column that are in scope s and match filter, a text that must occur
+// in their title, description or owner regardless of case.
+func (b *board) columns(s scope, filter string) [numColumns][]*handle.GetTaskResponse {
+	inScope := map[string]bool{}
+	for _, plan := range b.plansIn(s) {
+		inScope[plan.Id] = true
+	}
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	var columns [numColumns][]*handle.GetTaskResponse
+	for _, task := range b.tasks {
+		if !inScope[task.PlanId] {
+			continue
+		}
+		text := strings.ToLower(task.Title + "\n" + task.Description + "\n" + task.Owner)
+		if filter != "" && !strings.Contains(text, filter) {
+			continue
+		}
+		col := columnOf(task)
+		columns[col] = append(columns[col], task)
+	}
+	return columns
+}
+
+// marshalPatch encodes a merge patch.
+func marshalPatch(patch map[string]interface{}) json.RawMessage {
+	body, _ := json.Marshal(patch)
+	return body
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestColumnOf(t *testing.T) {
+	tests := []struct {
+		name string
+		task handle.GetTaskResponse
+		want int
+	}{
+		{name: "not started", task: handle.GetTaskResponse{}, want: notStarted},
+		{name: "in progress", task: handle.GetTaskResponse{Started: true}, want: inProgress},
+		{name: "completed", task: handle.GetTaskResponse{Started: true, Completed: true}, want: completed},
+		{name: "failed", task: handle.GetTaskResponse{Started: true, Failed: true}, want: failed},
+		{name: "failed before it started", task: handle.GetTaskResponse{Failed: true}, want: failed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, columnOf(&tt.task))
+		})
+	}
+}
+
+// applyPatch sets the fields of task that patch, a patch of movePatch, names.
+func applyPatch(task *handle.GetTaskResponse, patch map[string]interface{}) {
+	for key, value := range patch {
+		switch key {
+		case "started":
+			task.Started = value.(bool)
+		case "completed":
+			task.Completed = value.(bool)
+		case "failed":
+			task.Failed = value.(bool)
+		}
+	}
+}
+
+func TestMovePatch(t *testing.T) {
+	starts := []handle.GetTaskResponse{
+		{},
+		{Started: true},
+		{Started: true, Completed: true},
+		{Started: true, Failed: true},
+		{Failed: true},
+	}
+	for column := 0; column < numColumns; column++ {
+		t.Run(columnTitles[column], func(t *testing.T) {
+			for _, start := range starts {
+				task := start
+				applyPatch(&task, movePatch(column))
+				assert.Equal(t, column, columnOf(&task), "moving %+v", start)
+			}
+		})
+	}
+
+	// moving to the first column resets the task, and a task that fails keeps whether it was started
+	assert.Equal(t, map[string]interface{}{"started": false, "completed": false, "failed": false}, movePatch(notStarted))
+	assert.NotContains(t, movePatch(failed), "started")
+	task := handle.GetTaskResponse{Started: true, Completed: true}
+	applyPatch(&task, movePatch(numColumns-1))
+	assert.Equal(t, handle.GetTaskResponse{Started: true, Failed: true}, task)
+}
+
+func TestBoard_Columns(t *testing.T) {
+	b := &board{
+		plans: []*models.Plan{
+			{Id: "plan1", GoalId: "goal1"},
+			{Id: "plan2", GoalId: "goal1"},
+			{Id: "plan3", GoalId: "goal2"},
+		},
+		tasks: []*handle.GetTaskResponse{
+			{ID: "todo", PlanId: "plan1", Title: "Write the notes"},
+			{ID: "doing", PlanId: "plan2", Title: "Tag", Started: true, Owner: "alice"},
+			{ID: "done", PlanId: "plan3", Title: "Build", Started: true, Completed: true},
+			{ID: "broken", PlanId: "plan1", Title: "Deploy", Description: "to the release NOTES server", Failed: true},
+			{ID: "elsewhere", PlanId: "plan4", Title: "Not on the board"},
+		},
+	}
+	ids := func(columns [numColumns][]*handle.GetTaskResponse) [numColumns][]string {
+		var res [numColumns][]string
+		for col, tasks := range columns {
+			for _, task := range tasks {
+				res[col] = append(res[col], task.ID)
+			}
+		}
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		scope  scope
+		filter string
+		want   [numColumns][]string
+	}{
+		{name: "planner", want: [numColumns][]string{{"todo"}, {"doing"}, {"done"}, {"broken"}}},
+		{name: "goal", scope: scope{goalID: "goal1"}, want: [numColumns][]string{{"todo"}, {"doing"}, nil, {"broken"}}},
+		{name: "plan", scope: scope{planID: "plan1"}, want: [numColumns][]string{{"todo"}, nil, nil, {"broken"}}},
+		{name: "unknown plan", scope: scope{planID: "plan4"}},
+		{name: "filter by title and description", filter: " notes ", want: [numColumns][]string{{"todo"}, nil, nil, {"broken"}}},
+		{name: "filter by owner", filter: "ALICE", want: [numColumns][]string{nil, {"doing"}, nil, nil}},
+		{name: "filter in scope", scope: scope{goalID: "goal2"}, filter: "notes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(b.columns(tt.scope, tt.filter)))
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/ooyeku/flow/cmd/cli"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/rivo/tview"
+	"strings"
+)
+
+// help is the line of keys shown at the bottom of the board.
+const help = "←/→ column  ↑/↓ card  </> move  enter edit  n new  d delete  / filter  tab sidebar  p planner  r reload  q quit"
+
+// detailWidth is the width of the detail pane when it is open.
+const detailWidth = 48
+
+// app is the state of the terminal UI: the board of a planner, what part of it is shown and the widgets.
+type app struct {
+	ctl    *cli.Controls
+	board  *board
+	scope  scope
+	filter string
+	// shown holds the cards of each column, in the order of their lists
+	shown [numColumns][]*handle.GetTaskResponse
+	// column is the column whose list has, or last had, the focus
+	column int
+	// editing is set while the detail pane or the filter has the focus, so that keys are typed rather than
+	// taken as commands
+	editing bool
+
+	tui     *tview.Application
+	pages   *tview.Pages
+	content *tview.Flex
+	sidebar *tview.TreeView
+	lists   [numColumns]*tview.List
+	detail  *tview.Form
+	filterF *tview.InputField
+	status  *tview.TextView
+}
+
+// Run shows the tasks of a planner as a kanban board in the terminal until the user quits, on the controls
+// of ctl. Without a plannerID, the user picks the planner first, unless there is only one.
+// The board has a column for each status of the tasks, a sidebar of the goals and plans of the planner,
+// which narrows the board down to one of them, a detail pane to create and edit tasks, and a filter.
+func Run(ctl *cli.Controls, plannerID string) error {
+	planners, err := ctl.Planner.FindPlanners(&handle.ListRequest{Sort: "title"})
+	if err != nil {
+		return err
+	}
+	if len(planners.Planners) == 0 {
+		return errors.New("there are no planners yet; create one with flow planner create")
+	}
+	if plannerID == "" && len(planners.Planners) == 1 {
+		plannerID = planners.Planners[0].Id
+	}
+
+	a := &app{ctl: ctl, tui: tview.NewApplication()}
+	a.layout()
+	if plannerID == "" {
+		a.pickPlanner(planners.Planners)
+	} else if err := a.load(plannerID); err != nil {
+		return err
+	}
+	return a.tui.Run()
+}
+
+// layout builds the widgets: the filter on top, the sidebar, the columns and the detail pane in the middle
+// and the status line at the bottom.
+func (a *app) layout() {
+	a.filterF = tview.NewInputField().SetLabel("Filter: ").SetPlaceholder("press / to filter the cards")
+	a.filterF.SetChangedFunc(func(text string) {
+		a.filter = text
+		a.refresh("")
+	})
+	a.filterF.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			a.filterF.SetText("")
+		}
+		a.focusColumn(a.column)
+	})
+	a.filterF.SetFocusFunc(func() { a.editing = true })
+	a.filterF.SetBlurFunc(func() { a.editing = false })
+
+	a.sidebar = tview.NewTreeView()
+	a.sidebar.SetBorder(true).SetTitle(" Goals and plans ")
+	a.sidebar.SetChangedFunc(func(node *tview.TreeNode) {
+		if s, ok := node.GetReference().(scope); ok {
+			a.scope = s
+			a.refresh("")
+		}
+	})
+	a.sidebar.SetSelectedFunc(func(*tview.TreeNode) { a.focusColumn(a.column) })
+	a.sidebar.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRight {
+			a.focusColumn(a.column)
+			return nil
+		}
+		return event
+	})
+
+	columns := tview.NewFlex()
+	for col := range a.lists {
+		col := col
+		list := tview.NewList().ShowSecondaryText(true).SetSelectedFocusOnly(true).SetHighlightFullLine(true)
+		list.SetBorder(true)
+		list.SetSelectedFunc(func(int, string, string, rune) { a.edit(a.selected()) })
+		list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey { return a.columnKey(col, event) })
+		a.lists[col] = list
+		columns.AddItem(list, 0, 1, col == 0)
+	}
+
+	a.detail = tview.NewForm()
+	a.detail.SetBorder(true)
+	a.detail.SetCancelFunc(a.closeDetail)
+
+	a.content = tview.NewFlex().
+		AddItem(a.sidebar, 32, 0, false).
+		AddItem(columns, 0, 1, true).
+		AddItem(a.detail, 0, 0, false)
+
+	a.status = tview.NewTextView().SetDynamicColors(true)
+	main := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.filterF, 1, 0, false).
+		AddItem(a.content, 0, 1, true).
+		AddItem(a.status, 1, 0, false)
+	a.pages = tview.NewPages().AddPage("board", main, true, true)
+	a.tui.SetRoot(a.pages, true)
+
+	a.tui.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a.editing || a.pages.HasPage("modal") {
+			return event
+		}
+		switch {
+		case event.Rune() == 'q':
+			a.tui.Stop()
+		case event.Rune() == '/':
+			a.tui.SetFocus(a.filterF)
+		case event.Rune() == 'r':
+			a.reload("Reloaded")
+		case event.Rune() == 'p':
+			a.switchPlanner()
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if a.sidebar.HasFocus() {
+				a.focusColumn(a.column)
+			} else {
+				a.tui.SetFocus(a.sidebar)
+			}
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+// columnKey handles the keys of the list of a column: the arrows move between the columns,
+// < and > move the selected card, n adds a card and d deletes one.
+func (a *app) columnKey(col int, event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case event.Key() == tcell.KeyLeft && event.Modifiers()&tcell.ModShift != 0, event.Rune() == '<':
+		a.move(-1)
+	case event.Key() == tcell.KeyRight && event.Modifiers()&tcell.ModShift != 0, event.Rune() == '>':
+		a.move(1)
+	case event.Key() == tcell.KeyLeft || event.Rune() == 'h':
+		if col == 0 {
+			a.tui.SetFocus(a.sidebar)
+		} else {
+			a.focusColumn(col - 1)
+		}
+	case event.Key() == tcell.KeyRight || event.Rune() == 'l':
+		if col < numColumns-1 {
+			a.focusColumn(col + 1)
+		}
+	case event.Rune() == 'j':
+		return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	case event.Rune() == 'k':
+		return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+	case event.Rune() == 'e':
+		a.edit(a.selected())
+	case event.Rune() == 'n':
+		a.edit(nil)
+	case event.Rune() == 'd':
+		a.confirmDelete(a.selected())
+	default:
+		return event
+	}
+	return nil
+}
+
+// focusColumn gives the focus to the list of a column.
+func (a *app) focusColumn(col int) {
+	a.column = col
+	a.tui.SetFocus(a.lists[col])
+}
+
+// selected returns the card selected in the current column, or nil if the column is empty.
+func (a *app) selected() *handle.GetTaskResponse {
+	cards := a.shown[a.column]
+	i := a.lists[a.column].GetCurrentItem()
+	if i < 0 || i >= len(cards) {
+		return nil
+	}
+	return cards[i]
+}
+
+// message shows a message in the status line, followed by the keys; errors are given in [red].
+func (a *app) message(format string, args ...interface{}) {
+	a.status.SetText(fmt.Sprintf(format, args...) + "  [gray]" + help)
+}
+
+// fail shows err in the status line; a conflict is reported as such and the board is reloaded.
+func (a *app) fail(what string, err error) {
+	if errors.Is(err, store.ErrRevisionMismatch) {
+		a.reload(fmt.Sprintf("[red]%s: the task was changed elsewhere; the board is reloaded", what))
+		return
+	}
+	a.message("[red]%s: %s", what, tview.Escape(err.Error()))
+}
+
+// load loads the board of a planner and shows all of it.
+func (a *app) load(plannerID string) error {
+	b, err := loadBoard(a.ctl, plannerID)
+	if err != nil {
+		return err
+	}
+	a.board = b
+	a.scope = scope{}
+	a.buildSidebar()
+	a.refresh("")
+	a.message("Planner %s", tview.Escape(b.planner.Title))
+	a.focusColumn(a.column)
+	return nil
+}
+
+// reload loads the board of the planner again, keeping what is shown, and then shows msg.
+func (a *app) reload(msg string) {
+	selected := ""
+	if task := a.selected(); task != nil {
+		selected = task.ID
+	}
+	b, err := loadBoard(a.ctl, a.board.planner.Id)
+	if err != nil {
+		a.message("[red]Reloading: %s", tview.Escape(err.Error()))
+		return
+	}
+	a.board = b
+	a.buildSidebar()
+	a.refresh(selected)
+	a.message("%s", msg)
+}
+
+// buildSidebar fills the sidebar with the planner, its goals and their plans, keeping the scope selected.
+func (a *app) buildSidebar() {
+	root := tview.NewTreeNode(a.board.planner.Title).SetReference(scope{}).SetColor(tcell.ColorYellow)
+	current := root
+	for _, goal := range a.board.goals {
+		s := scope{goalID: goal.Id}
+		node := tview.NewTreeNode(fmt.Sprintf("%s [%s]", goal.Objective, goal.GoalStatus)).SetReference(s).SetColor(tcell.ColorGreen)
+		if s == a.scope {
+			current = node
+		}
+		for _, plan := range a.board.plansIn(s) {
+			s := scope{goalID: goal.Id, planID: plan.Id}
+			child := tview.NewTreeNode(fmt.Sprintf("%s [%s]", plan.PlanName, plan.PlanStatus)).SetReference(s)
+			if s == a.scope {
+				current = child
+			}
+			node.AddChild(child)
+		}
+		root.AddChild(node)
+	}
+	if current == root {
+		a.scope = scope{}
+	}
+	a.sidebar.SetRoot(root).SetCurrentNode(current)
+}
+
+// refresh fills the columns with the cards in scope that match the filter. The card of the task with the id
+// selectID is selected, wherever it is; otherwise the selection of each column stays where it was.
+func (a *app) refresh(selectID string) {
+	a.shown = a.board.columns(a.scope, a.filter)
+	for col, list := range a.lists {
+		current := list.GetCurrentItem()
+		list.Clear()
+		list.SetTitle(fmt.Sprintf(" %s (%d) ", columnTitles[col], len(a.shown[col])))
+		for i, task := range a.shown[col] {
+			details := task.Owner
+			if plan := a.board.plan(task.PlanId); plan != nil && a.scope.planID == "" {
+				details = strings.TrimPrefix(details+" · "+plan.PlanName, " · ")
+			}
+			list.AddItem(tview.Escape(task.Title), "  "+tview.Escape(details), 0, nil)
+			if task.ID == selectID {
+				current = i
+				a.column = col
+			}
+		}
+		if current >= list.GetItemCount() {
+			current = list.GetItemCount() - 1
+		}
+		if current >= 0 {
+			list.SetCurrentItem(current)
+		}
+	}
+}
+
+// move moves the selected card by delta columns, to the left if delta is negative.
+func (a *app) move(delta int) {
+	task := a.selected()
+	target := a.column + delta
+	if task == nil || target < 0 || target >= numColumns {
+		return
+	}
+	_, err := a.ctl.Task.PatchTask(&handle.PatchTaskRequest{ID: task.ID, Patch: marshalPatch(movePatch(target)), Revision: task.Revision})
+	if err != nil {
+		a.fail("Moving "+tview.Escape(task.Title), err)
+		return
+	}
+	a.column = target
+	a.reload(fmt.Sprintf("Moved %s to %s", tview.Escape(task.Title), columnTitles[target]))
+	a.focusColumn(a.column)
+}
+
+// edit opens the detail pane on a task, or on a new task if task is nil. Saving the form creates the task
+// or changes the fields that were edited.
+func (a *app) edit(task *handle.GetTaskResponse) {
+	plans := a.board.plansIn(a.scope)
+	if len(plans) == 0 {
+		plans = a.board.plans
+	}
+	if task == nil && len(plans) == 0 {
+		a.message("[red]Add a goal and a plan to the planner before adding tasks")
+		return
+	}
+	if task != nil {
+		// a task can be moved to any plan of the planner
+		plans = a.board.plans
+	}
+	planNames := make([]string, len(plans))
+	planIndex := 0
+	for i, plan := range plans {
+		planNames[i] = plan.PlanName
+		if task != nil && plan.Id == task.PlanId {
+			planIndex = i
+		}
+	}
+	status := a.column
+	title := " New task "
+	var name, description, owner string
+	if task != nil {
+		status = columnOf(task)
+		title = " Edit task "
+		name, description, owner = task.Title, task.Description, task.Owner
+	}
+
+	a.detail.Clear(true)
+	a.detail.SetTitle(title)
+	a.detail.AddInputField("Title", name, 0, nil, nil).
+		AddTextArea("Description", description, 0, 5, 0, nil).
+		AddInputField("Owner", owner, 0, nil, nil).
+		AddDropDown("Plan", planNames, planIndex, nil).
+		AddDropDown("Status", columnTitles[:], status, nil).
+		AddButton("Save", func() { a.save(task, plans) }).
+		AddButton("Cancel", a.closeDetail)
+	a.content.ResizeItem(a.detail, detailWidth, 0)
+	a.editing = true
+	a.tui.SetFocus(a.detail)
+}
+
+// save creates or updates a task with the values of the detail pane.
+func (a *app) save(task *handle.GetTaskResponse, plans []*models.Plan) {
+	text := func(label string) string {
+		switch item := a.detail.GetFormItemByLabel(label).(type) {
+		case *tview.InputField:
+			return strings.TrimSpace(item.GetText())
+		case *tview.TextArea:
+			return strings.TrimSpace(item.GetText())
+		}
+		return ""
+	}
+	option := func(label string) int {
+		i, _ := a.detail.GetFormItemByLabel(label).(*tview.DropDown).GetCurrentOption()
+		return i
+	}
+	title, description, owner := text("Title"), text("Description"), text("Owner")
+	planID := plans[option("Plan")].Id
+	status := option("Status")
+	if title == "" {
+		a.message("[red]A task needs a title")
+		return
+	}
+
+	if task == nil {
+		res, err := a.ctl.Task.CreateTask(handle.CreateTaskRequest{Title: title, Description: description, Owner: owner, PlanId: planID})
+		if err != nil {
+			a.fail("Creating the task", err)
+			return
+		}
+		if status != notStarted {
+			_, err = a.ctl.Task.PatchTask(&handle.PatchTaskRequest{ID: res.ID, Patch: marshalPatch(movePatch(status))})
+			if err != nil {
+				a.fail("Setting the status of the task", err)
+				return
+			}
+		}
+		a.closeDetail()
+		a.column = status
+		a.reload("Created " + tview.Escape(title))
+		a.selectTask(res.ID)
+		return
+	}
+
+	patch := map[string]interface{}{}
+	if status != columnOf(task) {
+		patch = movePatch(status)
+	}
+	for field, value := range map[string]string{"title": title, "description": description, "owner": owner, "plan_id": planID} {
+		patch[field] = value
+	}
+	_, err := a.ctl.Task.PatchTask(&handle.PatchTaskRequest{ID: task.ID, Patch: marshalPatch(patch), Revision: task.Revision})
+	if err != nil {
+		a.fail("Saving "+tview.Escape(task.Title), err)
+		return
+	}
+	a.closeDetail()
+	a.column = status
+	a.reload("Saved " + tview.Escape(title))
+	a.selectTask(task.ID)
+}
+
+// selectTask selects the card of a task, in whatever column it is, and focuses its column.
+func (a *app) selectTask(id string) {
+	a.refresh(id)
+	a.focusColumn(a.column)
+}
+
+// closeDetail closes the detail pane and gives the focus back to the board.
+func (a *app) closeDetail() {
+	a.content.ResizeItem(a.detail, 0, 0)
+	a.editing = false
+	a.focusColumn(a.column)
+}
+
+// confirmDelete asks whether to delete a task and deletes it if the user agrees.
+func (a *app) confirmDelete(task *handle.GetTaskResponse) {
+	if task == nil {
+		return
+	}
+	a.modal(fmt.Sprintf("Delete %q?", task.Title), []string{"Delete", "Cancel"}, func(label string) {
+		if label != "Delete" {
+			return
+		}
+		if err := a.ctl.Task.DeleteTask(&handle.DeleteTaskRequest{ID: task.ID, Revision: task.Revision}); err != nil {
+			a.fail("Deleting "+tview.Escape(task.Title), err)
+			return
+		}
+		a.reload("Deleted " + tview.Escape(task.Title))
+	})
+}
+
+// modal shows a dialog with buttons over the board and calls done with the label of the button pressed,
+// or an empty label if the dialog was closed with Escape.
+func (a *app) modal(text string, buttons []string, done func(label string)) {
+	dialog := tview.NewModal().SetText(text).AddButtons(buttons).SetDoneFunc(func(_ int, label string) {
+		a.pages.RemovePage("modal")
+		a.focusColumn(a.column)
+		done(label)
+	})
+	a.pages.AddPage("modal", dialog, false, true)
+	a.tui.SetFocus(dialog)
+}
+
+// switchPlanner lets the user pick another planner.
+func (a *app) switchPlanner() {
+	planners, err := a.ctl.Planner.FindPlanners(&handle.ListRequest{Sort: "title"})
+	if err != nil {
+		a.message("[red]Listing the planners: %s", tview.Escape(err.Error()))
+		return
+	}
+	a.pickPlanner(planners.Planners)
+}
+
+// pickPlanner shows a list of planners over the board and loads the one the user picks.
+// Escape closes the list, unless no planner is loaded yet.
+func (a *app) pickPlanner(planners []*handle.GetPlannerResponse) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" Pick a planner ")
+	for _, planner := range planners {
+		id := planner.Id
+		list.AddItem(tview.Escape(planner.Title), "", 0, func() {
+			a.pages.RemovePage("modal")
+			if err := a.load(id); err != nil {
+				a.message("[red]Loading the planner: %s", tview.Escape(err.Error()))
+			}
+		})
+	}
+	list.SetDoneFunc(func() {
+		if a.board != nil {
+			a.pages.RemovePage("modal")
+			a.focusColumn(a.column)
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'q' && a.board == nil {
+			a.tui.Stop()
+			return nil
+		}
+		return event
+	})
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, len(planners)+2, 0, true).
+			AddItem(nil, 0, 1, false), 40, 0, true).
+		AddItem(nil, 0, 1, false)
+	a.pages.AddPage("modal", centered, true, true)
+	a.tui.SetFocus(list)
+}
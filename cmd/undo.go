@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+)
+
+var undoSteps int
+
+func init() {
+	rootCmd.AddCommand(undoCmd, redoCmd)
+	for _, cmd := range []*cobra.Command{undoCmd, redoCmd} {
+		addRemoteFlags(cmd)
+		addOutputFlag(cmd)
+		cmd.Flags().IntVarP(&undoSteps, "steps", "n", 1, "the number of changes to "+cmd.Name())
+	}
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "undo your last changes to tasks, goals, plans and planners",
+	Long: `undo your last changes to tasks, goals, plans and planners, newest first: a created record is deleted,
+an updated one gets its earlier fields back and a deleted one is restored. The changes made with the cli,
+the shell, the board, flow apply and the API are journaled for the user who made them; with --remote or
+--profile, the changes you made on the server with your token are undone.
+
+A change to a record that someone else has changed since, or that you may no longer change, is skipped
+and dropped from the journal rather than overwriting their change; it does not count as a step.
+The last 100 changes can be undone.
+
+Example usage:
+flow undo
+flow undo -n 3`,
+	Args:              cobra.NoArgs,
+	SilenceUsage:      true,
+	PersistentPreRunE: preRunWithOutput,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUndo(cmd, "undone", (*client.Client).Undo)
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "redo the changes you undid last",
+	Long: `redo the changes you undid last with flow undo, in the order you made them. Undone changes can be
+redone until you make a new change. As with undo, a change to a record that someone else has changed since
+is skipped.
+
+Example usage:
+flow redo
+flow redo -n 2`,
+	Args:              cobra.NoArgs,
+	SilenceUsage:      true,
+	PersistentPreRunE: preRunWithOutput,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUndo(cmd, "redone", (*client.Client).Redo)
+	},
+}
+
+// runUndo undoes or redoes --steps changes with do, Client.Undo or Client.Redo, and prints the changes it applied,
+// as done, and those it skipped.
+func runUndo(cmd *cobra.Command, done string, do func(c *client.Client, req *handle.UndoRequest) (*handle.UndoResponse, error)) error {
+	if undoSteps < 1 {
+		return usageError{fmt.Errorf("invalid --steps %d: expected 1 or more", undoSteps)}
+	}
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	res, err := do(c, &handle.UndoRequest{Steps: undoSteps})
+	if err != nil {
+		return err
+	}
+	if outputFormat == "table" && len(res.Changes) == 0 && len(res.Skipped) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Nothing to %s\n", cmd.Name())
+		return nil
+	}
+	return printResult(cmd, res, []string{"CHANGE", "RECORD", "ID", "NAME", "RESULT"}, func() [][]string {
+		rows := make([][]string, 0, len(res.Changes)+len(res.Skipped))
+		for _, change := range res.Changes {
+			rows = append(rows, []string{change.Action, change.Resource, change.ResourceId, change.Name, done})
+		}
+		for _, change := range res.Skipped {
+			rows = append(rows, []string{change.Action, change.Resource, change.ResourceId, change.Name, "skipped"})
+		}
+		return rows
+	})
+}
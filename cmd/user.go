@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userAddCmd, userListCmd)
+}
+
+// withUserClient passes fn a client of the daemon, which it starts if it is not running. Only the daemon manages
+// the users and their tokens, on its socket, so the user commands cannot work against a remote server.
+func withUserClient(fn func(c *client.Client) error) error {
+	c, err := ensureDaemon()
+	if err != nil {
+		return err
+	}
+	return fn(c)
+}
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "manage the users allowed to call the server",
+	Long:  `manage the users allowed to call the server. Each user authenticates with API tokens created with "flow token create".`,
+}
+
+var userAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "add a user",
+	Long: `add a user. The name becomes the default owner of the tasks and planners the user creates through the server.
+
+Example usage:
+flow user add alice`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserClient(func(c *client.Client) error {
+			res, err := c.AddUser(&handle.AddUserRequest{Name: args[0]})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added user %s with id %s\n", args[0], res.Id)
+			return nil
+		})
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "list the users",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withUserClient(func(c *client.Client) error {
+			users, err := c.ListUsers()
+			if err != nil {
+				return err
+			}
+			for _, user := range users {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", user.Id, user.Name, user.CreatedAt.Format("2006-01-02"))
+			}
+			return nil
+		})
+	},
+}
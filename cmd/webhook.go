@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/ooyeku/flow/pkg/client"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var (
+	webhookTypes     []string
+	webhookPlannerId string
+	webhookSecret    string
+	deliveryLimit    int
+)
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookAddCmd, webhookListCmd, webhookRemoveCmd, webhookDeliveriesCmd)
+	addRemoteFlags(webhookCmd)
+	webhookAddCmd.Flags().StringSliceVarP(&webhookTypes, "type", "t", nil, "an event type (task.completed) or resource (goal) to send; repeat for more, all events if not set")
+	webhookAddCmd.Flags().StringVarP(&webhookPlannerId, "planner", "p", "", "only send events about the records of this planner")
+	webhookAddCmd.Flags().StringVarP(&webhookSecret, "secret", "s", "", "the key used to sign the payloads; generated if not set")
+	webhookDeliveriesCmd.Flags().IntVarP(&deliveryLimit, "limit", "n", 20, "the number of delivery attempts to show, 0 for all")
+}
+
+// withWebhookClient connects as withControls does and passes the client to fn, which manages the webhooks
+// the server delivers the events to.
+func withWebhookClient(fn func(c *client.Client) error) error {
+	c, err := connect()
+	if err != nil {
+		return err
+	}
+	return fn(c)
+}
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "manage the webhooks the server sends events to",
+	Long: `manage the webhooks the server sends events to. While the server runs, it POSTs every matching
+event as JSON to the URL of each webhook, retrying failed deliveries with exponential backoff.
+The X-Flow-Signature header of each request carries "sha256=" and the hex encoded HMAC-SHA256 of the body,
+keyed with the secret of the webhook.
+
+With --remote or --profile, the webhooks of the token's user on that server are managed.`,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "add a webhook",
+	Long: `add a webhook. The secret is printed once and cannot be shown again.
+
+Example usage:
+flow webhook add https://ci.example.com/flow --type task.completed --type goal.overdue`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withWebhookClient(func(c *client.Client) error {
+			res, err := c.CreateWebhook(&handle.CreateWebhookRequest{
+				URL:       args[0],
+				Types:     webhookTypes,
+				PlannerId: webhookPlannerId,
+				Secret:    webhookSecret,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added webhook %s. Its secret, which will not be shown again, is:\n%s\n", res.Id, res.Secret)
+			return nil
+		})
+	},
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "list the webhooks",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withWebhookClient(func(c *client.Client) error {
+			webhooks, err := c.ListWebhooks()
+			if err != nil {
+				return err
+			}
+			for _, webhook := range webhooks {
+				types := strings.Join(webhook.Types, ",")
+				if types == "" {
+					types = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\t%s\n", webhook.Id, webhook.URL, types, webhook.Owner)
+			}
+			return nil
+		})
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:          "remove <webhook id>",
+	Short:        "remove a webhook and its delivery log",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withWebhookClient(func(c *client.Client) error {
+			if err := c.DeleteWebhook(&handle.DeleteWebhookRequest{Id: args[0]}); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed webhook %s\n", args[0])
+			return nil
+		})
+	},
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:          "deliveries <webhook id>",
+	Short:        "show the most recent delivery attempts of a webhook",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withWebhookClient(func(c *client.Client) error {
+			deliveries, err := c.ListWebhookDeliveries(&handle.ListWebhookDeliveriesRequest{Id: args[0]})
+			if err != nil {
+				return err
+			}
+			// the server sends every logged attempt, the most recent first
+			if deliveryLimit > 0 && len(deliveries) > deliveryLimit {
+				deliveries = deliveries[:deliveryLimit]
+			}
+			for _, delivery := range deliveries {
+				result := "ok"
+				if !delivery.Succeeded() {
+					result = delivery.Error
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\tevent %d\t%s\tattempt %d\t%s\n",
+					delivery.Time.Format("2006-01-02 15:04:05"), delivery.EventId, delivery.EventType, delivery.Attempt, result)
+			}
+			return nil
+		})
+	},
+}
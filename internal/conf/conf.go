@@ -1,11 +1,68 @@
 package conf
 
+import (
+	"os"
+	"path/filepath"
+)
+
 // The `dbPath` variable is a string that stores the file path of the database.
+// The `chatDBPath` variable stores the file path of the database of the chat history.
+// Both live in DataDir unless they are changed with SetDBPath, such as by the --db flag.
 var (
-	dbPath string = "internal/inmemory/goworkflow.db"
+	dbPath     string = filepath.Join(DataDir(), "goworkflow.db")
+	chatDBPath string = filepath.Join(DataDir(), "pv2.db")
 )
 
+// DataDir returns the directory flow keeps its databases in: $FLOW_HOME if it is set, otherwise ~/.flow.
+// It falls back to the working directory if the home directory is unknown.
+func DataDir() string {
+	if dir := os.Getenv("FLOW_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".flow")
+}
+
 // GetDBPath returns the path to the database used for the application.
 func GetDBPath() string {
 	return dbPath
 }
+
+// SetDBPath changes the path of the database used for the application.
+func SetDBPath(path string) {
+	dbPath = path
+}
+
+// GetSocketPath returns the path of the Unix socket the daemon that owns the database at GetDBPath listens on.
+// Each database has its own socket, next to it.
+func GetSocketPath() string {
+	return dbPath + ".sock"
+}
+
+// GetDaemonLogPath returns the path of the log file of a daemon started in the background for GetDBPath.
+func GetDaemonLogPath() string {
+	return dbPath + ".log"
+}
+
+// GetShellHistoryPath returns the path of the file the shell keeps the history of the commands entered in.
+func GetShellHistoryPath() string {
+	return filepath.Join(DataDir(), "shell_history")
+}
+
+// GetChatDBPath returns the path to the database the chat stores its history in.
+func GetChatDBPath() string {
+	return chatDBPath
+}
+
+// EnsureDirs creates the directories of the databases if they do not exist yet, since bolt only creates the files.
+func EnsureDirs() error {
+	for _, path := range []string{dbPath, chatDBPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package conf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Profile names a flow server the cli works against instead of the local daemon.
+//
+// Fields:
+// - Remote: the address of the server, such as "https://flow.team.local".
+// - Token: the API token to authenticate with, as created with "flow token create" on the server.
+type Profile struct {
+	Remote string `json:"remote"`
+	Token  string `json:"token"`
+}
+
+// GetProfilesPath returns the path of the file the profiles are kept in, a JSON object of profiles by name.
+//
+// Example:
+//
+//	{"team": {"remote": "https://flow.team.local", "token": "flow_..."}}
+func GetProfilesPath() string {
+	return filepath.Join(DataDir(), "profiles.json")
+}
+
+// LoadProfile returns the profile with the given name from the file at GetProfilesPath.
+func LoadProfile(name string) (*Profile, error) {
+	raw, err := os.ReadFile(GetProfilesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no profile %q: %s does not exist", name, GetProfilesPath())
+	}
+	if err != nil {
+		return nil, err
+	}
+	var profiles map[string]*Profile
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", GetProfilesPath(), err)
+	}
+	profile, ok := profiles[name]
+	if !ok || profile == nil {
+		return nil, fmt.Errorf("no profile %q in %s", name, GetProfilesPath())
+	}
+	return profile, nil
+}
@@ -0,0 +1,59 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/models"
+)
+
+// BoltEventStore represents the event log kept in a BoltDB database.
+type BoltEventStore struct {
+	db *storm.DB
+}
+
+// NewInMemoryEventStore returns a new instance of the BoltEventStore type with the provided storm.DB instance as its database.
+func NewInMemoryEventStore(db *storm.DB) *BoltEventStore {
+	return &BoltEventStore{
+		db: db,
+	}
+}
+
+// AppendEvent saves the event with the next id and, in the same transaction, deletes the oldest events
+// beyond the keep most recent ones. A keep of 0 or less keeps every event.
+func (s *BoltEventStore) AppendEvent(event *models.Event, keep int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	event.Id = 0
+	if err := tx.Save(event); err != nil {
+		return err
+	}
+	if keep > 0 {
+		count, err := tx.Count(new(models.Event))
+		if err != nil {
+			return err
+		}
+		if count > keep {
+			err := tx.Select().OrderBy("Id").Limit(count - keep).Delete(new(models.Event))
+			if err != nil && err != storm.ErrNotFound {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ListEvents returns up to limit events with an id greater than after, in the order they were appended.
+func (s *BoltEventStore) ListEvents(after, limit int) ([]*models.Event, error) {
+	query := s.db.Select(q.Gt("Id", after)).OrderBy("Id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	events := []*models.Event{}
+	if err := findAll(query, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
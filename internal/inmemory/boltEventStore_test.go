@@ -0,0 +1,43 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltEventStore(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening db: %v", err)
+	}
+	defer db.Close()
+	store := NewInMemoryEventStore(db)
+
+	for i := 0; i < 5; i++ {
+		event := &models.Event{Type: "task.created", Resource: "task", ResourceId: "task1"}
+		if err := store.AppendEvent(event, 3); err != nil {
+			t.Fatalf("Error appending event: %v", err)
+		}
+		if event.Id != i+1 {
+			t.Fatalf("Expected event id %d, got %d", i+1, event.Id)
+		}
+	}
+
+	events, err := store.ListEvents(0, 0)
+	if err != nil {
+		t.Fatalf("Error listing events: %v", err)
+	}
+	if len(events) != 3 || events[0].Id != 3 || events[2].Id != 5 {
+		t.Fatalf("Expected events 3 to 5, got %v", events)
+	}
+
+	events, err = store.ListEvents(3, 1)
+	if err != nil {
+		t.Fatalf("Error listing events: %v", err)
+	}
+	if len(events) != 1 || events[0].Id != 4 {
+		t.Fatalf("Expected event 4, got %v", events)
+	}
+}
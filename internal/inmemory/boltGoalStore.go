@@ -2,14 +2,17 @@ package inmemory
 
 import (
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"time"
 )
 
 // BoltGoalStore represents a goal store implementation that uses BoltDB as the underlying database.
 // CreateGoal creates a new goal in the store.
 type BoltGoalStore struct {
-	db *storm.DB
+	db storm.Node
 }
 
 // NewInMemoryGoalStore is a function that returns a new instance of BoltGoalStore
@@ -33,35 +36,89 @@ func NewInMemoryGoalStore(db *storm.DB) *BoltGoalStore {
 // - GoalUpdatedAt  : time.Time
 // - Deadline       : time.Time
 // - PlannerID      : string
+// The goal is stored with revision 1 unless it already carries a revision, and never in the trash:
+// it replaces a deleted goal with the same Id.
 // Returns an error if the save operation fails.
 func (s *BoltGoalStore) CreateGoal(goal *models.Goal) error {
+	if goal.Revision == 0 {
+		goal.Revision = 1
+	}
+	goal.DeletedAt, goal.DeletedWith = nil, ""
 	return s.db.Save(goal)
 }
 
-// UpdateGoal takes a Goal object and updates it in the database.
-// An error is returned if the update operation fails.
-func (s *BoltGoalStore) UpdateGoal(goal *models.Goal) error {
-	return s.db.Update(goal)
+// UpdateGoal takes a Goal object and replaces the stored goal with the same Id in the database.
+// Every field is written, including fields set to their zero value, and the goal's Revision is set to the stored revision plus one.
+// storm.ErrNotFound is returned if the goal does not exist or is in the trash, store.ErrRevisionMismatch if the stored goal
+// is not at expectedRevision, and an error if the update operation fails.
+func (s *BoltGoalStore) UpdateGoal(goal *models.Goal, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	current := new(models.Goal)
+	if err := tx.One("Id", goal.Id, current); err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(current.Revision, expectedRevision); err != nil {
+		return err
+	}
+	goal.Revision = current.Revision + 1
+	goal.DeletedAt, goal.DeletedWith = nil, ""
+	if err := tx.Save(goal); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeleteGoal takes an ID string and deletes the goal with that ID from the database.
-// It creates a new instance of models.Goal with the given ID, sets it as the ID of the goal to be deleted,
-// and then calls the DeleteStruct method of the s.db (storm.DB) object to delete the goal from the database.
-// An error is returned if the delete operation fails.
-func (s *BoltGoalStore) DeleteGoal(id string) error {
+// DeleteGoal takes an ID string and moves the goal with that ID to the trash, with its plans and their tasks.
+// It loads the goal, checks that it is still at expectedRevision (unless that is store.AnyRevision),
+// and then, in the same transaction, sets the DeletedAt of the goal and of the plans and tasks below it that are not
+// in the trash yet to the current time; the plans and tasks are marked as deleted with the goal.
+// storm.ErrNotFound is returned if the goal does not exist or is already in the trash,
+// store.ErrRevisionMismatch if the revision check fails, and an error if the delete operation fails.
+func (s *BoltGoalStore) DeleteGoal(id string, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 	goal := new(models.Goal)
-	goal.Id = id
-	return s.db.DeleteStruct(goal)
+	if err := tx.One("Id", id, goal); err != nil {
+		return err
+	}
+	if goal.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(goal.Revision, expectedRevision); err != nil {
+		return err
+	}
+	now := time.Now()
+	goal.DeletedAt = &now
+	if err := tx.Save(goal); err != nil {
+		return err
+	}
+	if err := trashPlans(tx, goal.Id, goal.Id, now); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetGoal takes an id string and returns the goal with that id from the database.
-// If the goal is not found, it returns nil and an error.
+// If the goal is not found or is in the trash, it returns nil and storm.ErrNotFound.
 // If an error occurs during the database query, it returns nil and the error.
 func (s *BoltGoalStore) GetGoal(id string) (*models.Goal, error) {
 	goal := new(models.Goal)
 	if err := s.db.One("Id", id, goal); err != nil {
 		return nil, err
 	}
+	if goal.DeletedAt != nil {
+		return nil, storm.ErrNotFound
+	}
 	return goal, nil
 }
 
@@ -70,7 +127,7 @@ func (s *BoltGoalStore) GetGoal(id string) (*models.Goal, error) {
 // If the goal is not found or an error occurs during the retrieval, nil goal and the error are returned.
 func (s *BoltGoalStore) GetGoalByObjective(objective string) (*models.Goal, error) {
 	goal := new(models.Goal)
-	if err := s.db.One("Objective", objective, goal); err != nil {
+	if err := first(s.db, goal, q.Eq("Objective", objective)); err != nil {
 		return nil, err
 	}
 	return goal, nil
@@ -83,16 +140,57 @@ func (s *BoltGoalStore) GetGoalByObjective(objective string) (*models.Goal, erro
 // is successful, it returns the slice of Goal objects and nil error.
 func (s *BoltGoalStore) GetGoalsByPlannerId(plannerId string) ([]*models.Goal, error) {
 	var goals []*models.Goal
-	if err := s.db.Find("PlannerId", plannerId, &goals); err != nil {
+	if err := find(s.db, &goals, q.Eq("PlannerId", plannerId)); err != nil {
 		return nil, err
 	}
 	return goals, nil
 }
 
-// ListGoals retrieves all goals from the database and returns them as a slice of Goal objects.
+// ListGoals retrieves all goals that are not in the trash from the database and returns them as a slice of Goal objects.
 func (s *BoltGoalStore) ListGoals() ([]*models.Goal, error) {
-	var goals []*models.Goal
-	if err := s.db.All(&goals); err != nil {
+	goals := []*models.Goal{}
+	if err := findAll(s.db.Select(notDeleted()), &goals); err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// goalSortFields maps the sortable json fields of a goal to their struct field names.
+var goalSortFields = map[string]string{
+	"objective":       "Objective",
+	"goal_status":     "GoalStatus",
+	"goal_created_at": "GoalCreatedAt",
+	"goal_updated_at": "GoalUpdatedAt",
+	"deadline":        "Deadline",
+}
+
+// FindGoals retrieves the goals matching the filters in opts, ordered and paged as requested.
+// The status, deadline and planner filters of opts apply to goals. Goals in the trash are left out.
+func (s *BoltGoalStore) FindGoals(opts *store.ListOptions) ([]*models.Goal, error) {
+	matchers := []q.Matcher{notDeleted()}
+	if opts != nil {
+		if opts.Status != "" {
+			matchers = append(matchers, q.Eq("GoalStatus", opts.Status))
+		}
+		if !opts.DeadlineBefore.IsZero() {
+			matchers = append(matchers, q.Lt("Deadline", opts.DeadlineBefore))
+		}
+		if opts.PlannerId != "" {
+			matchers = append(matchers, q.Eq("PlannerId", opts.PlannerId))
+		}
+		if opts.PlannerIds != nil {
+			matchers = append(matchers, q.In("PlannerId", opts.PlannerIds))
+		}
+		if opts.Ids != nil {
+			matchers = append(matchers, q.In("Id", opts.Ids))
+		}
+	}
+	query, err := selectQuery(s.db, matchers, opts, goalSortFields)
+	if err != nil {
+		return nil, err
+	}
+	goals := []*models.Goal{}
+	if err := findAll(query, &goals); err != nil {
 		return nil, err
 	}
 	return goals, nil
@@ -0,0 +1,16 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+)
+
+// CheckWritable returns an error unless the database is open and a write transaction can be started on it.
+// The transaction is rolled back at once, so the check writes nothing to the file;
+// it waits while another write transaction is in progress.
+func CheckWritable(db *storm.DB) error {
+	tx, err := db.Bolt.Begin(true)
+	if err != nil {
+		return err
+	}
+	return tx.Rollback()
+}
@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/models"
+)
+
+// BoltJournalStore represents the journal of the changes of each user, kept in a BoltDB database.
+type BoltJournalStore struct {
+	db *storm.DB
+}
+
+// NewInMemoryJournalStore returns a new instance of the BoltJournalStore type with the provided storm.DB instance as its database.
+func NewInMemoryJournalStore(db *storm.DB) *BoltJournalStore {
+	return &BoltJournalStore{
+		db: db,
+	}
+}
+
+// AppendEntry saves the entry with the next id and, in the same transaction, deletes the undone entries of its user
+// and their oldest entries beyond the keep most recent ones. A keep of 0 or less keeps every entry.
+func (s *BoltJournalStore) AppendEntry(entry *models.JournalEntry, keep int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = tx.Select(q.Eq("User", entry.User), q.Eq("Undone", true)).Delete(new(models.JournalEntry))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	entry.Id = 0
+	if err := tx.Save(entry); err != nil {
+		return err
+	}
+	if keep > 0 {
+		count, err := tx.Select(q.Eq("User", entry.User)).Count(new(models.JournalEntry))
+		if err != nil {
+			return err
+		}
+		if count > keep {
+			err := tx.Select(q.Eq("User", entry.User)).OrderBy("Id").Limit(count - keep).Delete(new(models.JournalEntry))
+			if err != nil && err != storm.ErrNotFound {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ListEntries returns the entries of the user, in the order they were appended.
+func (s *BoltJournalStore) ListEntries(user string) ([]*models.JournalEntry, error) {
+	entries := []*models.JournalEntry{}
+	if err := findAll(s.db.Select(q.Eq("User", user)).OrderBy("Id"), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UpdateEntry replaces the stored entry with the same id. It returns storm.ErrNotFound if there is none.
+func (s *BoltJournalStore) UpdateEntry(entry *models.JournalEntry) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := tx.One("Id", entry.Id, new(models.JournalEntry)); err != nil {
+		return err
+	}
+	if err := tx.Save(entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteEntry deletes the entry with the id. It returns storm.ErrNotFound if there is none.
+func (s *BoltJournalStore) DeleteEntry(id int) error {
+	return s.db.DeleteStruct(&models.JournalEntry{Id: id})
+}
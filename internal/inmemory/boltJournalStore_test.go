@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltJournalStore(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening db: %v", err)
+	}
+	defer db.Close()
+	store := NewInMemoryJournalStore(db)
+
+	for i := 0; i < 4; i++ {
+		entry := &models.JournalEntry{User: "alice", Action: models.EventCreated, Resource: "task", ResourceId: "task1"}
+		if err := store.AppendEntry(entry, 3); err != nil {
+			t.Fatalf("Error appending entry: %v", err)
+		}
+		if entry.Id != i+1 {
+			t.Fatalf("Expected entry id %d, got %d", i+1, entry.Id)
+		}
+	}
+	// the local user, without a name, has a journal of their own
+	if err := store.AppendEntry(&models.JournalEntry{Action: models.EventDeleted, Resource: "goal", ResourceId: "goal1"}, 3); err != nil {
+		t.Fatalf("Error appending entry: %v", err)
+	}
+
+	entries, err := store.ListEntries("alice")
+	if err != nil {
+		t.Fatalf("Error listing entries: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Id != 2 || entries[2].Id != 4 {
+		t.Fatalf("Expected entries 2 to 4, got %v", entries)
+	}
+	entries, err = store.ListEntries("")
+	if err != nil {
+		t.Fatalf("Error listing entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Id != 5 {
+		t.Fatalf("Expected entry 5, got %v", entries)
+	}
+
+	// a new change drops the undone entries of its user, which can no longer be redone
+	undone := &models.JournalEntry{Id: 4, User: "alice", Action: models.EventCreated, Resource: "task", ResourceId: "task1", Undone: true}
+	if err := store.UpdateEntry(undone); err != nil {
+		t.Fatalf("Error updating entry: %v", err)
+	}
+	if err := store.AppendEntry(&models.JournalEntry{User: "alice", Action: models.EventUpdated, Resource: "task", ResourceId: "task2"}, 3); err != nil {
+		t.Fatalf("Error appending entry: %v", err)
+	}
+	if err := store.DeleteEntry(2); err != nil {
+		t.Fatalf("Error deleting entry: %v", err)
+	}
+	entries, err = store.ListEntries("alice")
+	if err != nil {
+		t.Fatalf("Error listing entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Id != 3 || entries[1].Id != 6 {
+		t.Fatalf("Expected entries 3 and 6, got %v", entries)
+	}
+	if err := store.UpdateEntry(undone); err != storm.ErrNotFound {
+		t.Fatalf("Expected storm.ErrNotFound updating a dropped entry, got %v", err)
+	}
+}
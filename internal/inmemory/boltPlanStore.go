@@ -2,13 +2,16 @@ package inmemory
 
 import (
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"time"
 )
 
 // BoltPlanStore represents a store for managing plans using BoltDB.
 type BoltPlanStore struct {
-	db *storm.DB
+	db storm.Node
 }
 
 // GetPlanByName retrieves a plan by its name.
@@ -16,7 +19,7 @@ type BoltPlanStore struct {
 // It returns a pointer to a Plan object and an error. If there was an issue while retrieving the plan from the database, an error is returned. Otherwise, the plan is returned.
 func (s *BoltPlanStore) GetPlanByName(name string) (*models.Plan, error) {
 	plan := new(models.Plan)
-	if err := s.db.One("PlanName", name, plan); err != nil {
+	if err := first(s.db, plan, q.Eq("PlanName", name)); err != nil {
 		return nil, err
 	}
 	return plan, nil
@@ -27,7 +30,7 @@ func (s *BoltPlanStore) GetPlanByName(name string) (*models.Plan, error) {
 // It returns a slice of pointers to Plan objects and an error. If there was an issue while retrieving the plans from the database, an error is returned. Otherwise, the slice of plans
 func (s *BoltPlanStore) GetPlansByGoal(id string) ([]*models.Plan, error) {
 	var plans []*models.Plan
-	if err := s.db.Find("GoalId", id, &plans); err != nil {
+	if err := find(s.db, &plans, q.Eq("GoalId", id)); err != nil {
 		return nil, err
 	}
 	return plans, nil
@@ -44,25 +47,54 @@ func NewInMemoryPlanStore(db *storm.DB) *BoltPlanStore {
 // It takes a pointer to a models.Plan as an argument and returns an error.
 // The function calls the Save method of the underlying BoltDB connection,
 // passing the plan as the argument to save it as a new record in the database.
+// The plan is stored with revision 1 unless it already carries a revision, and never in the trash:
+// it replaces a deleted plan with the same Id.
 func (s *BoltPlanStore) CreatePlan(plan *models.Plan) error {
+	if plan.Revision == 0 {
+		plan.Revision = 1
+	}
+	plan.DeletedAt, plan.DeletedWith = nil, ""
 	return s.db.Save(plan)
 }
 
 // UpdatePlan updates an existing plan in the BoltPlanStore.
-// It takes a pointer to a Plan object representing the plan to be updated.
-// It returns an error if there was an issue while updating the plan in the database.
-func (s *BoltPlanStore) UpdatePlan(plan *models.Plan) error {
-	return s.db.Update(plan)
+// It takes a pointer to a Plan object representing the plan to be updated and replaces the stored plan with it,
+// including fields set to their zero value. The plan's Revision is set to the stored revision plus one.
+// It returns storm.ErrNotFound if the plan does not exist or is in the trash, store.ErrRevisionMismatch if the stored plan is not at
+// expectedRevision, or an error if there was an issue while updating the plan in the database.
+func (s *BoltPlanStore) UpdatePlan(plan *models.Plan, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	current := new(models.Plan)
+	if err := tx.One("Id", plan.Id, current); err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(current.Revision, expectedRevision); err != nil {
+		return err
+	}
+	plan.Revision = current.Revision + 1
+	plan.DeletedAt, plan.DeletedWith = nil, ""
+	if err := tx.Save(plan); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeletePlan deletes a plan from the BoltPlanStore.
+// DeletePlan moves a plan of the BoltPlanStore to the trash, with its tasks.
 // It takes in an id string as a parameter.
-// It creates a new Plan with the given id.
-// It then calls the DeleteStruct method on the BoltPlanStore's database connection, passing in the Plan.
-// The DeleteStruct method returns an error if there was an issue deleting the Plan.
+// It loads the Plan with the given id and, in one transaction, sets the DeletedAt of the plan and of its tasks
+// that are not in the trash yet to the current time; the tasks are marked as deleted with the plan.
+// storm.ErrNotFound is returned if the plan does not exist or is already in the trash.
+// If expectedRevision is not store.AnyRevision and the stored plan has another revision, store.ErrRevisionMismatch is returned.
 // If the deletion was successful, nil is returned.
 // Example usage:
-// err := store.DeletePlan("123")
+// err := store.DeletePlan("123", store.AnyRevision)
 //
 //	if err != nil {
 //	    fmt.Println("Error deleting plan:", err)
@@ -70,16 +102,38 @@ func (s *BoltPlanStore) UpdatePlan(plan *models.Plan) error {
 //
 //	    fmt.Println("Plan deleted successfully")
 //	}
-func (s *BoltPlanStore) DeletePlan(id string) error {
+func (s *BoltPlanStore) DeletePlan(id string, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 	plan := new(models.Plan)
-	plan.Id = id
-	return s.db.DeleteStruct(plan)
+	if err := tx.One("Id", id, plan); err != nil {
+		return err
+	}
+	if plan.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(plan.Revision, expectedRevision); err != nil {
+		return err
+	}
+	now := time.Now()
+	plan.DeletedAt = &now
+	if err := tx.Save(plan); err != nil {
+		return err
+	}
+	if err := trashTasks(tx, plan.Id, plan.Id, now); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetPlan retrieves a plan from the BoltPlanStore based on the specified ID.
 // It takes a string parameter "id" representing the ID of the plan to be retrieved.
 // Returns a pointer to a models.Plan object and an error.
-// If there was an issue retrieving the plan from the database, an error is returned.
+// If there was an issue retrieving the plan from the database, an error is returned;
+// storm.ErrNotFound if the plan does not exist or is in the trash.
 //
 // Example usage:
 //
@@ -99,15 +153,56 @@ func (s *BoltPlanStore) GetPlan(id string) (*models.Plan, error) {
 	if err := s.db.One("Id", id, plan); err != nil {
 		return nil, err
 	}
+	if plan.DeletedAt != nil {
+		return nil, storm.ErrNotFound
+	}
 	return plan, nil
 }
 
-// ListPlans retrieves all plans from the BoltPlanStore.
+// ListPlans retrieves all plans that are not in the trash from the BoltPlanStore.
 // It returns a slice of pointers to Plan objects representing the plans,
 // and an error if there was an issue while retrieving the plans from the database.
 func (s *BoltPlanStore) ListPlans() ([]*models.Plan, error) {
-	var plans []*models.Plan
-	if err := s.db.All(&plans); err != nil {
+	plans := []*models.Plan{}
+	if err := findAll(s.db.Select(notDeleted()), &plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// planSortFields maps the sortable json fields of a plan to their struct field names.
+var planSortFields = map[string]string{
+	"plan_name":       "PlanName",
+	"plan_status":     "PlanStatus",
+	"plan_date":       "PlanDate",
+	"plan_created_at": "PlanCreatedAt",
+	"plan_updated_at": "PlanUpdatedAt",
+}
+
+// FindPlans retrieves the plans matching the filters in opts, ordered and paged as requested.
+// The status and goal filters of opts apply to plans. Plans in the trash are left out.
+func (s *BoltPlanStore) FindPlans(opts *store.ListOptions) ([]*models.Plan, error) {
+	matchers := []q.Matcher{notDeleted()}
+	if opts != nil {
+		if opts.Status != "" {
+			matchers = append(matchers, q.Eq("PlanStatus", opts.Status))
+		}
+		if opts.GoalId != "" {
+			matchers = append(matchers, q.Eq("GoalId", opts.GoalId))
+		}
+		if opts.GoalIds != nil {
+			matchers = append(matchers, q.In("GoalId", opts.GoalIds))
+		}
+		if opts.Ids != nil {
+			matchers = append(matchers, q.In("Id", opts.Ids))
+		}
+	}
+	query, err := selectQuery(s.db, matchers, opts, planSortFields)
+	if err != nil {
+		return nil, err
+	}
+	plans := []*models.Plan{}
+	if err := findAll(query, &plans); err != nil {
 		return nil, err
 	}
 	return plans, nil
@@ -2,30 +2,33 @@ package inmemory
 
 import (
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"time"
 )
 
 // BoltPlannerStore represents a store for managing planners using a BoltDB database.
 type BoltPlannerStore struct {
-	db *storm.DB
+	db storm.Node
 }
 
 // GetPlannerByTitle retrieves a planner with the specified title from the BoltPlannerStore.
 func (s *BoltPlannerStore) GetPlannerByTitle(title string) (*models.Planner, error) {
 	planner := new(models.Planner)
-	if err := s.db.One("Title", title, planner); err != nil {
+	if err := first(s.db, planner, q.Eq("Title", title)); err != nil {
 		return nil, err
 	}
 	return planner, nil
 }
 
 // GetPlannerByOwner returns a list of planners owned by the specified user ID.
-// It queries the database to find all planners that have a "UserId" field matching the provided ID.
+// It queries the database to find all planners that have a "UserId" field matching the provided ID and are not in the trash.
 // If an error occurs during the query, it returns nil and the error.
 // Otherwise, it returns the list of found planners and nil for the error.
 func (s *BoltPlannerStore) GetPlannerByOwner(id string) ([]*models.Planner, error) {
 	var planners []*models.Planner
-	if err := s.db.Find("UserId", id, &planners); err != nil {
+	if err := find(s.db, &planners, q.Eq("UserId", id)); err != nil {
 		return nil, err
 	}
 	return planners, nil
@@ -41,22 +44,76 @@ func NewInMemoryPlannerStore(db *storm.DB) *BoltPlannerStore {
 // CreatePlanner creates a new planner in the BoltPlannerStore.
 // It takes a pointer to a models.Planner object as its argument and returns an error.
 // It saves the planner object to the underlying BoltDB database using the db.Save() method.
+// The planner is stored with revision 1 unless it already carries a revision, and never in the trash:
+// it replaces a deleted planner with the same Id.
 // If the save operation fails, it returns an error.
 func (s *BoltPlannerStore) CreatePlanner(planner *models.Planner) error {
+	if planner.Revision == 0 {
+		planner.Revision = 1
+	}
+	planner.DeletedAt = nil
 	return s.db.Save(planner)
 }
 
 // UpdatePlanner updates the details of a planner in the Bolt DB.
-// It takes a *models.Planner as input and returns an error if the update operation fails.
-func (s *BoltPlannerStore) UpdatePlanner(planner *models.Planner) error {
-	return s.db.Update(planner)
+// It takes a *models.Planner as input and replaces the stored planner with it, including fields set to their zero value.
+// The planner's Revision is set to the stored revision plus one.
+// It returns storm.ErrNotFound if the planner does not exist or is in the trash, store.ErrRevisionMismatch if the stored planner
+// is not at expectedRevision, or an error if the update operation fails.
+func (s *BoltPlannerStore) UpdatePlanner(planner *models.Planner, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	current := new(models.Planner)
+	if err := tx.One("Id", planner.Id, current); err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(current.Revision, expectedRevision); err != nil {
+		return err
+	}
+	planner.Revision = current.Revision + 1
+	planner.DeletedAt = nil
+	if err := tx.Save(planner); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeletePlanner deletes a planner from the BoltPlannerStore. It takes an ID as a parameter and returns an error.
-func (s *BoltPlannerStore) DeletePlanner(id string) error {
+// DeletePlanner moves a planner of the BoltPlannerStore to the trash. It takes an ID and the expected revision as parameters and returns an error.
+// The goals of the planner, their plans and their tasks that are not in the trash yet are moved there too,
+// in the same transaction, as deleted with the planner.
+// storm.ErrNotFound is returned if the planner does not exist or is already in the trash.
+// store.ErrRevisionMismatch is returned if expectedRevision is not store.AnyRevision and the planner has been changed since.
+func (s *BoltPlannerStore) DeletePlanner(id string, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 	planner := new(models.Planner)
-	planner.Id = id
-	return s.db.DeleteStruct(planner)
+	if err := tx.One("Id", id, planner); err != nil {
+		return err
+	}
+	if planner.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(planner.Revision, expectedRevision); err != nil {
+		return err
+	}
+	now := time.Now()
+	planner.DeletedAt = &now
+	if err := tx.Save(planner); err != nil {
+		return err
+	}
+	if err := trashGoals(tx, planner.Id, planner.Id, now); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetPlanner retrieves a planner by its ID from the BoltPlannerStore.
@@ -66,21 +123,55 @@ func (s *BoltPlannerStore) DeletePlanner(id string) error {
 //
 // Returns:
 // - *models.Planner: the retrieved planner.
-// - error: an error if the planner cannot be retrieved.
+// - error: an error if the planner cannot be retrieved, storm.ErrNotFound if it does not exist or is in the trash.
 func (s *BoltPlannerStore) GetPlanner(id string) (*models.Planner, error) {
 	planner := new(models.Planner)
 	if err := s.db.One("Id", id, planner); err != nil {
 		return nil, err
 	}
+	if planner.DeletedAt != nil {
+		return nil, storm.ErrNotFound
+	}
 	return planner, nil
 }
 
-// ListPlanners returns a list of all planners in the BoltPlannerStore database.
+// ListPlanners returns a list of all planners in the BoltPlannerStore database that are not in the trash.
 // Each planner is of type *models.Planner.
 // If there is an error during the retrieval process, an error is returned along with the empty list of planners.
 func (s *BoltPlannerStore) ListPlanners() ([]*models.Planner, error) {
-	var planners []*models.Planner
-	if err := s.db.All(&planners); err != nil {
+	planners := []*models.Planner{}
+	if err := findAll(s.db.Select(notDeleted()), &planners); err != nil {
+		return nil, err
+	}
+	return planners, nil
+}
+
+// plannerSortFields maps the sortable json fields of a planner to their struct field names.
+var plannerSortFields = map[string]string{
+	"title":   "Title",
+	"user_id": "UserId",
+}
+
+// FindPlanners retrieves the planners matching the filters in opts, ordered and paged as requested.
+// The owner filter of opts is matched against the planner's UserId and PlannerIds and Ids against its Id.
+// Planners in the trash are left out.
+func (s *BoltPlannerStore) FindPlanners(opts *store.ListOptions) ([]*models.Planner, error) {
+	matchers := []q.Matcher{notDeleted()}
+	if opts != nil && opts.Owner != "" {
+		matchers = append(matchers, q.Eq("UserId", opts.Owner))
+	}
+	if opts != nil && opts.PlannerIds != nil {
+		matchers = append(matchers, q.In("Id", opts.PlannerIds))
+	}
+	if opts != nil && opts.Ids != nil {
+		matchers = append(matchers, q.In("Id", opts.Ids))
+	}
+	query, err := selectQuery(s.db, matchers, opts, plannerSortFields)
+	if err != nil {
+		return nil, err
+	}
+	planners := []*models.Planner{}
+	if err := findAll(query, &planners); err != nil {
 		return nil, err
 	}
 	return planners, nil
@@ -0,0 +1,46 @@
+package inmemory
+
+import (
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/store"
+	"strings"
+)
+
+// selectQuery builds a storm query that matches all the given matchers and applies the
+// paging and ordering of opts to it.
+// sortFields maps the json field names accepted in opts.Sort to the struct field names storm orders by.
+// It returns store.ErrInvalidSort if opts.Sort names a field that is not in sortFields.
+func selectQuery(db storm.Node, matchers []q.Matcher, opts *store.ListOptions, sortFields map[string]string) (storm.Query, error) {
+	query := db.Select(matchers...)
+	if opts == nil {
+		return query, nil
+	}
+	if opts.Sort != "" {
+		name := strings.TrimPrefix(opts.Sort, "-")
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", store.ErrInvalidSort, name)
+		}
+		query = query.OrderBy(field)
+		if strings.HasPrefix(opts.Sort, "-") {
+			query = query.Reverse()
+		}
+	}
+	if opts.Offset > 0 {
+		query = query.Skip(opts.Offset)
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	return query, nil
+}
+
+// findAll runs the query into to, treating storm.ErrNotFound as an empty result.
+func findAll(query storm.Query, to interface{}) error {
+	if err := query.Find(to); err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
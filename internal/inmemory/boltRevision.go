@@ -0,0 +1,12 @@
+package inmemory
+
+import "github.com/ooyeku/flow/pkg/store"
+
+// checkRevision compares the revision of a stored record with the revision a caller expects it to have.
+// It returns store.ErrRevisionMismatch if they differ, unless expected is store.AnyRevision.
+func checkRevision(current, expected int) error {
+	if expected != store.AnyRevision && current != expected {
+		return store.ErrRevisionMismatch
+	}
+	return nil
+}
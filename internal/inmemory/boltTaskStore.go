@@ -2,13 +2,16 @@ package inmemory
 
 import (
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
 	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
 	"log"
+	"time"
 )
 
 // BoltTaskStore is a type that represents a task store backed by a BoltDB database.
 type BoltTaskStore struct {
-	db *storm.DB
+	db storm.Node
 }
 
 // NewInMemoryTaskStore creates a new in-memory task store with the given storm.DB instance.
@@ -68,53 +71,105 @@ func NewInMemoryTaskStore(db *storm.DB) *BoltTaskStore {
 
 // CreateTask method creates a new task in the BoltTaskStore.
 // It takes a pointer to a models.Task object as a parameter.
+// The task is stored with revision 1 unless it already carries a revision, and never in the trash:
+// it replaces a deleted task with the same ID.
 // Returns an error if the operation fails.
 func (s *BoltTaskStore) CreateTask(task *models.Task) error {
+	if task.Revision == 0 {
+		task.Revision = 1
+	}
+	task.DeletedAt, task.DeletedWith = nil, ""
 	return s.db.Save(task)
 }
 
-// UpdateTask updates a task with the specified ID. It takes the ID string and the task struct as input parameters.
-// It assigns the provided ID to the task's ID field and then updates the task in the BoltDB using the db.Update method.
-// Returns an error if there was an issue while updating the task in the BoltDB.
-func (s *BoltTaskStore) UpdateTask(id string, task *models.Task) error {
+// UpdateTask updates a task with the specified ID. It takes the ID string, the task struct and the expected revision as input parameters.
+// It assigns the provided ID to the task's ID field and then replaces the stored task with it using the Save method,
+// so that fields reset to their zero value (such as Started or Completed) are persisted too.
+// The revision check and the write happen in one transaction, and the task's Revision is set to the stored revision plus one.
+// Returns storm.ErrNotFound if no task has the ID or the task is in the trash, store.ErrRevisionMismatch if the stored task is not at expectedRevision,
+// or an error if there was an issue while updating the task in the BoltDB.
+func (s *BoltTaskStore) UpdateTask(id string, task *models.Task, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	current := new(models.Task)
+	if err := tx.One("ID", id, current); err != nil {
+		return err
+	}
+	if current.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(current.Revision, expectedRevision); err != nil {
+		return err
+	}
 	task.ID = id
-	return s.db.Update(task)
+	task.Revision = current.Revision + 1
+	task.DeletedAt, task.DeletedWith = nil, ""
+	if err := tx.Save(task); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeleteTask deletes a task from the BoltTaskStore.
+// DeleteTask moves a task of the BoltTaskStore to the trash.
 // It takes the ID of the task as a parameter and returns an error if any
 // occurred during the deletion process.
-// The function first creates a new Task object with the provided ID,
-// then calls the DeleteStruct method of the BoltDB instance to remove
-// the task from the database.
+// The function loads the task with the provided ID and saves it again with DeletedAt set to the current time,
+// so that the lookups and lists of the store leave it out until it is restored with the BoltTrashStore.
+// storm.ErrNotFound is returned if the task does not exist or is already in the trash.
+// If expectedRevision is not store.AnyRevision, the task is only deleted when it is still at that revision,
+// otherwise store.ErrRevisionMismatch is returned.
 //
 // Example usage:
-// err := myTaskStore.DeleteTask("task-123")
+// err := myTaskStore.DeleteTask("task-123", store.AnyRevision)
 //
 //	if err != nil {
 //	   fmt.Println("Error deleting task:", err)
 //	}
-func (s *BoltTaskStore) DeleteTask(id string) error {
+func (s *BoltTaskStore) DeleteTask(id string, expectedRevision int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 	task := new(models.Task)
-	task.ID = id
-	return s.db.DeleteStruct(task)
+	if err := tx.One("ID", id, task); err != nil {
+		return err
+	}
+	if task.DeletedAt != nil {
+		return storm.ErrNotFound
+	}
+	if err := checkRevision(task.Revision, expectedRevision); err != nil {
+		return err
+	}
+	now := time.Now()
+	task.DeletedAt = &now
+	if err := tx.Save(task); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetTask retrieves a task from the BoltTaskStore based on the given ID.
 // It returns the task and an error if any occurred.
-// If the task with the given ID is not found, it returns nil and an error.
+// If the task with the given ID is not found or is in the trash, it returns nil and storm.ErrNotFound.
 func (s *BoltTaskStore) GetTask(id string) (*models.Task, error) {
 	task := new(models.Task)
 	if err := s.db.One("ID", id, task); err != nil {
 		return nil, err
 	}
+	if task.DeletedAt != nil {
+		return nil, storm.ErrNotFound
+	}
 	return task, nil
 }
 
 // GetTaskByTitle retrieves a task from the BoltTaskStore based on the given title.
 func (s *BoltTaskStore) GetTaskByTitle(title string) (*models.Task, error) {
 	task := new(models.Task)
-	err := s.db.One("Title", title, task)
+	err := first(s.db, task, q.Eq("Title", title))
 	if err != nil {
 		log.Printf("Failed to get task by title: %v", err)
 		return nil, err
@@ -125,7 +180,7 @@ func (s *BoltTaskStore) GetTaskByTitle(title string) (*models.Task, error) {
 // GetTaskByOwner retrieves a task from the BoltTaskStore based on the given owner.
 func (s *BoltTaskStore) GetTaskByOwner(owner string) ([]*models.Task, error) {
 	var tasks []*models.Task
-	err := s.db.Find("Owner", owner, &tasks)
+	err := find(s.db, &tasks, q.Eq("Owner", owner))
 	if err != nil {
 		log.Printf("Failed to get task by owner: %v", err)
 		return nil, err
@@ -133,10 +188,58 @@ func (s *BoltTaskStore) GetTaskByOwner(owner string) ([]*models.Task, error) {
 	return tasks, nil
 }
 
-// ListTasks retrieves a list of tasks from the BoltTaskStore.
+// ListTasks retrieves a list of tasks from the BoltTaskStore, leaving out the tasks in the trash.
 func (s *BoltTaskStore) ListTasks() ([]*models.Task, error) {
-	var tasks []*models.Task
-	if err := s.db.All(&tasks); err != nil {
+	tasks := []*models.Task{}
+	if err := findAll(s.db.Select(notDeleted()), &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// taskSortFields maps the sortable json fields of a task to their struct field names.
+var taskSortFields = map[string]string{
+	"title":     "Title",
+	"owner":     "Owner",
+	"createdAt": "CreatedAt",
+	"updatedAt": "UpdatedAt",
+}
+
+// FindTasks retrieves the tasks matching the filters in opts, ordered and paged as requested.
+// Tasks have no status field, so a status filter is matched against the Started, Completed and Failed flags.
+// Tasks in the trash are left out.
+func (s *BoltTaskStore) FindTasks(opts *store.ListOptions) ([]*models.Task, error) {
+	matchers := []q.Matcher{notDeleted()}
+	if opts != nil {
+		if opts.Owner != "" {
+			matchers = append(matchers, q.Eq("Owner", opts.Owner))
+		}
+		if opts.PlanIds != nil {
+			matchers = append(matchers, q.In("PlanId", opts.PlanIds))
+		}
+		if opts.Ids != nil {
+			matchers = append(matchers, q.In("ID", opts.Ids))
+		}
+		switch opts.Status {
+		case "":
+		case models.NotStarted:
+			matchers = append(matchers, q.Eq("Started", false), q.Eq("Completed", false), q.Eq("Failed", false))
+		case models.InProgress:
+			matchers = append(matchers, q.Eq("Started", true), q.Eq("Completed", false), q.Eq("Failed", false))
+		case models.Completed:
+			matchers = append(matchers, q.Eq("Completed", true))
+		case models.Fail:
+			matchers = append(matchers, q.Eq("Failed", true))
+		default:
+			return []*models.Task{}, nil
+		}
+	}
+	query, err := selectQuery(s.db, matchers, opts, taskSortFields)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []*models.Task{}
+	if err := findAll(query, &tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
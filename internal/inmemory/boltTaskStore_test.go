@@ -3,8 +3,13 @@ package inmemory
 import (
 	"github.com/asdine/storm"
 	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
 	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -56,3 +61,50 @@ func TestTaskStore(t *testing.T) {
 	log.Printf("Owner: %s", taskGet.Owner)
 	log.Printf("Started: %t", taskGet.Started)
 }
+
+func TestBoltTaskStore_FindTasksByStatus(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening db: %v", err)
+	}
+	defer db.Close()
+	tasks := NewInMemoryTaskStore(db)
+	for _, task := range []*models.Task{
+		{ID: "waiting", Title: "Waiting"},
+		{ID: "running", Title: "Running", Started: true},
+		{ID: "done", Title: "Done", Started: true, Completed: true},
+		{ID: "dropped", Title: "Dropped", Failed: true},
+		{ID: "abandoned", Title: "Abandoned", Started: true, Failed: true},
+	} {
+		if err := tasks.CreateTask(task); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{models.NotStarted, []string{"waiting"}},
+		{models.InProgress, []string{"running"}},
+		{models.Completed, []string{"done"}},
+		{models.Fail, []string{"abandoned", "dropped"}},
+		{"Unknown", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			found, err := tasks.FindTasks(&store.ListOptions{Status: tt.status})
+			if err != nil {
+				t.Fatalf("Error finding tasks: %v", err)
+			}
+			ids := []string{}
+			for _, task := range found {
+				ids = append(ids, task.ID)
+			}
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/store"
+)
+
+// joinedTx is a storm node bound to a transaction that is owned by someone else.
+// The stores begin their own transaction for each update and delete; on a joinedTx, Begin joins
+// the outer transaction instead, and Commit and Rollback leave it to its owner.
+type joinedTx struct {
+	storm.Node
+}
+
+// Begin returns the node itself, so the caller works in the outer transaction.
+func (tx joinedTx) Begin(writable bool) (storm.Node, error) {
+	return tx, nil
+}
+
+// Commit does nothing; the outer transaction is committed by its owner.
+func (tx joinedTx) Commit() error {
+	return nil
+}
+
+// Rollback does nothing; the owner of the outer transaction rolls it back when one of its steps fails.
+func (tx joinedTx) Rollback() error {
+	return nil
+}
+
+// BoltTransactor runs functions with task, goal, plan and planner stores that share one BoltDB transaction.
+type BoltTransactor struct {
+	db *storm.DB
+}
+
+// NewInMemoryTransactor returns a BoltTransactor for the stores of the given storm.DB.
+//
+// Example usage:
+//
+//	err := inmemory.NewInMemoryTransactor(db).Transaction(func(stores *store.Stores) error {
+//		if err := stores.Plan.CreatePlan(plan); err != nil {
+//			return err
+//		}
+//		return stores.Task.CreateTask(task)
+//	})
+func NewInMemoryTransactor(db *storm.DB) *BoltTransactor {
+	return &BoltTransactor{
+		db: db,
+	}
+}
+
+// Transaction begins a writable transaction, runs fn with stores bound to it and commits it if fn returns nil.
+// If fn returns an error or panics, the transaction is rolled back and none of its changes are stored.
+func (t *BoltTransactor) Transaction(fn func(stores *store.Stores) error) error {
+	tx, err := t.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	node := joinedTx{tx}
+	err = fn(&store.Stores{
+		Task:    &BoltTaskStore{db: node},
+		Goal:    &BoltGoalStore{db: node},
+		Plan:    &BoltPlanStore{db: node},
+		Planner: &BoltPlannerStore{db: node},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
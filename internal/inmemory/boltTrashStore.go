@@ -0,0 +1,277 @@
+package inmemory
+
+import (
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"sort"
+	"time"
+)
+
+// deletedMatcher matches the records whose DeletedAt field is set, if deleted is true, or unset otherwise.
+type deletedMatcher struct {
+	deleted bool
+}
+
+// MatchField implements q.FieldMatcher for the DeletedAt field.
+func (m deletedMatcher) MatchField(v interface{}) (bool, error) {
+	at, _ := v.(*time.Time)
+	return (at != nil) == m.deleted, nil
+}
+
+// notDeleted returns a matcher of the records that are not in the trash, which the lists and lookups of the stores return.
+func notDeleted() q.Matcher {
+	return q.NewFieldMatcher("DeletedAt", deletedMatcher{deleted: false})
+}
+
+// deleted returns a matcher of the records in the trash.
+func deleted() q.Matcher {
+	return q.NewFieldMatcher("DeletedAt", deletedMatcher{deleted: true})
+}
+
+// first runs the query for a single record into to, treating the records in the trash as absent.
+func first(db storm.Node, to interface{}, matchers ...q.Matcher) error {
+	return db.Select(append(matchers, notDeleted())...).First(to)
+}
+
+// find runs the query into to, leaving out the records in the trash.
+// Like storm's Find, it returns storm.ErrNotFound if no record matches.
+func find(db storm.Node, to interface{}, matchers ...q.Matcher) error {
+	return db.Select(append(matchers, notDeleted())...).Find(to)
+}
+
+// trashPlans moves the plans of a goal that are not in the trash yet into it, with their tasks,
+// as deleted at the given time along with root.
+func trashPlans(tx storm.Node, goalId, root string, at time.Time) error {
+	var plans []*models.Plan
+	if err := findAll(tx.Select(q.Eq("GoalId", goalId), notDeleted()), &plans); err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		plan.DeletedAt, plan.DeletedWith = &at, root
+		if err := tx.Save(plan); err != nil {
+			return err
+		}
+		if err := trashTasks(tx, plan.Id, root, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trashTasks moves the tasks of a plan that are not in the trash yet into it, as deleted at the given time along with root.
+func trashTasks(tx storm.Node, planId, root string, at time.Time) error {
+	var tasks []*models.Task
+	if err := findAll(tx.Select(q.Eq("PlanId", planId), notDeleted()), &tasks); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		task.DeletedAt, task.DeletedWith = &at, root
+		if err := tx.Save(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trashGoals moves the goals of a planner that are not in the trash yet into it, with their plans and tasks,
+// as deleted at the given time along with root.
+func trashGoals(tx storm.Node, plannerId, root string, at time.Time) error {
+	var goals []*models.Goal
+	if err := findAll(tx.Select(q.Eq("PlannerId", plannerId), notDeleted()), &goals); err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		goal.DeletedAt, goal.DeletedWith = &at, root
+		if err := tx.Save(goal); err != nil {
+			return err
+		}
+		if err := trashPlans(tx, goal.Id, root, at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BoltTrashStore represents the trash of the task, goal, plan and planner stores kept in a BoltDB database:
+// the records their Delete* methods marked as deleted.
+type BoltTrashStore struct {
+	db *storm.DB
+}
+
+// NewInMemoryTrashStore returns a new instance of the BoltTrashStore type with the provided storm.DB instance as its database.
+func NewInMemoryTrashStore(db *storm.DB) *BoltTrashStore {
+	return &BoltTrashStore{
+		db: db,
+	}
+}
+
+// resourceRanks orders the kinds of records from the top of the hierarchy down.
+var resourceRanks = map[string]int{
+	"planner": 0,
+	"goal":    1,
+	"plan":    2,
+	"task":    3,
+}
+
+// ListTrash returns the records in the trash, most recently deleted first, and the records deleted together
+// from the top of the hierarchy down.
+func (s *BoltTrashStore) ListTrash() ([]*models.TrashItem, error) {
+	return listTrash(s.db)
+}
+
+// RestoreTrash clears the deletion of the records with the id and of the records deleted along with them,
+// in one transaction. The records keep their revisions, since they come back as they were deleted.
+// It returns storm.ErrNotFound if no record with the id is in the trash, and store.ErrParentInTrash,
+// naming the record to restore first, if the parent of the records was deleted on its own and is still in the trash.
+func (s *BoltTrashStore) RestoreTrash(id string) ([]*models.TrashItem, error) {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	items, err := trashGroup(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkParents(tx, items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		switch r := item.Record.(type) {
+		case *models.Task:
+			r.DeletedAt, r.DeletedWith = nil, ""
+		case *models.Goal:
+			r.DeletedAt, r.DeletedWith = nil, ""
+		case *models.Plan:
+			r.DeletedAt, r.DeletedWith = nil, ""
+		case *models.Planner:
+			r.DeletedAt = nil
+		}
+		if err := tx.Save(item.Record); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// PurgeTrash deletes the records with the id and the records deleted along with them for good, in one transaction.
+// It returns storm.ErrNotFound if no record with the id is in the trash.
+func (s *BoltTrashStore) PurgeTrash(id string) ([]*models.TrashItem, error) {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	items, err := trashGroup(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if err := tx.DeleteStruct(item.Record); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// trashGroup returns the records in the trash that were deleted as the records with the id were:
+// the records themselves, or the records they were deleted along with, and everything deleted along with those.
+func trashGroup(db storm.Node, id string) ([]*models.TrashItem, error) {
+	items, err := listTrash(db)
+	if err != nil {
+		return nil, err
+	}
+	roots := map[string]bool{}
+	for _, item := range items {
+		if item.Id == id {
+			roots[item.Root()] = true
+		}
+	}
+	if len(roots) == 0 {
+		return nil, storm.ErrNotFound
+	}
+	group := []*models.TrashItem{}
+	for _, item := range items {
+		if roots[item.Root()] {
+			group = append(group, item)
+		}
+	}
+	return group, nil
+}
+
+// checkParents returns store.ErrParentInTrash if the parent of one of the items, a group of trashGroup, is in the trash
+// without being part of the group.
+func checkParents(db storm.Node, group []*models.TrashItem) error {
+	items, err := listTrash(db)
+	if err != nil {
+		return err
+	}
+	inGroup := map[string]bool{}
+	for _, item := range group {
+		inGroup[item.Id] = true
+	}
+	for _, item := range group {
+		if item.ParentId == "" || inGroup[item.ParentId] {
+			continue
+		}
+		for _, parent := range items {
+			if parent.Id == item.ParentId && resourceRanks[parent.Resource] == resourceRanks[item.Resource]-1 {
+				return fmt.Errorf("%w: restore %s %s first", store.ErrParentInTrash, parent.Resource, parent.Id)
+			}
+		}
+	}
+	return nil
+}
+
+// listTrash reads the records in the trash of db, ordered as ListTrash returns them.
+func listTrash(db storm.Node) ([]*models.TrashItem, error) {
+	items := []*models.TrashItem{}
+	var planners []*models.Planner
+	if err := findAll(db.Select(deleted()), &planners); err != nil {
+		return nil, err
+	}
+	for _, planner := range planners {
+		items = append(items, &models.TrashItem{Resource: "planner", Id: planner.Id, Name: planner.Title,
+			DeletedAt: *planner.DeletedAt, Revision: planner.Revision, Record: planner})
+	}
+	var goals []*models.Goal
+	if err := findAll(db.Select(deleted()), &goals); err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		items = append(items, &models.TrashItem{Resource: "goal", Id: goal.Id, Name: goal.Objective, ParentId: goal.PlannerId,
+			DeletedAt: *goal.DeletedAt, DeletedWith: goal.DeletedWith, Revision: goal.Revision, Record: goal})
+	}
+	var plans []*models.Plan
+	if err := findAll(db.Select(deleted()), &plans); err != nil {
+		return nil, err
+	}
+	for _, plan := range plans {
+		items = append(items, &models.TrashItem{Resource: "plan", Id: plan.Id, Name: plan.PlanName, ParentId: plan.GoalId,
+			DeletedAt: *plan.DeletedAt, DeletedWith: plan.DeletedWith, Revision: plan.Revision, Record: plan})
+	}
+	var tasks []*models.Task
+	if err := findAll(db.Select(deleted()), &tasks); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		items = append(items, &models.TrashItem{Resource: "task", Id: task.ID, Name: task.Title, ParentId: task.PlanId,
+			DeletedAt: *task.DeletedAt, DeletedWith: task.DeletedWith, Revision: task.Revision, Record: task})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		return resourceRanks[items[i].Resource] < resourceRanks[items[j].Resource]
+	})
+	return items, nil
+}
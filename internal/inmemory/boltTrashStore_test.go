@@ -0,0 +1,117 @@
+package inmemory
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBoltTrashStore(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening db: %v", err)
+	}
+	defer db.Close()
+	planners := NewInMemoryPlannerStore(db)
+	goals := NewInMemoryGoalStore(db)
+	plans := NewInMemoryPlanStore(db)
+	tasks := NewInMemoryTaskStore(db)
+	trash := NewInMemoryTrashStore(db)
+
+	if err := planners.CreatePlanner(&models.Planner{Id: "planner1", Title: "Work"}); err != nil {
+		t.Fatalf("Error creating planner: %v", err)
+	}
+	if err := goals.CreateGoal(&models.Goal{Id: "goal1", Objective: "Ship", PlannerId: "planner1"}); err != nil {
+		t.Fatalf("Error creating goal: %v", err)
+	}
+	for _, id := range []string{"plan1", "plan2"} {
+		if err := plans.CreatePlan(&models.Plan{Id: id, PlanName: id, GoalId: "goal1"}); err != nil {
+			t.Fatalf("Error creating plan: %v", err)
+		}
+	}
+	for _, task := range []*models.Task{{ID: "task1", Title: "Write", PlanId: "plan1"}, {ID: "task2", Title: "Test", PlanId: "plan2"}} {
+		if err := tasks.CreateTask(task); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	// a plan deleted on its own stays in its own group when its goal is deleted later
+	if err := plans.DeletePlan("plan2", store.AnyRevision); err != nil {
+		t.Fatalf("Error deleting plan: %v", err)
+	}
+	if err := planners.DeletePlanner("planner1", store.AnyRevision); err != nil {
+		t.Fatalf("Error deleting planner: %v", err)
+	}
+	if _, err := tasks.GetTask("task1"); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected the task of the planner to be hidden, got %v", err)
+	}
+	if _, err := goals.GetGoalByObjective("Ship"); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected the goal of the planner to be hidden, got %v", err)
+	}
+	if list, err := tasks.ListTasks(); err != nil || len(list) != 0 {
+		t.Fatalf("Expected no tasks, got %v, %v", list, err)
+	}
+	if err := tasks.UpdateTask("task1", &models.Task{Title: "Rewrite"}, store.AnyRevision); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected a deleted task not to be updated, got %v", err)
+	}
+	if err := goals.DeleteGoal("goal1", store.AnyRevision); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected a deleted goal not to be deleted again, got %v", err)
+	}
+
+	items, err := trash.ListTrash()
+	if err != nil {
+		t.Fatalf("Error listing trash: %v", err)
+	}
+	if len(items) != 6 || items[0].Id != "planner1" || items[1].DeletedWith != "planner1" || items[5].Id != "task2" {
+		t.Fatalf("Expected the planner's group before the plan's, got %v", items)
+	}
+
+	// the plan deleted on its own cannot come back while its goal is in the trash
+	if _, err := trash.RestoreTrash("task2"); !errors.Is(err, store.ErrParentInTrash) || !strings.Contains(err.Error(), "goal goal1") {
+		t.Fatalf("Expected the goal of the plan to be restored first, got %v", err)
+	}
+	if items, err := trash.ListTrash(); err != nil || len(items) != 6 {
+		t.Fatalf("Expected nothing restored, got %v, %v", items, err)
+	}
+
+	// restoring a record deleted along with the planner brings the whole planner back, but not the plan deleted before
+	restored, err := trash.RestoreTrash("task1")
+	if err != nil {
+		t.Fatalf("Error restoring: %v", err)
+	}
+	if len(restored) != 4 || restored[0].Id != "planner1" || restored[0].Revision != 1 {
+		t.Fatalf("Expected the planner, its goal, plan and task restored, got %v", restored)
+	}
+	task, err := tasks.GetTask("task1")
+	if err != nil {
+		t.Fatalf("Error getting restored task: %v", err)
+	}
+	if task.DeletedAt != nil || task.DeletedWith != "" || task.Revision != 1 {
+		t.Fatalf("Expected the task restored at its revision, got %+v", task)
+	}
+	if _, err := plans.GetPlan("plan2"); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected plan2 to stay in the trash, got %v", err)
+	}
+	if _, err := trash.RestoreTrash("task1"); !errors.Is(err, storm.ErrNotFound) {
+		t.Fatalf("Expected a restored record not to be in the trash, got %v", err)
+	}
+
+	purged, err := trash.PurgeTrash("plan2")
+	if err != nil {
+		t.Fatalf("Error purging: %v", err)
+	}
+	if len(purged) != 2 {
+		t.Fatalf("Expected the plan and its task purged, got %v", purged)
+	}
+	if items, err := trash.ListTrash(); err != nil || len(items) != 0 {
+		t.Fatalf("Expected an empty trash, got %v, %v", items, err)
+	}
+	var all []*models.Task
+	if err := db.All(&all); err != nil || len(all) != 1 {
+		t.Fatalf("Expected the purged task to be gone, got %v, %v", all, err)
+	}
+}
@@ -0,0 +1,84 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/models"
+)
+
+// BoltUserStore represents a store for managing users and API tokens using a BoltDB database.
+type BoltUserStore struct {
+	db *storm.DB
+}
+
+// NewInMemoryUserStore returns a new instance of the BoltUserStore type with the provided storm.DB instance as its database.
+func NewInMemoryUserStore(db *storm.DB) *BoltUserStore {
+	return &BoltUserStore{
+		db: db,
+	}
+}
+
+// CreateUser saves a new user. It returns storm.ErrAlreadyExists if a user with the same name exists.
+func (s *BoltUserStore) CreateUser(user *models.User) error {
+	return s.db.Save(user)
+}
+
+// GetUser retrieves the user with the given ID.
+func (s *BoltUserStore) GetUser(id string) (*models.User, error) {
+	user := new(models.User)
+	if err := s.db.One("Id", id, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByName retrieves the user with the given name.
+func (s *BoltUserStore) GetUserByName(name string) (*models.User, error) {
+	user := new(models.User)
+	if err := s.db.One("Name", name, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsers returns every user.
+func (s *BoltUserStore) ListUsers() ([]*models.User, error) {
+	var users []*models.User
+	if err := s.db.All(&users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// CreateToken saves a new API token.
+func (s *BoltUserStore) CreateToken(token *models.Token) error {
+	return s.db.Save(token)
+}
+
+// GetTokenByHash retrieves the token with the given hash.
+func (s *BoltUserStore) GetTokenByHash(hash string) (*models.Token, error) {
+	token := new(models.Token)
+	if err := s.db.One("Hash", hash, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ListTokens returns the tokens of the user with the given ID.
+func (s *BoltUserStore) ListTokens(userId string) ([]*models.Token, error) {
+	var tokens []*models.Token
+	if err := findAll(s.db.Select(q.Eq("UserId", userId)), &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// DeleteToken deletes the token with the given ID, revoking it.
+// It returns storm.ErrNotFound if no token has the ID.
+func (s *BoltUserStore) DeleteToken(id string) error {
+	token := new(models.Token)
+	if err := s.db.One("Id", id, token); err != nil {
+		return err
+	}
+	return s.db.DeleteStruct(token)
+}
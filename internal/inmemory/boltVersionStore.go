@@ -22,7 +22,7 @@ func (s *BoltVersionStore) CreateVersion(v *models.Version) error {
 
 func (s *BoltVersionStore) UpdateVersion(id string, v *models.Version) error {
 	existingVersion := &models.Version{}
-	if err := s.db.One("ID", id, existingVersion); err != nil {
+	if err := s.db.One("ID", models.EntityID(id), existingVersion); err != nil {
 		return err
 	}
 	*existingVersion = *v
@@ -31,7 +31,7 @@ func (s *BoltVersionStore) UpdateVersion(id string, v *models.Version) error {
 
 func (s *BoltVersionStore) GetVersion(id string) (*models.Version, error) {
 	v := &models.Version{}
-	if err := s.db.One("ID", id, v); err != nil {
+	if err := s.db.One("ID", models.EntityID(id), v); err != nil {
 		return nil, err
 	}
 	return v, nil
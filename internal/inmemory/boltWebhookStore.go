@@ -0,0 +1,154 @@
+package inmemory
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+	"github.com/ooyeku/flow/pkg/models"
+)
+
+// BoltWebhookStore represents a store for managing webhooks and their delivery log using a BoltDB database.
+type BoltWebhookStore struct {
+	db *storm.DB
+}
+
+// NewInMemoryWebhookStore returns a new instance of the BoltWebhookStore type with the provided storm.DB instance as its database.
+func NewInMemoryWebhookStore(db *storm.DB) *BoltWebhookStore {
+	return &BoltWebhookStore{
+		db: db,
+	}
+}
+
+// CreateWebhook saves a new webhook.
+func (s *BoltWebhookStore) CreateWebhook(webhook *models.Webhook) error {
+	return s.db.Save(webhook)
+}
+
+// GetWebhook retrieves the webhook with the given ID.
+func (s *BoltWebhookStore) GetWebhook(id string) (*models.Webhook, error) {
+	webhook := new(models.Webhook)
+	if err := s.db.One("Id", id, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns every webhook.
+func (s *BoltWebhookStore) ListWebhooks() ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	if err := s.db.All(&webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes the webhook with the given ID, its deliveries and its pending deliveries in one transaction.
+func (s *BoltWebhookStore) DeleteWebhook(id string) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	webhook := new(models.Webhook)
+	if err := tx.One("Id", id, webhook); err != nil {
+		return err
+	}
+	if err := tx.DeleteStruct(webhook); err != nil {
+		return err
+	}
+	err = tx.Select(q.Eq("WebhookId", id)).Delete(new(models.Delivery))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	err = tx.Select(q.Eq("WebhookId", id)).Delete(new(models.PendingDelivery))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AppendDelivery saves the delivery with the next id and, in the same transaction, deletes the oldest deliveries
+// beyond the keep most recent ones. A keep of 0 or less keeps every delivery.
+func (s *BoltWebhookStore) AppendDelivery(delivery *models.Delivery, keep int) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	delivery.Id = 0
+	if err := tx.Save(delivery); err != nil {
+		return err
+	}
+	if keep > 0 {
+		count, err := tx.Count(new(models.Delivery))
+		if err != nil {
+			return err
+		}
+		if count > keep {
+			err := tx.Select().OrderBy("Id").Limit(count - keep).Delete(new(models.Delivery))
+			if err != nil && err != storm.ErrNotFound {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ListDeliveries returns up to limit deliveries of the webhook with the given ID, the most recent first.
+func (s *BoltWebhookStore) ListDeliveries(webhookId string, limit int) ([]*models.Delivery, error) {
+	query := s.db.Select(q.Eq("WebhookId", webhookId)).OrderBy("Id").Reverse()
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	deliveries := []*models.Delivery{}
+	if err := findAll(query, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// dispatchBucket and dispatchKey locate the id of the last event handed to the webhooks.
+const (
+	dispatchBucket = "webhookDispatch"
+	dispatchKey    = "last"
+)
+
+// SavePending stores the pending delivery, replacing the one of the same webhook and event.
+func (s *BoltWebhookStore) SavePending(pending *models.PendingDelivery) error {
+	pending.Id = models.PendingDeliveryId(pending.WebhookId, pending.EventId)
+	return s.db.Save(pending)
+}
+
+// DeletePending deletes the pending delivery of the event to the webhook, if there is one.
+func (s *BoltWebhookStore) DeletePending(webhookId string, eventId int) error {
+	err := s.db.DeleteStruct(&models.PendingDelivery{Id: models.PendingDeliveryId(webhookId, eventId)})
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// ListPending returns every pending delivery.
+func (s *BoltWebhookStore) ListPending() ([]*models.PendingDelivery, error) {
+	pending := []*models.PendingDelivery{}
+	if err := s.db.All(&pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// GetDispatched returns the id of the last event handed to the webhooks, or 0 if none was.
+func (s *BoltWebhookStore) GetDispatched() (int, error) {
+	var id int
+	if err := s.db.Get(dispatchBucket, dispatchKey, &id); err != nil {
+		if err == storm.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// SetDispatched records the id of the last event handed to the webhooks.
+func (s *BoltWebhookStore) SetDispatched(eventId int) error {
+	return s.db.Set(dispatchBucket, dispatchKey, eventId)
+}
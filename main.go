@@ -2,15 +2,12 @@ package main
 
 import (
 	"github.com/ooyeku/flow/cmd"
-	"os/exec"
+	"os"
 )
 
 func main() {
-	exec.Command("go", "build", "cmd/cli/cliapp.go").Run()
-	exec.Command("go", "build", "cmd/chat/pv1.go").Run()
-	exec.Command("go", "build", "cmd/chat/pv2.go").Run()
 	err := cmd.Execute()
 	if err != nil {
-		return
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,28 @@
+package chat
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	bolt "go.etcd.io/bbolt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// HistoryFile is the path of a chat database whose history is read by other programs, such as the search index.
+// Each read opens the database read-only and closes it again, so the chat can keep writing to it in between.
+type HistoryFile string
+
+// ListChatResponses returns the chat responses stored in the database. A database that does not exist yet has none.
+// It gives up after a second if the chat holds the database.
+func (f HistoryFile) ListChatResponses() ([]*ChatResponse, error) {
+	if _, err := os.Stat(string(f)); errors.Is(err, fs.ErrNotExist) {
+		return []*ChatResponse{}, nil
+	}
+	db, err := storm.Open(string(f), storm.BoltOptions(0600, &bolt.Options{ReadOnly: true, Timeout: time.Second}))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return NewStromRepo(db).ListChatResponses()
+}
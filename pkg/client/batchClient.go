@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// Batch applies the operations of a batch in one transaction. If an operation fails, none of them are applied
+// and the returned error names the failed operation.
+func (c *Client) Batch(req *handle.BatchRequest) (*handle.BatchResponse, error) {
+	res := &handle.BatchResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/batch", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
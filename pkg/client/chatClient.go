@@ -0,0 +1,117 @@
+package client
+
+import (
+	"github.com/google/uuid"
+	"github.com/ooyeku/flow/pkg/chat"
+	"net/http"
+	"net/url"
+)
+
+// ChatStore is a chat.PPLXChatStore that keeps the chat history in the flow daemon, through the /chat routes
+// it serves on its Unix socket, so that several chats can run at the same time.
+//
+// Example:
+//
+//	store := client.NewSocketClient(conf.GetSocketPath()).ChatStore()
+//	service := chat.NewChatService(store)
+type ChatStore struct {
+	client *Client
+}
+
+// ChatStore returns a chat store backed by the daemon c talks to.
+func (c *Client) ChatStore() *ChatStore {
+	return &ChatStore{client: c}
+}
+
+func (s *ChatStore) CreateThread(t *chat.Thread) error {
+	_, err := s.client.do(call{method: http.MethodPost, path: "/chat/threads", body: t}, nil)
+	return err
+}
+
+func (s *ChatStore) GetThread(ID uuid.UUID) (*chat.Thread, error) {
+	t := &chat.Thread{}
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/threads/" + ID.String()}, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *ChatStore) ListThreads() ([]*chat.Thread, error) {
+	var threads []*chat.Thread
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/threads"}, &threads); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+func (s *ChatStore) UpdateThread(ID uuid.UUID, t *chat.Thread) error {
+	_, err := s.client.do(call{method: http.MethodPut, path: "/chat/threads/" + ID.String(), body: t}, nil)
+	return err
+}
+
+func (s *ChatStore) DeleteThread(ID uuid.UUID) error {
+	_, err := s.client.do(call{method: http.MethodDelete, path: "/chat/threads/" + ID.String()}, nil)
+	return err
+}
+
+func (s *ChatStore) CreateTopic(t *chat.ChatTopic) error {
+	_, err := s.client.do(call{method: http.MethodPost, path: "/chat/topics", body: t}, nil)
+	return err
+}
+
+func (s *ChatStore) GetTopic(ID uuid.UUID) (*chat.ChatTopic, error) {
+	t := &chat.ChatTopic{}
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/topics/" + ID.String()}, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *ChatStore) ListTopics() ([]*chat.ChatTopic, error) {
+	var topics []*chat.ChatTopic
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/topics"}, &topics); err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+func (s *ChatStore) DeleteTopic(ID uuid.UUID) error {
+	_, err := s.client.do(call{method: http.MethodDelete, path: "/chat/topics/" + ID.String()}, nil)
+	return err
+}
+
+func (s *ChatStore) UpdateTopic(ID uuid.UUID, t *chat.ChatTopic) error {
+	_, err := s.client.do(call{method: http.MethodPut, path: "/chat/topics/" + ID.String(), body: t}, nil)
+	return err
+}
+
+func (s *ChatStore) SaveChatResponse(cr *chat.ChatResponse) error {
+	_, err := s.client.do(call{method: http.MethodPost, path: "/chat/responses", body: cr}, nil)
+	return err
+}
+
+func (s *ChatStore) GetChatResponse(ID string) (*chat.ChatResponse, error) {
+	cr := &chat.ChatResponse{}
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/responses/" + segment(ID)}, cr); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (s *ChatStore) ListChatResponses() ([]*chat.ChatResponse, error) {
+	var responses []*chat.ChatResponse
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/chat/responses"}, &responses); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+func (s *ChatStore) ClearEntries() error {
+	_, err := s.client.do(call{method: http.MethodDelete, path: "/chat/responses"}, nil)
+	return err
+}
+
+func (s *ChatStore) ClearEntriesByTopic(name string) error {
+	_, err := s.client.do(call{method: http.MethodDelete, path: "/chat/responses", query: url.Values{"topic": {name}}}, nil)
+	return err
+}
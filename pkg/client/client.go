@@ -0,0 +1,245 @@
+// Package client is a typed Go client for the HTTP API of a flow server.
+//
+// Its methods mirror the methods of the controls in pkg/handle and take the same request and response types,
+// so code written against a control can talk to a remote server instead:
+//
+//	c := client.NewClient("http://localhost:8080", os.Getenv("FLOW_TOKEN"))
+//	res, err := c.CreateTask(handle.CreateTaskRequest{Title: "Write docs", Owner: "alice"})
+//
+// The operations correspond to the operationIds of the document served at /openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client sends requests to a flow server.
+//
+// Fields:
+// - BaseURL: the address of the server, such as "http://localhost:8080".
+// - Token: the API token sent as a bearer token, as created with "flow token create".
+// - HTTPClient: the client used to send requests; http.DefaultClient is used if it is nil.
+// - User: the user the requests act as, sent in the UserHeader header. Only the daemon honors it,
+// for requests without a token over its Unix socket; flow apply --as uses it.
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+	User       string
+}
+
+// UserHeader is the header of the user a request to the daemon acts as; see Client.User.
+const UserHeader = "X-Flow-User"
+
+// NewClient creates a Client for the server at baseURL that authenticates with token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+	}
+}
+
+// socketBaseURL is the base URL of the requests sent over a Unix socket; the host is not used.
+const socketBaseURL = "http://flow"
+
+// NewSocketClient creates a Client for the flow daemon listening on the Unix socket at path.
+// The daemon trusts requests over its socket as coming from the user who runs it, so they need no token.
+func NewSocketClient(path string) *Client {
+	return &Client{
+		BaseURL:    socketBaseURL,
+		HTTPClient: &http.Client{Transport: SocketTransport(path)},
+	}
+}
+
+// SocketTransport returns an http.Transport that sends every request to the Unix socket at path, whatever its URL.
+func SocketTransport(path string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}
+}
+
+// Ping checks that the server is running and answers requests, with its /healthz probe.
+func (c *Client) Ping() error {
+	_, err := c.do(call{method: http.MethodGet, path: "/healthz"}, nil)
+	return err
+}
+
+// StopDaemon asks the daemon to shut down, as SIGTERM does. Only a client on its Unix socket may stop it.
+func (c *Client) StopDaemon() error {
+	_, err := c.do(call{method: http.MethodPost, path: "/daemon/stop"}, nil)
+	return err
+}
+
+// Error is returned when the server responds with a status code other than 2xx.
+// It matches store.ErrRevisionMismatch for 412 Precondition Failed, store.ErrParentInTrash for 409 Conflict,
+// services.ErrForbidden for 403 Forbidden and storm.ErrNotFound for 404 Not Found, so callers can check it with errors.Is as they would check errors
+// of a local service.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is reports whether the error corresponds to target.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case store.ErrRevisionMismatch:
+		return e.StatusCode == http.StatusPreconditionFailed
+	case store.ErrParentInTrash:
+		return e.StatusCode == http.StatusConflict
+	case services.ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case storm.ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// mergePatchContentType is the media type of the body of the Patch* requests.
+const mergePatchContentType = "application/merge-patch+json"
+
+// call describes one request to the server.
+type call struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	contentType string
+	revision    int
+}
+
+// newRequest builds the HTTP request of a call, with its body, conditional, authorization and user headers.
+func (c *Client) newRequest(ctx context.Context, req call) (*http.Request, error) {
+	u := c.BaseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if req.body != nil {
+		if raw, ok := req.body.(json.RawMessage); ok {
+			body = bytes.NewReader(raw)
+		} else {
+			data, err := json.Marshal(req.body)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(data)
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if req.body != nil {
+		contentType := req.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if req.revision != store.AnyRevision {
+		httpReq.Header.Set("If-Match", strconv.Quote(strconv.Itoa(req.revision)))
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.User != "" {
+		httpReq.Header.Set(UserHeader, c.User)
+	}
+	return httpReq, nil
+}
+
+// send sends an HTTP request and returns the response if its status code is 2xx, or an *Error otherwise.
+// The caller must close the body of the response.
+func (c *Client) send(httpReq *http.Request) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return res, &Error{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	return res, nil
+}
+
+// do sends the request and decodes the JSON response into out, if out is not nil.
+// It returns the response headers so callers can read X-Next-Cursor and ETag.
+func (c *Client) do(req call, out interface{}) (http.Header, error) {
+	httpReq, err := c.newRequest(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.send(httpReq)
+	if err != nil {
+		if res != nil {
+			return res.Header, err
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return res.Header, fmt.Errorf("decoding %s %s response: %w", req.method, req.path, err)
+		}
+	}
+	return res.Header, nil
+}
+
+// segment escapes a value for use as a path segment.
+func segment(value string) string {
+	return url.PathEscape(value)
+}
+
+// listQuery converts a ListRequest into the query parameters of a list endpoint.
+func listQuery(req *handle.ListRequest) url.Values {
+	query := url.Values{}
+	if req == nil {
+		return query
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	for name, value := range map[string]string{
+		"cursor":          req.Cursor,
+		"sort":            req.Sort,
+		"owner":           req.Owner,
+		"status":          req.Status,
+		"deadline_before": req.DeadlineBefore,
+		"planner_id":      req.PlannerId,
+		"goal_id":         req.GoalId,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
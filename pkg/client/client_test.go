@@ -0,0 +1,632 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/gorilla/mux"
+	"github.com/ooyeku/flow/api"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/chat"
+	"github.com/ooyeku/flow/pkg/graphql"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func SetupClientT(t *testing.T) *Client {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	planStore := inmemory.NewInMemoryPlanStore(db)
+	plannerStore := inmemory.NewInMemoryPlannerStore(db)
+	access := services.NewAccessService(plannerStore, goalStore, planStore, inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	taskService := services.NewTaskService(inmemory.NewInMemoryTaskStore(db)).WithEvents(events)
+	goalService := services.NewGoalService(goalStore).WithEvents(events)
+	planService := services.NewPlanService(planStore).WithEvents(events)
+	plannerService := services.NewPlannerService(plannerStore).WithEvents(events)
+	trashService := services.NewTrashService(inmemory.NewInMemoryTrashStore(db)).WithEvents(events)
+	journal := services.NewJournalService(inmemory.NewInMemoryJournalStore(db), services.DefaultJournalSize,
+		taskService, goalService, planService, plannerService).WithTrash(trashService)
+	handlers := &api.Handlers{
+		Task:    &api.TaskHandler{Control: handle.NewTaskControl(taskService.WithJournal(journal))},
+		Goal:    &api.GoalHandler{Control: handle.NewGoalControl(goalService.WithJournal(journal))},
+		Plan:    &api.PlanHandler{Control: handle.NewPlanControl(planService.WithJournal(journal))},
+		Planner: &api.PlannerHandler{Control: handle.NewPlannerControl(plannerService.WithJournal(journal))},
+		Event:   &api.EventHandler{Control: handle.NewEventControl(events)},
+		Undo:    &api.UndoHandler{Control: handle.NewUndoControl(journal)},
+		Trash:   &api.TrashHandler{Control: handle.NewTrashControl(trashService.WithJournal(journal))},
+	}
+	handlers.Batch = &api.BatchHandler{Control: handle.NewBatchControl(inmemory.NewInMemoryTransactor(db), events).WithJournal(journal)}
+	searchService := services.NewSearchService(inmemory.NewInMemoryTaskStore(db), goalStore, planStore, nil)
+	searchService.Watch(events)
+	handlers.Search = &api.SearchHandler{Control: handle.NewSearchControl(searchService)}
+	handlers.GraphQL = &api.GraphQLHandler{Task: handlers.Task, Goal: handlers.Goal, Plan: handlers.Plan, Planner: handlers.Planner}
+	routes := api.Routes(handlers)
+	r := mux.NewRouter()
+	api.Register(r, routes)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "")
+}
+
+// TestClient_Operations checks that the client and the OpenAPI document cover every route of the server.
+func TestClient_Operations(t *testing.T) {
+	routes := api.Routes(&api.Handlers{})
+	doc := api.OpenAPI(routes)
+	paths := doc["paths"].(map[string]interface{})
+	clientType := reflect.TypeOf(&Client{})
+	for _, route := range routes {
+		_, ok := clientType.MethodByName(route.OperationID)
+		assert.True(t, ok, "client has no method for operation %s", route.OperationID)
+		assert.Contains(t, paths, route.Path)
+	}
+
+	// every schema reference must resolve
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				assert.Contains(t, schemas, filepath.Base(ref))
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	walk(decoded)
+}
+
+func TestClient_Tasks(t *testing.T) {
+	c := SetupClientT(t)
+	for _, title := range []string{"First", "Second", "Third"} {
+		if _, err := c.CreateTask(handle.CreateTaskRequest{Title: title, Owner: "alice"}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	page, err := c.ListTasks(&handle.ListRequest{Limit: 2, Sort: "title"})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	assert.Len(t, page.Tasks, 2)
+	assert.NotEmpty(t, page.NextCursor)
+	page, err = c.ListTasks(&handle.ListRequest{Limit: 2, Sort: "title", Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Third", page.Tasks[0].Title)
+	assert.Empty(t, page.NextCursor)
+
+	task, err := c.GetTaskByTitle(&handle.GetTaskByTitleRequest{Title: "Third"})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	patched, err := c.PatchTask(&handle.PatchTaskRequest{ID: task.ID, Patch: json.RawMessage(`{"started": true}`), Revision: task.Revision})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.True(t, patched.Started)
+	assert.Equal(t, task.Revision+1, patched.Revision)
+
+	_, err = c.PatchTask(&handle.PatchTaskRequest{ID: task.ID, Patch: json.RawMessage(`{"title": "Stale"}`), Revision: task.Revision})
+	assert.ErrorIs(t, err, store.ErrRevisionMismatch)
+	err = c.DeleteTask(&handle.DeleteTaskRequest{ID: task.ID, Revision: task.Revision})
+	assert.ErrorIs(t, err, store.ErrRevisionMismatch)
+
+	assert.NoError(t, c.DeleteTask(&handle.DeleteTaskRequest{ID: task.ID, Revision: patched.Revision}))
+	tasks, err := c.GetTaskByOwner(&handle.GetTaskByOwnerRequest{Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to get tasks: %v", err)
+	}
+	assert.Len(t, tasks, 2)
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	c := SetupClientT(t)
+	task, err := c.CreateTask(handle.CreateTaskRequest{Title: "First", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := c.PatchTask(&handle.PatchTaskRequest{ID: task.ID, Patch: json.RawMessage(`{"started": true}`)}); err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	if _, err := c.CreateGoal(&handle.CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-02"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if err := c.DeleteTask(&handle.DeleteTaskRequest{ID: task.ID}); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// resume after the first event, skipping the goal
+	done := errors.New("done")
+	req := &handle.StreamEventsRequest{After: 1, Types: []string{"task"}}
+	var types []string
+	err = c.StreamEvents(context.Background(), req, func(event *models.Event) error {
+		assert.Equal(t, task.ID, event.ResourceId)
+		types = append(types, event.Type)
+		if len(types) == 2 {
+			return done
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, done)
+	assert.Equal(t, []string{"task.updated", "task.deleted"}, types)
+	assert.Equal(t, 4, req.After)
+}
+
+func TestClient_Batch(t *testing.T) {
+	c := SetupClientT(t)
+	res, err := c.Batch(&handle.BatchRequest{Operations: []*handle.BatchOperation{
+		{Op: handle.BatchCreate, Resource: "goal", Ref: "ship", Data: json.RawMessage(`{"objective": "Ship", "deadline": "2030-01-01"}`)},
+		{Op: handle.BatchCreate, Resource: "plan", Ref: "release", Data: json.RawMessage(`{"plan_name": "Release", "plan_date": "2029-12-01", "plan_time": "09:00", "GoalId": "${ship}"}`)},
+		{Op: handle.BatchCreate, Resource: "task", Data: json.RawMessage(`{"title": "Tag", "owner": "alice", "plan_id": "${release}"}`)},
+	}})
+	if err != nil {
+		t.Fatalf("failed to apply batch: %v", err)
+	}
+	task, err := c.GetTask(&handle.GetTaskRequest{ID: res.Results[2].Id})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, res.Results[1].Id, task.PlanId)
+
+	// nothing of a failed batch is stored
+	_, err = c.Batch(&handle.BatchRequest{Operations: []*handle.BatchOperation{
+		{Op: handle.BatchCreate, Resource: "task", Data: json.RawMessage(`{"title": "Lost"}`)},
+		{Op: handle.BatchUpdate, Resource: "task", Id: task.ID, Data: json.RawMessage(`{"started": true}`), Revision: 7},
+	}})
+	assert.True(t, errors.Is(err, store.ErrRevisionMismatch))
+	_, err = c.Batch(&handle.BatchRequest{Operations: []*handle.BatchOperation{
+		{Op: handle.BatchCreate, Resource: "task", Data: json.RawMessage(`{"title": "Lost"}`)},
+		{Op: "upsert", Resource: "task"},
+	}})
+	var clientErr *Error
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 400, clientErr.StatusCode)
+	}
+	_, err = c.GetTaskByTitle(&handle.GetTaskByTitleRequest{Title: "Lost"})
+	assert.Error(t, err)
+}
+
+func TestClient_Undo(t *testing.T) {
+	c := SetupClientT(t)
+	res, err := c.Batch(&handle.BatchRequest{Operations: []*handle.BatchOperation{
+		{Op: handle.BatchCreate, Resource: "task", Ref: "tag", Data: json.RawMessage(`{"title": "Tag", "owner": "alice"}`)},
+		{Op: handle.BatchUpdate, Resource: "task", Id: "${tag}", Data: json.RawMessage(`{"title": "Tag v2"}`)},
+	}})
+	if err != nil {
+		t.Fatalf("failed to apply batch: %v", err)
+	}
+	id := res.Results[0].Id
+
+	undone, err := c.Undo(&handle.UndoRequest{})
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, undone.Changes, 1) {
+		assert.Equal(t, "updated", undone.Changes[0].Action)
+		assert.Equal(t, "Tag v2", undone.Changes[0].Name)
+	}
+	task, err := c.GetTask(&handle.GetTaskRequest{ID: id})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "Tag", task.Title)
+
+	redone, err := c.Redo(&handle.UndoRequest{Steps: 2})
+	if err != nil {
+		t.Fatalf("failed to redo: %v", err)
+	}
+	assert.Len(t, redone.Changes, 1)
+	task, err = c.GetTask(&handle.GetTaskRequest{ID: id})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "Tag v2", task.Title)
+
+	_, err = c.Undo(&handle.UndoRequest{Steps: -1})
+	var clientErr *Error
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 400, clientErr.StatusCode)
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	c := SetupClientT(t)
+	task, err := c.CreateTask(handle.CreateTaskRequest{Title: "Write the release notes", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := c.CreateGoal(&handle.CreateGoalRequest{Objective: "Release v2", Deadline: "2030-01-01"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	res, err := c.Search(&handle.SearchRequest{Query: "relase note", Types: []string{"task"}})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if assert.Len(t, res.Results, 1) {
+		assert.Equal(t, task.ID, res.Results[0].ID)
+		assert.Equal(t, "Write the release notes", res.Results[0].Title)
+	}
+	res, err = c.Search(&handle.SearchRequest{Query: "release", Limit: 1})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.Len(t, res.Results, 1)
+
+	_, err = c.Search(&handle.SearchRequest{Query: " "})
+	var clientErr *Error
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 400, clientErr.StatusCode)
+	}
+}
+
+func TestClient_GraphQL(t *testing.T) {
+	c := SetupClientT(t)
+	run := func(query string, vars map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		res, err := c.GraphQL(&graphql.Request{Query: query, Variables: vars})
+		if err != nil {
+			t.Fatalf("failed to run query: %v", err)
+		}
+		if err := res.Err(); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(res.Data, &data); err != nil {
+			t.Fatalf("failed to decode data: %v", err)
+		}
+		return data
+	}
+	id := func(data map[string]interface{}, field string) string {
+		return data[field].(map[string]interface{})["id"].(string)
+	}
+
+	planner := id(run(`mutation { createPlanner(title: "Launch", userId: "alice") { id } }`, nil), "createPlanner")
+	goal := id(run(`mutation($planner: ID) { createGoal(objective: "Ship", deadline: "2030-01-02", plannerId: $planner) { id } }`,
+		map[string]interface{}{"planner": planner}), "createGoal")
+	plan := id(run(`mutation($goal: ID) { createPlan(name: "Release", date: "2030-01-01", time: "09:30", goalId: $goal) { id } }`,
+		map[string]interface{}{"goal": goal}), "createPlan")
+	for _, title := range []string{"Build", "Test"} {
+		run(`mutation($title: String!, $plan: ID) { createTask(title: $title, owner: "alice", planId: $plan) { id } }`,
+			map[string]interface{}{"title": title, "plan": plan})
+	}
+	task := id(run(`mutation($plan: ID) { createTask(title: "Tag", planId: $plan) { id } }`, map[string]interface{}{"plan": plan}), "createTask")
+	updated := run(`mutation($id: ID!) { updateTask(id: $id, completed: true) { title completed revision } }`, map[string]interface{}{"id": task})
+	assert.Equal(t, map[string]interface{}{"title": "Tag", "completed": true, "revision": float64(2)}, updated["updateTask"])
+
+	res, err := c.GraphQL(&graphql.Request{Query: `query($id: ID!) {
+		planner(id: $id) { title goals { objective plans { name time goal { id } tasks { title completed } } } }
+	}`, Variables: map[string]interface{}{"id": planner}})
+	if err != nil {
+		t.Fatalf("failed to run query: %v", err)
+	}
+	assert.NoError(t, res.Err())
+	assert.JSONEq(t, `{"planner": {"title": "Launch", "goals": [{"objective": "Ship", "plans": [{"name": "Release", "time": "09:30",
+		"goal": {"id": "`+goal+`"}, "tasks": [{"title": "Build", "completed": false}, {"title": "Test", "completed": false},
+		{"title": "Tag", "completed": true}]}]}]}}`, string(res.Data))
+
+	// a missing record is null, with an error at its path
+	res, err = c.GraphQL(&graphql.Request{Query: `{ goal(id: "missing") { objective } }`})
+	if err != nil {
+		t.Fatalf("failed to run query: %v", err)
+	}
+	assert.JSONEq(t, `{"goal": null}`, string(res.Data))
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, []interface{}{"goal"}, res.Errors[0].Path)
+	}
+}
+
+func TestClient_ChatStore(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "chat.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	r := mux.NewRouter()
+	api.RegisterChatRoutes(r, &api.ChatHandler{Service: chat.NewChatService(chat.NewStromRepo(db))})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	s := NewClient(server.URL, "").ChatStore()
+
+	topic := chat.NewChatTopic("General", "Anything")
+	if err := s.CreateTopic(topic); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	topic.Description = "Anything at all"
+	if err := s.UpdateTopic(topic.ID, topic); err != nil {
+		t.Fatalf("failed to update topic: %v", err)
+	}
+	got, err := s.GetTopic(topic.ID)
+	if err != nil {
+		t.Fatalf("failed to get topic: %v", err)
+	}
+	assert.Equal(t, "Anything at all", got.Description)
+
+	if err := s.SaveChatResponse(&chat.ChatResponse{ID: "r1", Model: "sonar", Topic: topic}); err != nil {
+		t.Fatalf("failed to save response: %v", err)
+	}
+	responses, err := s.ListChatResponses()
+	if err != nil {
+		t.Fatalf("failed to list responses: %v", err)
+	}
+	if assert.Len(t, responses, 1) {
+		assert.Equal(t, "General", responses[0].Topic.Name)
+	}
+
+	if err := s.DeleteTopic(topic.ID); err != nil {
+		t.Fatalf("failed to delete topic: %v", err)
+	}
+	_, err = s.GetTopic(topic.ID)
+	assert.True(t, errors.Is(err, storm.ErrNotFound))
+}
+
+// TestClient_SearchChat checks that the chat responses saved through the daemon are found without rebuilding the index.
+func TestClient_SearchChat(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	chatService := chat.NewChatService(chat.NewStromRepo(db))
+	searchService := services.NewSearchService(inmemory.NewInMemoryTaskStore(db), inmemory.NewInMemoryGoalStore(db),
+		inmemory.NewInMemoryPlanStore(db), chatService)
+	if err := searchService.Rebuild(); err != nil {
+		t.Fatalf("failed to build index: %v", err)
+	}
+	r := mux.NewRouter()
+	api.Register(r, api.Routes(&api.Handlers{Search: &api.SearchHandler{Control: handle.NewSearchControl(searchService)}}))
+	api.RegisterChatRoutes(r, &api.ChatHandler{Service: chatService, Search: searchService})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	c := NewClient(server.URL, "")
+
+	topic := chat.NewChatTopic("General", "Anything")
+	if err := c.ChatStore().CreateTopic(topic); err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	if err := c.ChatStore().CreateThread(chat.NewThread("Release")); err != nil {
+		t.Fatalf("failed to create thread: %v", err)
+	}
+	response := &chat.ChatResponse{ID: "r1", Model: "sonar", Topic: topic,
+		UserQuery: chat.Message{Query: "How do I tag a release?"}, Object: "Push a signed tag."}
+	if err := c.ChatStore().SaveChatResponse(response); err != nil {
+		t.Fatalf("failed to save response: %v", err)
+	}
+	res, err := c.Search(&handle.SearchRequest{Query: "signed tag", Types: []string{"chat"}})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if assert.Len(t, res.Results, 1) {
+		assert.Equal(t, "r1", res.Results[0].ID)
+		assert.Equal(t, "How do I tag a release?", res.Results[0].Title)
+	}
+
+	if err := c.ChatStore().ClearEntriesByTopic("General"); err != nil {
+		t.Fatalf("failed to clear the history: %v", err)
+	}
+	res, err = c.Search(&handle.SearchRequest{Query: "signed tag", Types: []string{"chat"}})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.Empty(t, res.Results)
+}
+
+func TestClient_Users(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	r := mux.NewRouter()
+	api.RegisterUserRoutes(r, &api.UserHandler{Control: handle.NewUserControl(services.NewUserService(inmemory.NewInMemoryUserStore(db)))})
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	c := NewClient(server.URL, "")
+
+	added, err := c.AddUser(&handle.AddUserRequest{Name: "alice"})
+	if err != nil {
+		t.Fatalf("failed to add user: %v", err)
+	}
+	_, err = c.AddUser(&handle.AddUserRequest{Name: "alice"})
+	var clientErr *Error
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 409, clientErr.StatusCode)
+	}
+	_, err = c.AddUser(&handle.AddUserRequest{Name: " "})
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 400, clientErr.StatusCode)
+	}
+	user, err := c.GetUser(&handle.GetUserRequest{Name: "alice"})
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	assert.Equal(t, added.Id, user.Id)
+	_, err = c.GetUser(&handle.GetUserRequest{Name: "bob"})
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+	users, err := c.ListUsers()
+	if err != nil {
+		t.Fatalf("failed to list users: %v", err)
+	}
+	assert.Len(t, users, 1)
+
+	token, err := c.CreateToken(&handle.CreateTokenRequest{UserName: "alice", Name: "laptop"})
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	assert.NotEmpty(t, token.Token)
+	tokens, err := c.ListTokens(&handle.ListTokensRequest{UserName: "alice"})
+	if err != nil {
+		t.Fatalf("failed to list tokens: %v", err)
+	}
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, "laptop", tokens[0].Name)
+		assert.Empty(t, tokens[0].Hash)
+	}
+	assert.NoError(t, c.RevokeToken(&handle.RevokeTokenRequest{Id: token.Id}))
+	assert.ErrorIs(t, c.RevokeToken(&handle.RevokeTokenRequest{Id: token.Id}), storm.ErrNotFound)
+	tokens, err = c.ListTokens(&handle.ListTokensRequest{UserName: "alice"})
+	if err != nil {
+		t.Fatalf("failed to list tokens: %v", err)
+	}
+	assert.Empty(t, tokens)
+}
+
+// TestClient_Stores runs the controls on the stores of the client, as the cli does against a remote server.
+func TestClient_Stores(t *testing.T) {
+	c := SetupClientT(t)
+	tasks := handle.NewTaskControl(services.NewTaskService(c.TaskStore()))
+	goals := handle.NewGoalControl(services.NewGoalService(c.GoalStore()))
+	planners := handle.NewPlannerControl(services.NewPlannerService(c.PlannerStore()))
+
+	created, err := tasks.CreateTask(handle.CreateTaskRequest{Title: "Build", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	// the id is the one the server gave the task
+	task, err := c.GetTask(&handle.GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, 1, task.Revision)
+
+	patched, err := tasks.PatchTask(&handle.PatchTaskRequest{ID: created.ID, Patch: []byte(`{"completed": true}`), Revision: 1})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.True(t, patched.Completed)
+	assert.Equal(t, 2, patched.Revision)
+	_, err = tasks.PatchTask(&handle.PatchTaskRequest{ID: created.ID, Patch: []byte(`{"title": "Ship"}`), Revision: 1})
+	assert.True(t, errors.Is(err, store.ErrRevisionMismatch))
+
+	planner, err := planners.CreatePlanner(&handle.CreatePlannerRequest{Title: "Launch", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	for _, objective := range []string{"Ship", "Celebrate", "Rest"} {
+		_, err := goals.CreateGoal(&handle.CreateGoalRequest{Objective: objective, Deadline: "2024-06-01", PlannerId: planner.Id})
+		if err != nil {
+			t.Fatalf("failed to create goal: %v", err)
+		}
+	}
+	page, err := goals.FindGoals(&handle.ListRequest{Limit: 2, Sort: "objective", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to list goals: %v", err)
+	}
+	if assert.Len(t, page.Goals, 2) {
+		assert.Equal(t, "Celebrate", page.Goals[0].Objective)
+	}
+	page, err = goals.FindGoals(&handle.ListRequest{Limit: 2, Sort: "objective", PlannerId: planner.Id, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("failed to list goals: %v", err)
+	}
+	if assert.Len(t, page.Goals, 1) {
+		assert.Equal(t, "Ship", page.Goals[0].Objective)
+	}
+
+	// removing a member is sent as a null field of the merge patch
+	if _, err := planners.SharePlanner(&handle.SharePlannerRequest{Id: planner.Id, User: "bob", Role: models.RoleViewer}); err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	shared, err := planners.SharePlanner(&handle.SharePlannerRequest{Id: planner.Id, User: "bob"})
+	if err != nil {
+		t.Fatalf("failed to unshare planner: %v", err)
+	}
+	stored, err := c.GetPlanner(&handle.GetPlannerRequest{Id: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to get planner: %v", err)
+	}
+	assert.NotContains(t, stored.Members, "bob")
+	assert.Equal(t, shared.Revision, stored.Revision)
+
+	if err := tasks.DeleteTask(&handle.DeleteTaskRequest{ID: created.ID}); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	_, err = tasks.GetTask(&handle.GetTaskRequest{ID: created.ID})
+	assert.True(t, errors.Is(err, storm.ErrNotFound))
+}
+
+func TestClient_Trash(t *testing.T) {
+	c := SetupClientT(t)
+	plan, err := c.CreatePlan(&handle.CreatePlanRequest{PlanName: "Release", PlanDate: "2030-01-01", PlanTime: "09:00"})
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := c.CreateTask(handle.CreateTaskRequest{Title: "Tag", Owner: "alice", PlanId: plan.ID})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := c.DeletePlan(&handle.DeletePlanRequest{Id: plan.ID}); err != nil {
+		t.Fatalf("failed to delete plan: %v", err)
+	}
+	_, err = c.GetTask(&handle.GetTaskRequest{ID: task.ID})
+	var clientErr *Error
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 404, clientErr.StatusCode)
+	}
+
+	trash, err := c.ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	if assert.Len(t, trash.Items, 2) {
+		assert.Equal(t, "plan", trash.Items[0].Resource)
+		assert.Equal(t, plan.ID, trash.Items[1].DeletedWith)
+	}
+
+	// restoring the task brings back the plan it was deleted with
+	restored, err := c.RestoreTrash(&handle.RestoreTrashRequest{Id: task.ID})
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	assert.Len(t, restored.Items, 2)
+	got, err := c.GetTask(&handle.GetTaskRequest{ID: task.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, plan.ID, got.PlanId)
+
+	_, err = c.EmptyTrash(&handle.EmptyTrashRequest{OlderThan: "a while"})
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 400, clientErr.StatusCode)
+	}
+	if err := c.DeleteTask(&handle.DeleteTaskRequest{ID: task.ID}); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	purged, err := c.EmptyTrash(&handle.EmptyTrashRequest{})
+	if err != nil {
+		t.Fatalf("failed to empty trash: %v", err)
+	}
+	assert.Len(t, purged.Items, 1)
+	_, err = c.RestoreTrash(&handle.RestoreTrashRequest{Id: task.ID})
+	if assert.True(t, errors.As(err, &clientErr)) {
+		assert.Equal(t, 404, clientErr.StatusCode)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxEventSize limits the size of one line of an event stream.
+const maxEventSize = 4 << 20
+
+// StreamEvents connects to the event stream of the server and calls fn with every event it receives,
+// until ctx is done, the server ends the stream or fn returns an error, which is then returned.
+// req.After is advanced to the id of each event received, so calling StreamEvents again with the same request
+// resumes the stream without missing or repeating events, as long as they are still in the server's event log.
+func (c *Client) StreamEvents(ctx context.Context, req *handle.StreamEventsRequest, fn func(*models.Event) error) error {
+	query := url.Values{}
+	if req.PlannerId != "" {
+		query.Set("planner_id", req.PlannerId)
+	}
+	if len(req.Types) > 0 {
+		query.Set("type", strings.Join(req.Types, ","))
+	}
+	httpReq, err := c.newRequest(ctx, call{method: http.MethodGet, path: "/api/v1/events", query: query})
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if req.After > 0 {
+		httpReq.Header.Set("Last-Event-ID", strconv.Itoa(req.After))
+	}
+	res, err := c.send(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			// only the data field is needed, the id and type are part of the event itself
+			if value, ok := strings.CutPrefix(line, "data:"); ok {
+				data = append(data, strings.TrimPrefix(value, " "))
+			}
+			continue
+		}
+		if len(data) == 0 {
+			continue
+		}
+		event := &models.Event{}
+		if err := json.Unmarshal([]byte(strings.Join(data, "\n")), event); err != nil {
+			return fmt.Errorf("decoding event: %w", err)
+		}
+		data = data[:0]
+		req.After = event.Id
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// ListGoals retrieves a page of goals.
+func (c *Client) ListGoals(req *handle.ListRequest) (*handle.ListGoalsResponse, error) {
+	res := &handle.ListGoalsResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/listgoals", query: listQuery(req)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateGoal creates a new goal and returns its id.
+func (c *Client) CreateGoal(req *handle.CreateGoalRequest) (*handle.CreateGoalResponse, error) {
+	res := &handle.CreateGoalResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/goal/new", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetGoal retrieves a goal by its ID.
+func (c *Client) GetGoal(req *handle.GetGoalRequest) (*handle.GetGoalResponse, error) {
+	res := &handle.GetGoalResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/goal/" + segment(req.Id)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetGoalByObjective retrieves a goal by its objective.
+func (c *Client) GetGoalByObjective(req *handle.GetGoalByObjectiveRequest) (*handle.GetGoalByObjectiveResponse, error) {
+	res := &handle.GetGoalByObjectiveResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/goal/obj/" + segment(req.Objective)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetGoalsByPlannerId retrieves the goals of a planner.
+func (c *Client) GetGoalsByPlannerId(req *handle.GetGoalsByPlannerIdRequest) (*handle.GetGoalsByPlannerIdResponse, error) {
+	res := &handle.GetGoalsByPlannerIdResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/goal/pid/" + segment(req.PlannerId)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdateGoal replaces a goal. If req.Revision is set, it is sent as If-Match.
+func (c *Client) UpdateGoal(req *handle.UpdateGoalRequest) error {
+	_, err := c.do(call{method: http.MethodPut, path: "/goal/" + segment(req.Id), body: req, revision: req.Revision}, nil)
+	return err
+}
+
+// DeleteGoal deletes a goal. If req.Revision is set, it is sent as If-Match.
+func (c *Client) DeleteGoal(req *handle.DeleteGoalRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/goal/" + segment(req.Id), revision: req.Revision}, nil)
+	return err
+}
+
+// PatchGoal applies the merge patch of the request to a goal and returns the updated goal.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) PatchGoal(req *handle.PatchGoalRequest) (*handle.GetGoalResponse, error) {
+	res := &handle.GetGoalResponse{}
+	_, err := c.do(call{
+		method:      http.MethodPatch,
+		path:        "/api/v1/goals/" + segment(req.Id),
+		body:        req.Patch,
+		contentType: mergePatchContentType,
+		revision:    req.Revision,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
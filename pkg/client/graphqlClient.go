@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/graphql"
+	"net/http"
+)
+
+// GraphQL runs a GraphQL query or mutation. The errors of the query are returned in the response, not as an error;
+// use Response.Err to check for them.
+func (c *Client) GraphQL(req *graphql.Request) (*graphql.Response, error) {
+	res := &graphql.Response{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/graphql", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// ListPlans retrieves a page of plans.
+func (c *Client) ListPlans(req *handle.ListRequest) (*handle.ListPlansResponse, error) {
+	res := &handle.ListPlansResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/listplans", query: listQuery(req)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatePlan creates a new plan and returns its id.
+func (c *Client) CreatePlan(req *handle.CreatePlanRequest) (*handle.CreatePlanResponse, error) {
+	res := &handle.CreatePlanResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/plan/new", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlan retrieves a plan by its ID.
+func (c *Client) GetPlan(req *handle.GetPlanRequest) (*handle.GetPlanResponse, error) {
+	res := &handle.GetPlanResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/plan/" + segment(req.Id)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlanByName retrieves a plan by its name.
+func (c *Client) GetPlanByName(req *handle.GetPlanByNameRequest) (*handle.GetPlanByNameResponse, error) {
+	res := &handle.GetPlanByNameResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/plan/name/" + segment(req.PlanName)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlansByGoal retrieves the plans of a goal.
+func (c *Client) GetPlansByGoal(req *handle.GetPlansByGoalRequest) (*handle.GetPlansByGoalResponse, error) {
+	res := &handle.GetPlansByGoalResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/plan/goal/" + segment(req.GoalId)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdatePlan replaces a plan. If req.Revision is set, it is sent as If-Match.
+func (c *Client) UpdatePlan(req *handle.UpdatePlanRequest) error {
+	_, err := c.do(call{method: http.MethodPut, path: "/plan/" + segment(req.Id), body: req, revision: req.Revision}, nil)
+	return err
+}
+
+// DeletePlan deletes a plan. If req.Revision is set, it is sent as If-Match.
+func (c *Client) DeletePlan(req *handle.DeletePlanRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/plan/" + segment(req.Id), revision: req.Revision}, nil)
+	return err
+}
+
+// PatchPlan applies the merge patch of the request to a plan and returns the updated plan.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) PatchPlan(req *handle.PatchPlanRequest) (*handle.GetPlanResponse, error) {
+	res := &handle.GetPlanResponse{}
+	_, err := c.do(call{
+		method:      http.MethodPatch,
+		path:        "/api/v1/plans/" + segment(req.Id),
+		body:        req.Patch,
+		contentType: mergePatchContentType,
+		revision:    req.Revision,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// ListPlanners retrieves a page of planners.
+func (c *Client) ListPlanners(req *handle.ListRequest) (*handle.ListPlannersResponse, error) {
+	res := &handle.ListPlannersResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/listplanners", query: listQuery(req)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatePlanner creates a new planner and returns its id.
+func (c *Client) CreatePlanner(req *handle.CreatePlannerRequest) (*handle.CreatePlannerResponse, error) {
+	res := &handle.CreatePlannerResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/planner/new", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlanner retrieves a planner by its ID.
+func (c *Client) GetPlanner(req *handle.GetPlannerRequest) (*handle.GetPlannerResponse, error) {
+	res := &handle.GetPlannerResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/planner/" + segment(req.Id)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlannerByTitle retrieves a planner by its title.
+func (c *Client) GetPlannerByTitle(req *handle.GetPlannerByTitleRequest) (*handle.GetPlannerByTitleResponse, error) {
+	res := &handle.GetPlannerByTitleResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/planner/title/" + segment(req.Title)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPlannerByOwner retrieves the planners of an owner.
+func (c *Client) GetPlannerByOwner(req *handle.GetPlannerByOwnerRequest) ([]*handle.GetPlannerByOwnerResponse, error) {
+	var res []*handle.GetPlannerByOwnerResponse
+	if _, err := c.do(call{method: http.MethodGet, path: "/planner/owner/" + segment(req.UserId)}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdatePlanner replaces the title and owner of a planner. If req.Revision is set, it is sent as If-Match.
+func (c *Client) UpdatePlanner(req *handle.UpdatePlannerRequest) error {
+	_, err := c.do(call{method: http.MethodPut, path: "/planner/" + segment(req.Id), body: req, revision: req.Revision}, nil)
+	return err
+}
+
+// DeletePlanner deletes a planner. If req.Revision is set, it is sent as If-Match.
+func (c *Client) DeletePlanner(req *handle.DeletePlannerRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/planner/" + segment(req.Id), revision: req.Revision}, nil)
+	return err
+}
+
+// PatchPlanner applies the merge patch of the request to a planner and returns the updated planner.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) PatchPlanner(req *handle.PatchPlannerRequest) (*handle.GetPlannerResponse, error) {
+	res := &handle.GetPlannerResponse{}
+	_, err := c.do(call{
+		method:      http.MethodPatch,
+		path:        "/api/v1/planners/" + segment(req.Id),
+		body:        req.Patch,
+		contentType: mergePatchContentType,
+		revision:    req.Revision,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SharePlanner gives req.User the role req.Role in a planner and returns the updated planner.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) SharePlanner(req *handle.SharePlannerRequest) (*handle.GetPlannerResponse, error) {
+	res := &handle.GetPlannerResponse{}
+	path := "/api/v1/planners/" + segment(req.Id) + "/members/" + segment(req.User)
+	if _, err := c.do(call{method: http.MethodPut, path: path, body: req, revision: req.Revision}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UnsharePlanner removes req.User from the members of a planner and returns the updated planner.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) UnsharePlanner(req *handle.SharePlannerRequest) (*handle.GetPlannerResponse, error) {
+	res := &handle.GetPlannerResponse{}
+	path := "/api/v1/planners/" + segment(req.Id) + "/members/" + segment(req.User)
+	if _, err := c.do(call{method: http.MethodDelete, path: path, revision: req.Revision}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Search runs a full-text search across tasks, goals, plans and the chat history.
+func (c *Client) Search(req *handle.SearchRequest) (*handle.SearchResponse, error) {
+	query := url.Values{"q": {req.Query}}
+	if len(req.Types) > 0 {
+		query.Set("type", strings.Join(req.Types, ","))
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	res := &handle.SearchResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/api/v1/search", query: query}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
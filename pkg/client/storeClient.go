@@ -0,0 +1,447 @@
+package client
+
+import (
+	"encoding/json"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"net/http"
+	"reflect"
+)
+
+// TaskStore, GoalStore, PlanStore and PlannerStore implement the interfaces of pkg/store over the REST API of
+// a flow server, so that the services and controls run unchanged against a server instead of a local database.
+// The server checks every request against the access of the user of the client's token, and records the events.
+//
+// A record is created with the fields of the create request of its endpoint; the id, the timestamps and the
+// fields the request lacks are the server's, and are copied back into the record. An update sends the
+// difference between the stored record and the new one as a merge patch, and copies back the new revision.
+// The Find* methods filter and sort on the server, and apply the id lists, the offset and the limit of
+// the options to the result.
+//
+// Example:
+//
+//	c := client.NewClient("https://flow.team.local", os.Getenv("FLOW_TOKEN"))
+//	tasks := handle.NewTaskControl(services.NewTaskService(c.TaskStore()))
+type TaskStore struct {
+	client *Client
+}
+
+// TaskStore returns a task store backed by the server c talks to.
+func (c *Client) TaskStore() *TaskStore {
+	return &TaskStore{client: c}
+}
+
+// GoalStore is the store.GoalStore of a flow server; see TaskStore.
+type GoalStore struct {
+	client *Client
+}
+
+// GoalStore returns a goal store backed by the server c talks to.
+func (c *Client) GoalStore() *GoalStore {
+	return &GoalStore{client: c}
+}
+
+// PlanStore is the store.PlanStore of a flow server; see TaskStore.
+type PlanStore struct {
+	client *Client
+}
+
+// PlanStore returns a plan store backed by the server c talks to.
+func (c *Client) PlanStore() *PlanStore {
+	return &PlanStore{client: c}
+}
+
+// PlannerStore is the store.PlannerStore of a flow server; see TaskStore.
+type PlannerStore struct {
+	client *Client
+}
+
+// PlannerStore returns a planner store backed by the server c talks to.
+func (c *Client) PlannerStore() *PlannerStore {
+	return &PlannerStore{client: c}
+}
+
+// record sends req and decodes the record in its response into v.
+// key names the field of the response that holds the record, if the endpoint wraps it, as {"goal": ...}.
+func (c *Client) record(req call, key string, v interface{}) error {
+	if key == "" {
+		_, err := c.do(req, v)
+		return err
+	}
+	var res map[string]json.RawMessage
+	if _, err := c.do(req, &res); err != nil {
+		return err
+	}
+	return json.Unmarshal(res[key], v)
+}
+
+// replace stores v as the record at path: it reads the stored record, sends the difference as a merge patch
+// to patchPath and decodes the updated record into v. key is as for record.
+// expectedRevision is sent as If-Match unless it is store.AnyRevision.
+func (c *Client) replace(path, patchPath, key string, v interface{}, expectedRevision int) error {
+	var current map[string]interface{}
+	if err := c.record(call{method: http.MethodGet, path: path}, key, &current); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var desired map[string]interface{}
+	if err := json.Unmarshal(raw, &desired); err != nil {
+		return err
+	}
+	return c.record(call{
+		method:      http.MethodPatch,
+		path:        patchPath,
+		body:        mergePatchFrom(current, desired),
+		contentType: mergePatchContentType,
+		revision:    expectedRevision,
+	}, key, v)
+}
+
+// mergePatchFrom returns the merge patch (RFC 7396) that turns the JSON object current into desired:
+// fields missing from desired are set to null, and objects that differ are patched field by field.
+func mergePatchFrom(current, desired map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for name := range current {
+		if _, ok := desired[name]; !ok {
+			patch[name] = nil
+		}
+	}
+	for name, value := range desired {
+		was, ok := current[name]
+		if ok && reflect.DeepEqual(was, value) {
+			continue
+		}
+		wasObject, wasOk := was.(map[string]interface{})
+		object, isObject := value.(map[string]interface{})
+		if ok && wasOk && isObject {
+			patch[name] = mergePatchFrom(wasObject, object)
+			continue
+		}
+		patch[name] = value
+	}
+	return patch
+}
+
+// listAll returns the query of a list endpoint for the filters and order of opts, without paging,
+// which page applies to the result.
+func listAll(opts *store.ListOptions) *handle.ListRequest {
+	req := &handle.ListRequest{}
+	if opts == nil {
+		return req
+	}
+	req.Sort = opts.Sort
+	req.Owner = opts.Owner
+	req.Status = opts.Status
+	req.PlannerId = opts.PlannerId
+	req.GoalId = opts.GoalId
+	if !opts.DeadlineBefore.IsZero() {
+		req.DeadlineBefore = opts.DeadlineBefore.Format("2006-01-02")
+	}
+	return req
+}
+
+// page returns the bounds of the records of a result of n records that opts asks for.
+func page(opts *store.ListOptions, n int) (int, int) {
+	if opts == nil {
+		return 0, n
+	}
+	start := opts.Offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if opts.Limit > 0 && start+opts.Limit < n {
+		end = start + opts.Limit
+	}
+	return start, end
+}
+
+// in reports whether id is in ids; a nil list contains every id.
+func in(ids []string, id string) bool {
+	if ids == nil {
+		return true
+	}
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTask creates the task on the server and copies the stored task, with the id the server gave it, into task.
+func (s *TaskStore) CreateTask(task *models.Task) error {
+	res := &handle.CreateTaskResponse{}
+	req := handle.CreateTaskRequest{Title: task.Title, Description: task.Description, Owner: task.Owner, PlanId: task.PlanId}
+	if _, err := s.client.do(call{method: http.MethodPost, path: "/task/new", body: req}, res); err != nil {
+		return err
+	}
+	return s.client.record(call{method: http.MethodGet, path: "/task/" + segment(res.ID)}, "", task)
+}
+
+// UpdateTask replaces the task with the given id with task on the server.
+func (s *TaskStore) UpdateTask(id string, task *models.Task, expectedRevision int) error {
+	return s.client.replace("/task/"+segment(id), "/api/v1/tasks/"+segment(id), "", task, expectedRevision)
+}
+
+// DeleteTask deletes the task with the given id on the server.
+func (s *TaskStore) DeleteTask(id string, expectedRevision int) error {
+	return s.client.DeleteTask(&handle.DeleteTaskRequest{ID: id, Revision: expectedRevision})
+}
+
+// GetTask retrieves a task by its id.
+func (s *TaskStore) GetTask(id string) (*models.Task, error) {
+	task := &models.Task{}
+	if err := s.client.record(call{method: http.MethodGet, path: "/task/" + segment(id)}, "", task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListTasks retrieves every task the server lets the client see.
+func (s *TaskStore) ListTasks() ([]*models.Task, error) {
+	return s.FindTasks(nil)
+}
+
+// GetTaskByTitle retrieves a task by its title.
+func (s *TaskStore) GetTaskByTitle(title string) (*models.Task, error) {
+	task := &models.Task{}
+	if err := s.client.record(call{method: http.MethodGet, path: "/task/title/" + segment(title)}, "", task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetTaskByOwner retrieves the tasks of an owner.
+func (s *TaskStore) GetTaskByOwner(owner string) ([]*models.Task, error) {
+	var tasks []*models.Task
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/task/owner/" + segment(owner)}, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindTasks retrieves the tasks matching opts.
+func (s *TaskStore) FindTasks(opts *store.ListOptions) ([]*models.Task, error) {
+	var tasks []*models.Task
+	if _, err := s.client.do(call{method: http.MethodGet, path: "/listtasks", query: listQuery(listAll(opts))}, &tasks); err != nil {
+		return nil, err
+	}
+	found := []*models.Task{}
+	for _, task := range tasks {
+		if opts == nil || in(opts.Ids, task.ID) && in(opts.PlanIds, task.PlanId) {
+			found = append(found, task)
+		}
+	}
+	start, end := page(opts, len(found))
+	return found[start:end], nil
+}
+
+// CreateGoal creates the goal on the server and copies the stored goal, with the id the server gave it, into goal.
+func (s *GoalStore) CreateGoal(goal *models.Goal) error {
+	req := &handle.CreateGoalRequest{Objective: goal.Objective, Deadline: goal.Deadline.Format("2006-01-02"), PlannerId: goal.PlannerId}
+	res, err := s.client.CreateGoal(req)
+	if err != nil {
+		return err
+	}
+	return s.client.record(call{method: http.MethodGet, path: "/goal/" + segment(res.ID)}, "goal", goal)
+}
+
+// UpdateGoal replaces the goal with the id of goal on the server.
+func (s *GoalStore) UpdateGoal(goal *models.Goal, expectedRevision int) error {
+	return s.client.replace("/goal/"+segment(goal.Id), "/api/v1/goals/"+segment(goal.Id), "goal", goal, expectedRevision)
+}
+
+// DeleteGoal deletes the goal with the given id on the server.
+func (s *GoalStore) DeleteGoal(id string, expectedRevision int) error {
+	return s.client.DeleteGoal(&handle.DeleteGoalRequest{Id: id, Revision: expectedRevision})
+}
+
+// GetGoal retrieves a goal by its id.
+func (s *GoalStore) GetGoal(id string) (*models.Goal, error) {
+	res, err := s.client.GetGoal(&handle.GetGoalRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Goal, nil
+}
+
+// ListGoals retrieves every goal the server lets the client see.
+func (s *GoalStore) ListGoals() ([]*models.Goal, error) {
+	return s.FindGoals(nil)
+}
+
+// GetGoalByObjective retrieves a goal by its objective.
+func (s *GoalStore) GetGoalByObjective(objective string) (*models.Goal, error) {
+	res, err := s.client.GetGoalByObjective(&handle.GetGoalByObjectiveRequest{Objective: objective})
+	if err != nil {
+		return nil, err
+	}
+	return res.Goal, nil
+}
+
+// GetGoalsByPlannerId retrieves the goals of a planner.
+func (s *GoalStore) GetGoalsByPlannerId(id string) ([]*models.Goal, error) {
+	res, err := s.client.GetGoalsByPlannerId(&handle.GetGoalsByPlannerIdRequest{PlannerId: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Goals, nil
+}
+
+// FindGoals retrieves the goals matching opts.
+func (s *GoalStore) FindGoals(opts *store.ListOptions) ([]*models.Goal, error) {
+	res, err := s.client.ListGoals(listAll(opts))
+	if err != nil {
+		return nil, err
+	}
+	found := []*models.Goal{}
+	for _, goal := range res.Goals {
+		if opts == nil || in(opts.Ids, goal.Id) && in(opts.PlannerIds, goal.PlannerId) {
+			found = append(found, goal)
+		}
+	}
+	start, end := page(opts, len(found))
+	return found[start:end], nil
+}
+
+// CreatePlan creates the plan on the server and copies the stored plan, with the id the server gave it, into plan.
+func (s *PlanStore) CreatePlan(plan *models.Plan) error {
+	res, err := s.client.CreatePlan(&handle.CreatePlanRequest{
+		PlanName:        plan.PlanName,
+		PlanDescription: plan.PlanDescription,
+		PlanDate:        plan.PlanDate.Format("2006-01-02"),
+		PlanTime:        plan.PlanTime.Format("15:04"),
+		GoalId:          plan.GoalId,
+	})
+	if err != nil {
+		return err
+	}
+	return s.client.record(call{method: http.MethodGet, path: "/plan/" + segment(res.ID)}, "plan", plan)
+}
+
+// UpdatePlan replaces the plan with the id of plan on the server.
+func (s *PlanStore) UpdatePlan(plan *models.Plan, expectedRevision int) error {
+	return s.client.replace("/plan/"+segment(plan.Id), "/api/v1/plans/"+segment(plan.Id), "plan", plan, expectedRevision)
+}
+
+// DeletePlan deletes the plan with the given id on the server.
+func (s *PlanStore) DeletePlan(id string, expectedRevision int) error {
+	return s.client.DeletePlan(&handle.DeletePlanRequest{Id: id, Revision: expectedRevision})
+}
+
+// GetPlan retrieves a plan by its id.
+func (s *PlanStore) GetPlan(id string) (*models.Plan, error) {
+	res, err := s.client.GetPlan(&handle.GetPlanRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plan, nil
+}
+
+// ListPlans retrieves every plan the server lets the client see.
+func (s *PlanStore) ListPlans() ([]*models.Plan, error) {
+	return s.FindPlans(nil)
+}
+
+// GetPlanByName retrieves a plan by its name.
+func (s *PlanStore) GetPlanByName(name string) (*models.Plan, error) {
+	res, err := s.client.GetPlanByName(&handle.GetPlanByNameRequest{PlanName: name})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plan, nil
+}
+
+// GetPlansByGoal retrieves the plans of a goal.
+func (s *PlanStore) GetPlansByGoal(id string) ([]*models.Plan, error) {
+	res, err := s.client.GetPlansByGoal(&handle.GetPlansByGoalRequest{GoalId: id})
+	if err != nil {
+		return nil, err
+	}
+	return res.Plans, nil
+}
+
+// FindPlans retrieves the plans matching opts.
+func (s *PlanStore) FindPlans(opts *store.ListOptions) ([]*models.Plan, error) {
+	res, err := s.client.ListPlans(listAll(opts))
+	if err != nil {
+		return nil, err
+	}
+	found := []*models.Plan{}
+	for _, plan := range res.Plans {
+		if opts == nil || in(opts.Ids, plan.Id) && in(opts.GoalIds, plan.GoalId) {
+			found = append(found, plan)
+		}
+	}
+	start, end := page(opts, len(found))
+	return found[start:end], nil
+}
+
+// CreatePlanner creates the planner on the server and copies the stored planner, with the id the server gave it,
+// into planner.
+func (s *PlannerStore) CreatePlanner(planner *models.Planner) error {
+	res, err := s.client.CreatePlanner(&handle.CreatePlannerRequest{Title: planner.Title, UserId: planner.UserId})
+	if err != nil {
+		return err
+	}
+	return s.client.record(call{method: http.MethodGet, path: "/planner/" + segment(res.Id)}, "", planner)
+}
+
+// UpdatePlanner replaces the planner with the id of planner on the server, with its members.
+func (s *PlannerStore) UpdatePlanner(planner *models.Planner, expectedRevision int) error {
+	return s.client.replace("/planner/"+segment(planner.Id), "/api/v1/planners/"+segment(planner.Id), "", planner, expectedRevision)
+}
+
+// DeletePlanner deletes the planner with the given id on the server.
+func (s *PlannerStore) DeletePlanner(id string, expectedRevision int) error {
+	return s.client.DeletePlanner(&handle.DeletePlannerRequest{Id: id, Revision: expectedRevision})
+}
+
+// GetPlanner retrieves a planner by its id. The server does not return the goals of a planner.
+func (s *PlannerStore) GetPlanner(id string) (*models.Planner, error) {
+	planner := &models.Planner{}
+	if err := s.client.record(call{method: http.MethodGet, path: "/planner/" + segment(id)}, "", planner); err != nil {
+		return nil, err
+	}
+	return planner, nil
+}
+
+// ListPlanners retrieves every planner the server lets the client see.
+func (s *PlannerStore) ListPlanners() ([]*models.Planner, error) {
+	return s.FindPlanners(nil)
+}
+
+// GetPlannerByTitle finds the planner by its title, then reads the whole planner, with its members and revision.
+func (s *PlannerStore) GetPlannerByTitle(title string) (*models.Planner, error) {
+	res, err := s.client.GetPlannerByTitle(&handle.GetPlannerByTitleRequest{Title: title})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPlanner(res.Id)
+}
+
+// GetPlannerByOwner lists the planners of the owner, with their members and revisions.
+func (s *PlannerStore) GetPlannerByOwner(id string) ([]*models.Planner, error) {
+	return s.FindPlanners(&store.ListOptions{Owner: id})
+}
+
+// FindPlanners retrieves the planners matching opts.
+func (s *PlannerStore) FindPlanners(opts *store.ListOptions) ([]*models.Planner, error) {
+	var planners []*models.Planner
+	if err := s.client.record(call{method: http.MethodGet, path: "/listplanners", query: listQuery(listAll(opts))}, "planners", &planners); err != nil {
+		return nil, err
+	}
+	found := []*models.Planner{}
+	for _, planner := range planners {
+		if opts == nil || in(opts.Ids, planner.Id) && in(opts.PlannerIds, planner.Id) {
+			found = append(found, planner)
+		}
+	}
+	start, end := page(opts, len(found))
+	return found[start:end], nil
+}
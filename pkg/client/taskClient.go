@@ -0,0 +1,84 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// ListTasks retrieves a page of tasks; the cursor of the next page is returned in NextCursor.
+func (c *Client) ListTasks(req *handle.ListRequest) (*handle.ListTasksResponse, error) {
+	var tasks []*handle.GetTaskResponse
+	header, err := c.do(call{method: http.MethodGet, path: "/listtasks", query: listQuery(req)}, &tasks)
+	if err != nil {
+		return nil, err
+	}
+	return &handle.ListTasksResponse{
+		Tasks:      tasks,
+		NextCursor: header.Get("X-Next-Cursor"),
+	}, nil
+}
+
+// CreateTask creates a new task and returns its id.
+func (c *Client) CreateTask(req handle.CreateTaskRequest) (*handle.CreateTaskResponse, error) {
+	res := &handle.CreateTaskResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/task/new", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetTask retrieves a task by its ID.
+func (c *Client) GetTask(req *handle.GetTaskRequest) (*handle.GetTaskResponse, error) {
+	res := &handle.GetTaskResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/task/" + segment(req.ID)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetTaskByTitle retrieves a task by its title.
+func (c *Client) GetTaskByTitle(req *handle.GetTaskByTitleRequest) (*handle.GetTaskResponse, error) {
+	res := &handle.GetTaskResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/task/title/" + segment(req.Title)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetTaskByOwner retrieves the tasks of an owner.
+func (c *Client) GetTaskByOwner(req *handle.GetTaskByOwnerRequest) ([]*handle.GetTaskResponse, error) {
+	var res []*handle.GetTaskResponse
+	if _, err := c.do(call{method: http.MethodGet, path: "/task/owner/" + segment(req.Owner)}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UpdateTask replaces a task. If req.Revision is set, it is sent as If-Match.
+func (c *Client) UpdateTask(req *handle.UpdateTaskRequest) error {
+	_, err := c.do(call{method: http.MethodPut, path: "/task/" + segment(req.ID), body: req, revision: req.Revision}, nil)
+	return err
+}
+
+// DeleteTask deletes a task. If req.Revision is set, it is sent as If-Match.
+func (c *Client) DeleteTask(req *handle.DeleteTaskRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/task/" + segment(req.ID), revision: req.Revision}, nil)
+	return err
+}
+
+// PatchTask applies the merge patch of the request to a task and returns the updated task.
+// If req.Revision is set, it is sent as If-Match.
+func (c *Client) PatchTask(req *handle.PatchTaskRequest) (*handle.GetTaskResponse, error) {
+	res := &handle.GetTaskResponse{}
+	_, err := c.do(call{
+		method:      http.MethodPatch,
+		path:        "/api/v1/tasks/" + segment(req.ID),
+		body:        req.Patch,
+		contentType: mergePatchContentType,
+		revision:    req.Revision,
+	}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
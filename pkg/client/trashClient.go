@@ -0,0 +1,33 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// ListTrash retrieves the deleted records in the trash that the caller could have deleted, most recently deleted first.
+func (c *Client) ListTrash() (*handle.TrashResponse, error) {
+	res := &handle.TrashResponse{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/api/v1/trash"}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RestoreTrash brings back a deleted record, together with the records deleted along with it, and returns them.
+func (c *Client) RestoreTrash(req *handle.RestoreTrashRequest) (*handle.TrashResponse, error) {
+	res := &handle.TrashResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/trash/" + segment(req.Id) + "/restore"}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// EmptyTrash purges the records deleted longer ago than req.OlderThan for good and returns them.
+func (c *Client) EmptyTrash(req *handle.EmptyTrashRequest) (*handle.TrashResponse, error) {
+	res := &handle.TrashResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/trash/empty", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
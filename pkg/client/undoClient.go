@@ -0,0 +1,25 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"net/http"
+)
+
+// Undo reverts the last changes of the user of the token, or of the daemon's user over its socket.
+// Changes to records that someone else has changed since are skipped and listed as such.
+func (c *Client) Undo(req *handle.UndoRequest) (*handle.UndoResponse, error) {
+	res := &handle.UndoResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/undo", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Redo applies again the last changes of the user that were undone.
+func (c *Client) Redo(req *handle.UndoRequest) (*handle.UndoResponse, error) {
+	res := &handle.UndoResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/redo", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
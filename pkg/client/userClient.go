@@ -0,0 +1,59 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+)
+
+// AddUser adds a user and returns its id. Like the other methods of users and tokens, it is only served
+// by the daemon, on its Unix socket; see api.UserHandler.
+func (c *Client) AddUser(req *handle.AddUserRequest) (*handle.AddUserResponse, error) {
+	res := &handle.AddUserResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/users", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListUsers retrieves every user.
+func (c *Client) ListUsers() ([]*models.User, error) {
+	var res []*models.User
+	if _, err := c.do(call{method: http.MethodGet, path: "/users"}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetUser retrieves a user by its name.
+func (c *Client) GetUser(req *handle.GetUserRequest) (*models.User, error) {
+	res := &models.User{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/users/" + segment(req.Name)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateToken creates an API token for req.UserName and returns its id and secret.
+func (c *Client) CreateToken(req *handle.CreateTokenRequest) (*handle.CreateTokenResponse, error) {
+	res := &handle.CreateTokenResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/users/" + segment(req.UserName) + "/tokens", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListTokens retrieves the API tokens of a user, without their secrets.
+func (c *Client) ListTokens(req *handle.ListTokensRequest) ([]*models.Token, error) {
+	var res []*models.Token
+	if _, err := c.do(call{method: http.MethodGet, path: "/users/" + segment(req.UserName) + "/tokens"}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RevokeToken revokes an API token.
+func (c *Client) RevokeToken(req *handle.RevokeTokenRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/tokens/" + segment(req.Id)}, nil)
+	return err
+}
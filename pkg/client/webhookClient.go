@@ -0,0 +1,50 @@
+package client
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"net/http"
+)
+
+// ListWebhooks retrieves the webhooks of the caller, without their secrets.
+func (c *Client) ListWebhooks() ([]*models.Webhook, error) {
+	var res []*models.Webhook
+	if _, err := c.do(call{method: http.MethodGet, path: "/api/v1/webhooks"}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateWebhook creates a webhook and returns its id and secret.
+func (c *Client) CreateWebhook(req *handle.CreateWebhookRequest) (*handle.CreateWebhookResponse, error) {
+	res := &handle.CreateWebhookResponse{}
+	if _, err := c.do(call{method: http.MethodPost, path: "/api/v1/webhooks", body: req}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetWebhook retrieves a webhook by its ID.
+func (c *Client) GetWebhook(req *handle.GetWebhookRequest) (*models.Webhook, error) {
+	res := &models.Webhook{}
+	if _, err := c.do(call{method: http.MethodGet, path: "/api/v1/webhooks/" + segment(req.Id)}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery log.
+func (c *Client) DeleteWebhook(req *handle.DeleteWebhookRequest) error {
+	_, err := c.do(call{method: http.MethodDelete, path: "/api/v1/webhooks/" + segment(req.Id)}, nil)
+	return err
+}
+
+// ListWebhookDeliveries retrieves the logged delivery attempts of a webhook, the most recent first.
+// req.Limit is not sent; the server returns every logged attempt.
+func (c *Client) ListWebhookDeliveries(req *handle.ListWebhookDeliveriesRequest) ([]*models.Delivery, error) {
+	var res []*models.Delivery
+	if _, err := c.do(call{method: http.MethodGet, path: "/api/v1/webhooks/" + segment(req.Id) + "/deliveries"}, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,543 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Request is a GraphQL request as sent in the body of a POST.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request: the data of the selected fields, in the order they were selected,
+// and the errors raised while parsing or executing it. Data is null if the request could not be executed.
+type Response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+// Err returns the errors of the response as one error, or nil if there are none.
+func (r *Response) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Errorf("graphql: %s", strings.Join(messages, "; "))
+}
+
+// Location is a position in the query document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an error of a response. Path lists the response keys of the field that failed; because fields are
+// resolved in batches, it does not include list indices.
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Path) > 0 {
+		parts := make([]string, len(e.Path))
+		for i, key := range e.Path {
+			parts[i] = fmt.Sprint(key)
+		}
+		return strings.Join(parts, ".") + ": " + e.Message
+	}
+	return e.Message
+}
+
+// Execute parses and runs a request against the schema. Query fields and mutation fields are both
+// resolved in the order they are selected.
+func (s *Schema) Execute(ctx context.Context, req *Request) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return failed(err)
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return failed(err)
+	}
+	root := s.Query
+	switch op.kind {
+	case "mutation":
+		if s.Mutation == nil {
+			return failed(&Error{Message: "the schema has no mutations", Locations: []Location{op.loc}})
+		}
+		root = s.Mutation
+	case "subscription":
+		return failed(&Error{Message: "subscriptions are not supported", Locations: []Location{op.loc}})
+	}
+	vars, err := variables(op, req.Variables)
+	if err != nil {
+		return failed(err)
+	}
+
+	e := &executor{ctx: ctx, doc: doc, vars: vars}
+	results := e.executeObjects(root, []interface{}{nil}, op.selections, nil)
+	data, err := json.Marshal(results[0])
+	if err != nil {
+		return failed(err)
+	}
+	return &Response{Data: data, Errors: e.errors}
+}
+
+// failed returns the response of a request that could not be executed.
+func failed(err error) *Response {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	return &Response{Data: json.RawMessage("null"), Errors: []*Error{gqlErr}}
+}
+
+// operation returns the operation to execute: the one named name, or the only one of the document.
+func (d *document) operation(name string) (*operation, error) {
+	if name == "" {
+		if len(d.operations) > 1 {
+			return nil, &Error{Message: "operationName is required for a document with several operations"}
+		}
+		return d.operations[0], nil
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+// variables returns the values of the variables of an operation, applying defaults and checking required variables.
+func variables(op *operation, given map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range op.variables {
+		value, ok := given[def.name]
+		switch {
+		case ok:
+			vars[def.name] = value
+		case def.hasDefault:
+			vars[def.name] = literal(def.defaultVal, nil)
+		case def.nonNull:
+			return nil, &Error{Message: fmt.Sprintf("variable $%s of type %s! is required", def.name, def.typ)}
+		}
+		if def.nonNull && ok && value == nil {
+			return nil, &Error{Message: fmt.Sprintf("variable $%s of type %s! must not be null", def.name, def.typ)}
+		}
+	}
+	return vars, nil
+}
+
+// literal returns the Go value of a value of the document, substituting variables.
+func literal(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case variableRef:
+		return vars[string(v)]
+	case enumValue:
+		return string(v)
+	case listValue:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = literal(item, vars)
+		}
+		return list
+	case objectValue:
+		obj := make(map[string]interface{}, len(v))
+		for _, f := range v {
+			obj[f.name] = literal(f.value, vars)
+		}
+		return obj
+	}
+	return value
+}
+
+// executor holds the state of the execution of one operation.
+type executor struct {
+	ctx    context.Context
+	doc    *document
+	vars   map[string]interface{}
+	errors []*Error
+}
+
+// fail records an error of the field at path.
+func (e *executor) fail(path []interface{}, loc Location, err error) {
+	e.errors = append(e.errors, &Error{Message: err.Error(), Locations: []Location{loc}, Path: path})
+}
+
+// collectedField is a response key with the fields selected under it, whose selection sets are merged.
+type collectedField struct {
+	key    string
+	fields []*field
+}
+
+// collectFields flattens the fragments of a selection set for objects of type obj, dropping
+// the selections excluded with @skip or @include and grouping the fields by response key.
+func (e *executor) collectFields(obj *Object, selections []selection, collected []*collectedField, visited map[string]bool) ([]*collectedField, error) {
+	for _, sel := range selections {
+		switch sel := sel.(type) {
+		case *field:
+			if ok, err := e.included(sel.directives); err != nil || !ok {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			key := sel.responseKey()
+			found := false
+			for _, c := range collected {
+				if c.key == key {
+					c.fields = append(c.fields, sel)
+					found = true
+					break
+				}
+			}
+			if !found {
+				collected = append(collected, &collectedField{key: key, fields: []*field{sel}})
+			}
+		case *fragmentSpread:
+			if ok, err := e.included(sel.directives); err != nil || !ok || visited[sel.name] {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			frag, ok := e.doc.fragments[sel.name]
+			if !ok {
+				return nil, &Error{Message: fmt.Sprintf("unknown fragment %q", sel.name), Locations: []Location{sel.loc}}
+			}
+			visited[sel.name] = true
+			if frag.typeCondition != obj.Name {
+				continue
+			}
+			var err error
+			if collected, err = e.collectFields(obj, frag.selections, collected, visited); err != nil {
+				return nil, err
+			}
+		case *inlineFragment:
+			if ok, err := e.included(sel.directives); err != nil || !ok {
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if sel.typeCondition != "" && sel.typeCondition != obj.Name {
+				continue
+			}
+			var err error
+			if collected, err = e.collectFields(obj, sel.selections, collected, visited); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return collected, nil
+}
+
+// included evaluates the @skip and @include directives of a selection.
+func (e *executor) included(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			continue
+		}
+		var cond interface{}
+		for _, arg := range d.arguments {
+			if arg.name == "if" {
+				cond = literal(arg.value, e.vars)
+			}
+		}
+		b, ok := cond.(bool)
+		if !ok {
+			return false, &Error{Message: fmt.Sprintf("@%s requires a Boolean argument if", d.name)}
+		}
+		if b == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// executeObjects resolves a selection set for a batch of objects of type obj and returns one result per source.
+// Each field is resolved once for the whole batch.
+func (e *executor) executeObjects(obj *Object, sources []interface{}, selections []selection, path []interface{}) []*orderedMap {
+	results := make([]*orderedMap, len(sources))
+	for i := range results {
+		results[i] = &orderedMap{values: map[string]interface{}{}}
+	}
+	collected, err := e.collectFields(obj, selections, nil, map[string]bool{})
+	if err != nil {
+		e.fail(path, Location{}, err)
+		return results
+	}
+	for _, c := range collected {
+		f := c.fields[0]
+		fieldPath := append(append([]interface{}{}, path...), c.key)
+		if f.name == "__typename" {
+			for _, result := range results {
+				result.set(c.key, obj.Name)
+			}
+			continue
+		}
+		def, ok := obj.Fields[f.name]
+		if !ok {
+			e.fail(fieldPath, f.loc, fmt.Errorf("cannot query field %q on type %s", f.name, obj.Name))
+			for _, result := range results {
+				result.set(c.key, nil)
+			}
+			continue
+		}
+		values, err := e.resolve(obj, def, f, sources)
+		if err != nil {
+			e.fail(fieldPath, f.loc, err)
+			values = make([]interface{}, len(sources))
+		}
+		var subSelections []selection
+		for _, same := range c.fields {
+			subSelections = append(subSelections, same.selections...)
+		}
+		completed := e.complete(def.Type, values, subSelections, fieldPath, f.loc)
+		for i, result := range results {
+			result.set(c.key, completed[i])
+		}
+	}
+	return results
+}
+
+// resolve checks and coerces the arguments of a field and calls its resolver.
+func (e *executor) resolve(obj *Object, def *Field, f *field, sources []interface{}) ([]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, arg := range f.arguments {
+		var declared *Argument
+		for _, a := range def.Args {
+			if a.Name == arg.name {
+				declared = a
+			}
+		}
+		if declared == nil {
+			return nil, fmt.Errorf("unknown argument %q of field %s.%s", arg.name, obj.Name, f.name)
+		}
+		if ref, ok := arg.value.(variableRef); ok {
+			if _, given := e.vars[string(ref)]; !given {
+				continue
+			}
+		}
+		value, err := coerce(literal(arg.value, e.vars), declared.Type)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s of %s.%s: %w", arg.name, obj.Name, f.name, err)
+		}
+		args[arg.name] = value
+	}
+	for _, a := range def.Args {
+		if _, ok := a.Type.(*NonNull); ok {
+			if _, given := args[a.Name]; !given {
+				return nil, fmt.Errorf("argument %s of %s.%s is required", a.Name, obj.Name, f.name)
+			}
+		}
+	}
+	values, err := def.Resolve(e.ctx, sources, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(sources) {
+		return nil, fmt.Errorf("resolver of %s.%s returned %d values for %d sources", obj.Name, f.name, len(values), len(sources))
+	}
+	return values, nil
+}
+
+// complete turns the resolved values of a field into response values: objects are executed with the
+// selection set of the field, all together, and lists are flattened so that their items are also completed in one batch.
+func (e *executor) complete(t Type, values []interface{}, selections []selection, path []interface{}, loc Location) []interface{} {
+	switch t := t.(type) {
+	case *NonNull:
+		return e.complete(t.Of, values, selections, path, loc)
+	case *Scalar:
+		if len(selections) > 0 {
+			e.fail(path, loc, fmt.Errorf("field of type %s cannot have a selection set", t.Name))
+			return make([]interface{}, len(values))
+		}
+		return values
+	case *List:
+		var items []interface{}
+		lengths := make([]int, len(values))
+		for i, value := range values {
+			lengths[i] = -1
+			if isNil(value) {
+				continue
+			}
+			list := reflect.ValueOf(value)
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				e.fail(path, loc, fmt.Errorf("expected a list, got %T", value))
+				continue
+			}
+			lengths[i] = list.Len()
+			for j := 0; j < list.Len(); j++ {
+				items = append(items, list.Index(j).Interface())
+			}
+		}
+		completedItems := e.complete(t.Of, items, selections, path, loc)
+		completed := make([]interface{}, len(values))
+		offset := 0
+		for i, n := range lengths {
+			if n < 0 {
+				continue
+			}
+			completed[i] = completedItems[offset : offset+n]
+			offset += n
+		}
+		return completed
+	case *Object:
+		if len(selections) == 0 {
+			e.fail(path, loc, fmt.Errorf("field of type %s must have a selection set", t.Name))
+			return make([]interface{}, len(values))
+		}
+		var sources []interface{}
+		var positions []int
+		for i, value := range values {
+			if !isNil(value) {
+				sources = append(sources, value)
+				positions = append(positions, i)
+			}
+		}
+		completed := make([]interface{}, len(values))
+		if len(sources) == 0 {
+			return completed
+		}
+		for i, result := range e.executeObjects(t, sources, selections, path) {
+			completed[positions[i]] = result
+		}
+		return completed
+	}
+	return values
+}
+
+// isNil reports whether a value is nil or a nil pointer, map or slice.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// coerce converts an argument value to the Go representation of its type.
+func coerce(value interface{}, t Type) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("must not be null")
+		}
+		return coerce(value, nonNull.Of)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerce(item, t.Of)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		switch t {
+		case Int:
+			switch n := value.(type) {
+			case int64:
+				if n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			case float64:
+				if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+					return int(n), nil
+				}
+			case json.Number:
+				if i, err := n.Int64(); err == nil && i >= math.MinInt32 && i <= math.MaxInt32 {
+					return int(i), nil
+				}
+			}
+		case Float:
+			switch n := value.(type) {
+			case int64:
+				return float64(n), nil
+			case float64:
+				return n, nil
+			}
+		case Boolean:
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+		case ID:
+			switch id := value.(type) {
+			case string:
+				return id, nil
+			case int64:
+				return fmt.Sprint(id), nil
+			case float64:
+				if id == math.Trunc(id) {
+					return fmt.Sprint(int64(id)), nil
+				}
+			}
+		default:
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not a valid %s", value, t.Name)
+	}
+	return value, nil
+}
+
+// orderedMap is an object of the response, which keeps its keys in the order they were selected.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		b.Write(name)
+		b.WriteByte(':')
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,121 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/graphql"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type author struct {
+	name  string
+	books []string
+}
+
+// library is a schema of authors and their books whose resolvers count how often they are called.
+func library(calls map[string]int) *graphql.Schema {
+	authors := map[string]*author{
+		"le guin":   {name: "Ursula K. Le Guin", books: []string{"earthsea", "dispossessed"}},
+		"pratchett": {name: "Terry Pratchett", books: []string{"mort"}},
+	}
+	titles := map[string]string{"earthsea": "A Wizard of Earthsea", "dispossessed": "The Dispossessed", "mort": "Mort"}
+	bookType := &graphql.Object{Name: "Book"}
+	authorType := &graphql.Object{Name: "Author", Fields: map[string]*graphql.Field{
+		"name": {Type: graphql.String, Resolve: graphql.Property(func(source interface{}) interface{} { return source.(*author).name })},
+		"books": {Type: graphql.ListOf(bookType), Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			calls["books"]++
+			values := make([]interface{}, len(sources))
+			for i, source := range sources {
+				values[i] = source.(*author).books
+			}
+			return values, nil
+		}},
+	}}
+	bookType.Fields = map[string]*graphql.Field{
+		"title": {Type: graphql.String, Args: []*graphql.Argument{{Name: "upper", Type: graphql.Boolean}},
+			Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+				calls["title"]++
+				values := make([]interface{}, len(sources))
+				for i, source := range sources {
+					title := titles[source.(string)]
+					if args["upper"] == true {
+						title = strings.ToUpper(title)
+					}
+					values[i] = title
+				}
+				return values, nil
+			}},
+	}
+	return &graphql.Schema{Query: &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"authors": {Type: graphql.ListOf(authorType), Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+			return []interface{}{[]*author{authors["le guin"], authors["pratchett"]}}, nil
+		}},
+		"author": {Type: authorType, Args: []*graphql.Argument{{Name: "id", Type: graphql.NonNullOf(graphql.ID)}},
+			Resolve: func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+				a, ok := authors[args["id"].(string)]
+				if !ok {
+					return nil, fmt.Errorf("no author %q", args["id"])
+				}
+				return []interface{}{a}, nil
+			}},
+	}}}
+}
+
+func TestSchema_Execute(t *testing.T) {
+	calls := map[string]int{}
+	schema := library(calls)
+	res := schema.Execute(context.Background(), &graphql.Request{Query: `
+		query Library($upper: Boolean = false) {
+			authors { name ...Books }
+		}
+		fragment Books on Author { books { title(upper: $upper) __typename } }
+	`, Variables: map[string]interface{}{"upper": true}})
+	assert.NoError(t, res.Err())
+	assert.JSONEq(t, `{"authors": [
+		{"name": "Ursula K. Le Guin", "books": [{"title": "A WIZARD OF EARTHSEA", "__typename": "Book"}, {"title": "THE DISPOSSESSED", "__typename": "Book"}]},
+		{"name": "Terry Pratchett", "books": [{"title": "MORT", "__typename": "Book"}]}
+	]}`, string(res.Data))
+	// the books of both authors and the titles of all three books are each resolved in one call
+	assert.Equal(t, map[string]int{"books": 1, "title": 1}, calls)
+
+	// aliases, directives and the order of the response keys
+	res = schema.Execute(context.Background(), &graphql.Request{Query: `{
+		b: author(id: "pratchett") { name @skip(if: true) books { title } }
+		a: author(id: "le guin") { name }
+	}`})
+	assert.NoError(t, res.Err())
+	assert.Equal(t, `{"b":{"books":[{"title":"Mort"}]},"a":{"name":"Ursula K. Le Guin"}}`, string(res.Data))
+}
+
+func TestSchema_Execute_Errors(t *testing.T) {
+	schema := library(map[string]int{})
+	for query, message := range map[string]string{
+		`{ authors { name `:                      "syntax error: unexpected end of document",
+		`{ author { name } }`:                    "author: argument id of Query.author is required",
+		`{ authors { age } }`:                    `authors.age: cannot query field "age" on type Author`,
+		`{ authors }`:                            "authors: field of type Author must have a selection set",
+		`{ author(id: "x", limit: 1) { name } }`: `author: unknown argument "limit" of field Query.author`,
+		`mutation { authors { name } }`:          "the schema has no mutations",
+	} {
+		res := schema.Execute(context.Background(), &graphql.Request{Query: query})
+		if assert.Len(t, res.Errors, 1, query) {
+			assert.Equal(t, message, res.Errors[0].Error(), query)
+		}
+	}
+
+	// a failing field is null and the rest of the response is kept
+	res := schema.Execute(context.Background(), &graphql.Request{Query: `{ x: author(id: "x") { name } authors { name } }`})
+	assert.JSONEq(t, `{"x": null, "authors": [{"name": "Ursula K. Le Guin"}, {"name": "Terry Pratchett"}]}`, string(res.Data))
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, []graphql.Location{{Line: 1, Column: 3}}, res.Errors[0].Locations)
+		assert.Equal(t, []interface{}{"x"}, res.Errors[0].Path)
+	}
+}
+
+func TestSchema_SDL(t *testing.T) {
+	sdl := library(map[string]int{}).SDL()
+	assert.Contains(t, sdl, "type Author {\n  books: [Book]\n  name: String\n}\n")
+	assert.Contains(t, sdl, "  author(id: ID!): Author\n")
+}
@@ -0,0 +1,184 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Type is the type of a field or argument: a *Scalar, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type. Values of scalar fields are encoded as JSON as they are returned by the resolvers.
+type Scalar struct {
+	Name        string
+	Description string
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// The built-in scalars.
+var (
+	String  = &Scalar{Name: "String"}
+	Int     = &Scalar{Name: "Int"}
+	Float   = &Scalar{Name: "Float"}
+	Boolean = &Scalar{Name: "Boolean"}
+	ID      = &Scalar{Name: "ID"}
+)
+
+// List is a list of values of another type. Resolvers return lists as slices.
+type List struct {
+	Of Type
+}
+
+func (l *List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull marks a type whose values are never null. It is only enforced for arguments.
+type NonNull struct {
+	Of Type
+}
+
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// ListOf returns a list type of t.
+func ListOf(t Type) *List { return &List{Of: t} }
+
+// NonNullOf returns the non-null type of t.
+func NonNullOf(t Type) *NonNull { return &NonNull{Of: t} }
+
+// Object is an object type with named fields.
+type Object struct {
+	Name        string
+	Description string
+	Fields      map[string]*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// ResolveFunc resolves a field for a batch of source objects at once and returns one value per source, in the same order.
+// The executor resolves each field of a selection once for all the objects at the same level of the response,
+// so a resolver that loads related records can fetch them for every source in a single store call.
+type ResolveFunc func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+// Field is a field of an object type.
+type Field struct {
+	Type        Type
+	Args        []*Argument
+	Description string
+	Resolve     ResolveFunc
+}
+
+// Argument declares an argument of a field. Argument values are passed to the resolver as
+// string, int, float64, bool, []interface{} or map[string]interface{}, or are absent if not given.
+type Argument struct {
+	Name        string
+	Type        Type
+	Description string
+}
+
+// Property returns a ResolveFunc that resolves a field of each source on its own, with fn.
+// It suits fields that read a value of the source and need no store lookups.
+func Property(fn func(source interface{}) interface{}) ResolveFunc {
+	return func(ctx context.Context, sources []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		values := make([]interface{}, len(sources))
+		for i, source := range sources {
+			values[i] = fn(source)
+		}
+		return values, nil
+	}
+}
+
+// Schema is the root of a GraphQL API: the query type and the optional mutation type.
+// The resolvers of their fields receive a single nil source.
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+}
+
+// SDL returns the schema in the GraphQL schema definition language, with the types and fields in alphabetical order.
+func (s *Schema) SDL() string {
+	objects := map[string]*Object{}
+	scalars := map[string]*Scalar{}
+	var collect func(t Type)
+	collect = func(t Type) {
+		switch t := t.(type) {
+		case *NonNull:
+			collect(t.Of)
+		case *List:
+			collect(t.Of)
+		case *Scalar:
+			scalars[t.Name] = t
+		case *Object:
+			if _, ok := objects[t.Name]; ok {
+				return
+			}
+			objects[t.Name] = t
+			for _, f := range t.Fields {
+				collect(f.Type)
+				for _, arg := range f.Args {
+					collect(arg.Type)
+				}
+			}
+		}
+	}
+	collect(s.Query)
+	if s.Mutation != nil {
+		collect(s.Mutation)
+	}
+
+	var b strings.Builder
+	b.WriteString("schema {\n  query: " + s.Query.Name + "\n")
+	if s.Mutation != nil {
+		b.WriteString("  mutation: " + s.Mutation.Name + "\n")
+	}
+	b.WriteString("}\n")
+	for _, name := range sortedKeys(scalars) {
+		if builtin := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}[name]; builtin {
+			continue
+		}
+		b.WriteString("\n")
+		writeDescription(&b, "", scalars[name].Description)
+		fmt.Fprintf(&b, "scalar %s\n", name)
+	}
+	for _, name := range sortedKeys(objects) {
+		obj := objects[name]
+		b.WriteString("\n")
+		writeDescription(&b, "", obj.Description)
+		fmt.Fprintf(&b, "type %s {\n", name)
+		for _, fieldName := range sortedKeys(obj.Fields) {
+			f := obj.Fields[fieldName]
+			writeDescription(&b, "  ", f.Description)
+			b.WriteString("  " + fieldName)
+			if len(f.Args) > 0 {
+				args := make([]string, len(f.Args))
+				for i, arg := range f.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + f.Type.String() + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// writeDescription writes a description as a block string, if there is one.
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description != "" {
+		b.WriteString(indent + `"""` + description + `"""` + "\n")
+	}
+}
+
+// sortedKeys returns the keys of a map in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,386 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"strings"
+)
+
+// ErrInvalidBatch is returned when an operation of a batch is malformed, such as an unknown op or resource,
+// a missing id or data, or a reference to a record the batch has not created.
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// MaxBatchOperations is the largest number of operations a batch may contain.
+const MaxBatchOperations = 1000
+
+// The operations of a batch.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one step of a batch.
+//
+// Fields:
+// - Op: "create", "update" or "delete".
+// - Resource: "task", "goal", "plan" or "planner".
+// - Ref: a name for the record a create operation makes. Later operations refer to its id with the string "${name}",
+// as their Id or as any string value in their Data.
+// - Id: the id of the record to update or delete.
+// - Data: for create, the request body of the resource's create endpoint, such as a CreateTaskRequest;
+// for update, a JSON Merge Patch (RFC 7396) as accepted by its PATCH endpoint.
+// - Revision: for update and delete, the revision of the record the change is based on; 0 skips the revision check.
+type BatchOperation struct {
+	Op       string          `json:"op"`
+	Resource string          `json:"resource"`
+	Ref      string          `json:"ref,omitempty"`
+	Id       string          `json:"id,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Revision int             `json:"revision,omitempty"`
+}
+
+// BatchRequest is an ordered list of operations that are applied together or not at all.
+//
+// Example:
+//
+//	{"operations": [
+//	  {"op": "create", "resource": "plan", "ref": "release", "data": {"plan_name": "Release", "plan_date": "2024-06-01", "plan_time": "09:00"}},
+//	  {"op": "create", "resource": "task", "data": {"title": "Tag the release", "plan_id": "${release}"}},
+//	  {"op": "update", "resource": "goal", "id": "1b4e28ba", "data": {"goal_status": "In Progress"}, "revision": 3}
+//	]}
+type BatchRequest struct {
+	Operations []*BatchOperation `json:"operations"`
+}
+
+// BatchResult reports the record an operation of a batch changed, with its revision after an update.
+type BatchResult struct {
+	Op       string `json:"op"`
+	Resource string `json:"resource"`
+	Ref      string `json:"ref,omitempty"`
+	Id       string `json:"id"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// BatchResponse holds the results of the operations of a batch, in the same order.
+type BatchResponse struct {
+	Results []*BatchResult `json:"results"`
+}
+
+// BatchError reports the operation that failed a batch, by its index in BatchRequest.Operations.
+// It wraps the error of the operation, so that errors.Is still finds errors such as services.ErrForbidden.
+type BatchError struct {
+	Index int
+	Op    *BatchOperation
+	Err   error
+}
+
+// Error returns the index, op and resource of the failed operation with its error.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operations[%d] (%s %s): %s", e.Index, e.Op.Op, e.Op.Resource, e.Err)
+}
+
+// Unwrap returns the error of the failed operation.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchControl applies batches of create, update and delete operations on tasks, goals, plans and planners
+// in one transaction. The operations go through the same services, with the same access checks and events,
+// as the other controls; the events are published once the transaction is committed.
+type BatchControl struct {
+	stores  store.Transactor
+	events  *services.EventService
+	journal *services.JournalService
+	user    string
+}
+
+// NewBatchControl creates a BatchControl whose batches run in transactions of stores
+// and record their changes with events, which may be nil.
+func NewBatchControl(stores store.Transactor, events *services.EventService) *BatchControl {
+	return &BatchControl{
+		stores: stores,
+		events: events,
+	}
+}
+
+// As returns a copy of the control that applies batches for the named user: records created without an owner
+// belong to the user, and each operation is checked against the user's role in the planners it touches.
+// The roles are read in the transaction of the batch, so a batch may create a planner and fill it.
+func (c *BatchControl) As(user string) *BatchControl {
+	scoped := *c
+	scoped.user = user
+	return &scoped
+}
+
+// WithJournal returns a copy of the control that records the changes of its batches in journal, once they are committed,
+// so that their user can undo them.
+func (c *BatchControl) WithJournal(journal *services.JournalService) *BatchControl {
+	scoped := *c
+	scoped.journal = journal
+	return &scoped
+}
+
+// batchControls are the controls that apply the operations of one batch, bound to its transaction.
+type batchControls struct {
+	task    *TaskControl
+	goal    *GoalControl
+	plan    *PlanControl
+	planner *PlannerControl
+	user    string
+	refs    map[string]string
+}
+
+// Batch applies the operations of the request in order, in one transaction. If an operation fails,
+// none of the changes of the batch are stored and a *BatchError names the operation.
+func (c *BatchControl) Batch(req *BatchRequest) (*BatchResponse, error) {
+	if len(req.Operations) > MaxBatchOperations {
+		return nil, fmt.Errorf("%w: more than %d operations", ErrInvalidBatch, MaxBatchOperations)
+	}
+	events := c.events.Deferred()
+	journal := c.journal.Deferred()
+	res := &BatchResponse{Results: make([]*BatchResult, 0, len(req.Operations))}
+	err := c.stores.Transaction(func(stores *store.Stores) error {
+		b := c.controls(stores, events, journal)
+		for i, op := range req.Operations {
+			result, err := b.apply(op)
+			if err != nil {
+				return &BatchError{Index: i, Op: op, Err: err}
+			}
+			res.Results = append(res.Results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	events.Flush()
+	journal.Flush()
+	return res, nil
+}
+
+// controls builds the controls of a batch on the stores of its transaction.
+func (c *BatchControl) controls(stores *store.Stores, events *services.EventService, journal *services.JournalService) *batchControls {
+	b := &batchControls{
+		task:    NewTaskControl(services.NewTaskService(stores.Task).WithEvents(events).WithJournal(journal)),
+		goal:    NewGoalControl(services.NewGoalService(stores.Goal).WithEvents(events).WithJournal(journal)),
+		plan:    NewPlanControl(services.NewPlanService(stores.Plan).WithEvents(events).WithJournal(journal)),
+		planner: NewPlannerControl(services.NewPlannerService(stores.Planner).WithEvents(events).WithJournal(journal)),
+		user:    c.user,
+		refs:    map[string]string{},
+	}
+	if c.user != "" {
+		access := services.NewAccessService(stores.Planner, stores.Goal, stores.Plan, stores.Task)
+		b.task = b.task.As(access, c.user)
+		b.goal = b.goal.As(access, c.user)
+		b.plan = b.plan.As(access, c.user)
+		b.planner = b.planner.As(access, c.user)
+	}
+	return b
+}
+
+// apply resolves the references of an operation and applies it.
+func (b *batchControls) apply(op *BatchOperation) (*BatchResult, error) {
+	id, err := b.resolve(op.Id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.resolveData(op.Data)
+	if err != nil {
+		return nil, err
+	}
+	result := &BatchResult{Op: op.Op, Resource: op.Resource, Ref: op.Ref, Id: id}
+	switch op.Op {
+	case BatchCreate:
+		if op.Ref != "" {
+			if _, ok := b.refs[op.Ref]; ok {
+				return nil, fmt.Errorf("%w: ref %q is already defined", ErrInvalidBatch, op.Ref)
+			}
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("%w: create needs data", ErrInvalidBatch)
+		}
+		if result.Id, err = b.create(op.Resource, data); err != nil {
+			return nil, err
+		}
+		if op.Ref != "" {
+			b.refs[op.Ref] = result.Id
+		}
+	case BatchUpdate:
+		if id == "" || len(data) == 0 {
+			return nil, fmt.Errorf("%w: update needs an id and data", ErrInvalidBatch)
+		}
+		if result.Revision, err = b.update(op.Resource, id, data, op.Revision); err != nil {
+			return nil, err
+		}
+	case BatchDelete:
+		if id == "" {
+			return nil, fmt.Errorf("%w: delete needs an id", ErrInvalidBatch)
+		}
+		if err := b.delete(op.Resource, id, op.Revision); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidBatch, op.Op)
+	}
+	return result, nil
+}
+
+// create decodes data into the create request of the resource and creates the record, returning its id.
+func (b *batchControls) create(resource string, data json.RawMessage) (string, error) {
+	decode := func(req interface{}) error {
+		if err := json.Unmarshal(data, req); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBatch, err)
+		}
+		return nil
+	}
+	switch resource {
+	case "task":
+		var req CreateTaskRequest
+		if err := decode(&req); err != nil {
+			return "", err
+		}
+		if req.Owner == "" {
+			req.Owner = b.user
+		}
+		res, err := b.task.CreateTask(req)
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+	case "goal":
+		var req CreateGoalRequest
+		if err := decode(&req); err != nil {
+			return "", err
+		}
+		res, err := b.goal.CreateGoal(&req)
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+	case "plan":
+		var req CreatePlanRequest
+		if err := decode(&req); err != nil {
+			return "", err
+		}
+		res, err := b.plan.CreatePlan(&req)
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+	case "planner":
+		var req CreatePlannerRequest
+		if err := decode(&req); err != nil {
+			return "", err
+		}
+		if req.UserId == "" {
+			req.UserId = b.user
+		}
+		res, err := b.planner.CreatePlanner(&req)
+		if err != nil {
+			return "", err
+		}
+		return res.Id, nil
+	}
+	return "", fmt.Errorf("%w: unknown resource %q", ErrInvalidBatch, resource)
+}
+
+// update applies data as a merge patch to the record with the given id and returns its new revision.
+func (b *batchControls) update(resource, id string, patch json.RawMessage, revision int) (int, error) {
+	switch resource {
+	case "task":
+		res, err := b.task.PatchTask(&PatchTaskRequest{ID: id, Patch: patch, Revision: revision})
+		if err != nil {
+			return 0, err
+		}
+		return res.Revision, nil
+	case "goal":
+		res, err := b.goal.PatchGoal(&PatchGoalRequest{Id: id, Patch: patch, Revision: revision})
+		if err != nil {
+			return 0, err
+		}
+		return res.Goal.Revision, nil
+	case "plan":
+		res, err := b.plan.PatchPlan(&PatchPlanRequest{Id: id, Patch: patch, Revision: revision})
+		if err != nil {
+			return 0, err
+		}
+		return res.Plan.Revision, nil
+	case "planner":
+		res, err := b.planner.PatchPlanner(&PatchPlannerRequest{Id: id, Patch: patch, Revision: revision})
+		if err != nil {
+			return 0, err
+		}
+		return res.Revision, nil
+	}
+	return 0, fmt.Errorf("%w: unknown resource %q", ErrInvalidBatch, resource)
+}
+
+// delete deletes the record with the given id.
+func (b *batchControls) delete(resource, id string, revision int) error {
+	switch resource {
+	case "task":
+		return b.task.DeleteTask(&DeleteTaskRequest{ID: id, Revision: revision})
+	case "goal":
+		return b.goal.DeleteGoal(&DeleteGoalRequest{Id: id, Revision: revision})
+	case "plan":
+		return b.plan.DeletePlan(&DeletePlanRequest{Id: id, Revision: revision})
+	case "planner":
+		return b.planner.DeletePlanner(&DeletePlannerRequest{Id: id, Revision: revision})
+	}
+	return fmt.Errorf("%w: unknown resource %q", ErrInvalidBatch, resource)
+}
+
+// resolve replaces a reference "${name}" with the id of the record the create operation with that ref made.
+// Other values are returned unchanged.
+func (b *batchControls) resolve(value string) (string, error) {
+	if !strings.HasPrefix(value, "${") || !strings.HasSuffix(value, "}") {
+		return value, nil
+	}
+	name := value[2 : len(value)-1]
+	id, ok := b.refs[name]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown ref %q", ErrInvalidBatch, name)
+	}
+	return id, nil
+}
+
+// resolveData replaces the references among the string values of data, at any depth.
+func (b *batchControls) resolveData(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 || !strings.Contains(string(data), "${") {
+		return data, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBatch, err)
+	}
+	var walk func(v interface{}) (interface{}, error)
+	walk = func(v interface{}) (interface{}, error) {
+		var err error
+		switch v := v.(type) {
+		case string:
+			return b.resolve(v)
+		case map[string]interface{}:
+			for key, child := range v {
+				if v[key], err = walk(child); err != nil {
+					return nil, err
+				}
+			}
+		case []interface{}:
+			for i, child := range v {
+				if v[i], err = walk(child); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return v, nil
+	}
+	resolved, err := walk(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resolved)
+}
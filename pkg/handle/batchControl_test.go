@@ -0,0 +1,93 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBatchControl_Batch(t *testing.T) {
+	_, db := SetupPlannerT(t)
+	defer TeardownPlannerT(t, db)
+	plannerStore := inmemory.NewInMemoryPlannerStore(db)
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	planStore := inmemory.NewInMemoryPlanStore(db)
+	taskStore := inmemory.NewInMemoryTaskStore(db)
+	access := services.NewAccessService(plannerStore, goalStore, planStore, taskStore)
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	sub := events.Subscribe(services.EventFilter{})
+	defer sub.Close()
+	alice := NewBatchControl(inmemory.NewInMemoryTransactor(db), events).As("alice")
+
+	res, err := alice.Batch(&BatchRequest{Operations: []*BatchOperation{
+		{Op: BatchCreate, Resource: "planner", Ref: "work", Data: json.RawMessage(`{"title": "Work"}`)},
+		{Op: BatchCreate, Resource: "goal", Ref: "ship", Data: json.RawMessage(`{"objective": "Ship", "deadline": "2030-01-01", "planner_id": "${work}"}`)},
+		{Op: BatchCreate, Resource: "plan", Ref: "release", Data: json.RawMessage(`{"plan_name": "Release", "plan_date": "2029-12-01", "plan_time": "09:00", "GoalId": "${ship}"}`)},
+		{Op: BatchCreate, Resource: "task", Ref: "tag", Data: json.RawMessage(`{"title": "Tag", "plan_id": "${release}"}`)},
+		{Op: BatchUpdate, Resource: "task", Id: "${tag}", Data: json.RawMessage(`{"started": true}`), Revision: 1},
+	}})
+	if err != nil {
+		t.Fatalf("failed to apply batch: %v", err)
+	}
+	if !assert.Len(t, res.Results, 5) {
+		return
+	}
+	assert.Equal(t, 2, res.Results[4].Revision)
+	task, err := taskStore.GetTask(res.Results[3].Id)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "alice", task.Owner)
+	assert.True(t, task.Started)
+	plan, err := planStore.GetPlan(res.Results[2].Id)
+	if err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	assert.Equal(t, res.Results[1].Id, plan.GoalId)
+
+	// the events are published after the commit, in the order of the operations
+	for _, want := range []string{"planner.created", "goal.created", "plan.created", "task.created", "task.updated"} {
+		event := <-sub.C
+		assert.Equal(t, want, event.Type)
+		assert.Equal(t, res.Results[0].Id, event.PlannerId)
+	}
+
+	// a failing operation rolls back the whole batch
+	_, err = alice.Batch(&BatchRequest{Operations: []*BatchOperation{
+		{Op: BatchCreate, Resource: "task", Data: json.RawMessage(`{"title": "Lost", "plan_id": "${release}"}`)},
+	}})
+	assert.True(t, errors.Is(err, ErrInvalidBatch))
+	_, err = alice.Batch(&BatchRequest{Operations: []*BatchOperation{
+		{Op: BatchCreate, Resource: "task", Data: json.RawMessage(`{"title": "Lost"}`)},
+		{Op: BatchUpdate, Resource: "goal", Id: res.Results[1].Id, Data: json.RawMessage(`{"objective": "Ship it"}`)},
+		{Op: BatchUpdate, Resource: "task", Id: task.ID, Data: json.RawMessage(`{"completed": true}`), Revision: 1},
+	}})
+	var batchErr *BatchError
+	if assert.True(t, errors.As(err, &batchErr)) {
+		assert.Equal(t, 2, batchErr.Index)
+		assert.True(t, errors.Is(err, store.ErrRevisionMismatch))
+	}
+	_, err = taskStore.GetTaskByTitle("Lost")
+	assert.True(t, errors.Is(err, storm.ErrNotFound))
+	goal, err := goalStore.GetGoal(res.Results[1].Id)
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+	assert.Equal(t, "Ship", goal.Objective)
+	select {
+	case event := <-sub.C:
+		t.Fatalf("unexpected event %s from a rolled back batch", event.Type)
+	default:
+	}
+
+	// bob has no role in alice's planner
+	_, err = alice.As("bob").Batch(&BatchRequest{Operations: []*BatchOperation{
+		{Op: BatchDelete, Resource: "task", Id: task.ID},
+	}})
+	assert.True(t, errors.Is(err, services.ErrForbidden))
+}
@@ -0,0 +1,63 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+)
+
+// EventControl represents a controller that provides access to the events recorded by the services.
+type EventControl struct {
+	Service *services.EventService
+}
+
+// NewEventControl creates a new instance of EventControl with the provided EventService.
+func NewEventControl(service *services.EventService) *EventControl {
+	return &EventControl{
+		Service: service,
+	}
+}
+
+// As returns a copy of the control whose service only delivers the events the named user can view.
+// See services.EventService.As.
+func (c *EventControl) As(access *services.AccessService, user string) *EventControl {
+	return &EventControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
+// StreamEventsRequest selects the events of a stream.
+//   - After: the id of the last event received before a reconnect; the logged events after it are sent first.
+//     With 0 only the events published from now on are sent.
+//   - PlannerId: only events about records of this planner.
+//   - Types: only events of these types, such as "task.created", or about these resources, such as "goal".
+type StreamEventsRequest struct {
+	After     int      `json:"after,omitempty"`
+	PlannerId string   `json:"planner_id,omitempty"`
+	Types     []string `json:"types,omitempty"`
+}
+
+// filter returns the EventFilter of the request.
+func (req *StreamEventsRequest) filter() services.EventFilter {
+	return services.EventFilter{
+		PlannerId: req.PlannerId,
+		Types:     req.Types,
+	}
+}
+
+// StreamEvents subscribes to the events selected by the request. It returns the logged events after req.After,
+// if any, which the caller should send first, and a subscription to the events published from now on.
+// Events of the subscription that are already among the logged events must be skipped by their id.
+// The subscription must be closed when the stream ends.
+func (c *EventControl) StreamEvents(req *StreamEventsRequest) ([]*models.Event, *services.Subscription, error) {
+	// subscribe before reading the log, so that no event falls between the two
+	sub := c.Service.Subscribe(req.filter())
+	if req.After <= 0 {
+		return nil, sub, nil
+	}
+	logged, err := c.Service.ListEvents(req.After, 0, req.filter())
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+	return logged, sub, nil
+}
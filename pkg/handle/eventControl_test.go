@@ -0,0 +1,66 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventControl_StreamEvents(t *testing.T) {
+	_, db := SetupPlannerT(t)
+	defer TeardownPlannerT(t, db)
+	plannerStore := inmemory.NewInMemoryPlannerStore(db)
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	access := services.NewAccessService(plannerStore, goalStore, inmemory.NewInMemoryPlanStore(db), inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	plannerControl := NewPlannerControl(services.NewPlannerService(plannerStore).WithEvents(events))
+	goalControl := NewGoalControl(services.NewGoalService(goalStore).WithEvents(events))
+	eventControl := NewEventControl(events)
+
+	bob := eventControl.As(access, "bob")
+	_, live, err := bob.StreamEvents(&StreamEventsRequest{})
+	if err != nil {
+		t.Fatalf("failed to stream events: %v", err)
+	}
+	defer live.Close()
+
+	planner, err := plannerControl.CreatePlanner(&CreatePlannerRequest{Title: "Team", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	if _, err := goalControl.CreateGoal(&CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-01", PlannerId: planner.Id}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if _, err := goalControl.CreateGoal(&CreateGoalRequest{Objective: "Loose", Deadline: "2030-01-01"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+
+	// bob is not a member of the planner, so only the goal outside it reaches bob
+	event := <-live.C
+	assert.Equal(t, "goal.created", event.Type)
+	assert.Empty(t, event.PlannerId)
+
+	logged, sub, err := eventControl.StreamEvents(&StreamEventsRequest{After: 1, PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to stream events: %v", err)
+	}
+	sub.Close()
+	if assert.Len(t, logged, 1) {
+		assert.Equal(t, "goal.created", logged[0].Type)
+		assert.Equal(t, planner.Id, logged[0].PlannerId)
+	}
+
+	if _, err := plannerControl.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "bob", Role: models.RoleViewer}); err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	event = <-live.C
+	assert.Equal(t, "planner.updated", event.Type)
+	logged, sub, err = bob.StreamEvents(&StreamEventsRequest{After: 1, Types: []string{"goal"}})
+	if err != nil {
+		t.Fatalf("failed to stream events: %v", err)
+	}
+	sub.Close()
+	assert.Len(t, logged, 2)
+}
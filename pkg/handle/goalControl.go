@@ -1,9 +1,11 @@
 package handle
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
+	"time"
 )
 
 // GoalControl represents a controller that provides methods to manage goals.
@@ -18,6 +20,14 @@ func NewGoalControl(service *services.GoalService) *GoalControl {
 	}
 }
 
+// As returns a copy of the control whose service acts for the named user, checking each request against
+// the user's role in the planners the goals belong to. See services.GoalService.As.
+func (c *GoalControl) As(access *services.AccessService, user string) *GoalControl {
+	return &GoalControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
 // CreateGoalRequest represents a request to create a goal.
 //
 // Fields:
@@ -61,11 +71,13 @@ func (c *GoalControl) CreateGoal(req *CreateGoalRequest) (*CreateGoalResponse, e
 
 // UpdateGoalRequest represents a request to update a goal.
 // It contains the ID, objective, deadline, and planner ID of the goal to be updated.
+// Revision is the revision of the goal the update is based on; leave it at 0 to skip the revision check.
 type UpdateGoalRequest struct {
 	Id        string `json:"id"`
 	Objective string `json:"objective"`
 	Deadline  string `json:"deadline"`
 	PlannerId string `json:"planner_id"`
+	Revision  int    `json:"revision,omitempty"`
 }
 
 // UpdateGoalResponse represents the response object of the UpdateGoal API.
@@ -78,7 +90,8 @@ type UpdateGoalResponse struct {
 // The deadline provided in the request will be converted to time.Time format.
 // The goal will be created using the GenerateGoalInstance method of the Goal model.
 // The UpdateGoal method of the GoalService will be called to update the goal.
-// If any error occurs during the goal update process, it will be returned.
+// If any error occurs during the goal update process, it will be returned,
+// including store.ErrRevisionMismatch if the goal is no longer at req.Revision.
 // Example usage:
 //
 //	req := &UpdateGoalRequest{
@@ -101,7 +114,7 @@ func (c *GoalControl) UpdateGoal(req *UpdateGoalRequest) error {
 
 	// for now, updategoal sets the createdat and updatedat fields to the current time
 	// this is because the frontend does not have a way to set these fields
-	err = c.Service.UpdateGoal(goal)
+	err = c.Service.UpdateGoal(goal, req.Revision)
 	if err != nil {
 		return err
 	}
@@ -109,9 +122,10 @@ func (c *GoalControl) UpdateGoal(req *UpdateGoalRequest) error {
 }
 
 // DeleteGoalRequest represents a request to delete a goal.
-// It contains the ID of the goal to be deleted.
+// It contains the ID of the goal to be deleted and, optionally, the revision the caller last read.
 type DeleteGoalRequest struct {
-	Id string `json:"id"`
+	Id       string `json:"id"`
+	Revision int    `json:"revision,omitempty"`
 }
 
 // DeleteGoal deletes a goal with the specified ID.
@@ -119,7 +133,7 @@ type DeleteGoalRequest struct {
 // The ID of the goal to be deleted is specified in the req parameter of type DeleteGoalRequest.
 // If the goal is successfully deleted, nil is returned. Otherwise, an error is returned.
 func (c *GoalControl) DeleteGoal(req *DeleteGoalRequest) error {
-	return c.Service.DeleteGoal(req.Id)
+	return c.Service.DeleteGoal(req.Id, req.Revision)
 }
 
 // GetGoalRequest represents a request to get a goal by its ID.
@@ -245,8 +259,10 @@ func (c *GoalControl) GetGoalsByPlannerId(req *GetGoalsByPlannerIdRequest) (*Get
 // ListGoalsResponse represents a response type that contains a list of goals.
 // The `Goals` field is a slice of pointers to `models.Goal` objects.
 // It is tagged with `"json:"goals""` to specify the JSON key for this field when serializing or deserializing to JSON.
+// NextCursor is set when more goals are available and is passed back as ListRequest.Cursor to fetch them.
 type ListGoalsResponse struct {
-	Goals []*models.Goal `json:"goals"`
+	Goals      []*models.Goal `json:"goals"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 // get goal id and objective of each goal
@@ -261,6 +277,68 @@ func (c *GoalControl) ListGoals() (*ListGoalsResponse, error) {
 	}, nil
 }
 
+// FindGoals retrieves one page of goals matching the filters of the request.
+// Filtering, ordering and paging are pushed down to the goal store.
+func (c *GoalControl) FindGoals(req *ListRequest) (*ListGoalsResponse, error) {
+	opts, err := req.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	goals, err := c.Service.FindGoals(opts)
+	if err != nil {
+		return nil, err
+	}
+	n, next := req.nextCursor(opts, len(goals))
+	return &ListGoalsResponse{
+		Goals:      goals[:n],
+		NextCursor: next,
+	}, nil
+}
+
+// PatchGoalRequest represents a request to partially update a goal.
+// Patch is a JSON Merge Patch (RFC 7396) applied to the goal's JSON representation;
+// only the fields present in the patch are changed.
+// The deadline may be given in the format "YYYY-MM-DD", as in CreateGoalRequest.
+// Revision is the revision of the goal the patch is based on; leave it at 0 to skip the revision check.
+type PatchGoalRequest struct {
+	Id       string          `json:"id"`
+	Patch    json.RawMessage `json:"patch"`
+	Revision int             `json:"revision,omitempty"`
+}
+
+// PatchGoal applies the merge patch of the request to the goal with the given ID.
+// Unlike UpdateGoal, the status of the goal and the fields missing from the patch are kept.
+// The ID, creation time and plans of the goal cannot be changed and GoalUpdatedAt is set to the current time.
+// It returns the updated goal, ErrInvalidPatch if the patch does not fit a goal,
+// or store.ErrRevisionMismatch if the goal is no longer at req.Revision.
+//
+// Example:
+//
+//	res, err := goalControl.PatchGoal(&PatchGoalRequest{
+//		Id:    "123456",
+//		Patch: json.RawMessage(`{"goal_status": "In Progress", "deadline": "2024-12-31"}`),
+//	})
+func (c *GoalControl) PatchGoal(req *PatchGoalRequest) (*GetGoalResponse, error) {
+	goal, err := c.Service.GetGoal(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	patched := &models.Goal{}
+	if err := applyMergePatch(goal, req.Patch, patched, map[string]string{"deadline": "2006-01-02"}); err != nil {
+		return nil, err
+	}
+	patched.Id = goal.Id
+	patched.Plans = goal.Plans
+	patched.GoalCreatedAt = goal.GoalCreatedAt
+	patched.GoalUpdatedAt = time.Now()
+	if err := c.Service.UpdateGoal(patched, patchRevision(goal.Revision, req.Revision)); err != nil {
+		return nil, err
+	}
+	return &GetGoalResponse{
+		Goal: patched,
+	}, nil
+}
+
 // generateGoalUUID generates a UUID string and returns it along with any error that occured during the process.
 // It uses the "github.com/google/uuid" package to generate a random UUID.
 func generateGoalUUID() (string, error) {
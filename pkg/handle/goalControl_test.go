@@ -48,3 +48,81 @@ func TestGoalControl_CreateGoal(t *testing.T) {
 
 	assert.NotEmpty(t, res.ID)
 }
+
+func TestGoalControl_FindGoals(t *testing.T) {
+	goalControl, db := SetupGoalT(t)
+	defer TeardownGoalT(t, db)
+	for _, deadline := range []string{"2021-03-01", "2021-01-01", "2021-02-01", "2022-01-01"} {
+		_, err := goalControl.CreateGoal(&CreateGoalRequest{
+			Objective: "objective " + deadline,
+			Deadline:  deadline,
+			PlannerId: "planner1",
+		})
+		if err != nil {
+			t.Fatalf("failed to create goal: %v", err)
+		}
+	}
+
+	req := &ListRequest{Limit: 2, Sort: "deadline", DeadlineBefore: "2021-12-31"}
+	res, err := goalControl.FindGoals(req)
+	if err != nil {
+		t.Fatalf("failed to find goals: %v", err)
+	}
+	assert.Len(t, res.Goals, 2)
+	assert.Equal(t, "objective 2021-01-01", res.Goals[0].Objective)
+	assert.Equal(t, "objective 2021-02-01", res.Goals[1].Objective)
+	assert.NotEmpty(t, res.NextCursor)
+
+	req.Cursor = res.NextCursor
+	res, err = goalControl.FindGoals(req)
+	if err != nil {
+		t.Fatalf("failed to find goals: %v", err)
+	}
+	assert.Len(t, res.Goals, 1)
+	assert.Equal(t, "objective 2021-03-01", res.Goals[0].Objective)
+	assert.Empty(t, res.NextCursor)
+
+	_, err = goalControl.FindGoals(&ListRequest{Sort: "unknown"})
+	assert.Error(t, err)
+	_, err = goalControl.FindGoals(&ListRequest{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestGoalControl_PatchGoal(t *testing.T) {
+	goalControl, db := SetupGoalT(t)
+	defer TeardownGoalT(t, db)
+	created, err := goalControl.CreateGoal(&CreateGoalRequest{
+		Objective: "objective",
+		Deadline:  "2021-01-01",
+		PlannerId: "planner1",
+	})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	before, err := goalControl.GetGoal(&GetGoalRequest{Id: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+
+	res, err := goalControl.PatchGoal(&PatchGoalRequest{
+		Id:    created.ID,
+		Patch: []byte(`{"goal_status": "In Progress", "deadline": "2021-06-30", "planner_id": null}`),
+	})
+	if err != nil {
+		t.Fatalf("failed to patch goal: %v", err)
+	}
+	assert.Equal(t, "objective", res.Goal.Objective)
+	assert.Equal(t, "In Progress", res.Goal.GoalStatus)
+	assert.Equal(t, "2021-06-30", res.Goal.Deadline.Format("2006-01-02"))
+	assert.Empty(t, res.Goal.PlannerId)
+
+	after, err := goalControl.GetGoal(&GetGoalRequest{Id: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+	assert.Equal(t, "In Progress", after.Goal.GoalStatus)
+	assert.True(t, before.Goal.GoalCreatedAt.Equal(after.Goal.GoalCreatedAt))
+
+	_, err = goalControl.PatchGoal(&PatchGoalRequest{Id: created.ID, Patch: []byte(`["not", "an", "object"]`)})
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
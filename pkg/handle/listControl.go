@@ -0,0 +1,107 @@
+package handle
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/ooyeku/flow/pkg/store"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a ListRequest carries a cursor that was not produced by a previous list call.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListRequest represents a request to list tasks, goals, plans or planners one page at a time.
+// Every field is optional; an empty ListRequest lists every record.
+//
+// Fields:
+// - Limit: the maximum number of records in the page.
+// - Cursor: the NextCursor of the previous page, or empty for the first page.
+// - Sort: the json name of the field to order by, prefixed with "-" for descending order.
+// - Owner: only list records owned by this user.
+// - Status: only list records with this status.
+// - DeadlineBefore: only list goals due before this date, in the format "YYYY-MM-DD".
+// - PlannerId: only list goals of this planner.
+// - GoalId: only list plans of this goal.
+// - Ids, PlannerIds, GoalIds, PlanIds: only list the records with these ids, the goals of these planners,
+// the plans of these goals or the tasks of these plans. They are not part of the query string of the list endpoints;
+// Go callers such as the GraphQL resolvers use them to load the records related to many others in one call.
+type ListRequest struct {
+	Limit          int      `json:"limit"`
+	Cursor         string   `json:"cursor"`
+	Sort           string   `json:"sort"`
+	Owner          string   `json:"owner"`
+	Status         string   `json:"status"`
+	DeadlineBefore string   `json:"deadline_before"`
+	PlannerId      string   `json:"planner_id"`
+	GoalId         string   `json:"goal_id"`
+	Ids            []string `json:"-"`
+	PlannerIds     []string `json:"-"`
+	GoalIds        []string `json:"-"`
+	PlanIds        []string `json:"-"`
+}
+
+// listOptions converts the request into store.ListOptions.
+// When a limit is set, one extra record is requested so that nextCursor can tell whether another page exists.
+func (req *ListRequest) listOptions() (*store.ListOptions, error) {
+	opts := &store.ListOptions{}
+	if req == nil {
+		return opts, nil
+	}
+	offset, err := decodeCursor(req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	opts.Offset = offset
+	if req.Limit > 0 {
+		opts.Limit = req.Limit + 1
+	}
+	opts.Sort = req.Sort
+	opts.Owner = req.Owner
+	opts.Status = req.Status
+	opts.PlannerId = req.PlannerId
+	opts.GoalId = req.GoalId
+	opts.Ids = req.Ids
+	opts.PlannerIds = req.PlannerIds
+	opts.GoalIds = req.GoalIds
+	opts.PlanIds = req.PlanIds
+	if req.DeadlineBefore != "" {
+		deadline, err := time.Parse("2006-01-02", req.DeadlineBefore)
+		if err != nil {
+			return nil, err
+		}
+		opts.DeadlineBefore = deadline
+	}
+	return opts, nil
+}
+
+// nextCursor returns the number of records that belong to the page and the cursor of the following page.
+// n is the number of records the store returned for opts; the cursor is empty on the last page.
+func (req *ListRequest) nextCursor(opts *store.ListOptions, n int) (int, string) {
+	if req == nil || req.Limit <= 0 || n <= req.Limit {
+		return n, ""
+	}
+	return req.Limit, encodeCursor(opts.Offset + req.Limit)
+}
+
+// encodeCursor turns an offset into an opaque cursor string.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor turns a cursor produced by encodeCursor back into an offset.
+// An empty cursor decodes to offset 0.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
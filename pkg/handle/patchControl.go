@@ -0,0 +1,86 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/store"
+	"time"
+)
+
+// ErrInvalidPatch is returned when a merge patch is not a JSON object or does not fit the resource it is applied to.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// applyMergePatch applies a JSON Merge Patch (RFC 7396) to the JSON representation of original
+// and decodes the result into patched, which should point to a zero value of the same type.
+// Members of the patch set to null are removed, so the corresponding fields of patched keep their zero value.
+//
+// dateFields maps the names of time fields to a shorthand layout, such as "2006-01-02" for a deadline.
+// String values of these fields in the patch are parsed with that layout and converted to RFC 3339,
+// so clients can send the same formats the create requests accept.
+func applyMergePatch(original interface{}, patch []byte, patched interface{}, dateFields map[string]string) error {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	patchObj, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+	for field, layout := range dateFields {
+		value, ok := patchObj[field].(string)
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(layout, value); err == nil {
+			patchObj[field] = t.Format(time.RFC3339Nano)
+		}
+	}
+
+	raw, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(doc, patchObj))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, patched); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return nil
+}
+
+// mergePatch implements the MergePatch algorithm of RFC 7396 on decoded JSON values.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+		} else {
+			targetObj[name] = mergePatch(targetObj[name], value)
+		}
+	}
+	return targetObj
+}
+
+// patchRevision returns the revision a patched record is written back with.
+// A patch is read, merged and written in separate steps, so when the client did not ask for a revision check
+// the revision that was read is used; a concurrent change in between then fails instead of being overwritten.
+func patchRevision(read, requested int) int {
+	if requested == store.AnyRevision {
+		return read
+	}
+	return requested
+}
@@ -1,9 +1,11 @@
 package handle
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
+	"time"
 )
 
 // PlanControl is a type that provides control operations for managing plans.
@@ -51,6 +53,14 @@ func NewPlanControl(service *services.PlanService) *PlanControl {
 	}
 }
 
+// As returns a copy of the control whose service acts for the named user, checking each request against
+// the user's role in the planners the plans belong to. See services.PlanService.As.
+func (c *PlanControl) As(access *services.AccessService, user string) *PlanControl {
+	return &PlanControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
 // CreatePlanRequest is a type that represents the request to create a new plan.
 // The CreatePlanRequest type has the following fields:
 // - PlanName: the name of the plan.
@@ -128,6 +138,8 @@ type UpdatePlanRequest struct {
 	PlanDate        string `json:"plan_date"`
 	PlanTime        string `json:"plan_time"`
 	GoalId          string `json:"goal_id"`
+	// revision of the plan the update is based on; 0 skips the revision check.
+	Revision int `json:"revision,omitempty"`
 }
 
 // UpdatePlan updates an existing plan with the provided request. It converts the PlanDate and PlanTime strings
@@ -136,7 +148,7 @@ type UpdatePlanRequest struct {
 // GoalId, PlanDate, and PlanTime values.
 // It updates the GoalId field of the plan instance with the GoalId value from the request.
 // Finally, it calls the UpdatePlan method of the PlanService stored in the PlanControl struct, passing the updated plan as the argument.
-// It returns an error if there was a problem updating the plan.
+// It returns store.ErrRevisionMismatch if the plan is no longer at req.Revision, or an error if there was a problem updating the plan.
 func (c *PlanControl) UpdatePlan(req *UpdatePlanRequest) error {
 	m := &models.Plan{}
 	// convert planDate to time.Time
@@ -151,23 +163,25 @@ func (c *PlanControl) UpdatePlan(req *UpdatePlanRequest) error {
 	}
 	plan := m.GeneratePlanInstance(req.Id, req.PlanName, req.PlanDescription, planDate, planTime, req.GoalId)
 	plan.GoalId = req.GoalId
-	return c.Service.UpdatePlan(plan)
+	return c.Service.UpdatePlan(plan, req.Revision)
 }
 
 // DeletePlanRequest is a type that represents a request to delete a plan.
 // The DeletePlanRequest type has a field Id of type string, which represents the ID of the plan to be deleted.
+// The optional Revision is the revision the caller last read; 0 skips the revision check.
 // Usage Example:
 //
 //	req := DeletePlanRequest{
 //	    Id: "example-id",
 //	}
 type DeletePlanRequest struct {
-	Id string `json:"id"`
+	Id       string `json:"id"`
+	Revision int    `json:"revision,omitempty"`
 }
 
 // DeletePlan deletes the plan with the specified ID by calling the DeletePlan method of the PlanService stored in the PlanControl struct and passing the ID as the argument.
 func (c *PlanControl) DeletePlan(req *DeletePlanRequest) error {
-	return c.Service.DeletePlan(req.Id)
+	return c.Service.DeletePlan(req.Id, req.Revision)
 }
 
 // GetPlanRequest is a type used to request the retrieval of a plan based on its ID.
@@ -291,7 +305,8 @@ func (c *PlanControl) GetPlansByGoal(req *GetPlansByGoalRequest) (*GetPlansByGoa
 //	  }, nil
 //	}
 type ListPlansResponse struct {
-	Plans []*models.Plan `json:"plans"`
+	Plans      []*models.Plan `json:"plans"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 // ListPlans returns a ListPlansResponse containing a list of plans retrieved from the PlanService.
@@ -312,6 +327,61 @@ func (c *PlanControl) ListPlans() (*ListPlansResponse, error) {
 // It uses the uuid.NewRandom function from the "github.com/google/uuid" package to generate a random UUID.
 // If an error occurs during the generation of the UUID, the function returns an empty string and the error.
 // Otherwise, it returns the generated UUID as a string and nil error.
+func (c *PlanControl) FindPlans(req *ListRequest) (*ListPlansResponse, error) {
+	opts, err := req.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	plans, err := c.Service.FindPlans(opts)
+	if err != nil {
+		return nil, err
+	}
+	n, next := req.nextCursor(opts, len(plans))
+	return &ListPlansResponse{
+		Plans:      plans[:n],
+		NextCursor: next,
+	}, nil
+}
+
+// PatchPlanRequest represents a request to partially update a plan.
+// Patch is a JSON Merge Patch (RFC 7396) applied to the plan's JSON representation;
+// only the fields present in the patch are changed.
+// The plan_date and plan_time may be given in the formats "YYYY-MM-DD" and "HH:MM", as in CreatePlanRequest.
+// Revision is the revision of the plan the patch is based on; leave it at 0 to skip the revision check.
+type PatchPlanRequest struct {
+	Id       string          `json:"id"`
+	Patch    json.RawMessage `json:"patch"`
+	Revision int             `json:"revision,omitempty"`
+}
+
+// PatchPlan applies the merge patch of the request to the plan with the given ID.
+// The name, description, date, time, status and goal of the plan can be patched; its ID, revision,
+// tasks and creation time cannot be changed, and PlanUpdatedAt is set to the current time.
+// It returns the updated plan, ErrInvalidPatch if the patch cannot be decoded or does not fit a plan,
+// or store.ErrRevisionMismatch if the plan is no longer at req.Revision.
+func (c *PlanControl) PatchPlan(req *PatchPlanRequest) (*GetPlanResponse, error) {
+	plan, err := c.Service.GetPlan(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	patched := &models.Plan{}
+	dateFields := map[string]string{"plan_date": "2006-01-02", "plan_time": "15:04"}
+	if err := applyMergePatch(plan, req.Patch, patched, dateFields); err != nil {
+		return nil, err
+	}
+	// id, tasks and creation time are not patchable
+	patched.Id = plan.Id
+	patched.Tasks = plan.Tasks
+	patched.PlanCreatedAt = plan.PlanCreatedAt
+	patched.PlanUpdatedAt = time.Now()
+	if err := c.Service.UpdatePlan(patched, patchRevision(plan.Revision, req.Revision)); err != nil {
+		return nil, err
+	}
+	return &GetPlanResponse{
+		Plan: patched,
+	}, nil
+}
+
 func generatePlanUUID() (string, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -1,6 +1,7 @@
 package handle
 
 import (
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
@@ -19,6 +20,14 @@ func NewPlannerControl(service *services.PlannerService) *PlannerControl {
 	}
 }
 
+// As returns a copy of the control whose service acts for the named user, checking each request against
+// the user's role in the planner. See services.PlannerService.As.
+func (c *PlannerControl) As(access *services.AccessService, user string) *PlannerControl {
+	return &PlannerControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
 // CreatePlannerRequest represents a request to create a planner.
 // It contains the title and user ID of the planner.
 type CreatePlannerRequest struct {
@@ -58,13 +67,16 @@ func (c *PlannerControl) CreatePlanner(req *CreatePlannerRequest) (*CreatePlanne
 // - Id: the ID of the planner to update
 // - Title: the new title for the planner
 // - UserId: the new user ID for the planner
+// - Revision: the revision of the planner the update is based on, or 0 to skip the revision check
 type UpdatePlannerRequest struct {
-	Id     string `json:"id"`
-	Title  string `json:"title"`
-	UserId string `json:"user_id"`
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	UserId   string `json:"user_id"`
+	Revision int    `json:"revision,omitempty"`
 }
 
 // UpdatePlanner updates the title and user ID of a planner based on the provided request.
+// It returns store.ErrRevisionMismatch if the planner is no longer at req.Revision.
 func (c *PlannerControl) UpdatePlanner(req *UpdatePlannerRequest) error {
 	planner, err := c.Service.GetPlanner(req.Id)
 	if err != nil {
@@ -72,16 +84,17 @@ func (c *PlannerControl) UpdatePlanner(req *UpdatePlannerRequest) error {
 	}
 	planner.UserId = req.UserId
 	planner.Title = req.Title
-	if err := c.Service.UpdatePlanner(planner); err != nil {
+	if err := c.Service.UpdatePlanner(planner, req.Revision); err != nil {
 		return err
 	}
 	return nil
 }
 
 // DeletePlannerRequest represents a request to delete a planner.
-// It contains the id of the planner to be deleted.
+// It contains the id of the planner to be deleted and, optionally, the revision the caller last read.
 type DeletePlannerRequest struct {
-	Id string `json:"id"`
+	Id       string `json:"id"`
+	Revision int    `json:"revision,omitempty"`
 }
 
 // DeletePlanner deletes a planner based on the provided request ID.
@@ -89,7 +102,7 @@ type DeletePlannerRequest struct {
 // If any error occurs during the process, it returns the error.
 // Otherwise, it returns nil.
 func (c *PlannerControl) DeletePlanner(req *DeletePlannerRequest) error {
-	if err := c.Service.DeletePlanner(req.Id); err != nil {
+	if err := c.Service.DeletePlanner(req.Id, req.Revision); err != nil {
 		return err
 	}
 	return nil
@@ -101,14 +114,16 @@ type GetPlannerRequest struct {
 }
 
 // GetPlannerResponse represents the response object when retrieving a planner.
-// It contains the ID, title, and user ID of the planner.
+// It contains the ID, title, user ID and members of the planner.
 // This type is used in the following methods:
 // - PlannerControl.GetPlanner
 // - PlannerControl.ListPlanners
 type GetPlannerResponse struct {
-	Id     string `json:"id"`
-	Title  string `json:"title"`
-	UserId string `json:"user_id"`
+	Id       string            `json:"id"`
+	Title    string            `json:"title"`
+	UserId   string            `json:"user_id"`
+	Members  map[string]string `json:"members,omitempty"`
+	Revision int               `json:"revision"`
 }
 
 // GetPlanner retrieves a planner based on the provided request.
@@ -121,9 +136,11 @@ func (c *PlannerControl) GetPlanner(req *GetPlannerRequest) (*GetPlannerResponse
 		return nil, err
 	}
 	return &GetPlannerResponse{
-		Id:     planner.Id,
-		Title:  planner.Title,
-		UserId: planner.UserId,
+		Id:       planner.Id,
+		Title:    planner.Title,
+		UserId:   planner.UserId,
+		Members:  planner.Members,
+		Revision: planner.Revision,
 	}, nil
 }
 
@@ -221,7 +238,8 @@ func (c *PlannerControl) GetPlannerByOwner(req *GetPlannerByOwnerRequest) ([]*Ge
 // It has a Planners field which is a slice of GetPlannerResponse structs.
 // The Planners field is tagged as 'planners' in JSON serialization.
 type ListPlannersResponse struct {
-	Planners []*GetPlannerResponse `json:"planners"`
+	Planners   []*GetPlannerResponse `json:"planners"`
+	NextCursor string                `json:"next_cursor,omitempty"`
 }
 
 // ListPlanners retrieves a list of all planners.
@@ -236,9 +254,11 @@ func (c *PlannerControl) ListPlanners() (*ListPlannersResponse, error) {
 	var plannerResponses []*GetPlannerResponse
 	for _, planner := range planners {
 		plannerResponses = append(plannerResponses, &GetPlannerResponse{
-			Id:     planner.Id,
-			Title:  planner.Title,
-			UserId: planner.UserId,
+			Id:       planner.Id,
+			Title:    planner.Title,
+			UserId:   planner.UserId,
+			Members:  planner.Members,
+			Revision: planner.Revision,
 		})
 	}
 	return &ListPlannersResponse{
@@ -249,6 +269,99 @@ func (c *PlannerControl) ListPlanners() (*ListPlannersResponse, error) {
 // generatePlannerUUID generates a new UUID (Universally Unique Identifier) using the `uuid.NewRandom()` function.
 // It returns the generated UUID as a string.
 // If there is an error generating the UUID, it returns an empty string and the error.
+func (c *PlannerControl) FindPlanners(req *ListRequest) (*ListPlannersResponse, error) {
+	opts, err := req.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	planners, err := c.Service.FindPlanners(opts)
+	if err != nil {
+		return nil, err
+	}
+	n, next := req.nextCursor(opts, len(planners))
+	plannerResponses := make([]*GetPlannerResponse, 0, n)
+	for _, planner := range planners[:n] {
+		plannerResponses = append(plannerResponses, &GetPlannerResponse{
+			Id:       planner.Id,
+			Title:    planner.Title,
+			UserId:   planner.UserId,
+			Members:  planner.Members,
+			Revision: planner.Revision,
+		})
+	}
+	return &ListPlannersResponse{
+		Planners:   plannerResponses,
+		NextCursor: next,
+	}, nil
+}
+
+// PatchPlannerRequest represents a request to partially update a planner.
+// Patch is a JSON Merge Patch (RFC 7396) applied to the planner's JSON representation;
+// only the fields present in the patch are changed.
+// Revision is the revision of the planner the patch is based on; leave it at 0 to skip the revision check.
+type PatchPlannerRequest struct {
+	Id       string          `json:"id"`
+	Patch    json.RawMessage `json:"patch"`
+	Revision int             `json:"revision,omitempty"`
+}
+
+// PatchPlanner applies the merge patch of the request to the planner with the given ID.
+// The title, owner and members of the planner can be patched, the latter two by its owner only;
+// its ID, revision and goals cannot be changed.
+// It returns the updated planner, ErrInvalidPatch if the patch cannot be decoded or does not fit a planner,
+// or store.ErrRevisionMismatch if the planner is no longer at req.Revision.
+func (c *PlannerControl) PatchPlanner(req *PatchPlannerRequest) (*GetPlannerResponse, error) {
+	planner, err := c.Service.GetPlanner(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	patched := &models.Planner{}
+	if err := applyMergePatch(planner, req.Patch, patched, nil); err != nil {
+		return nil, err
+	}
+	// id and goals are not patchable
+	patched.Id = planner.Id
+	patched.Goals = planner.Goals
+	if err := c.Service.UpdatePlanner(patched, patchRevision(planner.Revision, req.Revision)); err != nil {
+		return nil, err
+	}
+	return &GetPlannerResponse{
+		Id:       patched.Id,
+		Title:    patched.Title,
+		UserId:   patched.UserId,
+		Members:  patched.Members,
+		Revision: patched.Revision,
+	}, nil
+}
+
+// SharePlannerRequest represents a request to share a planner with a user.
+// - Id: the ID of the planner
+// - User: the name of the user to share the planner with
+// - Role: models.RoleOwner, models.RoleEditor or models.RoleViewer, or empty to stop sharing the planner with the user
+// - Revision: the revision of the planner the change is based on, or 0 to skip the revision check
+type SharePlannerRequest struct {
+	Id       string `json:"id"`
+	User     string `json:"user"`
+	Role     string `json:"role"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// SharePlanner gives a user a role in a planner, or removes the user from its members if req.Role is empty.
+// Only owners of the planner may share it. It returns the updated planner.
+func (c *PlannerControl) SharePlanner(req *SharePlannerRequest) (*GetPlannerResponse, error) {
+	planner, err := c.Service.SharePlanner(req.Id, req.User, req.Role, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+	return &GetPlannerResponse{
+		Id:       planner.Id,
+		Title:    planner.Title,
+		UserId:   planner.UserId,
+		Members:  planner.Members,
+		Revision: planner.Revision,
+	}, nil
+}
+
 func generatePlannerUUID() (string, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -3,6 +3,7 @@ package handle
 import (
 	"github.com/asdine/storm"
 	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
 	"github.com/stretchr/testify/assert"
 	"os"
@@ -43,3 +44,86 @@ func TestPlannerControl_CreatePlanner(t *testing.T) {
 
 	assert.NotEmpty(t, res.Id)
 }
+
+func TestPlannerControl_Access(t *testing.T) {
+	plannerControl, db := SetupPlannerT(t)
+	defer TeardownPlannerT(t, db)
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	planStore := inmemory.NewInMemoryPlanStore(db)
+	access := services.NewAccessService(inmemory.NewInMemoryPlannerStore(db), goalStore, planStore,
+		inmemory.NewInMemoryTaskStore(db))
+	goalControl := NewGoalControl(services.NewGoalService(goalStore))
+	planControl := NewPlanControl(services.NewPlanService(planStore))
+	taskControl := NewTaskControl(services.NewTaskService(inmemory.NewInMemoryTaskStore(db)))
+
+	alice := plannerControl.As(access, "alice")
+	planner, err := alice.CreatePlanner(&CreatePlannerRequest{Title: "Team", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	goal, err := goalControl.As(access, "alice").CreateGoal(&CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-01", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	plan, err := planControl.As(access, "alice").CreatePlan(&CreatePlanRequest{PlanName: "Sprint", PlanDate: "2030-01-01", PlanTime: "09:00", GoalId: goal.ID})
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := taskControl.As(access, "alice").CreateTask(CreateTaskRequest{Title: "Review", Owner: "alice", PlanId: plan.ID})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	// tasks outside any planner stay visible to everyone
+	if _, err := taskControl.CreateTask(CreateTaskRequest{Title: "Loose", Owner: "carol"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	bob := plannerControl.As(access, "bob")
+	_, err = bob.GetPlanner(&GetPlannerRequest{Id: planner.Id})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	_, err = taskControl.As(access, "bob").GetTask(&GetTaskRequest{ID: task.ID})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	planners, err := bob.FindPlanners(&ListRequest{})
+	if err != nil {
+		t.Fatalf("failed to list planners: %v", err)
+	}
+	assert.Empty(t, planners.Planners)
+	tasks, err := taskControl.As(access, "bob").FindTasks(&ListRequest{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	if assert.Len(t, tasks.Tasks, 1) {
+		assert.Equal(t, "Loose", tasks.Tasks[0].Title)
+	}
+
+	// viewers can read but not change the planner's records
+	_, err = bob.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "bob", Role: models.RoleViewer})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	shared, err := alice.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "bob", Role: models.RoleViewer})
+	if err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	assert.Equal(t, map[string]string{"bob": models.RoleViewer}, shared.Members)
+	_, err = taskControl.As(access, "bob").GetTask(&GetTaskRequest{ID: task.ID})
+	assert.NoError(t, err)
+	tasks, err = taskControl.As(access, "bob").FindTasks(&ListRequest{})
+	if err != nil {
+		t.Fatalf("failed to list tasks: %v", err)
+	}
+	assert.Len(t, tasks.Tasks, 2)
+	err = taskControl.As(access, "bob").DeleteTask(&DeleteTaskRequest{ID: task.ID})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+
+	// editors can change the records but not delete or share the planner
+	_, err = alice.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "bob", Role: models.RoleEditor})
+	if err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	assert.NoError(t, taskControl.As(access, "bob").DeleteTask(&DeleteTaskRequest{ID: task.ID}))
+	_, err = bob.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "carol", Role: models.RoleViewer})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	assert.ErrorIs(t, bob.DeletePlanner(&DeletePlannerRequest{Id: planner.Id}), services.ErrForbidden)
+	_, err = alice.SharePlanner(&SharePlannerRequest{Id: planner.Id, User: "bob", Role: "admin"})
+	assert.ErrorIs(t, err, services.ErrInvalidMember)
+	assert.NoError(t, alice.DeletePlanner(&DeletePlannerRequest{Id: planner.Id}))
+}
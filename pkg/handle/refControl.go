@@ -0,0 +1,70 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/pkg/ref"
+	"github.com/ooyeku/flow/pkg/store"
+)
+
+// ResolveTask returns the id of the task ref refers to: its id, a unique prefix of it or @"title".
+// Only the tasks the control's user can see are matched. See ref.Resolve.
+func (c *TaskControl) ResolveTask(r string) (string, error) {
+	return ref.Resolve("task", r, func() ([]ref.Candidate, error) {
+		tasks, err := c.service.FindTasks(&store.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]ref.Candidate, 0, len(tasks))
+		for _, task := range tasks {
+			candidates = append(candidates, ref.Candidate{Id: task.ID, Name: task.Title})
+		}
+		return candidates, nil
+	})
+}
+
+// ResolveGoal returns the id of the goal ref refers to: its id, a unique prefix of it or @"objective".
+// Only the goals the control's user can see are matched. See ref.Resolve.
+func (c *GoalControl) ResolveGoal(r string) (string, error) {
+	return ref.Resolve("goal", r, func() ([]ref.Candidate, error) {
+		goals, err := c.Service.FindGoals(&store.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]ref.Candidate, 0, len(goals))
+		for _, goal := range goals {
+			candidates = append(candidates, ref.Candidate{Id: goal.Id, Name: goal.Objective})
+		}
+		return candidates, nil
+	})
+}
+
+// ResolvePlan returns the id of the plan ref refers to: its id, a unique prefix of it or @"name".
+// Only the plans the control's user can see are matched. See ref.Resolve.
+func (c *PlanControl) ResolvePlan(r string) (string, error) {
+	return ref.Resolve("plan", r, func() ([]ref.Candidate, error) {
+		plans, err := c.Service.FindPlans(&store.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]ref.Candidate, 0, len(plans))
+		for _, plan := range plans {
+			candidates = append(candidates, ref.Candidate{Id: plan.Id, Name: plan.PlanName})
+		}
+		return candidates, nil
+	})
+}
+
+// ResolvePlanner returns the id of the planner ref refers to: its id, a unique prefix of it or @"title".
+// Only the planners the control's user can see are matched. See ref.Resolve.
+func (c *PlannerControl) ResolvePlanner(r string) (string, error) {
+	return ref.Resolve("planner", r, func() ([]ref.Candidate, error) {
+		planners, err := c.Service.FindPlanners(&store.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		candidates := make([]ref.Candidate, 0, len(planners))
+		for _, planner := range planners {
+			candidates = append(candidates, ref.Candidate{Id: planner.Id, Name: planner.Title})
+		}
+		return candidates, nil
+	})
+}
@@ -0,0 +1,57 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/pkg/search"
+	"github.com/ooyeku/flow/pkg/services"
+)
+
+// DefaultSearchLimit is the number of hits returned by a search that does not set a limit.
+const DefaultSearchLimit = 20
+
+// SearchControl represents a controller that searches tasks, goals, plans and the chat history.
+type SearchControl struct {
+	Service *services.SearchService
+}
+
+// NewSearchControl creates a new instance of SearchControl with the provided SearchService.
+func NewSearchControl(service *services.SearchService) *SearchControl {
+	return &SearchControl{
+		Service: service,
+	}
+}
+
+// As returns a copy of the control whose service only finds the records the named user can view.
+// See services.SearchService.As.
+func (c *SearchControl) As(access *services.AccessService, user string) *SearchControl {
+	return &SearchControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
+// SearchRequest is a full-text search.
+//   - Query: the words to look for; a record must match all of them, exactly, as a prefix or with a typo.
+//   - Types: only records of these types: task, goal, plan or chat. All of them if empty.
+//   - Limit: the maximum number of hits, DefaultSearchLimit if 0.
+type SearchRequest struct {
+	Query string   `json:"q"`
+	Types []string `json:"types,omitempty"`
+	Limit int      `json:"limit,omitempty"`
+}
+
+// SearchResponse holds the hits of a search, best match first.
+type SearchResponse struct {
+	Results []search.Hit `json:"results"`
+}
+
+// Search runs a full-text search.
+func (c *SearchControl) Search(req *SearchRequest) (*SearchResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	hits, err := c.Service.Search(req.Query, req.Types, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResponse{Results: hits}, nil
+}
@@ -0,0 +1,101 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/chat"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/search"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// chatHistory is a services.ChatHistory held in memory.
+type chatHistory []*chat.ChatResponse
+
+func (h chatHistory) ListChatResponses() ([]*chat.ChatResponse, error) {
+	return h, nil
+}
+
+func TestSearchControl_Search(t *testing.T) {
+	_, db := SetupPlannerT(t)
+	defer TeardownPlannerT(t, db)
+	plannerStore := inmemory.NewInMemoryPlannerStore(db)
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	planStore := inmemory.NewInMemoryPlanStore(db)
+	taskStore := inmemory.NewInMemoryTaskStore(db)
+	access := services.NewAccessService(plannerStore, goalStore, planStore, taskStore)
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	plannerControl := NewPlannerControl(services.NewPlannerService(plannerStore).WithEvents(events))
+	goalControl := NewGoalControl(services.NewGoalService(goalStore).WithEvents(events))
+	taskControl := NewTaskControl(services.NewTaskService(taskStore).WithEvents(events))
+
+	planner, err := plannerControl.CreatePlanner(&CreatePlannerRequest{Title: "Work", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	goal, err := goalControl.CreateGoal(&CreateGoalRequest{Objective: "Release v2", Deadline: "2030-01-01", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	// a task stored before the index is built
+	if err := taskStore.CreateTask(&models.Task{ID: "t-old", Title: "Write the release notes"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	history := chatHistory{{ID: "c1", UserQuery: chat.Message{Query: "How do I tag a release?"}, Object: "Use git tag."}}
+	searchService := services.NewSearchService(taskStore, goalStore, planStore, history)
+	if err := searchService.Rebuild(); err != nil {
+		t.Fatalf("failed to build index: %v", err)
+	}
+	searchService.Watch(events)
+	searchControl := NewSearchControl(searchService)
+
+	// a task created afterwards is indexed from its event
+	task, err := taskControl.CreateTask(CreateTaskRequest{Title: "Tag the release", Description: "Push the signed tag"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	ids := func(res *SearchResponse) []string {
+		ids := []string{}
+		for _, hit := range res.Results {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+	res, err := searchControl.Search(&SearchRequest{Query: "relese"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.ElementsMatch(t, []string{goal.ID, "t-old", task.ID, "c1"}, ids(res))
+	res, err = searchControl.Search(&SearchRequest{Query: "sign", Types: []string{search.TypeTask}})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.Equal(t, []string{task.ID}, ids(res))
+
+	// bob sees neither alice's goal nor the chat history
+	res, err = searchControl.As(access, "bob").Search(&SearchRequest{Query: "release"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.ElementsMatch(t, []string{"t-old", task.ID}, ids(res))
+
+	// updated and deleted records leave the index
+	if _, err := goalControl.PatchGoal(&PatchGoalRequest{Id: goal.ID, Patch: []byte(`{"objective": "Ship v2"}`)}); err != nil {
+		t.Fatalf("failed to patch goal: %v", err)
+	}
+	if err := taskControl.DeleteTask(&DeleteTaskRequest{ID: task.ID}); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if err := taskStore.DeleteTask("t-old", store.AnyRevision); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	res, err = searchControl.Search(&SearchRequest{Query: "release"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	assert.Equal(t, []string{"c1"}, ids(res))
+}
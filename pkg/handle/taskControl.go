@@ -1,7 +1,7 @@
 package handle
 
 import (
-	_ "encoding/json"
+	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/services"
@@ -24,6 +24,14 @@ func NewTaskControl(service *services.TaskService) *TaskControl {
 	}
 }
 
+// As returns a copy of the control whose service acts for the named user, checking each request against
+// the user's role in the planners the tasks belong to. See services.TaskService.As.
+func (c *TaskControl) As(access *services.AccessService, user string) *TaskControl {
+	return &TaskControl{
+		service: c.service.As(access, user),
+	}
+}
+
 // CreateTaskResponse represents the response from creating a task.
 type CreateTaskResponse struct {
 	ID string `json:"id"`
@@ -35,6 +43,7 @@ type CreateTaskRequest struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Owner       string `json:"owner"`
+	PlanId      string `json:"plan_id,omitempty"`
 }
 
 // CreateTask generates a unique id for the task and creates a new task with the provided request. It saves the task using the service's store and returns the task id in the response
@@ -47,6 +56,7 @@ func (c *TaskControl) CreateTask(req CreateTaskRequest) (*CreateTaskResponse, er
 	}
 	m := &models.Task{}
 	task := m.GenerateTaskInstance(id, req.Title, req.Description, req.Owner)
+	task.PlanId = req.PlanId
 	err = c.service.CreateTask(task)
 	if err != nil {
 		return nil, err
@@ -57,22 +67,26 @@ func (c *TaskControl) CreateTask(req CreateTaskRequest) (*CreateTaskResponse, er
 }
 
 // UpdateTaskRequest represents a request for updating a task.
-// It contains the ID of the task, along with the updated title, description, owner, started flag, and completed flag.
+// It contains the ID of the task, along with the updated title, description, owner, started, completed and failed flags.
+// Revision is the revision of the task the update is based on; leave it at 0 to skip the revision check.
 type UpdateTaskRequest struct {
 	ID          string `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Owner       string `json:"owner"`
+	PlanId      string `json:"plan_id,omitempty"`
 	Started     bool   `json:"started"`
 	Completed   bool   `json:"completed"`
+	Failed      bool   `json:"failed"`
+	Revision    int    `json:"revision,omitempty"`
 }
 
 // UpdateTask updates an existing task with the provided request.
 // It retrieves the task from the service using the provided task ID.
 // Then it generates a new task instance with the updated information
-// and updates the relevant fields (Started, Completed, UpdatedAt).
+// and updates the relevant fields (Started, Completed, Failed, UpdatedAt).
 // Finally, it calls the UpdateTask method of the service to save the changes.
-// Returns an error if any operation fails.
+// Returns store.ErrRevisionMismatch if the task is no longer at req.Revision, or an error if any operation fails.
 func (c *TaskControl) UpdateTask(req *UpdateTaskRequest) error {
 	task, err := c.service.GetTask(req.ID)
 	if err != nil {
@@ -86,25 +100,29 @@ func (c *TaskControl) UpdateTask(req *UpdateTaskRequest) error {
 	}
 
 	// these fields are not updated by GenerateTaskInstance
+	task.PlanId = req.PlanId
 	task.Started = req.Started
 	task.Completed = req.Completed
+	task.Failed = req.Failed
 	task.UpdatedAt = time.Now()
 
-	if err := c.service.UpdateTask(req.ID, task); err != nil {
+	if err := c.service.UpdateTask(req.ID, task, req.Revision); err != nil {
 		return err
 	}
 	return nil
 }
 
 // DeleteTaskRequest represents a request to delete a task with a given ID.
+// Revision is the revision of the task the caller last read; leave it at 0 to skip the revision check.
 type DeleteTaskRequest struct {
-	ID string `json:"id"`
+	ID       string `json:"id"`
+	Revision int    `json:"revision,omitempty"`
 }
 
 // DeleteTask deletes a task with the provided ID.
-// It calls the DeleteTask method of the service's store and returns any error that occurred.
+// It calls the DeleteTask method of the service and returns any error that occurred.
 func (c *TaskControl) DeleteTask(req *DeleteTaskRequest) error {
-	if err := c.service.Store.DeleteTask(req.ID); err != nil {
+	if err := c.service.DeleteTask(req.ID, req.Revision); err != nil {
 		return err
 	}
 	return nil
@@ -160,8 +178,10 @@ func (req *GetTaskByOwnerRequest) GetID() string {
 // Title represents the title of the task.
 // Description represents the description of the task.
 // Owner represents the owner of the task.
+// PlanId represents the plan the task belongs to, if any.
 // Started represents whether the task has been started or not.
 // Completed represents whether the task has been completed or not.
+// Failed represents whether the task was given up on.
 // CreatedAt represents the timestamp when the task was created.
 // UpdatedAt represents the timestamp when the task was last updated.
 type GetTaskResponse struct {
@@ -169,10 +189,13 @@ type GetTaskResponse struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Owner       string    `json:"owner"`
+	PlanId      string    `json:"plan_id,omitempty"`
 	Started     bool      `json:"started"`
 	Completed   bool      `json:"completed"`
+	Failed      bool      `json:"failed"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Revision    int       `json:"revision"`
 }
 
 // GetTask retrieves a task with the specified ID from the service's store.
@@ -188,10 +211,13 @@ func (c *TaskControl) GetTask(req *GetTaskRequest) (*GetTaskResponse, error) {
 		Title:       task.Title,
 		Description: task.Description,
 		Owner:       task.Owner,
+		PlanId:      task.PlanId,
 		Started:     task.Started,
 		Completed:   task.Completed,
+		Failed:      task.Failed,
 		CreatedAt:   task.CreatedAt,
 		UpdatedAt:   task.UpdatedAt,
+		Revision:    task.Revision,
 	}, nil
 }
 
@@ -208,10 +234,13 @@ func (c *TaskControl) GetTaskByTitle(req *GetTaskByTitleRequest) (*GetTaskRespon
 		Title:       task.Title,
 		Description: task.Description,
 		Owner:       task.Owner,
+		PlanId:      task.PlanId,
 		Started:     task.Started,
 		Completed:   task.Completed,
+		Failed:      task.Failed,
 		CreatedAt:   task.CreatedAt,
 		UpdatedAt:   task.UpdatedAt,
+		Revision:    task.Revision,
 	}, nil
 }
 
@@ -229,18 +258,23 @@ func (c *TaskControl) GetTaskByOwner(req *GetTaskByOwnerRequest) ([]*GetTaskResp
 			Title:       task.Title,
 			Description: task.Description,
 			Owner:       task.Owner,
+			PlanId:      task.PlanId,
 			Started:     task.Started,
 			Completed:   task.Completed,
+			Failed:      task.Failed,
 			CreatedAt:   task.CreatedAt,
 			UpdatedAt:   task.UpdatedAt,
+			Revision:    task.Revision,
 		})
 	}
 	return taskResponses, nil
 }
 
 // ListTasksResponse represents the response structure containing a list of tasks.
+// NextCursor is set when more tasks are available and is passed back as ListRequest.Cursor to fetch them.
 type ListTasksResponse struct {
-	Tasks []*GetTaskResponse `json:"tasks"`
+	Tasks      []*GetTaskResponse `json:"tasks"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 // ListTasks retrieves all tasks using the service's store and returns a list of GetTaskResponse objects representing the tasks.
@@ -256,15 +290,96 @@ func (c *TaskControl) ListTasks() ([]GetTaskResponse, error) {
 			Title:       task.Title,
 			Description: task.Description,
 			Owner:       task.Owner,
+			PlanId:      task.PlanId,
 			Started:     task.Started,
 			Completed:   task.Completed,
+			Failed:      task.Failed,
 			CreatedAt:   task.CreatedAt,
 			UpdatedAt:   task.UpdatedAt,
+			Revision:    task.Revision,
 		})
 	}
 	return taskResponses, nil
 }
 
+// FindTasks retrieves one page of tasks matching the filters of the request.
+// Filtering, ordering and paging are pushed down to the task store.
+func (c *TaskControl) FindTasks(req *ListRequest) (*ListTasksResponse, error) {
+	opts, err := req.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.service.FindTasks(opts)
+	if err != nil {
+		return nil, err
+	}
+	n, next := req.nextCursor(opts, len(tasks))
+	taskResponses := make([]*GetTaskResponse, 0, n)
+	for _, task := range tasks[:n] {
+		taskResponses = append(taskResponses, &GetTaskResponse{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Owner:       task.Owner,
+			PlanId:      task.PlanId,
+			Started:     task.Started,
+			Completed:   task.Completed,
+			Failed:      task.Failed,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+			Revision:    task.Revision,
+		})
+	}
+	return &ListTasksResponse{
+		Tasks:      taskResponses,
+		NextCursor: next,
+	}, nil
+}
+
+// PatchTaskRequest represents a request to partially update a task.
+// Patch is a JSON Merge Patch (RFC 7396) applied to the task's JSON representation;
+// only the fields present in the patch are changed.
+// Revision is the revision of the task the patch is based on; leave it at 0 to skip the revision check.
+type PatchTaskRequest struct {
+	ID       string          `json:"id"`
+	Patch    json.RawMessage `json:"patch"`
+	Revision int             `json:"revision,omitempty"`
+}
+
+// PatchTask applies the merge patch of the request to the task with the given ID.
+// The ID and creation time of the task cannot be changed and UpdatedAt is set to the current time.
+// It returns the updated task, ErrInvalidPatch if the patch does not fit a task,
+// or store.ErrRevisionMismatch if the task is no longer at req.Revision.
+func (c *TaskControl) PatchTask(req *PatchTaskRequest) (*GetTaskResponse, error) {
+	task, err := c.service.GetTask(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	patched := &models.Task{}
+	if err := applyMergePatch(task, req.Patch, patched, nil); err != nil {
+		return nil, err
+	}
+	patched.ID = task.ID
+	patched.CreatedAt = task.CreatedAt
+	patched.UpdatedAt = time.Now()
+	if err := c.service.UpdateTask(req.ID, patched, patchRevision(task.Revision, req.Revision)); err != nil {
+		return nil, err
+	}
+	return &GetTaskResponse{
+		ID:          patched.ID,
+		Title:       patched.Title,
+		Description: patched.Description,
+		Owner:       patched.Owner,
+		PlanId:      patched.PlanId,
+		Started:     patched.Started,
+		Completed:   patched.Completed,
+		Failed:      patched.Failed,
+		CreatedAt:   patched.CreatedAt,
+		UpdatedAt:   patched.UpdatedAt,
+		Revision:    patched.Revision,
+	}, nil
+}
+
 // generateTaskUUID generates a unique task UUID using the uuid package.
 // It returns the generated UUID as a string and any error that occurred during the generation process.
 func generateTaskUUID() (string, error) {
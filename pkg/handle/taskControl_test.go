@@ -4,6 +4,7 @@ import (
 	"github.com/asdine/storm"
 	"github.com/ooyeku/flow/internal/inmemory"
 	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -43,3 +44,119 @@ func TestTaskControl_CreateTask(t *testing.T) {
 
 	assert.NotEmpty(t, res.ID)
 }
+
+func TestTaskControl_FindTasks(t *testing.T) {
+	taskControl, db := SetupTaskT(t)
+	defer TeardownTaskT(t, db)
+	for _, owner := range []string{"alice", "bob", "alice"} {
+		_, err := taskControl.CreateTask(CreateTaskRequest{Title: "task of " + owner, Owner: owner})
+		if err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	res, err := taskControl.FindTasks(&ListRequest{Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to find tasks: %v", err)
+	}
+	assert.Len(t, res.Tasks, 2)
+	assert.Empty(t, res.NextCursor)
+
+	res, err = taskControl.FindTasks(&ListRequest{Status: "Completed"})
+	if err != nil {
+		t.Fatalf("failed to find tasks: %v", err)
+	}
+	assert.Empty(t, res.Tasks)
+}
+
+func TestTaskControl_PatchTask(t *testing.T) {
+	taskControl, db := SetupTaskT(t)
+	defer TeardownTaskT(t, db)
+	created, err := taskControl.CreateTask(CreateTaskRequest{Title: "My Task", Description: "desc", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	_, err = taskControl.PatchTask(&PatchTaskRequest{ID: created.ID, Patch: []byte(`{"started": true}`)})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	res, err := taskControl.PatchTask(&PatchTaskRequest{ID: created.ID, Patch: []byte(`{"started": false, "title": "Renamed"}`)})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.False(t, res.Started)
+	assert.Equal(t, "Renamed", res.Title)
+
+	task, err := taskControl.GetTask(&GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.False(t, task.Started)
+	assert.Equal(t, "desc", task.Description)
+	assert.Equal(t, "alice", task.Owner)
+
+	res, err = taskControl.PatchTask(&PatchTaskRequest{ID: created.ID, Patch: []byte(`{"failed": true}`)})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.True(t, res.Failed)
+	task, err = taskControl.GetTask(&GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.True(t, task.Failed)
+}
+
+func TestTaskControl_Revision(t *testing.T) {
+	taskControl, db := SetupTaskT(t)
+	defer TeardownTaskT(t, db)
+	created, err := taskControl.CreateTask(CreateTaskRequest{Title: "My Task", Description: "desc", Owner: "alice"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	task, err := taskControl.GetTask(&GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, 1, task.Revision)
+
+	res, err := taskControl.PatchTask(&PatchTaskRequest{ID: created.ID, Patch: []byte(`{"started": true}`), Revision: 1})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.Equal(t, 2, res.Revision)
+
+	// a client still holding revision 1 must not overwrite the change
+	_, err = taskControl.PatchTask(&PatchTaskRequest{ID: created.ID, Patch: []byte(`{"title": "Stale"}`), Revision: 1})
+	assert.ErrorIs(t, err, store.ErrRevisionMismatch)
+	err = taskControl.DeleteTask(&DeleteTaskRequest{ID: created.ID, Revision: 1})
+	assert.ErrorIs(t, err, store.ErrRevisionMismatch)
+
+	task, err = taskControl.GetTask(&GetTaskRequest{ID: created.ID})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "My Task", task.Title)
+	assert.Equal(t, 2, task.Revision)
+
+	err = taskControl.DeleteTask(&DeleteTaskRequest{ID: created.ID, Revision: 2})
+	assert.NoError(t, err)
+}
+
+func TestTaskControl_ResolveTask(t *testing.T) {
+	taskControl, db := SetupTaskT(t)
+	defer TeardownTaskT(t, db)
+	res, err := taskControl.CreateTask(CreateTaskRequest{Title: "Ship v2"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	for _, r := range []string{res.ID, res.ID[:8], `@"Ship v2"`, "@ship V2"} {
+		id, err := taskControl.ResolveTask(r)
+		assert.NoError(t, err, r)
+		assert.Equal(t, res.ID, id, r)
+	}
+	_, err = taskControl.ResolveTask("@Ship v3")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+}
@@ -0,0 +1,102 @@
+package handle
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTrash is returned when the trash is emptied with an age that cannot be parsed.
+var ErrInvalidTrash = errors.New("invalid trash request")
+
+// TrashControl lists, restores and purges the deleted tasks, goals, plans and planners in the trash.
+type TrashControl struct {
+	Service *services.TrashService
+}
+
+// NewTrashControl creates a new instance of TrashControl with the provided TrashService.
+func NewTrashControl(service *services.TrashService) *TrashControl {
+	return &TrashControl{
+		Service: service,
+	}
+}
+
+// As returns a copy of the control that only lists, restores and purges the records the named user could have deleted.
+// See services.TrashService.As.
+func (c *TrashControl) As(access *services.AccessService, user string) *TrashControl {
+	return &TrashControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
+// RestoreTrashRequest names the record to bring back from the trash.
+type RestoreTrashRequest struct {
+	Id string `json:"id"`
+}
+
+// EmptyTrashRequest selects the records to purge from the trash for good: those deleted longer ago than OlderThan,
+// a number of days such as "30d" or a duration such as "12h"; an empty OlderThan purges the whole trash.
+type EmptyTrashRequest struct {
+	OlderThan string `json:"older_than,omitempty"`
+}
+
+// TrashResponse lists records in the trash, or the records a restore or purge took out of it.
+type TrashResponse struct {
+	Items []*models.TrashItem `json:"items"`
+}
+
+// ListTrash lists the records in the trash, most recently deleted first.
+func (c *TrashControl) ListTrash() (*TrashResponse, error) {
+	items, err := c.Service.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	return &TrashResponse{Items: items}, nil
+}
+
+// RestoreTrash brings back the record with the id of the request, together with the records deleted along with it.
+func (c *TrashControl) RestoreTrash(req *RestoreTrashRequest) (*TrashResponse, error) {
+	items, err := c.Service.RestoreTrash(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &TrashResponse{Items: items}, nil
+}
+
+// EmptyTrash purges the records deleted longer ago than req.OlderThan, with the records deleted along with them.
+func (c *TrashControl) EmptyTrash(req *EmptyTrashRequest) (*TrashResponse, error) {
+	age, err := ParseAge(req.OlderThan)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.Service.EmptyTrash(time.Now().Add(-age))
+	if err != nil {
+		return nil, err
+	}
+	return &TrashResponse{Items: items}, nil
+}
+
+// ParseAge parses an age given as a number of days, such as "30d", or as a duration accepted by time.ParseDuration,
+// such as "12h". An empty age is 0. It returns ErrInvalidTrash for anything else, or a negative age.
+func ParseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var age time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		age, err = time.ParseDuration(s)
+	}
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("%w: expected an age such as 30d or 12h, got %q", ErrInvalidTrash, s)
+	}
+	return age, nil
+}
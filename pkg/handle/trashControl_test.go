@@ -0,0 +1,61 @@
+package handle
+
+import (
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	for s, want := range map[string]time.Duration{"": 0, "30d": 30 * 24 * time.Hour, "0d": 0, "12h": 12 * time.Hour} {
+		age, err := ParseAge(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, want, age, s)
+		}
+	}
+	for _, s := range []string{"d", "30", "thirty days", "-1d"} {
+		_, err := ParseAge(s)
+		assert.ErrorIs(t, err, ErrInvalidTrash, s)
+	}
+}
+
+func TestTrashControl(t *testing.T) {
+	_, db := SetupPlannerT(t)
+	defer TeardownPlannerT(t, db)
+	taskControl := NewTaskControl(services.NewTaskService(inmemory.NewInMemoryTaskStore(db)))
+	trashControl := NewTrashControl(services.NewTrashService(inmemory.NewInMemoryTrashStore(db)))
+
+	task, err := taskControl.CreateTask(CreateTaskRequest{Title: "Old", Owner: "me"})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := taskControl.DeleteTask(&DeleteTaskRequest{ID: task.ID, Revision: store.AnyRevision}); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	res, err := trashControl.ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	if assert.Len(t, res.Items, 1) {
+		assert.Equal(t, task.ID, res.Items[0].Id)
+	}
+
+	_, err = trashControl.EmptyTrash(&EmptyTrashRequest{OlderThan: "soon"})
+	assert.ErrorIs(t, err, ErrInvalidTrash)
+	res, err = trashControl.EmptyTrash(&EmptyTrashRequest{OlderThan: "30d"})
+	if err != nil {
+		t.Fatalf("failed to empty trash: %v", err)
+	}
+	assert.Empty(t, res.Items, "the task was deleted just now")
+
+	res, err = trashControl.RestoreTrash(&RestoreTrashRequest{Id: task.ID})
+	if err != nil {
+		t.Fatalf("failed to restore task: %v", err)
+	}
+	assert.Len(t, res.Items, 1)
+	_, err = taskControl.GetTask(&GetTaskRequest{ID: task.ID})
+	assert.NoError(t, err)
+}
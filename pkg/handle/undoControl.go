@@ -0,0 +1,119 @@
+package handle
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"time"
+)
+
+// ErrInvalidUndo is returned when an undo or redo asks for a negative number of steps.
+var ErrInvalidUndo = errors.New("invalid undo")
+
+// UndoControl undoes and redoes the changes recorded in the journal by the task, goal, plan and planner services.
+type UndoControl struct {
+	Service *services.JournalService
+}
+
+// NewUndoControl creates a new instance of UndoControl with the provided JournalService.
+func NewUndoControl(service *services.JournalService) *UndoControl {
+	return &UndoControl{
+		Service: service,
+	}
+}
+
+// As returns a copy of the control that undoes and redoes the changes of the named user, with the user's access
+// to the planners. See services.JournalService.As.
+func (c *UndoControl) As(access *services.AccessService, user string) *UndoControl {
+	return &UndoControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
+// UndoRequest is the number of changes to undo or redo; 0 means one.
+type UndoRequest struct {
+	Steps int `json:"steps,omitempty"`
+}
+
+// JournalChange describes a change an undo or redo applied or skipped.
+//   - Action: the change as it was made: "created", "updated" or "deleted".
+//   - Resource, ResourceId, Name: the kind, id and title, objective or plan name of the record.
+//   - Time: when the change was made.
+type JournalChange struct {
+	Id         int       `json:"id"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceId string    `json:"resource_id"`
+	Name       string    `json:"name,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// UndoResponse lists the changes an undo or redo applied, in the order it applied them, and those it skipped
+// because the records have been changed by someone else since, or the user may no longer change them.
+type UndoResponse struct {
+	Changes []*JournalChange `json:"changes"`
+	Skipped []*JournalChange `json:"skipped"`
+}
+
+// Undo reverts the last req.Steps changes of the user that have not been undone, newest first.
+func (c *UndoControl) Undo(req *UndoRequest) (*UndoResponse, error) {
+	steps, err := req.steps()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Service.Undo(steps)
+	if err != nil {
+		return nil, err
+	}
+	return undoResponse(res), nil
+}
+
+// Redo applies again the last req.Steps changes of the user that were undone, oldest first.
+// Undone changes can only be redone until the user makes a new change.
+func (c *UndoControl) Redo(req *UndoRequest) (*UndoResponse, error) {
+	steps, err := req.steps()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Service.Redo(steps)
+	if err != nil {
+		return nil, err
+	}
+	return undoResponse(res), nil
+}
+
+// steps returns the number of steps of the request, 1 if it is not set.
+func (req *UndoRequest) steps() (int, error) {
+	switch {
+	case req.Steps < 0:
+		return 0, fmt.Errorf("%w: steps must not be negative, got %d", ErrInvalidUndo, req.Steps)
+	case req.Steps == 0:
+		return 1, nil
+	}
+	return req.Steps, nil
+}
+
+// undoResponse converts the result of an undo or redo of the journal into an UndoResponse.
+func undoResponse(res *services.JournalResult) *UndoResponse {
+	return &UndoResponse{
+		Changes: journalChanges(res.Applied),
+		Skipped: journalChanges(res.Skipped),
+	}
+}
+
+// journalChanges converts journal entries into JournalChanges, without the records they hold.
+func journalChanges(entries []*models.JournalEntry) []*JournalChange {
+	changes := make([]*JournalChange, 0, len(entries))
+	for _, entry := range entries {
+		changes = append(changes, &JournalChange{
+			Id:         entry.Id,
+			Action:     entry.Action,
+			Resource:   entry.Resource,
+			ResourceId: entry.ResourceId,
+			Name:       entry.Name,
+			Time:       entry.Time,
+		})
+	}
+	return changes
+}
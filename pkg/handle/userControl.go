@@ -0,0 +1,201 @@
+package handle
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/asdine/storm"
+	"github.com/google/uuid"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"strings"
+	"time"
+)
+
+// tokenPrefix is the prefix of every API token, which makes tokens easy to recognize in configuration files and logs.
+const tokenPrefix = "flow_"
+
+// ErrInvalidToken is returned by Authenticate when the token is unknown or has been revoked.
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrUserExists is returned by AddUser when a user with the same name exists.
+var ErrUserExists = errors.New("user already exists")
+
+// UserControl is a type that handles users and their API tokens.
+type UserControl struct {
+	Service *services.UserService
+}
+
+// NewUserControl creates a new instance of UserControl with the provided UserService.
+func NewUserControl(service *services.UserService) *UserControl {
+	return &UserControl{
+		Service: service,
+	}
+}
+
+// AddUserRequest represents a request to add a user.
+type AddUserRequest struct {
+	Name string `json:"name"`
+}
+
+// AddUserResponse represents the response of AddUser. It contains the ID of the new user.
+type AddUserResponse struct {
+	Id string `json:"id"`
+}
+
+// AddUser creates a new user with the name of the request.
+// It returns ErrUserExists if the name is taken.
+func (c *UserControl) AddUser(req *AddUserRequest) (*AddUserResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("user name is required")
+	}
+	if _, err := c.Service.GetUserByName(name); err == nil {
+		return nil, ErrUserExists
+	} else if !errors.Is(err, storm.ErrNotFound) {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Id:        id.String(),
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	if err := c.Service.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return &AddUserResponse{
+		Id: user.Id,
+	}, nil
+}
+
+// GetUserRequest represents a request to get the user with the given name.
+type GetUserRequest struct {
+	Name string `json:"name"`
+}
+
+// GetUser returns the user with the name of the request, or storm.ErrNotFound if there is none.
+func (c *UserControl) GetUser(req *GetUserRequest) (*models.User, error) {
+	user, err := c.Service.GetUserByName(req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", req.Name, err)
+	}
+	return user, nil
+}
+
+// ListUsers returns every user.
+func (c *UserControl) ListUsers() ([]*models.User, error) {
+	return c.Service.ListUsers()
+}
+
+// CreateTokenRequest represents a request to create an API token.
+// UserName is the name of the user the token authenticates as; Name is an optional label, such as the host the token is used on.
+type CreateTokenRequest struct {
+	UserName string `json:"user_name"`
+	Name     string `json:"name"`
+}
+
+// CreateTokenResponse represents the response of CreateToken.
+// Token is the secret to send in the Authorization header; it is not stored and cannot be retrieved later.
+type CreateTokenResponse struct {
+	Id    string `json:"id"`
+	Token string `json:"token"`
+}
+
+// CreateToken generates a new random API token for a user and stores its hash.
+func (c *UserControl) CreateToken(req *CreateTokenRequest) (*CreateTokenResponse, error) {
+	user, err := c.Service.GetUserByName(req.UserName)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", req.UserName, err)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	plain := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	token := &models.Token{
+		Id:        id.String(),
+		UserId:    user.Id,
+		Name:      req.Name,
+		Hash:      hashToken(plain),
+		CreatedAt: time.Now(),
+	}
+	if err := c.Service.CreateToken(token); err != nil {
+		return nil, err
+	}
+	return &CreateTokenResponse{
+		Id:    token.Id,
+		Token: plain,
+	}, nil
+}
+
+// RevokeTokenRequest represents a request to revoke the API token with the given ID.
+type RevokeTokenRequest struct {
+	Id string `json:"id"`
+}
+
+// RevokeToken deletes an API token so that it can no longer be used.
+func (c *UserControl) RevokeToken(req *RevokeTokenRequest) error {
+	if err := c.Service.DeleteToken(req.Id); err != nil {
+		return fmt.Errorf("token %s: %w", req.Id, err)
+	}
+	return nil
+}
+
+// ListTokensRequest represents a request to list the API tokens of a user.
+type ListTokensRequest struct {
+	UserName string `json:"user_name"`
+}
+
+// ListTokens returns the API tokens of a user. The tokens carry their ID, label and creation time, but not the secret.
+func (c *UserControl) ListTokens(req *ListTokensRequest) ([]*models.Token, error) {
+	user, err := c.Service.GetUserByName(req.UserName)
+	if err != nil {
+		return nil, fmt.Errorf("user %s: %w", req.UserName, err)
+	}
+	tokens, err := c.Service.ListTokens(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		token.Hash = ""
+	}
+	return tokens, nil
+}
+
+// Authenticate returns the user an API token belongs to.
+// It returns ErrInvalidToken if the token is unknown or has been revoked.
+func (c *UserControl) Authenticate(token string) (*models.User, error) {
+	if !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	stored, err := c.Service.GetTokenByHash(hashToken(token))
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := c.Service.GetUser(stored.UserId)
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	return user, err
+}
+
+// hashToken returns the hex encoded SHA-256 hash of a token.
+// Tokens are 256 bit random values, so a fast unsalted hash is enough to keep them secret at rest.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package handle
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func SetupUserT(t *testing.T) (*UserControl, *storm.DB) {
+	db, _ := storm.Open("test.db")
+	uStore := inmemory.NewInMemoryUserStore(db)
+	service := services.NewUserService(uStore)
+	userControl := NewUserControl(service)
+	return userControl, db
+}
+
+func TeardownUserT(t *testing.T, db *storm.DB) {
+	err := db.Close()
+	if err != nil {
+		t.Fatalf("failed to close db: %v", err)
+	}
+	err = os.Remove("test.db")
+	if err != nil {
+		t.Fatalf("failed to remove db: %v", err)
+	}
+}
+
+func TestUserControl_Tokens(t *testing.T) {
+	userControl, db := SetupUserT(t)
+	defer TeardownUserT(t, db)
+	if _, err := userControl.AddUser(&AddUserRequest{Name: "alice"}); err != nil {
+		t.Fatalf("failed to add user: %v", err)
+	}
+	_, err := userControl.AddUser(&AddUserRequest{Name: "alice"})
+	assert.ErrorIs(t, err, ErrUserExists)
+
+	created, err := userControl.CreateToken(&CreateTokenRequest{UserName: "alice", Name: "laptop"})
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	user, err := userControl.Authenticate(created.Token)
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	assert.Equal(t, "alice", user.Name)
+
+	tokens, err := userControl.ListTokens(&ListTokensRequest{UserName: "alice"})
+	if err != nil {
+		t.Fatalf("failed to list tokens: %v", err)
+	}
+	assert.Len(t, tokens, 1)
+	assert.Empty(t, tokens[0].Hash)
+
+	_, err = userControl.Authenticate(created.Token + "x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	if err := userControl.RevokeToken(&RevokeTokenRequest{Id: created.Id}); err != nil {
+		t.Fatalf("failed to revoke token: %v", err)
+	}
+	_, err = userControl.Authenticate(created.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
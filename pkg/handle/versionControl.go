@@ -17,6 +17,14 @@ func NewVersionControl(versionService *services.VersionService) *VersionControl
 	}
 }
 
+// As returns a copy of the control whose service acts for the named user, checking each request against
+// the user's role in the planners of the versioned goals, plans and tasks. See services.VersionService.As.
+func (vc *VersionControl) As(access *services.AccessService, user string) *VersionControl {
+	return &VersionControl{
+		versionService: vc.versionService.As(access, user),
+	}
+}
+
 type CreateVersionRequest struct {
 	GoalID string `json:"goalId"`
 	PlanID string `json:"planId"`
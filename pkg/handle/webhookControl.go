@@ -0,0 +1,136 @@
+package handle
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"time"
+)
+
+// WebhookControl is a type that handles webhooks and their delivery log.
+type WebhookControl struct {
+	Service *services.WebhookService
+}
+
+// NewWebhookControl creates a new instance of WebhookControl with the provided WebhookService.
+func NewWebhookControl(service *services.WebhookService) *WebhookControl {
+	return &WebhookControl{
+		Service: service,
+	}
+}
+
+// As returns a copy of the control whose service acts for the named user. See services.WebhookService.As.
+func (c *WebhookControl) As(access *services.AccessService, user string) *WebhookControl {
+	return &WebhookControl{
+		Service: c.Service.As(access, user),
+	}
+}
+
+// CreateWebhookRequest represents a request to create a webhook.
+// - URL: the http or https URL the events are POSTed to.
+// - Types: the event types ("task.completed", "goal.overdue") or resources ("plan") to send; every event if empty.
+// - PlannerId: only send events about records of this planner, if set.
+// - Secret: the key used to sign the payloads; a random secret is generated if empty.
+type CreateWebhookRequest struct {
+	URL       string   `json:"url"`
+	Types     []string `json:"types,omitempty"`
+	PlannerId string   `json:"planner_id,omitempty"`
+	Secret    string   `json:"secret,omitempty"`
+}
+
+// CreateWebhookResponse represents the response of CreateWebhook.
+// Secret is the key of the signatures; it is not returned by the other operations.
+type CreateWebhookResponse struct {
+	Id     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// CreateWebhook creates a webhook for the events selected by the request.
+func (c *WebhookControl) CreateWebhook(req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	secret := req.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(random)
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	webhook := &models.Webhook{
+		Id:        id.String(),
+		URL:       req.URL,
+		Types:     req.Types,
+		PlannerId: req.PlannerId,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if err := c.Service.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return &CreateWebhookResponse{
+		Id:     webhook.Id,
+		Secret: secret,
+	}, nil
+}
+
+// GetWebhookRequest represents a request to get the webhook with the given ID.
+type GetWebhookRequest struct {
+	Id string `json:"id"`
+}
+
+// GetWebhook returns a webhook without its secret.
+func (c *WebhookControl) GetWebhook(req *GetWebhookRequest) (*models.Webhook, error) {
+	webhook, err := c.Service.GetWebhook(req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", req.Id, err)
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks without their secrets.
+func (c *WebhookControl) ListWebhooks() ([]*models.Webhook, error) {
+	webhooks, err := c.Service.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhookRequest represents a request to delete the webhook with the given ID.
+type DeleteWebhookRequest struct {
+	Id string `json:"id"`
+}
+
+// DeleteWebhook deletes a webhook and its delivery log. Deliveries in progress are still attempted.
+func (c *WebhookControl) DeleteWebhook(req *DeleteWebhookRequest) error {
+	if err := c.Service.DeleteWebhook(req.Id); err != nil {
+		return fmt.Errorf("webhook %s: %w", req.Id, err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveriesRequest represents a request to list the most recent delivery attempts of a webhook.
+// A Limit of 0 returns every attempt in the delivery log.
+type ListWebhookDeliveriesRequest struct {
+	Id    string `json:"id"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// ListWebhookDeliveries returns the delivery attempts of a webhook, the most recent first.
+func (c *WebhookControl) ListWebhookDeliveries(req *ListWebhookDeliveriesRequest) ([]*models.Delivery, error) {
+	deliveries, err := c.Service.ListDeliveries(req.Id, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", req.Id, err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,305 @@
+// Package metrics collects the metrics of the server and exposes them in the Prometheus text format.
+//
+// It implements the few metric types the server needs, counters, histograms and gauges computed when they
+// are scraped, without the Prometheus client library. Each metric is a family of series told apart by the
+// values of its labels.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// textContentType is the media type of the Prometheus text format.
+const textContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the histogram buckets for request and store latencies.
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric is a family of series written by Registry.WriteText.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds the metrics of a process. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// register adds a metric under a name that must not be taken yet.
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteText writes every metric in the Prometheus text format, in the order they were registered.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler that serves the metrics of the registry to a Prometheus scraper.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", textContentType)
+		if err := r.WriteText(w); err != nil {
+			log.Printf("Error writing metrics: %s", err)
+		}
+	})
+}
+
+// family holds what every metric has: its name, help text, type and label names.
+type family struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+// writeHeader writes the HELP and TYPE lines of the family.
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+}
+
+// key joins label values into a map key; \xff cannot appear in valid UTF-8 label values.
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// checkValues panics unless there is one value for each label of the family.
+func (f *family) checkValues(values []string) {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labels), len(values)))
+	}
+}
+
+// labelString formats label names and values as {name="value",...}, adding the extra pair if name is set.
+func labelString(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extraName, escapeLabel(extraValue))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// escapeLabel escapes a label value as the text format requires.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
+
+// escapeHelp escapes a help text as the text format requires.
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// formatFloat formats a sample value, with the spellings of the text format for infinities and NaN.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns the keys of a series map in order, so that every scrape lists the series the same way.
+func sortedKeys[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter is a family of values that only go up, such as the number of requests served.
+type Counter struct {
+	family
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+// counterSeries is the value of a counter for one set of label values.
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounter registers a counter with the given labels.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{family: family{name: name, help: help, typ: "counter", labels: labels}, series: map[string]*counterSeries{}}
+	r.register(name, c)
+	return c
+}
+
+// Add adds delta, which must not be negative, to the series of the given label values.
+func (c *Counter) Add(delta float64, values ...string) {
+	c.checkValues(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key(values)]
+	if !ok {
+		s = &counterSeries{values: append([]string(nil), values...)}
+		c.series[key(values)] = s
+	}
+	s.value += delta
+}
+
+// Inc adds one to the series of the given label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeHeader(w)
+	for _, k := range sortedKeys(c.series) {
+		s := c.series[k]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelString(c.labels, s.values, "", ""), formatFloat(s.value))
+	}
+}
+
+// Histogram is a family of distributions, such as the latencies of requests, counted in buckets.
+type Histogram struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// histogramSeries is the distribution of a histogram for one set of label values.
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds, in increasing order, and labels.
+// The +Inf bucket is added to the buckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		family:  family{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: buckets,
+		series:  map[string]*histogramSeries{},
+	}
+	r.register(name, h)
+	return h
+}
+
+// Observe adds a value to the series of the given label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.checkValues(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key(values)]
+	if !ok {
+		s = &histogramSeries{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key(values)] = s
+	}
+	// the counts are kept per bucket and summed up when they are written
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// ObserveDuration adds the seconds since start to the series of the given label values.
+func (h *Histogram) ObserveDuration(start time.Time, values ...string) {
+	h.Observe(time.Since(start).Seconds(), values...)
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w)
+	for _, k := range sortedKeys(h.series) {
+		s := h.series[k]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, s.values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labelString(h.labels, s.values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labelString(h.labels, s.values, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labelString(h.labels, s.values, "", ""), s.count)
+	}
+}
+
+// Sample is the value of one series of a gauge, with the values of its labels.
+type Sample struct {
+	Values []string
+	Value  float64
+}
+
+// gaugeFunc is a family of gauges whose samples are computed when they are scraped.
+type gaugeFunc struct {
+	family
+	collect func() ([]Sample, error)
+}
+
+// NewGaugeFunc registers a gauge whose samples collect computes on every scrape, such as the size of a file.
+// If collect fails, the error is logged and the gauge is left out of the scrape.
+func (r *Registry) NewGaugeFunc(name, help string, collect func() ([]Sample, error), labels ...string) {
+	r.register(name, &gaugeFunc{family: family{name: name, help: help, typ: "gauge", labels: labels}, collect: collect})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	samples, err := g.collect()
+	if err != nil {
+		log.Printf("Error collecting metric %s: %s", g.name, err)
+		return
+	}
+	g.writeHeader(w)
+	for _, s := range samples {
+		g.checkValues(s.Values)
+		fmt.Fprintf(w, "%s%s %s\n", g.name, labelString(g.labels, s.Values, "", ""), formatFloat(s.Value))
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("http_requests_total", "Requests served.", "method", "code")
+	latency := r.NewHistogram("http_request_duration_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	r.NewGaugeFunc("db_size_bytes", "Size of the database.", func() ([]Sample, error) {
+		return []Sample{{Value: 4096}}, nil
+	})
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	requests.Add(3, "POST", "400")
+	latency.Observe(0.05, `/task/{id}`)
+	latency.Observe(0.5, `/task/{id}`)
+	latency.Observe(2, `/task/{id}`)
+	requests.Inc("GET", "say \"hi\"\n")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	assert.Equal(t, `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 2
+http_requests_total{method="GET",code="say \"hi\"\n"} 1
+http_requests_total{method="POST",code="400"} 3
+# HELP http_request_duration_seconds Request latency.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/task/{id}",le="0.1"} 1
+http_request_duration_seconds_bucket{route="/task/{id}",le="1"} 2
+http_request_duration_seconds_bucket{route="/task/{id}",le="+Inf"} 3
+http_request_duration_seconds_sum{route="/task/{id}"} 2.55
+http_request_duration_seconds_count{route="/task/{id}"} 3
+# HELP db_size_bytes Size of the database.
+# TYPE db_size_bytes gauge
+db_size_bytes 4096
+`, b.String())
+
+	assert.Panics(t, func() { requests.Inc("GET") })
+	assert.Panics(t, func() { r.NewCounter("http_requests_total", "Again.") })
+}
+
+func TestInstrumentTaskStore(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	r := NewRegistry()
+	durations := r.NewHistogram("store_operation_duration_seconds", "Store latency.", DefaultBuckets, "store", "operation")
+	tasks := InstrumentTaskStore(inmemory.NewInMemoryTaskStore(db), durations)
+	r.NewGaugeFunc("entities", "Records by status.", EntityCounts(tasks, inmemory.NewInMemoryGoalStore(db), inmemory.NewInMemoryPlanStore(db)), "type", "status")
+
+	assert.NoError(t, tasks.CreateTask(&models.Task{ID: "t1", Title: "Write"}))
+	assert.NoError(t, tasks.CreateTask(&models.Task{ID: "t2", Title: "Ship", Started: true}))
+	assert.NoError(t, tasks.CreateTask(&models.Task{ID: "t3", Title: "Port", Started: true, Failed: true}))
+	_, err = tasks.GetTask("missing")
+	assert.Error(t, err)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	text := b.String()
+	assert.Contains(t, text, `store_operation_duration_seconds_count{store="task",operation="CreateTask"} 3`)
+	assert.Contains(t, text, `store_operation_duration_seconds_count{store="task",operation="GetTask"} 1`)
+	assert.Contains(t, text, `entities{type="task",status="In Progress"} 1`)
+	assert.Contains(t, text, `entities{type="task",status="Not Started"} 1`)
+	assert.Contains(t, text, `entities{type="task",status="Fail"} 1`)
+}
+
+func TestInstrumentTransactor(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	r := NewRegistry()
+	durations := r.NewHistogram("store_operation_duration_seconds", "Store latency.", DefaultBuckets, "store", "operation")
+	transactor := InstrumentTransactor(inmemory.NewInMemoryTransactor(db), durations)
+
+	err = transactor.Transaction(func(stores *store.Stores) error {
+		if err := stores.Plan.CreatePlan(&models.Plan{Id: "p1", PlanName: "Week 1"}); err != nil {
+			return err
+		}
+		return stores.Task.CreateTask(&models.Task{ID: "t1", Title: "Write", PlanId: "p1"})
+	})
+	assert.NoError(t, err)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("failed to write metrics: %v", err)
+	}
+	text := b.String()
+	assert.Contains(t, text, `store_operation_duration_seconds_count{store="plan",operation="CreatePlan"} 1`)
+	assert.Contains(t, text, `store_operation_duration_seconds_count{store="task",operation="CreateTask"} 1`)
+}
@@ -0,0 +1,289 @@
+package metrics
+
+import (
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"sort"
+	"time"
+)
+
+// The stores below pass every call on to the store they wrap and observe how long it took in a histogram
+// with the labels store and operation, such as "task" and "GetTask".
+
+// taskStore is a store.TaskStore that times the calls to the store it wraps.
+type taskStore struct {
+	store     store.TaskStore
+	durations *Histogram
+}
+
+// InstrumentTaskStore returns a store.TaskStore that observes the duration of every call to s in durations.
+func InstrumentTaskStore(s store.TaskStore, durations *Histogram) store.TaskStore {
+	return &taskStore{store: s, durations: durations}
+}
+
+func (s *taskStore) CreateTask(task *models.Task) error {
+	defer s.durations.ObserveDuration(time.Now(), "task", "CreateTask")
+	return s.store.CreateTask(task)
+}
+
+func (s *taskStore) UpdateTask(id string, task *models.Task, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "task", "UpdateTask")
+	return s.store.UpdateTask(id, task, expectedRevision)
+}
+
+func (s *taskStore) DeleteTask(id string, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "task", "DeleteTask")
+	return s.store.DeleteTask(id, expectedRevision)
+}
+
+func (s *taskStore) GetTask(id string) (*models.Task, error) {
+	defer s.durations.ObserveDuration(time.Now(), "task", "GetTask")
+	return s.store.GetTask(id)
+}
+
+func (s *taskStore) ListTasks() ([]*models.Task, error) {
+	defer s.durations.ObserveDuration(time.Now(), "task", "ListTasks")
+	return s.store.ListTasks()
+}
+
+func (s *taskStore) GetTaskByTitle(title string) (*models.Task, error) {
+	defer s.durations.ObserveDuration(time.Now(), "task", "GetTaskByTitle")
+	return s.store.GetTaskByTitle(title)
+}
+
+func (s *taskStore) GetTaskByOwner(owner string) ([]*models.Task, error) {
+	defer s.durations.ObserveDuration(time.Now(), "task", "GetTaskByOwner")
+	return s.store.GetTaskByOwner(owner)
+}
+
+func (s *taskStore) FindTasks(opts *store.ListOptions) ([]*models.Task, error) {
+	defer s.durations.ObserveDuration(time.Now(), "task", "FindTasks")
+	return s.store.FindTasks(opts)
+}
+
+// goalStore is a store.GoalStore that times the calls to the store it wraps.
+type goalStore struct {
+	store     store.GoalStore
+	durations *Histogram
+}
+
+// InstrumentGoalStore returns a store.GoalStore that observes the duration of every call to s in durations.
+func InstrumentGoalStore(s store.GoalStore, durations *Histogram) store.GoalStore {
+	return &goalStore{store: s, durations: durations}
+}
+
+func (s *goalStore) CreateGoal(goal *models.Goal) error {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "CreateGoal")
+	return s.store.CreateGoal(goal)
+}
+
+func (s *goalStore) UpdateGoal(goal *models.Goal, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "UpdateGoal")
+	return s.store.UpdateGoal(goal, expectedRevision)
+}
+
+func (s *goalStore) DeleteGoal(id string, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "DeleteGoal")
+	return s.store.DeleteGoal(id, expectedRevision)
+}
+
+func (s *goalStore) GetGoal(id string) (*models.Goal, error) {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "GetGoal")
+	return s.store.GetGoal(id)
+}
+
+func (s *goalStore) ListGoals() ([]*models.Goal, error) {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "ListGoals")
+	return s.store.ListGoals()
+}
+
+func (s *goalStore) GetGoalByObjective(objective string) (*models.Goal, error) {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "GetGoalByObjective")
+	return s.store.GetGoalByObjective(objective)
+}
+
+func (s *goalStore) GetGoalsByPlannerId(id string) ([]*models.Goal, error) {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "GetGoalsByPlannerId")
+	return s.store.GetGoalsByPlannerId(id)
+}
+
+func (s *goalStore) FindGoals(opts *store.ListOptions) ([]*models.Goal, error) {
+	defer s.durations.ObserveDuration(time.Now(), "goal", "FindGoals")
+	return s.store.FindGoals(opts)
+}
+
+// planStore is a store.PlanStore that times the calls to the store it wraps.
+type planStore struct {
+	store     store.PlanStore
+	durations *Histogram
+}
+
+// InstrumentPlanStore returns a store.PlanStore that observes the duration of every call to s in durations.
+func InstrumentPlanStore(s store.PlanStore, durations *Histogram) store.PlanStore {
+	return &planStore{store: s, durations: durations}
+}
+
+func (s *planStore) CreatePlan(plan *models.Plan) error {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "CreatePlan")
+	return s.store.CreatePlan(plan)
+}
+
+func (s *planStore) UpdatePlan(plan *models.Plan, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "UpdatePlan")
+	return s.store.UpdatePlan(plan, expectedRevision)
+}
+
+func (s *planStore) DeletePlan(id string, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "DeletePlan")
+	return s.store.DeletePlan(id, expectedRevision)
+}
+
+func (s *planStore) GetPlan(id string) (*models.Plan, error) {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "GetPlan")
+	return s.store.GetPlan(id)
+}
+
+func (s *planStore) ListPlans() ([]*models.Plan, error) {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "ListPlans")
+	return s.store.ListPlans()
+}
+
+func (s *planStore) GetPlanByName(name string) (*models.Plan, error) {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "GetPlanByName")
+	return s.store.GetPlanByName(name)
+}
+
+func (s *planStore) GetPlansByGoal(id string) ([]*models.Plan, error) {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "GetPlansByGoal")
+	return s.store.GetPlansByGoal(id)
+}
+
+func (s *planStore) FindPlans(opts *store.ListOptions) ([]*models.Plan, error) {
+	defer s.durations.ObserveDuration(time.Now(), "plan", "FindPlans")
+	return s.store.FindPlans(opts)
+}
+
+// plannerStore is a store.PlannerStore that times the calls to the store it wraps.
+type plannerStore struct {
+	store     store.PlannerStore
+	durations *Histogram
+}
+
+// InstrumentPlannerStore returns a store.PlannerStore that observes the duration of every call to s in durations.
+func InstrumentPlannerStore(s store.PlannerStore, durations *Histogram) store.PlannerStore {
+	return &plannerStore{store: s, durations: durations}
+}
+
+func (s *plannerStore) CreatePlanner(planner *models.Planner) error {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "CreatePlanner")
+	return s.store.CreatePlanner(planner)
+}
+
+func (s *plannerStore) UpdatePlanner(planner *models.Planner, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "UpdatePlanner")
+	return s.store.UpdatePlanner(planner, expectedRevision)
+}
+
+func (s *plannerStore) DeletePlanner(id string, expectedRevision int) error {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "DeletePlanner")
+	return s.store.DeletePlanner(id, expectedRevision)
+}
+
+func (s *plannerStore) GetPlanner(id string) (*models.Planner, error) {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "GetPlanner")
+	return s.store.GetPlanner(id)
+}
+
+func (s *plannerStore) ListPlanners() ([]*models.Planner, error) {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "ListPlanners")
+	return s.store.ListPlanners()
+}
+
+func (s *plannerStore) GetPlannerByTitle(title string) (*models.Planner, error) {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "GetPlannerByTitle")
+	return s.store.GetPlannerByTitle(title)
+}
+
+func (s *plannerStore) GetPlannerByOwner(id string) ([]*models.Planner, error) {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "GetPlannerByOwner")
+	return s.store.GetPlannerByOwner(id)
+}
+
+func (s *plannerStore) FindPlanners(opts *store.ListOptions) ([]*models.Planner, error) {
+	defer s.durations.ObserveDuration(time.Now(), "planner", "FindPlanners")
+	return s.store.FindPlanners(opts)
+}
+
+// transactor is a store.Transactor whose transactions run with instrumented stores.
+type transactor struct {
+	transactor store.Transactor
+	durations  *Histogram
+}
+
+// InstrumentTransactor returns a store.Transactor that runs the transactions of t with its stores wrapped
+// as the Instrument*Store functions do, so that the calls made in a transaction are observed in durations too.
+func InstrumentTransactor(t store.Transactor, durations *Histogram) store.Transactor {
+	return &transactor{transactor: t, durations: durations}
+}
+
+func (t *transactor) Transaction(fn func(stores *store.Stores) error) error {
+	return t.transactor.Transaction(func(stores *store.Stores) error {
+		return fn(&store.Stores{
+			Task:    InstrumentTaskStore(stores.Task, t.durations),
+			Goal:    InstrumentGoalStore(stores.Goal, t.durations),
+			Plan:    InstrumentPlanStore(stores.Plan, t.durations),
+			Planner: InstrumentPlannerStore(stores.Planner, t.durations),
+		})
+	})
+}
+
+// EntityCounts returns a collect function for Registry.NewGaugeFunc that counts the tasks, goals and plans
+// by their status, with the labels type and status. A task has no status field: it is "Fail" once failed,
+// "Completed" once completed, "In Progress" once started and "Not Started" before.
+func EntityCounts(tasks store.TaskStore, goals store.GoalStore, plans store.PlanStore) func() ([]Sample, error) {
+	return func() ([]Sample, error) {
+		counts := map[[2]string]float64{}
+		allTasks, err := tasks.ListTasks()
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range allTasks {
+			counts[[2]string{"task", taskStatus(task)}]++
+		}
+		allGoals, err := goals.ListGoals()
+		if err != nil {
+			return nil, err
+		}
+		for _, goal := range allGoals {
+			counts[[2]string{"goal", goal.GoalStatus}]++
+		}
+		allPlans, err := plans.ListPlans()
+		if err != nil {
+			return nil, err
+		}
+		for _, plan := range allPlans {
+			counts[[2]string{"plan", plan.PlanStatus}]++
+		}
+		samples := make([]Sample, 0, len(counts))
+		for labels, count := range counts {
+			samples = append(samples, Sample{Values: []string{labels[0], labels[1]}, Value: count})
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			return key(samples[i].Values) < key(samples[j].Values)
+		})
+		return samples, nil
+	}
+}
+
+// taskStatus returns the status of a task from its Started, Completed and Failed flags.
+func taskStatus(task *models.Task) string {
+	switch {
+	case task.Failed:
+		return models.Fail
+	case task.Completed:
+		return models.Completed
+	case task.Started:
+		return models.InProgress
+	}
+	return models.NotStarted
+}
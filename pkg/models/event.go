@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded by an event. EventCompleted follows the update that completes a task,
+// EventOverdue is recorded when the deadline of a goal that is not completed passes,
+// and EventRestored when a record is brought back from the trash.
+const (
+	EventCreated   = "created"
+	EventUpdated   = "updated"
+	EventDeleted   = "deleted"
+	EventCompleted = "completed"
+	EventOverdue   = "overdue"
+	EventRestored  = "restored"
+)
+
+// Event records a change to a task, goal, plan, planner or version.
+//
+// Fields:
+// - Id: assigned by the event log in increasing order; clients resume a stream from it.
+// - Type: the resource and the action, such as "task.created", "task.completed" or "goal.overdue".
+// - Resource: the kind of record that changed: "task", "goal", "plan", "planner" or "version".
+// - ResourceId: the id of the record that changed.
+// - PlannerId: the planner the record belongs to, if any; only members of the planner receive the event.
+// - Revision: the revision of the record after the change.
+// - Time: when the change was made.
+// - Data: the record after the change, or before it for a deletion.
+type Event struct {
+	Id         int             `json:"id" storm:"id,increment"`
+	Type       string          `json:"type"`
+	Resource   string          `json:"resource"`
+	ResourceId string          `json:"resource_id"`
+	PlannerId  string          `json:"planner_id,omitempty"`
+	Revision   int             `json:"revision,omitempty"`
+	Time       time.Time       `json:"time"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// EventType returns the type of an event about an action on a resource, such as "task.created".
+func EventType(resource, action string) string {
+	return resource + "." + action
+}
@@ -5,14 +5,17 @@ import "time"
 // Goal represents a specific objective that a user wants to achieve.
 //
 //	type Goal struct {
-//	    Id            string    `json:"id" storm:"id,unique"`
-//	    Objective     string    `json:"objective"`
-//	    Plans         []Plan    `json:"plans"`
-//	    GoalStatus    string    `json:"goal_status"`
-//	    GoalCreatedAt time.Time `json:"goal_created_at"`
-//	    GoalUpdatedAt time.Time `json:"goal_updated_at"`
-//	    Deadline      time.Time `json:"deadline"`
-//	    PlannerID     string    `json:"planner_id"`
+//	    Id            string     `json:"id" storm:"id,unique"`
+//	    Objective     string     `json:"objective"`
+//	    Plans         []Plan     `json:"plans"`
+//	    GoalStatus    string     `json:"goal_status"`
+//	    GoalCreatedAt time.Time  `json:"goal_created_at"`
+//	    GoalUpdatedAt time.Time  `json:"goal_updated_at"`
+//	    Deadline      time.Time  `json:"deadline"`
+//	    PlannerID     string     `json:"planner_id"`
+//	    Revision      int        `json:"revision"`
+//	    DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+//	    DeletedWith   string     `json:"deleted_with,omitempty"`
 //	}
 //
 // The Goal struct has the following fields:
@@ -25,18 +28,24 @@ import "time"
 // - GoalUpdatedAt: The last time the goal was updated.
 // - Deadline: The date and time when the goal should be achieved.
 // - PlannerId: The identifier of the planner associated with the goal.
+// - Revision: Incremented by the store on every update, used for optimistic concurrency control.
+// - DeletedAt: Set once the goal is in the trash.
+// - DeletedWith: The id of the planner whose deletion took the goal to the trash, or empty if the goal was deleted itself.
 //
 // The Goal struct is used in conjunction with the Plan struct, which represents an action plan for achieving the goal.
 // Each goal can have one or more plans associated with it.
 type Goal struct {
-	Id            string    `json:"id" storm:"id,unique"`
-	Objective     string    `json:"objective"`
-	Plans         []Plan    `json:"plans"`
-	GoalStatus    string    `json:"goal_status"`
-	GoalCreatedAt time.Time `json:"goal_created_at"`
-	GoalUpdatedAt time.Time `json:"goal_updated_at"`
-	Deadline      time.Time `json:"deadline"`
-	PlannerId     string    `json:"planner_id"`
+	Id            string     `json:"id" storm:"id,unique"`
+	Objective     string     `json:"objective"`
+	Plans         []Plan     `json:"plans"`
+	GoalStatus    string     `json:"goal_status"`
+	GoalCreatedAt time.Time  `json:"goal_created_at"`
+	GoalUpdatedAt time.Time  `json:"goal_updated_at"`
+	Deadline      time.Time  `json:"deadline"`
+	PlannerId     string     `json:"planner_id"`
+	Revision      int        `json:"revision"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	DeletedWith   string     `json:"deleted_with,omitempty"`
 }
 
 // GenerateGoalInstance generates a new instance of the Goal struct with the provided id, objective, and deadline. It sets the GoalStatus to "Not Started", GoalCreatedAt and GoalUpdatedAt
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JournalEntry records a change a user made to a task, goal, plan or planner, so that they can undo it and redo it.
+//
+// Fields:
+// - Id: assigned by the journal in increasing order.
+// - User: the user who made the change; empty for the user of the local daemon, who has no token.
+// - Action: EventCreated, EventUpdated, EventDeleted or EventRestored.
+// - Resource: the kind of record that changed: "task", "goal", "plan" or "planner".
+// - ResourceId, Name: the id of the record and its title, objective or plan name.
+// - Before: the record before the change; empty for a creation or a restore from the trash.
+// - After: the record after the change; empty for a deletion.
+// - Revision: the revision the record must still be at for the change to be undone, or once undone, redone;
+// 0 if the record must not exist. A record at another revision has been changed by someone else since.
+// - Undone: the change has been undone and may be redone.
+// - Time: when the change was made.
+type JournalEntry struct {
+	Id         int             `json:"id" storm:"id,increment"`
+	User       string          `json:"user,omitempty"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceId string          `json:"resource_id"`
+	Name       string          `json:"name,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Revision   int             `json:"revision"`
+	Undone     bool            `json:"undone"`
+	Time       time.Time       `json:"time"`
+}
@@ -3,17 +3,22 @@ package models
 import "time"
 
 // Plan represents a plan.
+// DeletedAt is set once the plan is in the trash; DeletedWith is then the id of the goal or planner whose deletion
+// took it along, or empty if the plan was deleted itself.
 type Plan struct {
-	Id              string    `json:"id" storm:"id,unique"`
-	PlanName        string    `json:"plan_name"`
-	PlanDescription string    `json:"plan_description"`
-	PlanDate        time.Time `json:"plan_date"`
-	PlanTime        time.Time `json:"plan_time"`
-	PlanStatus      string    `json:"plan_status"`
-	Tasks           []Task    `json:"tasks"`
-	PlanCreatedAt   time.Time `json:"plan_created_at"`
-	PlanUpdatedAt   time.Time `json:"plan_updated_at"`
-	GoalId          string    `json:"goal_id"`
+	Id              string     `json:"id" storm:"id,unique"`
+	PlanName        string     `json:"plan_name"`
+	PlanDescription string     `json:"plan_description"`
+	PlanDate        time.Time  `json:"plan_date"`
+	PlanTime        time.Time  `json:"plan_time"`
+	PlanStatus      string     `json:"plan_status"`
+	Tasks           []Task     `json:"tasks"`
+	PlanCreatedAt   time.Time  `json:"plan_created_at"`
+	PlanUpdatedAt   time.Time  `json:"plan_updated_at"`
+	GoalId          string     `json:"goal_id"`
+	Revision        int        `json:"revision"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	DeletedWith     string     `json:"deleted_with,omitempty"`
 }
 
 // GeneratePlanInstance is a method of the Plan struct that creates a new instance of a plan with the given information.
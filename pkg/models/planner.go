@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Constants representing the different states of a task
 const (
 	NotStarted = "Not Started"
@@ -8,12 +10,56 @@ const (
 	Fail       = "Fail"
 )
 
+// Roles a user can have in a planner, from the least to the most privileged.
+// A viewer can read the planner and its goals, plans and tasks, an editor can also change them,
+// and an owner can also delete the planner and share it with other users.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// roleRanks orders the roles by privilege.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
 // Planner represents a planner object with its attributes.
+// UserId is the user the planner belongs to, who is always an owner of it.
+// Members maps the names of the other users the planner is shared with to their role.
+// DeletedAt is set once the planner is in the trash, with its goals, plans and tasks.
 type Planner struct {
-	Id     string `json:"id" storm:"id,unique"`
-	Title  string `json:"title"`
-	UserId string `json:"user_id"`
-	Goals  []Goal `json:"goals"`
+	Id        string            `json:"id" storm:"id,unique"`
+	Title     string            `json:"title"`
+	UserId    string            `json:"user_id"`
+	Members   map[string]string `json:"members,omitempty"`
+	Goals     []Goal            `json:"goals"`
+	Revision  int               `json:"revision"`
+	DeletedAt *time.Time        `json:"deleted_at,omitempty"`
+}
+
+// RoleOf returns the role of the named user in the planner: RoleOwner for the user the planner belongs to,
+// the role in Members for anyone else, or an empty string if the planner is not shared with the user.
+func (p *Planner) RoleOf(user string) string {
+	if user != "" && user == p.UserId {
+		return RoleOwner
+	}
+	return p.Members[user]
+}
+
+// ValidRole reports whether role is one of RoleViewer, RoleEditor and RoleOwner.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants at least the permissions of required.
+// An empty or unknown role allows nothing.
+func RoleAllows(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
 }
 
 // GeneratePlannerInstance generates a new instance of Planner with the given id, title, and userId.
@@ -33,3 +33,26 @@ func createPlanner() *Planner {
 		Goals:  []Goal{},
 	}
 }
+
+func TestPlanner_RoleOf(t *testing.T) {
+	p := createPlanner()
+	p.Members = map[string]string{"bob": RoleEditor, "carol": RoleViewer}
+
+	cases := []struct {
+		user, required string
+		allowed        bool
+	}{
+		{"test-userId", RoleOwner, true},
+		{"bob", RoleEditor, true},
+		{"bob", RoleOwner, false},
+		{"carol", RoleViewer, true},
+		{"carol", RoleEditor, false},
+		{"dave", RoleViewer, false},
+		{"", RoleViewer, false},
+	}
+	for _, c := range cases {
+		if got := RoleAllows(p.RoleOf(c.user), c.required); got != c.allowed {
+			t.Errorf("RoleAllows(RoleOf(%q), %q) = %v, expected %v", c.user, c.required, got, c.allowed)
+		}
+	}
+}
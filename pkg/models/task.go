@@ -3,15 +3,24 @@ package models
 import "time"
 
 // Task represents a to-do item
+// PlanId is the plan the task belongs to, if any; the task is shared with the members of the plan's planner.
+// Failed marks a task that was given up on, which is not Completed.
+// DeletedAt is set once the task is in the trash; DeletedWith is then the id of the record whose deletion took it along,
+// or empty if the task was deleted itself.
 type Task struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Owner       string    `json:"owner"`
-	Started     bool      `json:"started"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Owner       string     `json:"owner"`
+	PlanId      string     `json:"plan_id,omitempty"`
+	Started     bool       `json:"started"`
+	Completed   bool       `json:"completed"`
+	Failed      bool       `json:"failed"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Revision    int        `json:"revision"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	DeletedWith string     `json:"deleted_with,omitempty"`
 }
 
 // GenerateTaskInstance generates a new instance of the Task struct with the provided parameters.
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// TrashItem describes a deleted task, goal, plan or planner, which stays in the trash until it is restored or the trash is emptied.
+//
+// Fields:
+// - Resource: the kind of record: "task", "goal", "plan" or "planner".
+// - Id, Name: the id of the record and its title, objective or plan name.
+// - ParentId: the planner of a goal, the goal of a plan or the plan of a task, if any.
+// - DeletedAt: when the record was deleted.
+// - DeletedWith: the id of the record whose deletion took this one along, or empty if it was deleted itself.
+// A record is restored and purged together with the records deleted with it.
+// - Revision: the revision of the record.
+// - Record: the deleted record itself; it is not part of the JSON of the item.
+type TrashItem struct {
+	Resource    string      `json:"resource"`
+	Id          string      `json:"id"`
+	Name        string      `json:"name,omitempty"`
+	ParentId    string      `json:"parent_id,omitempty"`
+	DeletedAt   time.Time   `json:"deleted_at"`
+	DeletedWith string      `json:"deleted_with,omitempty"`
+	Revision    int         `json:"revision"`
+	Record      interface{} `json:"-"`
+}
+
+// Root returns the id of the record whose deletion put the item in the trash: DeletedWith, or the item's own id.
+func (i *TrashItem) Root() string {
+	if i.DeletedWith != "" {
+		return i.DeletedWith
+	}
+	return i.Id
+}
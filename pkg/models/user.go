@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// User represents a person or program allowed to call the server.
+// Name identifies the user: it is the default Owner of the tasks and the default UserId of the planners the user creates.
+type User struct {
+	Id        string    `json:"id" storm:"id,unique"`
+	Name      string    `json:"name" storm:"unique"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Token represents an API token of a user.
+// Only the SHA-256 hash of the token is stored; the token itself is shown once, when it is created.
+type Token struct {
+	Id        string    `json:"id" storm:"id,unique"`
+	UserId    string    `json:"user_id" storm:"index"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash" storm:"unique"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Webhook represents a subscription of an outside service to the events of the server.
+// Every event that matches Types and PlannerId, and that Owner is allowed to see, is POSTed to URL
+// as JSON, signed with Secret.
+//
+// Fields:
+// - Id: Identifier of the webhook. It must be unique.
+// - URL: the http or https URL the events are sent to.
+// - Types: the event types ("task.completed") or resources ("goal") to send; all events if empty.
+// - PlannerId: only send events about records of this planner, if set.
+// - Secret: the key of the HMAC-SHA256 signature of each payload. It is only returned when the webhook is created.
+// - Owner: the user who created the webhook, or empty for webhooks added with "flow webhook add".
+// - CreatedAt: when the webhook was created.
+type Webhook struct {
+	Id        string    `json:"id" storm:"id,unique"`
+	URL       string    `json:"url"`
+	Types     []string  `json:"types,omitempty"`
+	PlannerId string    `json:"planner_id,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	Owner     string    `json:"owner,omitempty" storm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Delivery records one attempt to send an event to a webhook.
+//
+// Fields:
+// - Id: assigned by the delivery log in increasing order.
+// - WebhookId: the webhook the event was sent to.
+// - EventId, EventType: the event that was sent.
+// - Attempt: 1 for the first attempt, incremented on every retry.
+// - StatusCode: the HTTP status of the response, or 0 if no response was received.
+// - Error: why the attempt failed, empty if it succeeded.
+// - Time: when the attempt was made.
+type Delivery struct {
+	Id         int       `json:"id" storm:"id,increment"`
+	WebhookId  string    `json:"webhook_id" storm:"index"`
+	EventId    int       `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Succeeded reports whether the attempt was answered with a 2xx status.
+func (d *Delivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode <= 299
+}
+
+// PendingDelivery is an event a webhook has not received yet, kept so that a restarted server resumes the delivery.
+//
+// Fields:
+// - Id: the webhook id and the event id, joined by a colon; see PendingDeliveryId.
+// - WebhookId, EventId: the webhook and the event to send to it.
+// - Attempt: the attempt to make next, 1 for the first one.
+// - Due: when to make it.
+type PendingDelivery struct {
+	Id        string    `json:"id" storm:"id"`
+	WebhookId string    `json:"webhook_id" storm:"index"`
+	EventId   int       `json:"event_id"`
+	Attempt   int       `json:"attempt"`
+	Due       time.Time `json:"due"`
+}
+
+// PendingDeliveryId returns the id of the pending delivery of an event to a webhook.
+func PendingDeliveryId(webhookId string, eventId int) string {
+	return webhookId + ":" + strconv.Itoa(eventId)
+}
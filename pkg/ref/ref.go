@@ -0,0 +1,108 @@
+// Package ref resolves the references to tasks, goals, plans and planners that users type instead of
+// their full ids: a unique prefix of the id, as git accepts for commits, or the name of the record after
+// an @, such as @"Ship v2". The cli and the HTTP API resolve references the same way.
+package ref
+
+import (
+	"fmt"
+	"github.com/asdine/storm"
+	"strconv"
+	"strings"
+)
+
+// MinPrefix is the shortest id prefix a reference may be; shorter references are only matched as whole ids.
+const MinPrefix = 4
+
+// idLength is the length of the UUIDs the controls give records. A reference of that length is taken
+// as an id without looking the records up.
+const idLength = 36
+
+// Candidate is a record a reference may name: its id and its name, which is the title of a task or planner,
+// the objective of a goal or the name of a plan.
+type Candidate struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AmbiguousError is returned when a reference matches more than one record. It lists the records,
+// so that the user can pick one with a longer prefix or its id.
+type AmbiguousError struct {
+	Kind       string
+	Ref        string
+	Candidates []Candidate
+}
+
+// Error names the reference and lists every candidate it matches, one per line, with its id and name.
+func (e *AmbiguousError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s is ambiguous; it matches:", e.Kind, e.Ref)
+	for _, c := range e.Candidates {
+		fmt.Fprintf(&b, "\n  %s  %s", c.Id, c.Name)
+	}
+	return b.String()
+}
+
+// IsName reports whether ref refers to a record by its name.
+func IsName(ref string) bool {
+	return strings.HasPrefix(ref, "@")
+}
+
+// name returns the name a name reference gives: the text after the @, unquoted if it is quoted.
+func name(ref string) string {
+	name := strings.TrimSpace(strings.TrimPrefix(ref, "@"))
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		return unquoted
+	}
+	return name
+}
+
+// Resolve returns the id of the record of the given kind, such as "task", that ref refers to.
+// list returns the records ref may refer to, which are only listed if ref is not a whole id.
+//
+// A reference starting with @ names the record, exactly or else regardless of case; any other reference
+// is an id or a prefix of one at least MinPrefix long. Resolve returns an error wrapping storm.ErrNotFound
+// if no record matches and an *AmbiguousError if several do.
+func Resolve(kind, ref string, list func() ([]Candidate, error)) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if !IsName(ref) && len(ref) >= idLength {
+		return ref, nil
+	}
+	if ref == "" || ref == "@" {
+		return "", fmt.Errorf("no %s given: %w", kind, storm.ErrNotFound)
+	}
+	candidates, err := list()
+	if err != nil {
+		return "", err
+	}
+	var matches []Candidate
+	if IsName(ref) {
+		matches = match(candidates, func(c Candidate) bool { return c.Name == name(ref) })
+		if len(matches) == 0 {
+			matches = match(candidates, func(c Candidate) bool { return strings.EqualFold(c.Name, name(ref)) })
+		}
+	} else {
+		matches = match(candidates, func(c Candidate) bool { return c.Id == ref })
+		if len(matches) == 0 && len(ref) >= MinPrefix {
+			matches = match(candidates, func(c Candidate) bool { return strings.HasPrefix(c.Id, ref) })
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %s: %w", kind, ref, storm.ErrNotFound)
+	case 1:
+		return matches[0].Id, nil
+	default:
+		return "", &AmbiguousError{Kind: kind, Ref: ref, Candidates: matches}
+	}
+}
+
+// match returns the candidates for which ok is true.
+func match(candidates []Candidate, ok func(Candidate) bool) []Candidate {
+	var matches []Candidate
+	for _, c := range candidates {
+		if ok(c) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,55 @@
+package ref
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	candidates := []Candidate{
+		{Id: "3fa85f64-5717-4562-b3fc-2c963f66afa6", Name: "Ship v2"},
+		{Id: "3fa8aa00-5717-4562-b3fc-2c963f66afa6", Name: "Tag the release"},
+		{Id: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Name: "ship v2"},
+		{Id: "7c9e", Name: "Short"},
+	}
+	listed := 0
+	list := func() ([]Candidate, error) {
+		listed++
+		return candidates, nil
+	}
+	resolve := func(ref string) string {
+		id, err := Resolve("task", ref, list)
+		assert.NoError(t, err, ref)
+		return id
+	}
+
+	// a whole id is taken as it is, without listing the records
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", resolve("00000000-0000-0000-0000-000000000000"))
+	assert.Equal(t, 0, listed)
+
+	assert.Equal(t, candidates[0].Id, resolve("3fa85"))
+	assert.Equal(t, candidates[2].Id, resolve(" 7c9e6 "))
+	// an exact id wins over the ids it prefixes
+	assert.Equal(t, "7c9e", resolve("7c9e"))
+	assert.Equal(t, candidates[0].Id, resolve(`@"Ship v2"`))
+	assert.Equal(t, candidates[2].Id, resolve("@ship v2"))
+	assert.Equal(t, candidates[1].Id, resolve("@TAG THE RELEASE"))
+
+	_, err := Resolve("task", "3fa8", list)
+	var ambiguous *AmbiguousError
+	assert.True(t, errors.As(err, &ambiguous))
+	assert.Equal(t, candidates[:2], ambiguous.Candidates)
+	assert.Contains(t, err.Error(), "3fa85f64-5717-4562-b3fc-2c963f66afa6  Ship v2")
+
+	// prefixes shorter than MinPrefix and unknown names are not found
+	for _, ref := range []string{"3fa", "ffff", "@Nothing", "@", ""} {
+		_, err = Resolve("task", ref, list)
+		assert.ErrorIs(t, err, storm.ErrNotFound, ref)
+	}
+
+	listErr := errors.New("list failed")
+	_, err = Resolve("task", "3fa8", func() ([]Candidate, error) { return nil, listErr })
+	assert.ErrorIs(t, err, listErr)
+}
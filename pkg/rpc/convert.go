@@ -0,0 +1,143 @@
+package rpc
+
+import (
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// timestamp converts a time into a protobuf timestamp; the zero time is left unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// listRequest converts the paging and filters of a list call into a handle.ListRequest.
+func listRequest(req *flowpb.ListRequest) *handle.ListRequest {
+	return &handle.ListRequest{
+		Limit:          int(req.GetLimit()),
+		Cursor:         req.GetCursor(),
+		Sort:           req.GetSort(),
+		Owner:          req.GetOwner(),
+		Status:         req.GetStatus(),
+		DeadlineBefore: req.GetDeadlineBefore(),
+		PlannerId:      req.GetPlannerId(),
+		GoalId:         req.GetGoalId(),
+	}
+}
+
+// taskMessage converts a task returned by the task control into its protobuf message.
+func taskMessage(task *handle.GetTaskResponse) *flowpb.Task {
+	return &flowpb.Task{
+		Id:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Owner:       task.Owner,
+		PlanId:      task.PlanId,
+		Started:     task.Started,
+		Completed:   task.Completed,
+		Failed:      task.Failed,
+		CreatedAt:   timestamp(task.CreatedAt),
+		UpdatedAt:   timestamp(task.UpdatedAt),
+		Revision:    int64(task.Revision),
+	}
+}
+
+// goalMessage converts a goal into its protobuf message.
+func goalMessage(goal *models.Goal) *flowpb.Goal {
+	return &flowpb.Goal{
+		Id:        goal.Id,
+		Objective: goal.Objective,
+		Status:    goal.GoalStatus,
+		Deadline:  timestamp(goal.Deadline),
+		CreatedAt: timestamp(goal.GoalCreatedAt),
+		UpdatedAt: timestamp(goal.GoalUpdatedAt),
+		PlannerId: goal.PlannerId,
+		Revision:  int64(goal.Revision),
+	}
+}
+
+// planMessage converts a plan into its protobuf message, with its date and time in the formats of CreatePlanRequest.
+func planMessage(plan *models.Plan) *flowpb.Plan {
+	return &flowpb.Plan{
+		Id:          plan.Id,
+		Name:        plan.PlanName,
+		Description: plan.PlanDescription,
+		Date:        plan.PlanDate.Format("2006-01-02"),
+		Time:        plan.PlanTime.Format("15:04"),
+		Status:      plan.PlanStatus,
+		CreatedAt:   timestamp(plan.PlanCreatedAt),
+		UpdatedAt:   timestamp(plan.PlanUpdatedAt),
+		GoalId:      plan.GoalId,
+		Revision:    int64(plan.Revision),
+	}
+}
+
+// plannerMessage converts a planner returned by the planner control into its protobuf message.
+func plannerMessage(planner *handle.GetPlannerResponse) *flowpb.Planner {
+	return &flowpb.Planner{
+		Id:       planner.Id,
+		Title:    planner.Title,
+		UserId:   planner.UserId,
+		Members:  planner.Members,
+		Revision: int64(planner.Revision),
+	}
+}
+
+// versionMessage converts a version and the snapshot it holds into its protobuf message.
+func versionMessage(version *models.Version) *flowpb.Version {
+	msg := &flowpb.Version{
+		Id:        string(version.ID),
+		GoalId:    string(version.GoalID),
+		PlanId:    string(version.PlanID),
+		TaskId:    string(version.TaskID),
+		Major:     int32(version.No.Major),
+		Minor:     int32(version.No.Minor),
+		Patch:     int32(version.No.Patch),
+		CreatedAt: timestamp(version.CreatedAt),
+		CreatedBy: version.CreatedBy,
+	}
+	if version.PreviousVersion != nil {
+		msg.PreviousVersionId = string(version.PreviousVersion.ID)
+	}
+	if version.Image.Goal != nil {
+		msg.Goal = goalMessage(version.Image.Goal)
+	}
+	for _, plan := range version.Image.Plans {
+		msg.Plans = append(msg.Plans, planMessage(plan))
+	}
+	for _, task := range version.Image.Tasks {
+		msg.Tasks = append(msg.Tasks, taskMessage(&handle.GetTaskResponse{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Owner:       task.Owner,
+			PlanId:      task.PlanId,
+			Started:     task.Started,
+			Completed:   task.Completed,
+			Failed:      task.Failed,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+			Revision:    task.Revision,
+		}))
+	}
+	return msg
+}
+
+// eventMessage converts an event into its protobuf message.
+func eventMessage(event *models.Event) *flowpb.Event {
+	return &flowpb.Event{
+		Id:         int64(event.Id),
+		Type:       event.Type,
+		Resource:   event.Resource,
+		ResourceId: event.ResourceId,
+		PlannerId:  event.PlannerId,
+		Revision:   int64(event.Revision),
+		Time:       timestamp(event.Time),
+		Data:       event.Data,
+	}
+}
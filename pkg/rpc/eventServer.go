@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/grpc/metadata"
+)
+
+// events returns the event control that serves a call: with Access set, it only streams the events
+// of the planners shared with the authenticated caller.
+func (s *Server) events(ctx context.Context) *handle.EventControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Event
+	}
+	return s.Event.As(s.Access, user)
+}
+
+// WatchChanges streams the changes to tasks, goals, plans, planners and versions, like the Server-Sent Events
+// of the HTTP API. A client that reconnects with the id of the last event it received in after first receives
+// the logged events it missed. The stream ends when the client cancels it or falls too far behind,
+// in which case it should reconnect.
+func (s *Server) WatchChanges(req *flowpb.WatchChangesRequest, stream flowpb.Flow_WatchChangesServer) error {
+	ctx := stream.Context()
+	after := int(req.GetAfter())
+	logged, sub, err := s.events(ctx).StreamEvents(&handle.StreamEventsRequest{
+		After:     after,
+		PlannerId: req.GetPlannerId(),
+		Types:     req.GetTypes(),
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	defer sub.Close()
+	// the header tells the client that the subscription is in place before the first event arrives
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	last := after
+	for _, event := range logged {
+		if err := stream.Send(eventMessage(event)); err != nil {
+			return err
+		}
+		last = event.Id
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if event.Id <= last {
+				continue
+			}
+			if err := stream.Send(eventMessage(event)); err != nil {
+				return err
+			}
+			last = event.Id
+		}
+	}
+}
@@ -0,0 +1,1558 @@
+// The gRPC API of the flow server. It offers the task, goal, plan, planner and version operations
+// of pkg/handle, with the same access checks as the HTTP API, and a stream of changes.
+//
+// Every call must carry an API token in the "authorization" metadata ("Bearer <token>") or in "x-api-key".
+//
+// The Go code in pkg/rpc/flowpb is generated from this file with protoc-gen-go and protoc-gen-go-grpc:
+//
+//	protoc --go_out=. --go_opt=module=github.com/ooyeku/flow \
+//	  --go-grpc_out=. --go-grpc_opt=module=github.com/ooyeku/flow api/proto/flow.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: api/proto/flow.proto
+
+package flowpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Flow_CreateTask_FullMethodName         = "/flow.v1.Flow/CreateTask"
+	Flow_GetTask_FullMethodName            = "/flow.v1.Flow/GetTask"
+	Flow_GetTaskByTitle_FullMethodName     = "/flow.v1.Flow/GetTaskByTitle"
+	Flow_GetTasksByOwner_FullMethodName    = "/flow.v1.Flow/GetTasksByOwner"
+	Flow_ListTasks_FullMethodName          = "/flow.v1.Flow/ListTasks"
+	Flow_UpdateTask_FullMethodName         = "/flow.v1.Flow/UpdateTask"
+	Flow_PatchTask_FullMethodName          = "/flow.v1.Flow/PatchTask"
+	Flow_DeleteTask_FullMethodName         = "/flow.v1.Flow/DeleteTask"
+	Flow_CreateGoal_FullMethodName         = "/flow.v1.Flow/CreateGoal"
+	Flow_GetGoal_FullMethodName            = "/flow.v1.Flow/GetGoal"
+	Flow_GetGoalByObjective_FullMethodName = "/flow.v1.Flow/GetGoalByObjective"
+	Flow_GetGoalsByPlanner_FullMethodName  = "/flow.v1.Flow/GetGoalsByPlanner"
+	Flow_ListGoals_FullMethodName          = "/flow.v1.Flow/ListGoals"
+	Flow_UpdateGoal_FullMethodName         = "/flow.v1.Flow/UpdateGoal"
+	Flow_PatchGoal_FullMethodName          = "/flow.v1.Flow/PatchGoal"
+	Flow_DeleteGoal_FullMethodName         = "/flow.v1.Flow/DeleteGoal"
+	Flow_CreatePlan_FullMethodName         = "/flow.v1.Flow/CreatePlan"
+	Flow_GetPlan_FullMethodName            = "/flow.v1.Flow/GetPlan"
+	Flow_GetPlanByName_FullMethodName      = "/flow.v1.Flow/GetPlanByName"
+	Flow_GetPlansByGoal_FullMethodName     = "/flow.v1.Flow/GetPlansByGoal"
+	Flow_ListPlans_FullMethodName          = "/flow.v1.Flow/ListPlans"
+	Flow_UpdatePlan_FullMethodName         = "/flow.v1.Flow/UpdatePlan"
+	Flow_PatchPlan_FullMethodName          = "/flow.v1.Flow/PatchPlan"
+	Flow_DeletePlan_FullMethodName         = "/flow.v1.Flow/DeletePlan"
+	Flow_CreatePlanner_FullMethodName      = "/flow.v1.Flow/CreatePlanner"
+	Flow_GetPlanner_FullMethodName         = "/flow.v1.Flow/GetPlanner"
+	Flow_GetPlannerByTitle_FullMethodName  = "/flow.v1.Flow/GetPlannerByTitle"
+	Flow_GetPlannersByOwner_FullMethodName = "/flow.v1.Flow/GetPlannersByOwner"
+	Flow_ListPlanners_FullMethodName       = "/flow.v1.Flow/ListPlanners"
+	Flow_UpdatePlanner_FullMethodName      = "/flow.v1.Flow/UpdatePlanner"
+	Flow_PatchPlanner_FullMethodName       = "/flow.v1.Flow/PatchPlanner"
+	Flow_DeletePlanner_FullMethodName      = "/flow.v1.Flow/DeletePlanner"
+	Flow_SharePlanner_FullMethodName       = "/flow.v1.Flow/SharePlanner"
+	Flow_CreateVersion_FullMethodName      = "/flow.v1.Flow/CreateVersion"
+	Flow_GetVersion_FullMethodName         = "/flow.v1.Flow/GetVersion"
+	Flow_GetPreviousVersion_FullMethodName = "/flow.v1.Flow/GetPreviousVersion"
+	Flow_ListVersions_FullMethodName       = "/flow.v1.Flow/ListVersions"
+	Flow_UpdateVersion_FullMethodName      = "/flow.v1.Flow/UpdateVersion"
+	Flow_WatchChanges_FullMethodName       = "/flow.v1.Flow/WatchChanges"
+)
+
+// FlowClient is the client API for Flow service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FlowClient interface {
+	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	GetTask(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Task, error)
+	GetTaskByTitle(ctx context.Context, in *GetTaskByTitleRequest, opts ...grpc.CallOption) (*Task, error)
+	GetTasksByOwner(ctx context.Context, in *GetByOwnerRequest, opts ...grpc.CallOption) (*TaskList, error)
+	ListTasks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*TaskList, error)
+	UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchTask(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Task, error)
+	DeleteTask(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreateGoal(ctx context.Context, in *CreateGoalRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	GetGoal(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Goal, error)
+	GetGoalByObjective(ctx context.Context, in *GetGoalByObjectiveRequest, opts ...grpc.CallOption) (*Goal, error)
+	GetGoalsByPlanner(ctx context.Context, in *GetGoalsByPlannerRequest, opts ...grpc.CallOption) (*GoalList, error)
+	ListGoals(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*GoalList, error)
+	UpdateGoal(ctx context.Context, in *UpdateGoalRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchGoal(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Goal, error)
+	DeleteGoal(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	GetPlan(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Plan, error)
+	GetPlanByName(ctx context.Context, in *GetPlanByNameRequest, opts ...grpc.CallOption) (*Plan, error)
+	GetPlansByGoal(ctx context.Context, in *GetPlansByGoalRequest, opts ...grpc.CallOption) (*PlanList, error)
+	ListPlans(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PlanList, error)
+	UpdatePlan(ctx context.Context, in *UpdatePlanRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchPlan(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Plan, error)
+	DeletePlan(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CreatePlanner(ctx context.Context, in *CreatePlannerRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	GetPlanner(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Planner, error)
+	GetPlannerByTitle(ctx context.Context, in *GetPlannerByTitleRequest, opts ...grpc.CallOption) (*Planner, error)
+	GetPlannersByOwner(ctx context.Context, in *GetByOwnerRequest, opts ...grpc.CallOption) (*PlannerList, error)
+	ListPlanners(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PlannerList, error)
+	UpdatePlanner(ctx context.Context, in *UpdatePlannerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PatchPlanner(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Planner, error)
+	DeletePlanner(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SharePlanner(ctx context.Context, in *SharePlannerRequest, opts ...grpc.CallOption) (*Planner, error)
+	CreateVersion(ctx context.Context, in *CreateVersionRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	GetVersion(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Version, error)
+	GetPreviousVersion(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Version, error)
+	ListVersions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionList, error)
+	UpdateVersion(ctx context.Context, in *UpdateVersionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// WatchChanges streams the changes to the records the caller can see, as the HTTP API does at /api/v1/events.
+	// A client that reconnects with the id of the last event it received in after first receives the events it missed.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Flow_WatchChangesClient, error)
+}
+
+type flowClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFlowClient(cc grpc.ClientConnInterface) FlowClient {
+	return &flowClient{cc}
+}
+
+func (c *flowClient) CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Flow_CreateTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetTask(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Flow_GetTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetTaskByTitle(ctx context.Context, in *GetTaskByTitleRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Flow_GetTaskByTitle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetTasksByOwner(ctx context.Context, in *GetByOwnerRequest, opts ...grpc.CallOption) (*TaskList, error) {
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, Flow_GetTasksByOwner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) ListTasks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*TaskList, error) {
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, Flow_ListTasks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_UpdateTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) PatchTask(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Flow_PatchTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) DeleteTask(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_DeleteTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) CreateGoal(ctx context.Context, in *CreateGoalRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Flow_CreateGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetGoal(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Goal, error) {
+	out := new(Goal)
+	err := c.cc.Invoke(ctx, Flow_GetGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetGoalByObjective(ctx context.Context, in *GetGoalByObjectiveRequest, opts ...grpc.CallOption) (*Goal, error) {
+	out := new(Goal)
+	err := c.cc.Invoke(ctx, Flow_GetGoalByObjective_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetGoalsByPlanner(ctx context.Context, in *GetGoalsByPlannerRequest, opts ...grpc.CallOption) (*GoalList, error) {
+	out := new(GoalList)
+	err := c.cc.Invoke(ctx, Flow_GetGoalsByPlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) ListGoals(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*GoalList, error) {
+	out := new(GoalList)
+	err := c.cc.Invoke(ctx, Flow_ListGoals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) UpdateGoal(ctx context.Context, in *UpdateGoalRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_UpdateGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) PatchGoal(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Goal, error) {
+	out := new(Goal)
+	err := c.cc.Invoke(ctx, Flow_PatchGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) DeleteGoal(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_DeleteGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) CreatePlan(ctx context.Context, in *CreatePlanRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Flow_CreatePlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlan(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Plan, error) {
+	out := new(Plan)
+	err := c.cc.Invoke(ctx, Flow_GetPlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlanByName(ctx context.Context, in *GetPlanByNameRequest, opts ...grpc.CallOption) (*Plan, error) {
+	out := new(Plan)
+	err := c.cc.Invoke(ctx, Flow_GetPlanByName_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlansByGoal(ctx context.Context, in *GetPlansByGoalRequest, opts ...grpc.CallOption) (*PlanList, error) {
+	out := new(PlanList)
+	err := c.cc.Invoke(ctx, Flow_GetPlansByGoal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) ListPlans(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PlanList, error) {
+	out := new(PlanList)
+	err := c.cc.Invoke(ctx, Flow_ListPlans_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) UpdatePlan(ctx context.Context, in *UpdatePlanRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_UpdatePlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) PatchPlan(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Plan, error) {
+	out := new(Plan)
+	err := c.cc.Invoke(ctx, Flow_PatchPlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) DeletePlan(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_DeletePlan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) CreatePlanner(ctx context.Context, in *CreatePlannerRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Flow_CreatePlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlanner(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Planner, error) {
+	out := new(Planner)
+	err := c.cc.Invoke(ctx, Flow_GetPlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlannerByTitle(ctx context.Context, in *GetPlannerByTitleRequest, opts ...grpc.CallOption) (*Planner, error) {
+	out := new(Planner)
+	err := c.cc.Invoke(ctx, Flow_GetPlannerByTitle_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPlannersByOwner(ctx context.Context, in *GetByOwnerRequest, opts ...grpc.CallOption) (*PlannerList, error) {
+	out := new(PlannerList)
+	err := c.cc.Invoke(ctx, Flow_GetPlannersByOwner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) ListPlanners(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*PlannerList, error) {
+	out := new(PlannerList)
+	err := c.cc.Invoke(ctx, Flow_ListPlanners_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) UpdatePlanner(ctx context.Context, in *UpdatePlannerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_UpdatePlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) PatchPlanner(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Planner, error) {
+	out := new(Planner)
+	err := c.cc.Invoke(ctx, Flow_PatchPlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) DeletePlanner(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_DeletePlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) SharePlanner(ctx context.Context, in *SharePlannerRequest, opts ...grpc.CallOption) (*Planner, error) {
+	out := new(Planner)
+	err := c.cc.Invoke(ctx, Flow_SharePlanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) CreateVersion(ctx context.Context, in *CreateVersionRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := c.cc.Invoke(ctx, Flow_CreateVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetVersion(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Version, error) {
+	out := new(Version)
+	err := c.cc.Invoke(ctx, Flow_GetVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) GetPreviousVersion(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Version, error) {
+	out := new(Version)
+	err := c.cc.Invoke(ctx, Flow_GetPreviousVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) ListVersions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionList, error) {
+	out := new(VersionList)
+	err := c.cc.Invoke(ctx, Flow_ListVersions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) UpdateVersion(ctx context.Context, in *UpdateVersionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Flow_UpdateVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flowClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (Flow_WatchChangesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Flow_ServiceDesc.Streams[0], Flow_WatchChanges_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &flowWatchChangesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Flow_WatchChangesClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type flowWatchChangesClient struct {
+	grpc.ClientStream
+}
+
+func (x *flowWatchChangesClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FlowServer is the server API for Flow service.
+// All implementations must embed UnimplementedFlowServer
+// for forward compatibility
+type FlowServer interface {
+	CreateTask(context.Context, *CreateTaskRequest) (*CreateResponse, error)
+	GetTask(context.Context, *GetRequest) (*Task, error)
+	GetTaskByTitle(context.Context, *GetTaskByTitleRequest) (*Task, error)
+	GetTasksByOwner(context.Context, *GetByOwnerRequest) (*TaskList, error)
+	ListTasks(context.Context, *ListRequest) (*TaskList, error)
+	UpdateTask(context.Context, *UpdateTaskRequest) (*emptypb.Empty, error)
+	PatchTask(context.Context, *PatchRequest) (*Task, error)
+	DeleteTask(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreateGoal(context.Context, *CreateGoalRequest) (*CreateResponse, error)
+	GetGoal(context.Context, *GetRequest) (*Goal, error)
+	GetGoalByObjective(context.Context, *GetGoalByObjectiveRequest) (*Goal, error)
+	GetGoalsByPlanner(context.Context, *GetGoalsByPlannerRequest) (*GoalList, error)
+	ListGoals(context.Context, *ListRequest) (*GoalList, error)
+	UpdateGoal(context.Context, *UpdateGoalRequest) (*emptypb.Empty, error)
+	PatchGoal(context.Context, *PatchRequest) (*Goal, error)
+	DeleteGoal(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreatePlan(context.Context, *CreatePlanRequest) (*CreateResponse, error)
+	GetPlan(context.Context, *GetRequest) (*Plan, error)
+	GetPlanByName(context.Context, *GetPlanByNameRequest) (*Plan, error)
+	GetPlansByGoal(context.Context, *GetPlansByGoalRequest) (*PlanList, error)
+	ListPlans(context.Context, *ListRequest) (*PlanList, error)
+	UpdatePlan(context.Context, *UpdatePlanRequest) (*emptypb.Empty, error)
+	PatchPlan(context.Context, *PatchRequest) (*Plan, error)
+	DeletePlan(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	CreatePlanner(context.Context, *CreatePlannerRequest) (*CreateResponse, error)
+	GetPlanner(context.Context, *GetRequest) (*Planner, error)
+	GetPlannerByTitle(context.Context, *GetPlannerByTitleRequest) (*Planner, error)
+	GetPlannersByOwner(context.Context, *GetByOwnerRequest) (*PlannerList, error)
+	ListPlanners(context.Context, *ListRequest) (*PlannerList, error)
+	UpdatePlanner(context.Context, *UpdatePlannerRequest) (*emptypb.Empty, error)
+	PatchPlanner(context.Context, *PatchRequest) (*Planner, error)
+	DeletePlanner(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	SharePlanner(context.Context, *SharePlannerRequest) (*Planner, error)
+	CreateVersion(context.Context, *CreateVersionRequest) (*CreateResponse, error)
+	GetVersion(context.Context, *GetRequest) (*Version, error)
+	GetPreviousVersion(context.Context, *GetRequest) (*Version, error)
+	ListVersions(context.Context, *emptypb.Empty) (*VersionList, error)
+	UpdateVersion(context.Context, *UpdateVersionRequest) (*emptypb.Empty, error)
+	// WatchChanges streams the changes to the records the caller can see, as the HTTP API does at /api/v1/events.
+	// A client that reconnects with the id of the last event it received in after first receives the events it missed.
+	WatchChanges(*WatchChangesRequest, Flow_WatchChangesServer) error
+	mustEmbedUnimplementedFlowServer()
+}
+
+// UnimplementedFlowServer must be embedded to have forward compatible implementations.
+type UnimplementedFlowServer struct {
+}
+
+func (UnimplementedFlowServer) CreateTask(context.Context, *CreateTaskRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTask not implemented")
+}
+func (UnimplementedFlowServer) GetTask(context.Context, *GetRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedFlowServer) GetTaskByTitle(context.Context, *GetTaskByTitleRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskByTitle not implemented")
+}
+func (UnimplementedFlowServer) GetTasksByOwner(context.Context, *GetByOwnerRequest) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTasksByOwner not implemented")
+}
+func (UnimplementedFlowServer) ListTasks(context.Context, *ListRequest) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedFlowServer) UpdateTask(context.Context, *UpdateTaskRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTask not implemented")
+}
+func (UnimplementedFlowServer) PatchTask(context.Context, *PatchRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchTask not implemented")
+}
+func (UnimplementedFlowServer) DeleteTask(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTask not implemented")
+}
+func (UnimplementedFlowServer) CreateGoal(context.Context, *CreateGoalRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGoal not implemented")
+}
+func (UnimplementedFlowServer) GetGoal(context.Context, *GetRequest) (*Goal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGoal not implemented")
+}
+func (UnimplementedFlowServer) GetGoalByObjective(context.Context, *GetGoalByObjectiveRequest) (*Goal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGoalByObjective not implemented")
+}
+func (UnimplementedFlowServer) GetGoalsByPlanner(context.Context, *GetGoalsByPlannerRequest) (*GoalList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGoalsByPlanner not implemented")
+}
+func (UnimplementedFlowServer) ListGoals(context.Context, *ListRequest) (*GoalList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGoals not implemented")
+}
+func (UnimplementedFlowServer) UpdateGoal(context.Context, *UpdateGoalRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGoal not implemented")
+}
+func (UnimplementedFlowServer) PatchGoal(context.Context, *PatchRequest) (*Goal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchGoal not implemented")
+}
+func (UnimplementedFlowServer) DeleteGoal(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteGoal not implemented")
+}
+func (UnimplementedFlowServer) CreatePlan(context.Context, *CreatePlanRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePlan not implemented")
+}
+func (UnimplementedFlowServer) GetPlan(context.Context, *GetRequest) (*Plan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlan not implemented")
+}
+func (UnimplementedFlowServer) GetPlanByName(context.Context, *GetPlanByNameRequest) (*Plan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlanByName not implemented")
+}
+func (UnimplementedFlowServer) GetPlansByGoal(context.Context, *GetPlansByGoalRequest) (*PlanList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlansByGoal not implemented")
+}
+func (UnimplementedFlowServer) ListPlans(context.Context, *ListRequest) (*PlanList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlans not implemented")
+}
+func (UnimplementedFlowServer) UpdatePlan(context.Context, *UpdatePlanRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePlan not implemented")
+}
+func (UnimplementedFlowServer) PatchPlan(context.Context, *PatchRequest) (*Plan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchPlan not implemented")
+}
+func (UnimplementedFlowServer) DeletePlan(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePlan not implemented")
+}
+func (UnimplementedFlowServer) CreatePlanner(context.Context, *CreatePlannerRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePlanner not implemented")
+}
+func (UnimplementedFlowServer) GetPlanner(context.Context, *GetRequest) (*Planner, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlanner not implemented")
+}
+func (UnimplementedFlowServer) GetPlannerByTitle(context.Context, *GetPlannerByTitleRequest) (*Planner, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlannerByTitle not implemented")
+}
+func (UnimplementedFlowServer) GetPlannersByOwner(context.Context, *GetByOwnerRequest) (*PlannerList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlannersByOwner not implemented")
+}
+func (UnimplementedFlowServer) ListPlanners(context.Context, *ListRequest) (*PlannerList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPlanners not implemented")
+}
+func (UnimplementedFlowServer) UpdatePlanner(context.Context, *UpdatePlannerRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePlanner not implemented")
+}
+func (UnimplementedFlowServer) PatchPlanner(context.Context, *PatchRequest) (*Planner, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchPlanner not implemented")
+}
+func (UnimplementedFlowServer) DeletePlanner(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePlanner not implemented")
+}
+func (UnimplementedFlowServer) SharePlanner(context.Context, *SharePlannerRequest) (*Planner, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SharePlanner not implemented")
+}
+func (UnimplementedFlowServer) CreateVersion(context.Context, *CreateVersionRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVersion not implemented")
+}
+func (UnimplementedFlowServer) GetVersion(context.Context, *GetRequest) (*Version, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedFlowServer) GetPreviousVersion(context.Context, *GetRequest) (*Version, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPreviousVersion not implemented")
+}
+func (UnimplementedFlowServer) ListVersions(context.Context, *emptypb.Empty) (*VersionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVersions not implemented")
+}
+func (UnimplementedFlowServer) UpdateVersion(context.Context, *UpdateVersionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVersion not implemented")
+}
+func (UnimplementedFlowServer) WatchChanges(*WatchChangesRequest, Flow_WatchChangesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedFlowServer) mustEmbedUnimplementedFlowServer() {}
+
+// UnsafeFlowServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FlowServer will
+// result in compilation errors.
+type UnsafeFlowServer interface {
+	mustEmbedUnimplementedFlowServer()
+}
+
+func RegisterFlowServer(s grpc.ServiceRegistrar, srv FlowServer) {
+	s.RegisterService(&Flow_ServiceDesc, srv)
+}
+
+func _Flow_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).CreateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_CreateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).CreateTask(ctx, req.(*CreateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetTask(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetTaskByTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskByTitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetTaskByTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetTaskByTitle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetTaskByTitle(ctx, req.(*GetTaskByTitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetTasksByOwner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByOwnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetTasksByOwner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetTasksByOwner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetTasksByOwner(ctx, req.(*GetByOwnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).ListTasks(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_UpdateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).UpdateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_UpdateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).UpdateTask(ctx, req.(*UpdateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_PatchTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).PatchTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_PatchTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).PatchTask(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_DeleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).DeleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_DeleteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).DeleteTask(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_CreateGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).CreateGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_CreateGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).CreateGoal(ctx, req.(*CreateGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetGoal(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetGoalByObjective_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGoalByObjectiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetGoalByObjective(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetGoalByObjective_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetGoalByObjective(ctx, req.(*GetGoalByObjectiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetGoalsByPlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGoalsByPlannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetGoalsByPlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetGoalsByPlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetGoalsByPlanner(ctx, req.(*GetGoalsByPlannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_ListGoals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).ListGoals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_ListGoals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).ListGoals(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_UpdateGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).UpdateGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_UpdateGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).UpdateGoal(ctx, req.(*UpdateGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_PatchGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).PatchGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_PatchGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).PatchGoal(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_DeleteGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).DeleteGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_DeleteGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).DeleteGoal(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_CreatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).CreatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_CreatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).CreatePlan(ctx, req.(*CreatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlan(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlanByName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlanByNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlanByName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlanByName_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlanByName(ctx, req.(*GetPlanByNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlansByGoal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlansByGoalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlansByGoal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlansByGoal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlansByGoal(ctx, req.(*GetPlansByGoalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_ListPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).ListPlans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_ListPlans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).ListPlans(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_UpdatePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePlanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).UpdatePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_UpdatePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).UpdatePlan(ctx, req.(*UpdatePlanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_PatchPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).PatchPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_PatchPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).PatchPlan(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_DeletePlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).DeletePlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_DeletePlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).DeletePlan(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_CreatePlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePlannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).CreatePlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_CreatePlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).CreatePlanner(ctx, req.(*CreatePlannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlanner(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlannerByTitle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlannerByTitleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlannerByTitle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlannerByTitle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlannerByTitle(ctx, req.(*GetPlannerByTitleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPlannersByOwner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByOwnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPlannersByOwner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPlannersByOwner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPlannersByOwner(ctx, req.(*GetByOwnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_ListPlanners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).ListPlanners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_ListPlanners_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).ListPlanners(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_UpdatePlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePlannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).UpdatePlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_UpdatePlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).UpdatePlanner(ctx, req.(*UpdatePlannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_PatchPlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).PatchPlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_PatchPlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).PatchPlanner(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_DeletePlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).DeletePlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_DeletePlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).DeletePlanner(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_SharePlanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SharePlannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).SharePlanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_SharePlanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).SharePlanner(ctx, req.(*SharePlannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_CreateVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).CreateVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_CreateVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).CreateVersion(ctx, req.(*CreateVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetVersion(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_GetPreviousVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).GetPreviousVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_GetPreviousVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).GetPreviousVersion(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_ListVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).ListVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_ListVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).ListVersions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_UpdateVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlowServer).UpdateVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flow_UpdateVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlowServer).UpdateVersion(ctx, req.(*UpdateVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flow_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FlowServer).WatchChanges(m, &flowWatchChangesServer{stream})
+}
+
+type Flow_WatchChangesServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type flowWatchChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *flowWatchChangesServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Flow_ServiceDesc is the grpc.ServiceDesc for Flow service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Flow_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "flow.v1.Flow",
+	HandlerType: (*FlowServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTask",
+			Handler:    _Flow_CreateTask_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _Flow_GetTask_Handler,
+		},
+		{
+			MethodName: "GetTaskByTitle",
+			Handler:    _Flow_GetTaskByTitle_Handler,
+		},
+		{
+			MethodName: "GetTasksByOwner",
+			Handler:    _Flow_GetTasksByOwner_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _Flow_ListTasks_Handler,
+		},
+		{
+			MethodName: "UpdateTask",
+			Handler:    _Flow_UpdateTask_Handler,
+		},
+		{
+			MethodName: "PatchTask",
+			Handler:    _Flow_PatchTask_Handler,
+		},
+		{
+			MethodName: "DeleteTask",
+			Handler:    _Flow_DeleteTask_Handler,
+		},
+		{
+			MethodName: "CreateGoal",
+			Handler:    _Flow_CreateGoal_Handler,
+		},
+		{
+			MethodName: "GetGoal",
+			Handler:    _Flow_GetGoal_Handler,
+		},
+		{
+			MethodName: "GetGoalByObjective",
+			Handler:    _Flow_GetGoalByObjective_Handler,
+		},
+		{
+			MethodName: "GetGoalsByPlanner",
+			Handler:    _Flow_GetGoalsByPlanner_Handler,
+		},
+		{
+			MethodName: "ListGoals",
+			Handler:    _Flow_ListGoals_Handler,
+		},
+		{
+			MethodName: "UpdateGoal",
+			Handler:    _Flow_UpdateGoal_Handler,
+		},
+		{
+			MethodName: "PatchGoal",
+			Handler:    _Flow_PatchGoal_Handler,
+		},
+		{
+			MethodName: "DeleteGoal",
+			Handler:    _Flow_DeleteGoal_Handler,
+		},
+		{
+			MethodName: "CreatePlan",
+			Handler:    _Flow_CreatePlan_Handler,
+		},
+		{
+			MethodName: "GetPlan",
+			Handler:    _Flow_GetPlan_Handler,
+		},
+		{
+			MethodName: "GetPlanByName",
+			Handler:    _Flow_GetPlanByName_Handler,
+		},
+		{
+			MethodName: "GetPlansByGoal",
+			Handler:    _Flow_GetPlansByGoal_Handler,
+		},
+		{
+			MethodName: "ListPlans",
+			Handler:    _Flow_ListPlans_Handler,
+		},
+		{
+			MethodName: "UpdatePlan",
+			Handler:    _Flow_UpdatePlan_Handler,
+		},
+		{
+			MethodName: "PatchPlan",
+			Handler:    _Flow_PatchPlan_Handler,
+		},
+		{
+			MethodName: "DeletePlan",
+			Handler:    _Flow_DeletePlan_Handler,
+		},
+		{
+			MethodName: "CreatePlanner",
+			Handler:    _Flow_CreatePlanner_Handler,
+		},
+		{
+			MethodName: "GetPlanner",
+			Handler:    _Flow_GetPlanner_Handler,
+		},
+		{
+			MethodName: "GetPlannerByTitle",
+			Handler:    _Flow_GetPlannerByTitle_Handler,
+		},
+		{
+			MethodName: "GetPlannersByOwner",
+			Handler:    _Flow_GetPlannersByOwner_Handler,
+		},
+		{
+			MethodName: "ListPlanners",
+			Handler:    _Flow_ListPlanners_Handler,
+		},
+		{
+			MethodName: "UpdatePlanner",
+			Handler:    _Flow_UpdatePlanner_Handler,
+		},
+		{
+			MethodName: "PatchPlanner",
+			Handler:    _Flow_PatchPlanner_Handler,
+		},
+		{
+			MethodName: "DeletePlanner",
+			Handler:    _Flow_DeletePlanner_Handler,
+		},
+		{
+			MethodName: "SharePlanner",
+			Handler:    _Flow_SharePlanner_Handler,
+		},
+		{
+			MethodName: "CreateVersion",
+			Handler:    _Flow_CreateVersion_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _Flow_GetVersion_Handler,
+		},
+		{
+			MethodName: "GetPreviousVersion",
+			Handler:    _Flow_GetPreviousVersion_Handler,
+		},
+		{
+			MethodName: "ListVersions",
+			Handler:    _Flow_ListVersions_Handler,
+		},
+		{
+			MethodName: "UpdateVersion",
+			Handler:    _Flow_UpdateVersion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _Flow_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/flow.proto",
+}
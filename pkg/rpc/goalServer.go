@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// goals returns the goal control that serves a call: with Access set, it acts for the authenticated caller
+// and only reaches the goals of the planners shared with them.
+func (s *Server) goals(ctx context.Context) *handle.GoalControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Goal
+	}
+	return s.Goal.As(s.Access, user)
+}
+
+// CreateGoal creates a goal.
+func (s *Server) CreateGoal(ctx context.Context, req *flowpb.CreateGoalRequest) (*flowpb.CreateResponse, error) {
+	res, err := s.goals(ctx).CreateGoal(&handle.CreateGoalRequest{
+		Objective: req.GetObjective(),
+		Deadline:  req.GetDeadline(),
+		PlannerId: req.GetPlannerId(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.CreateResponse{Id: res.ID}, nil
+}
+
+// GetGoal returns the goal with the given id.
+func (s *Server) GetGoal(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Goal, error) {
+	res, err := s.goals(ctx).GetGoal(&handle.GetGoalRequest{Id: req.GetId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return goalMessage(res.Goal), nil
+}
+
+// GetGoalByObjective returns the goal with the given objective.
+func (s *Server) GetGoalByObjective(ctx context.Context, req *flowpb.GetGoalByObjectiveRequest) (*flowpb.Goal, error) {
+	res, err := s.goals(ctx).GetGoalByObjective(&handle.GetGoalByObjectiveRequest{Objective: req.GetObjective()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return goalMessage(res.Goal), nil
+}
+
+// GetGoalsByPlanner returns the goals of a planner.
+func (s *Server) GetGoalsByPlanner(ctx context.Context, req *flowpb.GetGoalsByPlannerRequest) (*flowpb.GoalList, error) {
+	res, err := s.goals(ctx).GetGoalsByPlannerId(&handle.GetGoalsByPlannerIdRequest{PlannerId: req.GetPlannerId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.GoalList{}
+	for _, goal := range res.Goals {
+		list.Goals = append(list.Goals, goalMessage(goal))
+	}
+	return list, nil
+}
+
+// ListGoals returns one page of the goals matching the filters of the request.
+func (s *Server) ListGoals(ctx context.Context, req *flowpb.ListRequest) (*flowpb.GoalList, error) {
+	page, err := s.goals(ctx).FindGoals(listRequest(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.GoalList{NextCursor: page.NextCursor}
+	for _, goal := range page.Goals {
+		list.Goals = append(list.Goals, goalMessage(goal))
+	}
+	return list, nil
+}
+
+// UpdateGoal replaces the fields of a goal.
+func (s *Server) UpdateGoal(ctx context.Context, req *flowpb.UpdateGoalRequest) (*emptypb.Empty, error) {
+	err := s.goals(ctx).UpdateGoal(&handle.UpdateGoalRequest{
+		Id:        req.GetId(),
+		Objective: req.GetObjective(),
+		Deadline:  req.GetDeadline(),
+		PlannerId: req.GetPlannerId(),
+		Revision:  int(req.GetRevision()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// PatchGoal applies a JSON Merge Patch to a goal and returns the updated goal.
+func (s *Server) PatchGoal(ctx context.Context, req *flowpb.PatchRequest) (*flowpb.Goal, error) {
+	res, err := s.goals(ctx).PatchGoal(&handle.PatchGoalRequest{Id: req.GetId(), Patch: req.GetPatch(), Revision: int(req.GetRevision())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return goalMessage(res.Goal), nil
+}
+
+// DeleteGoal deletes a goal.
+func (s *Server) DeleteGoal(ctx context.Context, req *flowpb.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.goals(ctx).DeleteGoal(&handle.DeleteGoalRequest{Id: req.GetId(), Revision: int(req.GetRevision())}); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
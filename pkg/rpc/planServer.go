@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// plans returns the plan control that serves a call: with Access set, it acts for the authenticated caller
+// and only reaches the plans of the planners shared with them.
+func (s *Server) plans(ctx context.Context) *handle.PlanControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Plan
+	}
+	return s.Plan.As(s.Access, user)
+}
+
+// CreatePlan creates a plan.
+func (s *Server) CreatePlan(ctx context.Context, req *flowpb.CreatePlanRequest) (*flowpb.CreateResponse, error) {
+	res, err := s.plans(ctx).CreatePlan(&handle.CreatePlanRequest{
+		PlanName:        req.GetName(),
+		PlanDescription: req.GetDescription(),
+		PlanDate:        req.GetDate(),
+		PlanTime:        req.GetTime(),
+		GoalId:          req.GetGoalId(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.CreateResponse{Id: res.ID}, nil
+}
+
+// GetPlan returns the plan with the given id.
+func (s *Server) GetPlan(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Plan, error) {
+	res, err := s.plans(ctx).GetPlan(&handle.GetPlanRequest{Id: req.GetId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return planMessage(res.Plan), nil
+}
+
+// GetPlanByName returns the plan with the given name.
+func (s *Server) GetPlanByName(ctx context.Context, req *flowpb.GetPlanByNameRequest) (*flowpb.Plan, error) {
+	res, err := s.plans(ctx).GetPlanByName(&handle.GetPlanByNameRequest{PlanName: req.GetName()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return planMessage(res.Plan), nil
+}
+
+// GetPlansByGoal returns the plans of a goal.
+func (s *Server) GetPlansByGoal(ctx context.Context, req *flowpb.GetPlansByGoalRequest) (*flowpb.PlanList, error) {
+	res, err := s.plans(ctx).GetPlansByGoal(&handle.GetPlansByGoalRequest{GoalId: req.GetGoalId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.PlanList{}
+	for _, plan := range res.Plans {
+		list.Plans = append(list.Plans, planMessage(plan))
+	}
+	return list, nil
+}
+
+// ListPlans returns one page of the plans matching the filters of the request.
+func (s *Server) ListPlans(ctx context.Context, req *flowpb.ListRequest) (*flowpb.PlanList, error) {
+	page, err := s.plans(ctx).FindPlans(listRequest(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.PlanList{NextCursor: page.NextCursor}
+	for _, plan := range page.Plans {
+		list.Plans = append(list.Plans, planMessage(plan))
+	}
+	return list, nil
+}
+
+// UpdatePlan replaces the fields of a plan.
+func (s *Server) UpdatePlan(ctx context.Context, req *flowpb.UpdatePlanRequest) (*emptypb.Empty, error) {
+	err := s.plans(ctx).UpdatePlan(&handle.UpdatePlanRequest{
+		Id:              req.GetId(),
+		PlanName:        req.GetName(),
+		PlanDescription: req.GetDescription(),
+		PlanDate:        req.GetDate(),
+		PlanTime:        req.GetTime(),
+		GoalId:          req.GetGoalId(),
+		Revision:        int(req.GetRevision()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// PatchPlan applies a JSON Merge Patch to a plan and returns the updated plan.
+func (s *Server) PatchPlan(ctx context.Context, req *flowpb.PatchRequest) (*flowpb.Plan, error) {
+	res, err := s.plans(ctx).PatchPlan(&handle.PatchPlanRequest{Id: req.GetId(), Patch: req.GetPatch(), Revision: int(req.GetRevision())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return planMessage(res.Plan), nil
+}
+
+// DeletePlan deletes a plan.
+func (s *Server) DeletePlan(ctx context.Context, req *flowpb.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.plans(ctx).DeletePlan(&handle.DeletePlanRequest{Id: req.GetId(), Revision: int(req.GetRevision())}); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// planners returns the planner control that serves a call: with Access set, it acts for the authenticated caller
+// and only reaches the planners shared with them.
+func (s *Server) planners(ctx context.Context) *handle.PlannerControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Planner
+	}
+	return s.Planner.As(s.Access, user)
+}
+
+// CreatePlanner creates a planner. A planner without an owner is owned by the caller.
+func (s *Server) CreatePlanner(ctx context.Context, req *flowpb.CreatePlannerRequest) (*flowpb.CreateResponse, error) {
+	userId := req.GetUserId()
+	if userId == "" {
+		userId = caller(ctx)
+	}
+	res, err := s.planners(ctx).CreatePlanner(&handle.CreatePlannerRequest{Title: req.GetTitle(), UserId: userId})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.CreateResponse{Id: res.Id}, nil
+}
+
+// GetPlanner returns the planner with the given id.
+func (s *Server) GetPlanner(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Planner, error) {
+	planner, err := s.planners(ctx).GetPlanner(&handle.GetPlannerRequest{Id: req.GetId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return plannerMessage(planner), nil
+}
+
+// GetPlannerByTitle returns the planner with the given title.
+func (s *Server) GetPlannerByTitle(ctx context.Context, req *flowpb.GetPlannerByTitleRequest) (*flowpb.Planner, error) {
+	planner, err := s.planners(ctx).GetPlannerByTitle(&handle.GetPlannerByTitleRequest{Title: req.GetTitle()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.Planner{Id: planner.Id, Title: planner.Title, UserId: planner.UserId}, nil
+}
+
+// GetPlannersByOwner returns the planners of the given owner.
+func (s *Server) GetPlannersByOwner(ctx context.Context, req *flowpb.GetByOwnerRequest) (*flowpb.PlannerList, error) {
+	planners, err := s.planners(ctx).GetPlannerByOwner(&handle.GetPlannerByOwnerRequest{UserId: req.GetOwner()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.PlannerList{}
+	for _, planner := range planners {
+		list.Planners = append(list.Planners, &flowpb.Planner{Id: planner.Id, Title: planner.Title, UserId: planner.UserId})
+	}
+	return list, nil
+}
+
+// ListPlanners returns one page of the planners matching the filters of the request.
+func (s *Server) ListPlanners(ctx context.Context, req *flowpb.ListRequest) (*flowpb.PlannerList, error) {
+	page, err := s.planners(ctx).FindPlanners(listRequest(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.PlannerList{NextCursor: page.NextCursor}
+	for _, planner := range page.Planners {
+		list.Planners = append(list.Planners, plannerMessage(planner))
+	}
+	return list, nil
+}
+
+// UpdatePlanner replaces the title and owner of a planner.
+func (s *Server) UpdatePlanner(ctx context.Context, req *flowpb.UpdatePlannerRequest) (*emptypb.Empty, error) {
+	err := s.planners(ctx).UpdatePlanner(&handle.UpdatePlannerRequest{
+		Id:       req.GetId(),
+		Title:    req.GetTitle(),
+		UserId:   req.GetUserId(),
+		Revision: int(req.GetRevision()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// PatchPlanner applies a JSON Merge Patch to a planner and returns the updated planner.
+func (s *Server) PatchPlanner(ctx context.Context, req *flowpb.PatchRequest) (*flowpb.Planner, error) {
+	planner, err := s.planners(ctx).PatchPlanner(&handle.PatchPlannerRequest{Id: req.GetId(), Patch: req.GetPatch(), Revision: int(req.GetRevision())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return plannerMessage(planner), nil
+}
+
+// DeletePlanner deletes a planner.
+func (s *Server) DeletePlanner(ctx context.Context, req *flowpb.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.planners(ctx).DeletePlanner(&handle.DeletePlannerRequest{Id: req.GetId(), Revision: int(req.GetRevision())}); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// SharePlanner gives a user a role in a planner, or removes the user from its members if the role is empty.
+func (s *Server) SharePlanner(ctx context.Context, req *flowpb.SharePlannerRequest) (*flowpb.Planner, error) {
+	planner, err := s.planners(ctx).SharePlanner(&handle.SharePlannerRequest{
+		Id:       req.GetId(),
+		User:     req.GetUser(),
+		Role:     req.GetRole(),
+		Revision: int(req.GetRevision()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return plannerMessage(planner), nil
+}
@@ -0,0 +1,164 @@
+// Package rpc serves the flow API over gRPC. The service is defined in api/proto/flow.proto
+// and its generated code lives in pkg/rpc/flowpb.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log"
+	"strings"
+	"time"
+)
+
+// Server implements the Flow gRPC service on the same controls as the HTTP handlers in package api.
+// Each call is authenticated with the API token in its metadata and served for its caller,
+// with the access the caller has in the planners.
+type Server struct {
+	flowpb.UnimplementedFlowServer
+	Task    *handle.TaskControl
+	Goal    *handle.GoalControl
+	Plan    *handle.PlanControl
+	Planner *handle.PlannerControl
+	Version *handle.VersionControl
+	Event   *handle.EventControl
+	Access  *services.AccessService
+	Users   *handle.UserControl
+}
+
+// GRPCServer returns a gRPC server with the Flow service registered and the interceptors that authenticate each call.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.authenticateStream),
+	)
+	server := grpc.NewServer(opts...)
+	flowpb.RegisterFlowServer(server, s)
+	return server
+}
+
+// callerKey is the context key under which the authenticated user of a call is stored.
+type callerKey struct{}
+
+// caller returns the name of the authenticated user of a call, or an empty string.
+func caller(ctx context.Context) string {
+	if user, ok := ctx.Value(callerKey{}).(*models.User); ok {
+		return user.Name
+	}
+	return ""
+}
+
+// callToken returns the API token in the metadata of a call, taken from "authorization" ("Bearer <token>")
+// or from "x-api-key". It returns an empty string if there is none.
+func callToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// authenticate returns a copy of ctx that carries the user the API token of the call belongs to.
+// Calls without a valid token fail with UNAUTHENTICATED.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	token := callToken(ctx)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing API token")
+	}
+	user, err := s.Users.Authenticate(token)
+	if errors.Is(err, handle.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		log.Printf("Error due to: %s", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return context.WithValue(ctx, callerKey{}, user), nil
+}
+
+// authenticateUnary authenticates a unary call and logs its method and duration.
+func (s *Server) authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := handler(ctx, req)
+	log.Printf("grpc %s %v", info.FullMethod, time.Since(start))
+	return res, err
+}
+
+// contextStream is a server stream with a replaced context, such as one that carries the authenticated caller.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context of the stream.
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticateStream authenticates a streaming call.
+func (s *Server) authenticateStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	log.Printf("grpc %s", info.FullMethod)
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// EndStreamsOn returns a server option that cancels the context of every streaming call once ctx is done.
+// GracefulStop waits for the calls in flight, and WatchChanges only returns when its context ends,
+// so a server that shuts down gracefully cancels ctx first to let the watchers go.
+func EndStreamsOn(ctx context.Context) grpc.ServerOption {
+	return grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		streamCtx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: streamCtx})
+	})
+}
+
+// statusError converts an error of the controls into a gRPC status with the code the HTTP API
+// would answer with a matching status: PERMISSION_DENIED for 403, NOT_FOUND for 404,
+// FAILED_PRECONDITION for 412 and INVALID_ARGUMENT for 400.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	log.Printf("Error due to: %s", err)
+	var parseErr *time.ParseError
+	code := codes.Internal
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, storm.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, store.ErrRevisionMismatch):
+		code = codes.FailedPrecondition
+	case errors.Is(err, handle.ErrInvalidPatch), errors.Is(err, handle.ErrInvalidCursor),
+		errors.Is(err, store.ErrInvalidSort), errors.Is(err, services.ErrInvalidMember),
+		errors.As(err, &parseErr):
+		code = codes.InvalidArgument
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,305 @@
+package rpc
+
+import (
+	"context"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// SetupServerT serves the Flow service over an in-memory connection, with the given server options,
+// and returns a client and the API tokens of the users alice and bob.
+func SetupServerT(t *testing.T, opts ...grpc.ServerOption) (flowpb.FlowClient, map[string]string) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	planStore := inmemory.NewInMemoryPlanStore(db)
+	plannerStore := inmemory.NewInMemoryPlannerStore(db)
+	access := services.NewAccessService(plannerStore, goalStore, planStore, inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	users := handle.NewUserControl(services.NewUserService(inmemory.NewInMemoryUserStore(db)))
+	tokens := map[string]string{}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := users.AddUser(&handle.AddUserRequest{Name: name}); err != nil {
+			t.Fatalf("failed to add user: %v", err)
+		}
+		res, err := users.CreateToken(&handle.CreateTokenRequest{UserName: name, Name: "test"})
+		if err != nil {
+			t.Fatalf("failed to create token: %v", err)
+		}
+		tokens[name] = res.Token
+	}
+	s := &Server{
+		Task:    handle.NewTaskControl(services.NewTaskService(inmemory.NewInMemoryTaskStore(db)).WithEvents(events)),
+		Goal:    handle.NewGoalControl(services.NewGoalService(goalStore).WithEvents(events)),
+		Plan:    handle.NewPlanControl(services.NewPlanService(planStore).WithEvents(events)),
+		Planner: handle.NewPlannerControl(services.NewPlannerService(plannerStore).WithEvents(events)),
+		Version: handle.NewVersionControl(services.NewVersionService(inmemory.NewInMemoryVersionStore(db)).WithEvents(events)),
+		Event:   handle.NewEventControl(events),
+		Access:  access,
+		Users:   users,
+	}
+	listener := bufconn.Listen(1 << 20)
+	server := s.GRPCServer(opts...)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return flowpb.NewFlowClient(conn), tokens
+}
+
+// as returns a context whose calls carry the given API token.
+func as(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestServer_Authentication(t *testing.T) {
+	c, tokens := SetupServerT(t)
+	_, err := c.ListTasks(context.Background(), &flowpb.ListRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = c.ListTasks(as("flow_invalid"), &flowpb.ListRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", tokens["alice"])
+	_, err = c.ListTasks(ctx, &flowpb.ListRequest{})
+	assert.NoError(t, err)
+}
+
+func TestServer_Planners(t *testing.T) {
+	c, tokens := SetupServerT(t)
+	alice, bob := as(tokens["alice"]), as(tokens["bob"])
+	planner, err := c.CreatePlanner(alice, &flowpb.CreatePlannerRequest{Title: "Work"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	goal, err := c.CreateGoal(alice, &flowpb.CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-01", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	plan, err := c.CreatePlan(alice, &flowpb.CreatePlanRequest{Name: "Release", Date: "2029-12-01", Time: "09:30", GoalId: goal.Id})
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := c.CreateTask(alice, &flowpb.CreateTaskRequest{Title: "Tag", PlanId: plan.Id})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	got, err := c.GetTask(alice, &flowpb.GetRequest{Id: task.Id})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "alice", got.Owner)
+	gotPlan, err := c.GetPlan(alice, &flowpb.GetRequest{Id: plan.Id})
+	if err != nil {
+		t.Fatalf("failed to get plan: %v", err)
+	}
+	assert.Equal(t, "2029-12-01", gotPlan.Date)
+	assert.Equal(t, "09:30", gotPlan.Time)
+
+	// bob cannot see the planner until alice shares it
+	_, err = c.GetTask(bob, &flowpb.GetRequest{Id: task.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	shared, err := c.SharePlanner(alice, &flowpb.SharePlannerRequest{Id: planner.Id, User: "bob", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	assert.Equal(t, "viewer", shared.Members["bob"])
+	goals, err := c.ListGoals(bob, &flowpb.ListRequest{PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to list goals: %v", err)
+	}
+	assert.Len(t, goals.Goals, 1)
+	_, err = c.PatchTask(bob, &flowpb.PatchRequest{Id: task.Id, Patch: []byte(`{"completed": true}`)})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	patched, err := c.PatchTask(alice, &flowpb.PatchRequest{Id: task.Id, Patch: []byte(`{"completed": true}`), Revision: got.Revision})
+	if err != nil {
+		t.Fatalf("failed to patch task: %v", err)
+	}
+	assert.True(t, patched.Completed)
+	_, err = c.UpdateTask(alice, &flowpb.UpdateTaskRequest{Id: task.Id, Title: patched.Title, Owner: patched.Owner,
+		PlanId: patched.PlanId, Started: true, Failed: true, Revision: patched.Revision})
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	failed, err := c.GetTask(alice, &flowpb.GetRequest{Id: task.Id})
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.True(t, failed.Failed)
+	assert.False(t, failed.Completed)
+	_, err = c.DeleteTask(alice, &flowpb.DeleteRequest{Id: task.Id, Revision: got.Revision})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = c.PatchTask(alice, &flowpb.PatchRequest{Id: task.Id, Patch: []byte(`[]`)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = c.DeleteTask(alice, &flowpb.DeleteRequest{Id: task.Id})
+	assert.NoError(t, err)
+	_, err = c.GetTask(alice, &flowpb.GetRequest{Id: task.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_Versions(t *testing.T) {
+	c, tokens := SetupServerT(t)
+	alice, bob := as(tokens["alice"]), as(tokens["bob"])
+	planner, err := c.CreatePlanner(alice, &flowpb.CreatePlannerRequest{Title: "Work"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	goal, err := c.CreateGoal(alice, &flowpb.CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-01", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	plan, err := c.CreatePlan(alice, &flowpb.CreatePlanRequest{Name: "Week 1", Date: "2030-01-01", Time: "09:00", GoalId: goal.Id})
+	if err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	task, err := c.CreateTask(alice, &flowpb.CreateTaskRequest{Title: "Write", PlanId: plan.Id})
+	if err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	created, err := c.CreateVersion(alice, &flowpb.CreateVersionRequest{GoalId: goal.Id, Major: 1})
+	if err != nil {
+		t.Fatalf("failed to create version: %v", err)
+	}
+	_, err = c.CreateVersion(alice, &flowpb.CreateVersionRequest{TaskId: task.Id, Major: 1})
+	assert.NoError(t, err)
+	_, err = c.UpdateVersion(alice, &flowpb.UpdateVersionRequest{Id: created.Id, Major: 1, Minor: 2})
+	assert.NoError(t, err)
+	version, err := c.GetVersion(alice, &flowpb.GetRequest{Id: created.Id})
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+	assert.Equal(t, "alice", version.CreatedBy)
+	assert.Equal(t, int32(2), version.Minor)
+	list, err := c.ListVersions(alice, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
+	assert.Len(t, list.Versions, 2)
+
+	// bob sees none of the versions of alice's planner until she shares it, and a viewer cannot renumber them
+	_, err = c.GetVersion(bob, &flowpb.GetRequest{Id: created.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = c.CreateVersion(bob, &flowpb.CreateVersionRequest{TaskId: task.Id, Major: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err = c.ListVersions(bob, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
+	assert.Empty(t, list.Versions)
+	if _, err := c.SharePlanner(alice, &flowpb.SharePlannerRequest{Id: planner.Id, User: "bob", Role: "viewer"}); err != nil {
+		t.Fatalf("failed to share planner: %v", err)
+	}
+	list, err = c.ListVersions(bob, &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
+	assert.Len(t, list.Versions, 2)
+	_, err = c.UpdateVersion(bob, &flowpb.UpdateVersionRequest{Id: created.Id, Major: 9})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_WatchChanges(t *testing.T) {
+	c, tokens := SetupServerT(t)
+	alice, bob := as(tokens["alice"]), as(tokens["bob"])
+	planner, err := c.CreatePlanner(alice, &flowpb.CreatePlannerRequest{Title: "Work"})
+	if err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(alice, 5*time.Second)
+	defer cancel()
+	stream, err := c.WatchChanges(ctx, &flowpb.WatchChangesRequest{Types: []string{"goal"}})
+	if err != nil {
+		t.Fatalf("failed to watch changes: %v", err)
+	}
+	bobCtx, bobCancel := context.WithTimeout(bob, 5*time.Second)
+	defer bobCancel()
+	bobStream, err := c.WatchChanges(bobCtx, &flowpb.WatchChangesRequest{})
+	if err != nil {
+		t.Fatalf("failed to watch changes: %v", err)
+	}
+	// the streams are subscribed once the server has received the request; a header round trip ensures it
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("failed to read header: %v", err)
+	}
+	if _, err := bobStream.Header(); err != nil {
+		t.Fatalf("failed to read header: %v", err)
+	}
+
+	if _, err := c.CreateTask(alice, &flowpb.CreateTaskRequest{Title: "Unrelated"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	goal, err := c.CreateGoal(alice, &flowpb.CreateGoalRequest{Objective: "Ship", Deadline: "2030-01-01", PlannerId: planner.Id})
+	if err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+	assert.Equal(t, "goal.created", event.Type)
+	assert.Equal(t, goal.Id, event.ResourceId)
+	assert.Equal(t, planner.Id, event.PlannerId)
+
+	// bob is not a member of the planner, so the first event he receives is about the task without a planner
+	event, err = bobStream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+	assert.Equal(t, "task.created", event.Type)
+
+	// a reconnecting client receives the events it missed
+	resumed, err := c.WatchChanges(ctx, &flowpb.WatchChangesRequest{After: event.Id})
+	if err != nil {
+		t.Fatalf("failed to watch changes: %v", err)
+	}
+	missed, err := resumed.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+	assert.Equal(t, "goal.created", missed.Type)
+}
+
+func TestEndStreamsOn(t *testing.T) {
+	shutdown, stop := context.WithCancel(context.Background())
+	defer stop()
+	c, tokens := SetupServerT(t, EndStreamsOn(shutdown))
+	ctx, cancel := context.WithTimeout(as(tokens["alice"]), 5*time.Second)
+	defer cancel()
+	stream, err := c.WatchChanges(ctx, &flowpb.WatchChangesRequest{})
+	if err != nil {
+		t.Fatalf("failed to watch changes: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("failed to read header: %v", err)
+	}
+
+	stop()
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.NoError(t, ctx.Err())
+}
@@ -0,0 +1,116 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// tasks returns the task control that serves a call: with Access set, it acts for the authenticated caller
+// and only reaches the tasks of the planners shared with them.
+func (s *Server) tasks(ctx context.Context) *handle.TaskControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Task
+	}
+	return s.Task.As(s.Access, user)
+}
+
+// CreateTask creates a task. A task without an owner is owned by the caller.
+func (s *Server) CreateTask(ctx context.Context, req *flowpb.CreateTaskRequest) (*flowpb.CreateResponse, error) {
+	owner := req.GetOwner()
+	if owner == "" {
+		owner = caller(ctx)
+	}
+	res, err := s.tasks(ctx).CreateTask(handle.CreateTaskRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Owner:       owner,
+		PlanId:      req.GetPlanId(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.CreateResponse{Id: res.ID}, nil
+}
+
+// GetTask returns the task with the given id.
+func (s *Server) GetTask(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Task, error) {
+	task, err := s.tasks(ctx).GetTask(&handle.GetTaskRequest{ID: req.GetId()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return taskMessage(task), nil
+}
+
+// GetTaskByTitle returns the task with the given title.
+func (s *Server) GetTaskByTitle(ctx context.Context, req *flowpb.GetTaskByTitleRequest) (*flowpb.Task, error) {
+	task, err := s.tasks(ctx).GetTaskByTitle(&handle.GetTaskByTitleRequest{Title: req.GetTitle()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return taskMessage(task), nil
+}
+
+// GetTasksByOwner returns the tasks of the given owner.
+func (s *Server) GetTasksByOwner(ctx context.Context, req *flowpb.GetByOwnerRequest) (*flowpb.TaskList, error) {
+	tasks, err := s.tasks(ctx).GetTaskByOwner(&handle.GetTaskByOwnerRequest{Owner: req.GetOwner()})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	res := &flowpb.TaskList{}
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, taskMessage(task))
+	}
+	return res, nil
+}
+
+// ListTasks returns one page of the tasks matching the filters of the request.
+func (s *Server) ListTasks(ctx context.Context, req *flowpb.ListRequest) (*flowpb.TaskList, error) {
+	page, err := s.tasks(ctx).FindTasks(listRequest(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	res := &flowpb.TaskList{NextCursor: page.NextCursor}
+	for _, task := range page.Tasks {
+		res.Tasks = append(res.Tasks, taskMessage(task))
+	}
+	return res, nil
+}
+
+// UpdateTask replaces the fields of a task.
+func (s *Server) UpdateTask(ctx context.Context, req *flowpb.UpdateTaskRequest) (*emptypb.Empty, error) {
+	err := s.tasks(ctx).UpdateTask(&handle.UpdateTaskRequest{
+		ID:          req.GetId(),
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Owner:       req.GetOwner(),
+		PlanId:      req.GetPlanId(),
+		Started:     req.GetStarted(),
+		Completed:   req.GetCompleted(),
+		Failed:      req.GetFailed(),
+		Revision:    int(req.GetRevision()),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// PatchTask applies a JSON Merge Patch to a task and returns the updated task.
+func (s *Server) PatchTask(ctx context.Context, req *flowpb.PatchRequest) (*flowpb.Task, error) {
+	task, err := s.tasks(ctx).PatchTask(&handle.PatchTaskRequest{ID: req.GetId(), Patch: req.GetPatch(), Revision: int(req.GetRevision())})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return taskMessage(task), nil
+}
+
+// DeleteTask deletes a task.
+func (s *Server) DeleteTask(ctx context.Context, req *flowpb.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.tasks(ctx).DeleteTask(&handle.DeleteTaskRequest{ID: req.GetId(), Revision: int(req.GetRevision())}); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
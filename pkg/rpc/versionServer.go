@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ooyeku/flow/pkg/handle"
+	"github.com/ooyeku/flow/pkg/rpc/flowpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// versions returns the version control that serves a call: with Access set, it acts for the authenticated caller
+// and only reaches the versions of the records in the planners shared with them.
+func (s *Server) versions(ctx context.Context) *handle.VersionControl {
+	user := caller(ctx)
+	if s.Access == nil || user == "" {
+		return s.Version
+	}
+	return s.Version.As(s.Access, user)
+}
+
+// CreateVersion records a version of a goal, plan or task. A version without an author is created by the caller.
+func (s *Server) CreateVersion(ctx context.Context, req *flowpb.CreateVersionRequest) (*flowpb.CreateResponse, error) {
+	createReq := &handle.CreateVersionRequest{
+		GoalID:    req.GetGoalId(),
+		PlanID:    req.GetPlanId(),
+		TaskID:    req.GetTaskId(),
+		CreatedBy: req.GetCreatedBy(),
+	}
+	createReq.No.Major = int(req.GetMajor())
+	createReq.No.Minor = int(req.GetMinor())
+	createReq.No.Patch = int(req.GetPatch())
+	if createReq.CreatedBy == "" {
+		createReq.CreatedBy = caller(ctx)
+	}
+	res, err := s.versions(ctx).CreateVersion(createReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &flowpb.CreateResponse{Id: res.ID}, nil
+}
+
+// GetVersion returns the version with the given id.
+func (s *Server) GetVersion(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Version, error) {
+	version, err := s.versions(ctx).GetVersion(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return versionMessage(version), nil
+}
+
+// GetPreviousVersion returns the version the version with the given id follows.
+func (s *Server) GetPreviousVersion(ctx context.Context, req *flowpb.GetRequest) (*flowpb.Version, error) {
+	version, err := s.versions(ctx).GetPreviousVersion(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return versionMessage(version), nil
+}
+
+// ListVersions returns every version the caller can view.
+func (s *Server) ListVersions(ctx context.Context, _ *emptypb.Empty) (*flowpb.VersionList, error) {
+	versions, err := s.versions(ctx).ListVersions()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	list := &flowpb.VersionList{}
+	for _, version := range versions {
+		list.Versions = append(list.Versions, versionMessage(version))
+	}
+	return list, nil
+}
+
+// UpdateVersion changes the version number of a version.
+func (s *Server) UpdateVersion(ctx context.Context, req *flowpb.UpdateVersionRequest) (*emptypb.Empty, error) {
+	control := s.versions(ctx)
+	version, err := control.GetVersion(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	version.No.Major = int(req.GetMajor())
+	version.No.Minor = int(req.GetMinor())
+	version.No.Patch = int(req.GetPatch())
+	if err := control.UpdateVersion(req.GetId(), version); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
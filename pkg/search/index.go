@@ -0,0 +1,303 @@
+// Package search implements the full-text index behind GET /api/v1/search and flow search.
+//
+// The index is an inverted index held in memory: every term maps to the documents it appears in.
+// A query matches the terms of a document exactly, as a prefix ("rel" finds "release") or, for terms of
+// four letters or more, within a small edit distance ("relase" finds "release"). Results are ranked by
+// how well and how often the query terms match, weighing rare terms and titles above common terms and texts.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// The types of the documents in the index.
+const (
+	TypeTask = "task"
+	TypeGoal = "goal"
+	TypePlan = "plan"
+	TypeChat = "chat"
+)
+
+// The weights of a query term according to how it matches a term of a document.
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.6
+	fuzzyWeight  = 0.4
+)
+
+// titleBoost is how much more a term counts in the title of a document than in its text.
+const titleBoost = 3
+
+// Document is a record to index.
+//
+// Fields:
+// - Type, ID: the kind of record (TypeTask, TypeGoal, TypePlan or TypeChat) and its id.
+// - Title: the name of the record, such as the title of a task or the question of a chat.
+// - Text: its longer text, such as the description of a task or the answer of a chat.
+type Document struct {
+	Type  string
+	ID    string
+	Title string
+	Text  string
+}
+
+// key returns the key of the document in the index.
+func (d *Document) key() string {
+	return d.Type + "/" + d.ID
+}
+
+// Hit is a document that matches a query.
+type Hit struct {
+	Type  string  `json:"type"`
+	ID    string  `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// Index is an inverted index of documents. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*Document
+	postings map[string]map[string]float64
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[string]*Document{},
+		postings: map[string]map[string]float64{},
+	}
+}
+
+// Len returns the number of documents in the index.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Add adds a document to the index, replacing the document of the same type and id if there is one.
+func (x *Index) Add(doc Document) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	key := doc.key()
+	x.remove(key)
+	x.docs[key] = &doc
+	for term, weight := range termWeights(&doc) {
+		docs, ok := x.postings[term]
+		if !ok {
+			docs = map[string]float64{}
+			x.postings[term] = docs
+		}
+		docs[key] = weight
+	}
+}
+
+// Reset replaces the documents of the index with docs.
+func (x *Index) Reset(docs []Document) {
+	fresh := NewIndex()
+	for _, doc := range docs {
+		fresh.Add(doc)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.docs, x.postings = fresh.docs, fresh.postings
+}
+
+// Remove removes the document of the given type and id from the index, if it is there.
+func (x *Index) Remove(typ, id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove((&Document{Type: typ, ID: id}).key())
+}
+
+// remove removes a document and its postings. The caller must hold mu.
+func (x *Index) remove(key string) {
+	doc, ok := x.docs[key]
+	if !ok {
+		return
+	}
+	delete(x.docs, key)
+	for term := range termWeights(doc) {
+		delete(x.postings[term], key)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+}
+
+// Options narrows a search.
+//
+// Fields:
+// - Types: only documents of these types; all types if empty.
+// - Limit: the maximum number of hits; all of them if 0.
+// - Filter: if set, only the hits it accepts are returned. It is called in the order of the ranking,
+// and only until Limit hits are accepted, so it may look up each hit.
+type Options struct {
+	Types  []string
+	Limit  int
+	Filter func(hit Hit) bool
+}
+
+// Search returns the documents that match every term of the query, best match first.
+// Hits with the same score are ordered by title. A query without terms matches nothing.
+func (x *Index) Search(query string, opts Options) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []Hit{}
+	}
+	hits := x.rank(terms, opts.Types)
+	if opts.Filter == nil && (opts.Limit <= 0 || len(hits) <= opts.Limit) {
+		return hits
+	}
+	accepted := []Hit{}
+	for _, hit := range hits {
+		if opts.Limit > 0 && len(accepted) == opts.Limit {
+			break
+		}
+		if opts.Filter == nil || opts.Filter(hit) {
+			accepted = append(accepted, hit)
+		}
+	}
+	return accepted
+}
+
+// rank scores the documents of the given types that match all the terms and sorts them by score.
+func (x *Index) rank(terms []string, types []string) []Hit {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	var scores map[string]float64
+	for _, term := range terms {
+		matches := x.match(term)
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		// a document must match every term of the query
+		for key, score := range scores {
+			if match, ok := matches[key]; ok {
+				scores[key] = score + match
+			} else {
+				delete(scores, key)
+			}
+		}
+	}
+	wanted := map[string]bool{}
+	for _, typ := range types {
+		wanted[typ] = true
+	}
+	hits := []Hit{}
+	for key, score := range scores {
+		doc := x.docs[key]
+		if len(wanted) > 0 && !wanted[doc.Type] {
+			continue
+		}
+		hits = append(hits, Hit{Type: doc.Type, ID: doc.ID, Title: doc.Title, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Title != hits[j].Title {
+			return hits[i].Title < hits[j].Title
+		}
+		return hits[i].Type+hits[i].ID < hits[j].Type+hits[j].ID
+	})
+	return hits
+}
+
+// match returns the score of every document with a term that matches the query term.
+// When several terms of a document match, such as "release" and "released" for "rel", the best one counts.
+// The caller must hold mu.
+func (x *Index) match(query string) map[string]float64 {
+	maxEdits := allowedEdits(query)
+	scores := map[string]float64{}
+	for term, docs := range x.postings {
+		var weight float64
+		switch {
+		case term == query:
+			weight = exactWeight
+		case strings.HasPrefix(term, query):
+			weight = prefixWeight
+		case maxEdits > 0 && editDistance(query, term, maxEdits) <= maxEdits:
+			weight = fuzzyWeight
+		default:
+			continue
+		}
+		// rare terms say more about a document than common ones
+		idf := math.Log(1 + float64(len(x.docs))/float64(len(docs)))
+		for key, tf := range docs {
+			if score := weight * tf * idf; score > scores[key] {
+				scores[key] = score
+			}
+		}
+	}
+	return scores
+}
+
+// termWeights returns the terms of a document with their weight: the number of times they appear,
+// with the terms of the title counting titleBoost times.
+func termWeights(doc *Document) map[string]float64 {
+	weights := map[string]float64{}
+	for _, term := range Tokenize(doc.Title) {
+		weights[term] += titleBoost
+	}
+	for _, term := range Tokenize(doc.Text) {
+		weights[term]++
+	}
+	return weights
+}
+
+// Tokenize splits a text into lower case terms at every character that is not a letter or a digit.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// allowedEdits returns the edit distance within which a term matches the query term:
+// none for short terms, where a single edit already changes the word, one up to seven letters and two beyond.
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b, or max+1 as soon as it is known to exceed max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > max || -diff > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			best = min(best, cur[j])
+		}
+		if best > max {
+			return max + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
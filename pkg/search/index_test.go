@@ -0,0 +1,56 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIndex_Search(t *testing.T) {
+	x := NewIndex()
+	x.Add(Document{Type: TypeTask, ID: "t1", Title: "Tag the release", Text: "Push the v2 tag"})
+	x.Add(Document{Type: TypeTask, ID: "t2", Title: "Write release notes", Text: "List the changes since the last release"})
+	x.Add(Document{Type: TypeGoal, ID: "g1", Title: "Ship v2"})
+	x.Add(Document{Type: TypePlan, ID: "p1", Title: "Launch", Text: "Announce the released version"})
+	x.Add(Document{Type: TypeChat, ID: "c1", Title: "How do I tag a release in git?", Text: "Use git tag -a"})
+
+	ids := func(hits []Hit) []string {
+		ids := []string{}
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	// the task with the term twice ranks first, equal scores are ordered by title
+	// and "released" matches as a prefix, below the exact matches
+	assert.Equal(t, []string{"t2", "c1", "t1", "p1"}, ids(x.Search("release", Options{})))
+	assert.Equal(t, []string{"c1", "t1"}, ids(x.Search("Tag release", Options{})))
+	assert.Equal(t, []string{"g1"}, ids(x.Search("shi", Options{})))
+	// "relase" is one edit away from "release", but two from "released"
+	assert.Equal(t, []string{"t2", "c1", "t1"}, ids(x.Search("relase", Options{})))
+	assert.Empty(t, x.Search("tga", Options{}))
+	assert.Empty(t, x.Search(" ?! ", Options{}))
+
+	assert.Equal(t, []string{"t2", "t1"}, ids(x.Search("release", Options{Types: []string{TypeTask}})))
+	assert.Equal(t, []string{"t2"}, ids(x.Search("release", Options{Limit: 1})))
+	notT2 := func(hit Hit) bool { return hit.ID != "t2" }
+	assert.Equal(t, []string{"c1", "t1"}, ids(x.Search("release", Options{Limit: 2, Filter: notT2})))
+
+	// replacing and removing documents updates their terms
+	x.Add(Document{Type: TypeTask, ID: "t2", Title: "Write the changelog"})
+	assert.Equal(t, []string{"c1", "t1", "p1"}, ids(x.Search("release", Options{})))
+	assert.Equal(t, []string{"t2"}, ids(x.Search("changelog", Options{})))
+	x.Remove(TypeTask, "t2")
+	assert.Empty(t, x.Search("changelog", Options{}))
+	assert.Equal(t, 4, x.Len())
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("plan", "plan", 2))
+	assert.Equal(t, 1, editDistance("plan", "plans", 2))
+	assert.Equal(t, 3, editDistance("kitten", "sitting", 3))
+	// beyond max, the distance is only known to be greater than max
+	assert.Equal(t, 2, editDistance("kitten", "sitting", 1))
+	assert.Equal(t, 2, editDistance("a", "abcdef", 1))
+	assert.Equal(t, 1, editDistance("über", "uber", 1))
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+)
+
+// ErrForbidden is returned by the services when the user they act for lacks the role an operation requires.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrInvalidMember is returned by PlannerService.SharePlanner for an unknown role or for the user the planner belongs to.
+var ErrInvalidMember = errors.New("invalid member")
+
+// AccessService resolves the role of a user in the planners and in the goals, plans and tasks below them.
+//
+// Access passes down the hierarchy: a goal is governed by its planner, a plan by its goal and a task by its plan.
+// Records that do not belong to a planner (a goal without PlannerId, a plan without GoalId, a task without PlanId)
+// are shared with every user, as all records were before planners had members.
+//
+// The task, goal, plan and planner services check every call against it once they are scoped to a user with As.
+type AccessService struct {
+	planners store.PlannerStore
+	goals    store.GoalStore
+	plans    store.PlanStore
+	tasks    store.TaskStore
+}
+
+// NewAccessService creates an AccessService that reads the planners, goals, plans and tasks from the given stores.
+//
+// Example usage:
+//
+//	access := services.NewAccessService(plannerStore, goalStore, planStore, taskStore)
+//	goalService := services.NewGoalService(goalStore).As(access, "alice")
+func NewAccessService(planners store.PlannerStore, goals store.GoalStore, plans store.PlanStore, tasks store.TaskStore) *AccessService {
+	return &AccessService{
+		planners: planners,
+		goals:    goals,
+		plans:    plans,
+		tasks:    tasks,
+	}
+}
+
+// forbidden returns an ErrForbidden describing the missing role.
+func forbidden(user, required, kind, id string) error {
+	return fmt.Errorf("%w: %s needs the %s role on %s %s", ErrForbidden, user, required, kind, id)
+}
+
+// CheckPlanner returns ErrForbidden unless user has at least the required role in the planner.
+func (a *AccessService) CheckPlanner(user, plannerId, required string) error {
+	planner, err := a.planners.GetPlanner(plannerId)
+	if err != nil {
+		return err
+	}
+	if !models.RoleAllows(planner.RoleOf(user), required) {
+		return forbidden(user, required, "planner", plannerId)
+	}
+	return nil
+}
+
+// CheckGoal returns ErrForbidden unless user has at least the required role in the planner of the goal.
+// An empty goalId stands for no goal and is always allowed.
+func (a *AccessService) CheckGoal(user, goalId, required string) error {
+	plannerId, err := a.PlannerOfGoal(goalId)
+	if err != nil || plannerId == "" {
+		return err
+	}
+	return a.CheckPlanner(user, plannerId, required)
+}
+
+// CheckPlan returns ErrForbidden unless user has at least the required role in the planner the plan belongs to.
+// An empty planId stands for no plan and is always allowed.
+func (a *AccessService) CheckPlan(user, planId, required string) error {
+	plannerId, err := a.PlannerOfPlan(planId)
+	if err != nil || plannerId == "" {
+		return err
+	}
+	return a.CheckPlanner(user, plannerId, required)
+}
+
+// PlannerOfGoal returns the id of the planner the goal belongs to, or an empty string for no goal or no planner.
+func (a *AccessService) PlannerOfGoal(goalId string) (string, error) {
+	if goalId == "" {
+		return "", nil
+	}
+	goal, err := a.goals.GetGoal(goalId)
+	if err != nil {
+		return "", err
+	}
+	return goal.PlannerId, nil
+}
+
+// PlannerOfPlan returns the id of the planner the plan belongs to, or an empty string for no plan or no planner.
+func (a *AccessService) PlannerOfPlan(planId string) (string, error) {
+	if planId == "" {
+		return "", nil
+	}
+	plan, err := a.plans.GetPlan(planId)
+	if err != nil {
+		return "", err
+	}
+	return a.PlannerOfGoal(plan.GoalId)
+}
+
+// PlannerOfTask returns the id of the planner the task belongs to, or an empty string for no task or no planner.
+func (a *AccessService) PlannerOfTask(taskId string) (string, error) {
+	if taskId == "" {
+		return "", nil
+	}
+	task, err := a.tasks.GetTask(taskId)
+	if err != nil {
+		return "", err
+	}
+	return a.PlannerOfPlan(task.PlanId)
+}
+
+// PlannerOfVersion returns the id of the planner of the goal, plan or task the version was taken of,
+// or an empty string if that record belongs to no planner.
+func (a *AccessService) PlannerOfVersion(version *models.Version) (string, error) {
+	switch {
+	case version.GoalID != "":
+		return a.PlannerOfGoal(string(version.GoalID))
+	case version.PlanID != "":
+		return a.PlannerOfPlan(string(version.PlanID))
+	}
+	return a.PlannerOfTask(string(version.TaskID))
+}
+
+// PlannerIds returns the ids of the planners user can view.
+func (a *AccessService) PlannerIds(user string) ([]string, error) {
+	planners, err := a.planners.ListPlanners()
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, planner := range planners {
+		if models.RoleAllows(planner.RoleOf(user), models.RoleViewer) {
+			ids = append(ids, planner.Id)
+		}
+	}
+	return ids, nil
+}
+
+// GoalIds returns the ids of the goals user can view, including an empty id for "no goal".
+func (a *AccessService) GoalIds(user string) ([]string, error) {
+	plannerIds, err := a.PlannerIds(user)
+	if err != nil {
+		return nil, err
+	}
+	goals, err := a.goals.FindGoals(&store.ListOptions{PlannerIds: append(plannerIds, "")})
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{""}
+	for _, goal := range goals {
+		ids = append(ids, goal.Id)
+	}
+	return ids, nil
+}
+
+// PlanIds returns the ids of the plans user can view, including an empty id for "no plan".
+func (a *AccessService) PlanIds(user string) ([]string, error) {
+	goalIds, err := a.GoalIds(user)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := a.plans.FindPlans(&store.ListOptions{GoalIds: goalIds})
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{""}
+	for _, plan := range plans {
+		ids = append(ids, plan.Id)
+	}
+	return ids, nil
+}
+
+// idSet returns the ids as a set.
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// restrict returns the ids of requested that are also in allowed, or allowed if requested is nil,
+// so that a scoped service narrows the id lists of a caller instead of replacing them.
+func restrict(requested, allowed []string) []string {
+	if requested == nil {
+		return allowed
+	}
+	set := idSet(allowed)
+	ids := []string{}
+	for _, id := range requested {
+		if set[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// scopeOptions returns a copy of opts, which may be nil, for the Find* methods of the scoped services to restrict.
+func scopeOptions(opts *store.ListOptions) *store.ListOptions {
+	scoped := store.ListOptions{}
+	if opts != nil {
+		scoped = *opts
+	}
+	return &scoped
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"encoding/json"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultEventLogSize is the number of events kept in the event log for clients that resume a stream.
+const DefaultEventLogSize = 1000
+
+// subscriptionBuffer is the number of events a subscriber may fall behind before it is dropped.
+const subscriptionBuffer = 64
+
+// EventFilter selects the events a subscriber receives.
+//
+// Fields:
+// - PlannerId: only events about records of this planner.
+// - Types: only events of these types, such as "task.created", or about these resources, such as "goal".
+type EventFilter struct {
+	PlannerId string
+	Types     []string
+}
+
+// Match reports whether the event passes the filter. An empty filter matches every event.
+func (f EventFilter) Match(event *models.Event) bool {
+	if f.PlannerId != "" && event.PlannerId != f.PlannerId {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type || t == event.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives the events published after it was created, in the order of their ids.
+// C is closed when the subscription is closed or when the subscriber falls too far behind;
+// it can then resume from the id of the last event it received with EventService.ListEvents.
+type Subscription struct {
+	C       <-chan *models.Event
+	c       chan *models.Event
+	filter  EventFilter
+	service *EventService
+}
+
+// Close stops the subscription and closes C.
+func (sub *Subscription) Close() {
+	sub.service.hub.mu.Lock()
+	defer sub.service.hub.mu.Unlock()
+	sub.service.hub.remove(sub)
+}
+
+// eventHub holds the subscribers and observers of an EventService and the copies returned by As.
+// mu also serializes publishing, so that subscribers receive the events in the order of their ids.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	observers   []func(event *models.Event)
+}
+
+// remove drops a subscriber and closes its channel. The caller must hold mu.
+func (h *eventHub) remove(sub *Subscription) {
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.c)
+	}
+}
+
+// EventService is the event bus of the server: the task, goal, plan, planner and version services
+// record every change they make with it, and it appends the events to a bounded log and passes them on to the subscribers.
+// A service returned by As only delivers the events of the planners shared with its user.
+type EventService struct {
+	store    store.EventStore
+	keep     int
+	hub      *eventHub
+	resolver *AccessService
+	access   *AccessService
+	user     string
+	deferred *[]deferredEvent
+}
+
+// deferredEvent is a change recorded with a service returned by Deferred, which is published by Flush.
+type deferredEvent struct {
+	action string
+	record interface{}
+}
+
+// NewEventService creates an EventService that keeps the last keep events in store.
+// resolver is used to find the planner of the goals, plans, tasks and versions the events are about.
+//
+// Example usage:
+//
+//	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+//	taskService := services.NewTaskService(taskStore).WithEvents(events)
+func NewEventService(store store.EventStore, resolver *AccessService, keep int) *EventService {
+	return &EventService{
+		store:    store,
+		keep:     keep,
+		hub:      &eventHub{subscribers: map[*Subscription]struct{}{}},
+		resolver: resolver,
+	}
+}
+
+// As returns a copy of the service that acts for the named user: its subscriptions and event lists only contain
+// the events about records the user can view. The copy shares the log and the subscribers of s.
+func (s *EventService) As(access *AccessService, user string) *EventService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// Deferred returns a copy of the service that holds back the events recorded with it until Flush is called.
+// Services that make their changes in a transaction record them with it, so that the events are only
+// published once the transaction is committed, and dropped if it is rolled back. It returns nil if s is nil.
+func (s *EventService) Deferred() *EventService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.deferred = &[]deferredEvent{}
+	return &scoped
+}
+
+// Flush publishes the events recorded with a service returned by Deferred, in the order they were recorded.
+// It does nothing if s is nil or was not returned by Deferred.
+func (s *EventService) Flush() {
+	if s == nil || s.deferred == nil {
+		return
+	}
+	pending := *s.deferred
+	*s.deferred = nil
+	publisher := *s
+	publisher.deferred = nil
+	for _, event := range pending {
+		publisher.Record(event.action, event.record)
+	}
+}
+
+// Record publishes an event about an action (models.EventCreated, EventUpdated or EventDeleted) on a task, goal,
+// plan, planner or version. It does nothing if s is nil, so services without an event bus can call it unconditionally.
+// Failures are logged rather than returned, because the change itself has already been stored.
+func (s *EventService) Record(action string, record interface{}) {
+	if s == nil {
+		return
+	}
+	if s.deferred != nil {
+		*s.deferred = append(*s.deferred, deferredEvent{action: action, record: record})
+		return
+	}
+	event := &models.Event{}
+	var err error
+	switch r := record.(type) {
+	case *models.Task:
+		event.Resource, event.ResourceId, event.Revision = "task", r.ID, r.Revision
+		event.PlannerId, err = s.resolver.PlannerOfPlan(r.PlanId)
+	case *models.Goal:
+		event.Resource, event.ResourceId, event.Revision = "goal", r.Id, r.Revision
+		event.PlannerId = r.PlannerId
+	case *models.Plan:
+		event.Resource, event.ResourceId, event.Revision = "plan", r.Id, r.Revision
+		event.PlannerId, err = s.resolver.PlannerOfGoal(r.GoalId)
+	case *models.Planner:
+		event.Resource, event.ResourceId, event.Revision = "planner", r.Id, r.Revision
+		event.PlannerId = r.Id
+	case *models.Version:
+		event.Resource, event.ResourceId = "version", string(r.ID)
+		event.PlannerId, err = s.resolver.PlannerOfVersion(r)
+	default:
+		log.Printf("Error recording event: unsupported record %T", record)
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording %s event for %s: %s", action, event.ResourceId, err)
+		return
+	}
+	event.Type = models.EventType(event.Resource, action)
+	if event.Data, err = json.Marshal(record); err != nil {
+		log.Printf("Error recording %s event for %s: %s", action, event.ResourceId, err)
+		return
+	}
+	if err := s.Publish(event); err != nil {
+		log.Printf("Error recording %s event for %s: %s", action, event.ResourceId, err)
+	}
+}
+
+// Publish appends the event to the log, which assigns its id, and passes it on to the observers and the matching subscribers.
+// Subscribers whose buffer is full are dropped.
+func (s *EventService) Publish(event *models.Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if err := s.store.AppendEvent(event, s.keep); err != nil {
+		return err
+	}
+	for _, observe := range s.hub.observers {
+		observe(event)
+	}
+	for sub := range s.hub.subscribers {
+		if !sub.filter.Match(event) || !sub.service.visible(event) {
+			continue
+		}
+		select {
+		case sub.c <- event:
+		default:
+			s.hub.remove(sub)
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to the events published from now on that match filter.
+// The subscription must be closed when it is no longer needed.
+func (s *EventService) Subscribe(filter EventFilter) *Subscription {
+	c := make(chan *models.Event, subscriptionBuffer)
+	sub := &Subscription{C: c, c: c, filter: filter, service: s}
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Observe calls fn with every event published from now on, before it is passed on to the subscribers.
+// Unlike a subscription, an observer is never dropped and sees every event, whatever the user of s,
+// so that state kept beside the stores, such as the search index, is updated along with each change.
+// fn runs while the event is published and must not publish events itself.
+func (s *EventService) Observe(fn func(event *models.Event)) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.observers = append(s.hub.observers, fn)
+}
+
+// ListEvents returns up to limit logged events with an id greater than after that match filter, oldest first.
+// Events older than the last DefaultEventLogSize (or the size the service was created with) are no longer available.
+func (s *EventService) ListEvents(after, limit int, filter EventFilter) ([]*models.Event, error) {
+	events, err := s.store.ListEvents(after, 0)
+	if err != nil {
+		return nil, err
+	}
+	matched := []*models.Event{}
+	for _, event := range events {
+		if limit > 0 && len(matched) == limit {
+			break
+		}
+		if filter.Match(event) && s.visible(event) {
+			matched = append(matched, event)
+		}
+	}
+	return matched, nil
+}
+
+// visible reports whether the user of a scoped service can view the record an event is about.
+// Events about planners are checked against the planner in the event, so members also learn that it was deleted.
+func (s *EventService) visible(event *models.Event) bool {
+	if s.access == nil || event.PlannerId == "" {
+		return true
+	}
+	if event.Resource == "planner" {
+		planner := &models.Planner{}
+		if err := json.Unmarshal(event.Data, planner); err == nil {
+			return models.RoleAllows(planner.RoleOf(s.user), models.RoleViewer)
+		}
+	}
+	return s.access.CheckPlanner(s.user, event.PlannerId, models.RoleViewer) == nil
+}
@@ -1,13 +1,21 @@
 package services
 
 import (
+	"context"
 	"github.com/ooyeku/flow/pkg/models"
 	store2 "github.com/ooyeku/flow/pkg/store"
+	"log"
+	"time"
 )
 
 // GoalService is a type that provides operations for managing goals.
+// A service returned by As only lets its user see and change the goals of the planners shared with them.
 type GoalService struct {
-	store store2.GoalStore
+	store   store2.GoalStore
+	access  *AccessService
+	user    string
+	events  *EventService
+	journal *JournalService
 }
 
 // NewGoalService is a function that creates a new instance of GoalService.
@@ -23,21 +31,118 @@ func NewGoalService(store store2.GoalStore) *GoalService {
 	}
 }
 
+// As returns a copy of the service that acts for the named user: every call is checked against the user's role
+// in the planner of the goal it touches, and the lists only contain the goals the user can view.
+func (s *GoalService) As(access *AccessService, user string) *GoalService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every goal it creates, updates or deletes with events.
+func (s *GoalService) WithEvents(events *EventService) *GoalService {
+	scoped := *s
+	scoped.events = events
+	return &scoped
+}
+
+// WithJournal returns a copy of the service that records every goal it creates, updates or deletes in the journal,
+// before and after the change, so that its user can undo it.
+func (s *GoalService) WithJournal(journal *JournalService) *GoalService {
+	scoped := *s
+	scoped.journal = journal
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who lacks the required role in the planner of the goal.
+func (s *GoalService) check(goal *models.Goal, required string) error {
+	if s.access == nil || goal.PlannerId == "" {
+		return nil
+	}
+	return s.access.CheckPlanner(s.user, goal.PlannerId, required)
+}
+
+// visible returns the goals the service's user can view.
+func (s *GoalService) visible(goals []*models.Goal) ([]*models.Goal, error) {
+	if s.access == nil {
+		return goals, nil
+	}
+	ids, err := s.access.PlannerIds(s.user)
+	if err != nil {
+		return nil, err
+	}
+	planners := idSet(append(ids, ""))
+	var shared []*models.Goal
+	for _, goal := range goals {
+		if planners[goal.PlannerId] {
+			shared = append(shared, goal)
+		}
+	}
+	return shared, nil
+}
+
 // CreateGoal creates a new goal using the provided goal object
+// Adding a goal to a planner requires the editor role in it.
 func (s *GoalService) CreateGoal(goal *models.Goal) error {
-	return s.store.CreateGoal(goal)
+	if err := s.check(goal, models.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.store.CreateGoal(goal); err != nil {
+		return err
+	}
+	s.events.Record(models.EventCreated, goal)
+	s.journal.Record(s.user, models.EventCreated, nil, goal)
+	return nil
 }
 
 // UpdateGoal is a method of the GoalService struct that updates an existing goal with the provided data.
-// It takes a pointer to a Goal struct as a parameter and returns an error.
+// It takes a pointer to a Goal struct and the revision the caller last read as parameters and returns an error.
+// Passing store.AnyRevision skips the revision check; otherwise store.ErrRevisionMismatch is returned if the goal has been changed since.
 // The method uses the UpdateGoal method of the GoalStore interface to update the goal in the data store.
-func (s *GoalService) UpdateGoal(goal *models.Goal) error {
-	return s.store.UpdateGoal(goal)
+// Changing a goal requires the editor role in its planner, and in the new planner if the goal is moved.
+func (s *GoalService) UpdateGoal(goal *models.Goal, expectedRevision int) error {
+	var current *models.Goal
+	if s.access != nil || s.journal != nil {
+		var err error
+		if current, err = s.store.GetGoal(goal.Id); err != nil {
+			return err
+		}
+		if err := s.check(current, models.RoleEditor); err != nil {
+			return err
+		}
+		if err := s.check(goal, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.store.UpdateGoal(goal, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventUpdated, goal)
+	s.journal.Record(s.user, models.EventUpdated, current, goal)
+	return nil
 }
 
-// DeleteGoal deletes a goal with the specified ID.
-func (s *GoalService) DeleteGoal(id string) error {
-	return s.store.DeleteGoal(id)
+// DeleteGoal deletes a goal with the specified ID if it is still at expectedRevision (or unconditionally for store.AnyRevision).
+// The goal is moved to the trash with its plans and their tasks, which TrashService restores together.
+// Deleting a goal requires the editor role in its planner.
+func (s *GoalService) DeleteGoal(id string, expectedRevision int) error {
+	var goal *models.Goal
+	if s.access != nil || s.events != nil || s.journal != nil {
+		var err error
+		if goal, err = s.store.GetGoal(id); err != nil {
+			return err
+		}
+		if err := s.check(goal, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.store.DeleteGoal(id, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventDeleted, goal)
+	s.journal.Record(s.user, models.EventDeleted, goal, nil)
+	return nil
 }
 
 // GetGoal is a method of the GoalService struct that retrieves a goal with the specified ID.
@@ -45,7 +150,14 @@ func (s *GoalService) DeleteGoal(id string) error {
 // The method returns a pointer to a Goal struct and an error. If the goal is not found, it returns nil and an error.
 // The method uses the GetGoal method of the GoalStore interface to retrieve the goal from the data store.
 func (s *GoalService) GetGoal(id string) (*models.Goal, error) {
-	return s.store.GetGoal(id)
+	goal, err := s.store.GetGoal(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(goal, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return goal, nil
 }
 
 // ListGoals is a method of GoalService that retrieves a list of goals and returns them along with any errors encountered.
@@ -69,7 +181,11 @@ func (s *GoalService) GetGoal(id string) (*models.Goal, error) {
 // It returns a slice of pointers to Goal structs and an error.
 // The method uses the ListGoals method of the GoalStore interface to fetch the goals from the data store.
 func (s *GoalService) ListGoals() ([]*models.Goal, error) {
-	return s.store.ListGoals()
+	goals, err := s.store.ListGoals()
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(goals)
 }
 
 // GetGoalByObjective retrieves a goal by its objective.
@@ -86,7 +202,14 @@ func (s *GoalService) ListGoals() ([]*models.Goal, error) {
 //	  Goal: goal,
 //	}, nil
 func (s *GoalService) GetGoalByObjective(objective string) (*models.Goal, error) {
-	return s.store.GetGoalByObjective(objective)
+	goal, err := s.store.GetGoalByObjective(objective)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(goal, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return goal, nil
 }
 
 // GetGoalsByPlannerId is a method of the GoalService struct that retrieves all goals associated with a given planner ID.
@@ -104,5 +227,58 @@ func (s *GoalService) GetGoalByObjective(objective string) (*models.Goal, error)
 //
 // Note: The GetGoalsByPlannerIdRequest and GetGoalsByPlannerIdResponse types are not shown here, but they are used in the example above to pass the request and retrieve the response
 func (s *GoalService) GetGoalsByPlannerId(plannerId string) ([]*models.Goal, error) {
-	return s.store.GetGoalsByPlannerId(plannerId)
+	goals, err := s.store.GetGoalsByPlannerId(plannerId)
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(goals)
+}
+
+// FindGoals retrieves the goals matching the given list options.
+// Filtering, ordering and paging are performed by the goal store.
+func (s *GoalService) FindGoals(opts *store2.ListOptions) ([]*models.Goal, error) {
+	if s.access != nil {
+		ids, err := s.access.PlannerIds(s.user)
+		if err != nil {
+			return nil, err
+		}
+		opts = scopeOptions(opts)
+		opts.PlannerIds = restrict(opts.PlannerIds, append(ids, ""))
+	}
+	return s.store.FindGoals(opts)
+}
+
+// RecordOverdue records a "goal.overdue" event for every goal that is not completed and whose deadline
+// passed after since and no later than now. Calling it with consecutive time windows reports each goal once.
+func (s *GoalService) RecordOverdue(since, now time.Time) error {
+	goals, err := s.store.FindGoals(&store2.ListOptions{DeadlineBefore: now.Add(time.Nanosecond)})
+	if err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		if goal.Deadline.After(since) && goal.GoalStatus != models.Completed {
+			s.events.Record(models.EventOverdue, goal)
+		}
+	}
+	return nil
+}
+
+// WatchDeadlines calls RecordOverdue every interval until ctx is done, for the deadlines that passed since the previous call.
+// Deadlines that passed before WatchDeadlines was started are not reported.
+func (s *GoalService) WatchDeadlines(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.RecordOverdue(since, now); err != nil {
+				log.Printf("Error checking goal deadlines: %s", err)
+				continue
+			}
+			since = now
+		}
+	}
 }
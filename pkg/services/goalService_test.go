@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGoalService_RecordOverdue(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	access := services.NewAccessService(inmemory.NewInMemoryPlannerStore(db), goalStore, inmemory.NewInMemoryPlanStore(db),
+		inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	goalService := services.NewGoalService(goalStore).WithEvents(events)
+
+	now := time.Now()
+	for _, goal := range []*models.Goal{
+		{Id: "slipped", Deadline: now.Add(-time.Minute), GoalStatus: models.InProgress},
+		{Id: "done", Deadline: now.Add(-time.Minute), GoalStatus: models.Completed},
+		{Id: "reported", Deadline: now.Add(-time.Hour), GoalStatus: models.NotStarted},
+		{Id: "later", Deadline: now.Add(time.Hour), GoalStatus: models.NotStarted},
+	} {
+		if err := goalStore.CreateGoal(goal); err != nil {
+			t.Fatalf("failed to create goal: %v", err)
+		}
+	}
+
+	sub := events.Subscribe(services.EventFilter{Types: []string{"goal.overdue"}})
+	defer sub.Close()
+	if err := goalService.RecordOverdue(now.Add(-30*time.Minute), now); err != nil {
+		t.Fatalf("failed to record overdue goals: %v", err)
+	}
+	event := <-sub.C
+	assert.Equal(t, "slipped", event.ResourceId)
+	assert.Empty(t, sub.C)
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultJournalSize is the number of changes of each user the journal keeps for undo.
+const DefaultJournalSize = 100
+
+// JournalResult lists the changes an undo or redo applied, and those it skipped: changes to records that someone
+// else has changed since, or that the user may no longer change. Skipped changes are dropped from the journal.
+type JournalResult struct {
+	Applied []*models.JournalEntry
+	Skipped []*models.JournalEntry
+}
+
+// JournalService keeps the journal of the changes each user makes with the task, goal, plan and planner services,
+// with the records before and after each change, and undoes and redoes them. A service returned by As undoes
+// and redoes the changes of its user, with their access to the planners.
+type JournalService struct {
+	store    store.JournalStore
+	keep     int
+	tasks    *TaskService
+	goals    *GoalService
+	plans    *PlanService
+	planners *PlannerService
+	trash    *TrashService
+	access   *AccessService
+	user     string
+	mu       *sync.Mutex
+	deferred *[]*models.JournalEntry
+}
+
+// NewJournalService creates a JournalService that keeps the last keep changes of each user in store.
+// Undo and redo change the records with the given services, which should publish their changes on the event bus
+// but not record them in the journal: the services that do are copies returned by their WithJournal.
+//
+// Example usage:
+//
+//	taskService := services.NewTaskService(taskStore).WithEvents(events)
+//	journal := services.NewJournalService(inmemory.NewInMemoryJournalStore(db), services.DefaultJournalSize,
+//		taskService, goalService, planService, plannerService)
+//	taskControl := handle.NewTaskControl(taskService.WithJournal(journal))
+func NewJournalService(store store.JournalStore, keep int, tasks *TaskService, goals *GoalService, plans *PlanService, planners *PlannerService) *JournalService {
+	return &JournalService{
+		store:    store,
+		keep:     keep,
+		tasks:    tasks,
+		goals:    goals,
+		plans:    plans,
+		planners: planners,
+		mu:       &sync.Mutex{},
+	}
+}
+
+// WithTrash returns a copy of the service that undoes deletions, and redoes restores, by restoring the records from
+// the trash with trash, so that the records deleted along with them come back too. Like the other services of the
+// journal, trash should not record its restores in the journal.
+func (s *JournalService) WithTrash(trash *TrashService) *JournalService {
+	scoped := *s
+	scoped.trash = trash
+	return &scoped
+}
+
+// As returns a copy of the service that undoes and redoes the changes of the named user, checked against
+// the user's role in the planners of the records, as the user's own changes are.
+func (s *JournalService) As(access *AccessService, user string) *JournalService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// Deferred returns a copy of the service that holds back the changes recorded with it until Flush is called,
+// so that the changes of a transaction are only journaled once it is committed. It returns nil if s is nil.
+func (s *JournalService) Deferred() *JournalService {
+	if s == nil {
+		return nil
+	}
+	scoped := *s
+	scoped.deferred = &[]*models.JournalEntry{}
+	return &scoped
+}
+
+// Flush journals the changes recorded with a service returned by Deferred, in the order they were recorded.
+// It does nothing if s is nil or was not returned by Deferred.
+func (s *JournalService) Flush() {
+	if s == nil || s.deferred == nil {
+		return
+	}
+	pending := *s.deferred
+	*s.deferred = nil
+	for _, entry := range pending {
+		s.append(entry)
+	}
+}
+
+// Record journals a change the named user made: an action (models.EventCreated, EventUpdated, EventDeleted or EventRestored)
+// that took a task, goal, plan or planner from before to after; before is nil for a creation or a restore
+// and after for a deletion.
+// It does nothing if s is nil, so services without a journal can call it unconditionally.
+// Failures are logged rather than returned, because the change itself has already been stored.
+func (s *JournalService) Record(user, action string, before, after interface{}) {
+	if s == nil {
+		return
+	}
+	entry := &models.JournalEntry{User: user, Action: action, Time: time.Now()}
+	var err error
+	if before != nil {
+		if entry.Before, err = s.describe(entry, before); err != nil {
+			log.Printf("Error journaling %s of %s: %s", action, entry.ResourceId, err)
+			return
+		}
+	}
+	// the entry expects the record at its revision after the change, and a deleted record to be absent
+	if after != nil {
+		if entry.After, err = s.describe(entry, after); err != nil {
+			log.Printf("Error journaling %s of %s: %s", action, entry.ResourceId, err)
+			return
+		}
+	} else {
+		entry.Revision = 0
+	}
+	if s.deferred != nil {
+		*s.deferred = append(*s.deferred, entry)
+		return
+	}
+	s.append(entry)
+}
+
+// describe fills in the resource, id, name and revision of the entry from a record and returns the record as JSON.
+func (s *JournalService) describe(entry *models.JournalEntry, record interface{}) (json.RawMessage, error) {
+	switch r := record.(type) {
+	case *models.Task:
+		entry.Resource, entry.ResourceId, entry.Name, entry.Revision = "task", r.ID, r.Title, r.Revision
+	case *models.Goal:
+		entry.Resource, entry.ResourceId, entry.Name, entry.Revision = "goal", r.Id, r.Objective, r.Revision
+	case *models.Plan:
+		entry.Resource, entry.ResourceId, entry.Name, entry.Revision = "plan", r.Id, r.PlanName, r.Revision
+	case *models.Planner:
+		entry.Resource, entry.ResourceId, entry.Name, entry.Revision = "planner", r.Id, r.Title, r.Revision
+	default:
+		return nil, errors.New("unsupported record")
+	}
+	return json.Marshal(record)
+}
+
+// append stores an entry, logging a failure.
+func (s *JournalService) append(entry *models.JournalEntry) {
+	if err := s.store.AppendEntry(entry, s.keep); err != nil {
+		log.Printf("Error journaling %s of %s: %s", entry.Action, entry.ResourceId, err)
+	}
+}
+
+// Undo reverts the last steps changes of the user of the service that have not been undone, newest first.
+// A change is skipped if the record has been changed by someone else since, or the user may no longer change it;
+// skipped changes do not count as steps.
+func (s *JournalService) Undo(steps int) (*JournalResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.store.ListEntries(s.user)
+	if err != nil {
+		return nil, err
+	}
+	res := &JournalResult{}
+	for i := len(entries) - 1; i >= 0 && len(res.Applied) < steps; i-- {
+		entry := entries[i]
+		if entry.Undone {
+			continue
+		}
+		applied, err := s.apply(entry, entry.Before, res)
+		if err != nil {
+			return nil, err
+		}
+		if !applied {
+			continue
+		}
+		entry.Undone = true
+		// the change the user made to the record before this one can be undone from where this undo left it
+		for j := i - 1; j >= 0; j-- {
+			if sameRecord(entries[j], entry) {
+				entries[j].Revision = entry.Revision
+				if err := s.store.UpdateEntry(entries[j]); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		if err := s.store.UpdateEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Redo applies again the last steps changes of the user of the service that were undone, in the order they were made.
+// The undone changes can be redone until the user makes a new change. As with Undo, changes to records that someone
+// else has changed since are skipped.
+func (s *JournalService) Redo(steps int) (*JournalResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.store.ListEntries(s.user)
+	if err != nil {
+		return nil, err
+	}
+	res := &JournalResult{}
+	for i := 0; i < len(entries) && len(res.Applied) < steps; i++ {
+		entry := entries[i]
+		if !entry.Undone {
+			continue
+		}
+		applied, err := s.apply(entry, entry.After, res)
+		if err != nil {
+			return nil, err
+		}
+		if !applied {
+			continue
+		}
+		entry.Undone = false
+		// the next undone change to the record can be redone from where this redo left it
+		for j := i + 1; j < len(entries); j++ {
+			if sameRecord(entries[j], entry) {
+				entries[j].Revision = entry.Revision
+				if err := s.store.UpdateEntry(entries[j]); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+		if err := s.store.UpdateEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// sameRecord reports whether two entries are about the same record.
+func sameRecord(a, b *models.JournalEntry) bool {
+	return a.Resource == b.Resource && a.ResourceId == b.ResourceId
+}
+
+// apply brings the record of an entry to state, or deletes it if state is empty, provided the record is still
+// at the revision of the entry. It sets the revision of the entry to the one the record is left at and adds
+// the entry to the applied changes of res; if the record has been changed since or the user may no longer change it,
+// it drops the entry from the journal, adds it to the skipped changes and returns false.
+func (s *JournalService) apply(entry *models.JournalEntry, state json.RawMessage, res *JournalResult) (bool, error) {
+	revision, err := s.change(entry, state)
+	switch {
+	case errors.Is(err, store.ErrRevisionMismatch), errors.Is(err, storm.ErrNotFound), errors.Is(err, ErrForbidden):
+		if err := s.store.DeleteEntry(entry.Id); err != nil {
+			return false, err
+		}
+		res.Skipped = append(res.Skipped, entry)
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	entry.Revision = revision
+	res.Applied = append(res.Applied, entry)
+	return true, nil
+}
+
+// change brings the record of an entry to state with the services of the journal, as apply describes, and returns
+// the revision of the record afterwards, or 0 once it is deleted. A record that must not exist but does is reported
+// as store.ErrRevisionMismatch. A deleted record is restored from the trash if it is still there, and created again otherwise.
+func (s *JournalService) change(entry *models.JournalEntry, state json.RawMessage) (int, error) {
+	id, expected := entry.ResourceId, entry.Revision
+	// the store reads a missing record back as null
+	if string(state) == "null" {
+		state = nil
+	}
+	if len(state) > 0 && expected == 0 {
+		if revision, err := s.restore(entry); !errors.Is(err, storm.ErrNotFound) {
+			return revision, err
+		}
+	}
+	var err error
+	switch entry.Resource {
+	case "task":
+		tasks := s.tasks
+		if s.access != nil {
+			tasks = tasks.As(s.access, s.user)
+		}
+		if len(state) == 0 {
+			return 0, tasks.DeleteTask(id, expected)
+		}
+		task := &models.Task{}
+		if err := json.Unmarshal(state, task); err != nil {
+			return 0, err
+		}
+		if expected == 0 {
+			if err := absent(tasks.Store.GetTask(id)); err != nil {
+				return 0, err
+			}
+			err = tasks.CreateTask(task)
+		} else {
+			err = tasks.UpdateTask(id, task, expected)
+		}
+		return task.Revision, err
+	case "goal":
+		goals := s.goals
+		if s.access != nil {
+			goals = goals.As(s.access, s.user)
+		}
+		if len(state) == 0 {
+			return 0, goals.DeleteGoal(id, expected)
+		}
+		goal := &models.Goal{}
+		if err := json.Unmarshal(state, goal); err != nil {
+			return 0, err
+		}
+		if expected == 0 {
+			if err := absent(goals.store.GetGoal(id)); err != nil {
+				return 0, err
+			}
+			err = goals.CreateGoal(goal)
+		} else {
+			err = goals.UpdateGoal(goal, expected)
+		}
+		return goal.Revision, err
+	case "plan":
+		plans := s.plans
+		if s.access != nil {
+			plans = plans.As(s.access, s.user)
+		}
+		if len(state) == 0 {
+			return 0, plans.DeletePlan(id, expected)
+		}
+		plan := &models.Plan{}
+		if err := json.Unmarshal(state, plan); err != nil {
+			return 0, err
+		}
+		if expected == 0 {
+			if err := absent(plans.store.GetPlan(id)); err != nil {
+				return 0, err
+			}
+			err = plans.CreatePlan(plan)
+		} else {
+			err = plans.UpdatePlan(plan, expected)
+		}
+		return plan.Revision, err
+	case "planner":
+		planners := s.planners
+		if s.access != nil {
+			planners = planners.As(s.access, s.user)
+		}
+		if len(state) == 0 {
+			return 0, planners.DeletePlanner(id, expected)
+		}
+		planner := &models.Planner{}
+		if err := json.Unmarshal(state, planner); err != nil {
+			return 0, err
+		}
+		if expected == 0 {
+			if err := absent(planners.store.GetPlanner(id)); err != nil {
+				return 0, err
+			}
+			err = planners.CreatePlanner(planner)
+		} else {
+			err = planners.UpdatePlanner(planner, expected)
+		}
+		return planner.Revision, err
+	}
+	return 0, errors.New("unsupported resource " + entry.Resource)
+}
+
+// restore brings the record of an entry back from the trash, with the records deleted along with it, and returns
+// its revision. It returns storm.ErrNotFound if the service has no trash or the record is not in it.
+func (s *JournalService) restore(entry *models.JournalEntry) (int, error) {
+	if s.trash == nil {
+		return 0, storm.ErrNotFound
+	}
+	trash := s.trash
+	if s.access != nil {
+		trash = trash.As(s.access, s.user)
+	}
+	restored, err := trash.RestoreTrash(entry.ResourceId)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range restored {
+		if item.Resource == entry.Resource && item.Id == entry.ResourceId {
+			return item.Revision, nil
+		}
+	}
+	return 0, storm.ErrNotFound
+}
+
+// absent takes the result of looking up a record that must not exist and returns nil if none was found,
+// store.ErrRevisionMismatch if one was, or the error of the lookup.
+func absent(_ interface{}, err error) error {
+	switch {
+	case err == nil:
+		return store.ErrRevisionMismatch
+	case errors.Is(err, storm.ErrNotFound):
+		return nil
+	}
+	return err
+}
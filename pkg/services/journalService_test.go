@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+// journaled holds the services of a test, which journal their changes, the journal and the trash.
+type journaled struct {
+	access   *services.AccessService
+	tasks    *services.TaskService
+	goals    *services.GoalService
+	plans    *services.PlanService
+	planners *services.PlannerService
+	journal  *services.JournalService
+	trash    *services.TrashService
+}
+
+func setupJournal(t *testing.T) *journaled {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	taskService := services.NewTaskService(inmemory.NewInMemoryTaskStore(db))
+	goalService := services.NewGoalService(inmemory.NewInMemoryGoalStore(db))
+	planService := services.NewPlanService(inmemory.NewInMemoryPlanStore(db))
+	plannerService := services.NewPlannerService(inmemory.NewInMemoryPlannerStore(db))
+	trashService := services.NewTrashService(inmemory.NewInMemoryTrashStore(db))
+	journal := services.NewJournalService(inmemory.NewInMemoryJournalStore(db), services.DefaultJournalSize,
+		taskService, goalService, planService, plannerService).WithTrash(trashService)
+	return &journaled{
+		access: services.NewAccessService(inmemory.NewInMemoryPlannerStore(db), inmemory.NewInMemoryGoalStore(db),
+			inmemory.NewInMemoryPlanStore(db), inmemory.NewInMemoryTaskStore(db)),
+		tasks:    taskService.WithJournal(journal),
+		goals:    goalService.WithJournal(journal),
+		plans:    planService.WithJournal(journal),
+		planners: plannerService.WithJournal(journal),
+		journal:  journal,
+		trash:    trashService.WithJournal(journal),
+	}
+}
+
+func TestJournalService_UndoRedo(t *testing.T) {
+	j := setupJournal(t)
+	task := &models.Task{ID: "task1", Title: "Write docs"}
+	if err := j.tasks.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := j.tasks.UpdateTask("task1", &models.Task{Title: "Write the docs", Started: true}, store.AnyRevision); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if err := j.tasks.DeleteTask("task1", store.AnyRevision); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	// undoing the deletion restores the task as it was updated
+	res, err := j.journal.Undo(1)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, models.EventDeleted, res.Applied[0].Action)
+	}
+	restored, err := j.tasks.GetTask("task1")
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "Write the docs", restored.Title)
+	assert.True(t, restored.Started)
+
+	// two more steps revert the update and then the creation
+	res, err = j.journal.Undo(5)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 2) {
+		assert.Equal(t, models.EventUpdated, res.Applied[0].Action)
+		assert.Equal(t, models.EventCreated, res.Applied[1].Action)
+	}
+	_, err = j.tasks.GetTask("task1")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+	res, err = j.journal.Undo(1)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	assert.Empty(t, res.Applied)
+
+	// redo applies the changes again in the order they were made
+	res, err = j.journal.Redo(2)
+	if err != nil {
+		t.Fatalf("failed to redo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 2) {
+		assert.Equal(t, models.EventCreated, res.Applied[0].Action)
+		assert.Equal(t, models.EventUpdated, res.Applied[1].Action)
+	}
+	redone, err := j.tasks.GetTask("task1")
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "Write the docs", redone.Title)
+
+	// a new change drops the changes left to redo
+	if err := j.tasks.UpdateTask("task1", &models.Task{Title: "Publish the docs"}, store.AnyRevision); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	res, err = j.journal.Redo(1)
+	if err != nil {
+		t.Fatalf("failed to redo: %v", err)
+	}
+	assert.Empty(t, res.Applied)
+	res, err = j.journal.Undo(1)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	assert.Len(t, res.Applied, 1)
+	reverted, err := j.tasks.GetTask("task1")
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	assert.Equal(t, "Write the docs", reverted.Title)
+}
+
+func TestJournalService_UndoPerUser(t *testing.T) {
+	j := setupJournal(t)
+	planner := &models.Planner{Id: "planner1", Title: "Team", UserId: "alice",
+		Members: map[string]string{"alice": models.RoleOwner, "bob": models.RoleEditor}}
+	if err := j.planners.CreatePlanner(planner); err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	alice := j.goals.As(j.access, "alice")
+	bob := j.goals.As(j.access, "bob")
+	for _, id := range []string{"shared", "own"} {
+		if err := alice.CreateGoal(&models.Goal{Id: id, Objective: "Ship " + id, PlannerId: "planner1"}); err != nil {
+			t.Fatalf("failed to create goal: %v", err)
+		}
+	}
+	shared, err := alice.GetGoal("shared")
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+	shared.Objective = "Ship it"
+	if err := alice.UpdateGoal(shared, store.AnyRevision); err != nil {
+		t.Fatalf("failed to update goal: %v", err)
+	}
+	// bob's change supersedes alice's update and creation of the shared goal
+	shared.GoalStatus = models.InProgress
+	if err := bob.UpdateGoal(shared, store.AnyRevision); err != nil {
+		t.Fatalf("failed to update goal: %v", err)
+	}
+
+	res, err := j.journal.As(j.access, "alice").Undo(2)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, "own", res.Applied[0].ResourceId)
+	}
+	if assert.Len(t, res.Skipped, 2) {
+		assert.Equal(t, "shared", res.Skipped[0].ResourceId)
+		assert.Equal(t, models.EventUpdated, res.Skipped[0].Action)
+		assert.Equal(t, models.EventCreated, res.Skipped[1].Action)
+	}
+	_, err = alice.GetGoal("own")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+	goal, err := bob.GetGoal("shared")
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+	assert.Equal(t, "Ship it", goal.Objective)
+	assert.Equal(t, models.InProgress, goal.GoalStatus)
+
+	// bob undoes his own change only; the local user without a name has nothing to undo
+	res, err = j.journal.As(j.access, "bob").Undo(5)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	assert.Len(t, res.Applied, 1)
+	goal, err = bob.GetGoal("shared")
+	if err != nil {
+		t.Fatalf("failed to get goal: %v", err)
+	}
+	assert.Empty(t, goal.GoalStatus)
+	res, err = j.journal.Undo(5)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	assert.Len(t, res.Applied, 1, "only the planner was created by the local user")
+}
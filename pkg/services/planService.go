@@ -6,8 +6,13 @@ import (
 )
 
 // PlanService is a type that provides operations for managing plans.
+// A service returned by As only lets its user see and change the plans of the planners shared with them.
 type PlanService struct {
-	store store2.PlanStore
+	store   store2.PlanStore
+	access  *AccessService
+	user    string
+	events  *EventService
+	journal *JournalService
 }
 
 // NewPlanService initializes a new instance of the PlanService struct.
@@ -17,6 +22,57 @@ func NewPlanService(store store2.PlanStore) *PlanService {
 	}
 }
 
+// As returns a copy of the service that acts for the named user: every call is checked against the user's role
+// in the planner the plan belongs to, and the lists only contain the plans the user can view.
+func (s *PlanService) As(access *AccessService, user string) *PlanService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every plan it creates, updates or deletes with events.
+func (s *PlanService) WithEvents(events *EventService) *PlanService {
+	scoped := *s
+	scoped.events = events
+	return &scoped
+}
+
+// WithJournal returns a copy of the service that records every plan it creates, updates or deletes in the journal,
+// before and after the change, so that its user can undo it.
+func (s *PlanService) WithJournal(journal *JournalService) *PlanService {
+	scoped := *s
+	scoped.journal = journal
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who lacks the required role in the planner of the plan's goal.
+func (s *PlanService) check(plan *models.Plan, required string) error {
+	if s.access == nil {
+		return nil
+	}
+	return s.access.CheckGoal(s.user, plan.GoalId, required)
+}
+
+// visible returns the plans the service's user can view.
+func (s *PlanService) visible(plans []*models.Plan) ([]*models.Plan, error) {
+	if s.access == nil {
+		return plans, nil
+	}
+	ids, err := s.access.GoalIds(s.user)
+	if err != nil {
+		return nil, err
+	}
+	goals := idSet(ids)
+	var shared []*models.Plan
+	for _, plan := range plans {
+		if goals[plan.GoalId] {
+			shared = append(shared, plan)
+		}
+	}
+	return shared, nil
+}
+
 // CreatePlan creates a new plan with the given information.
 // It takes a pointer to a models.Plan and returns an error.
 // The plan parameter contains the following fields:
@@ -32,42 +88,107 @@ func NewPlanService(store store2.PlanStore) *PlanService {
 // CreatePlan is a method of the PlanService struct that creates a new plan with the provided data.
 // It takes a pointer to a Plan struct as a parameter and returns an error.
 // The method uses the CreatePlan method of the PlanStore interface to create the plan in the data store.
+// Adding a plan to a goal requires the editor role in the goal's planner.
 func (s *PlanService) CreatePlan(plan *models.Plan) error {
-	return s.store.CreatePlan(plan)
+	if err := s.check(plan, models.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.store.CreatePlan(plan); err != nil {
+		return err
+	}
+	s.events.Record(models.EventCreated, plan)
+	s.journal.Record(s.user, models.EventCreated, nil, plan)
+	return nil
 }
 
 // UpdatePlan updates the details of a plan.
 // Parameters:
 // - plan: a pointer to a Plan object representing the updated plan.
+// - expectedRevision: the revision the caller last read, or store.AnyRevision to skip the check.
 // Returns:
-// - error: an error, if any.
-func (s *PlanService) UpdatePlan(plan *models.Plan) error {
-	return s.store.UpdatePlan(plan)
+// - error: store.ErrRevisionMismatch if the plan has been changed since, or another error, if any.
+//
+// Changing a plan requires the editor role in its planner, and in the new planner if the plan is moved to another goal.
+func (s *PlanService) UpdatePlan(plan *models.Plan, expectedRevision int) error {
+	var current *models.Plan
+	if s.access != nil || s.journal != nil {
+		var err error
+		if current, err = s.store.GetPlan(plan.Id); err != nil {
+			return err
+		}
+		if err := s.check(current, models.RoleEditor); err != nil {
+			return err
+		}
+		if err := s.check(plan, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.store.UpdatePlan(plan, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventUpdated, plan)
+	s.journal.Record(s.user, models.EventUpdated, current, plan)
+	return nil
 }
 
 // DeletePlan is a method of the PlanService struct that deletes a plan from the store based on the provided ID.
-// It calls the DeletePlan method of the PlanStore interface using the provided ID as the parameter.
+// It calls the DeletePlan method of the PlanStore interface using the provided ID and expected revision as parameters.
+// The plan is moved to the trash with its tasks, which TrashService restores together.
 // The method returns an error if there was a problem deleting the plan.
-func (s *PlanService) DeletePlan(id string) error {
-	return s.store.DeletePlan(id)
+// Deleting a plan requires the editor role in its planner.
+func (s *PlanService) DeletePlan(id string, expectedRevision int) error {
+	var plan *models.Plan
+	if s.access != nil || s.events != nil || s.journal != nil {
+		var err error
+		if plan, err = s.store.GetPlan(id); err != nil {
+			return err
+		}
+		if err := s.check(plan, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.store.DeletePlan(id, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventDeleted, plan)
+	s.journal.Record(s.user, models.EventDeleted, plan, nil)
+	return nil
 }
 
 // GetPlan returns the plan with the specified ID.
 func (s *PlanService) GetPlan(id string) (*models.Plan, error) {
-	return s.store.GetPlan(id)
+	plan, err := s.store.GetPlan(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(plan, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return plan, nil
 }
 
 // ListPlans returns a list of plans from the PlanStore.
 // It calls the ListPlans method of the store to fetch the list of plans.
 // It returns a slice of Plan pointers and an error.
 func (s *PlanService) ListPlans() ([]*models.Plan, error) {
-	return s.store.ListPlans()
+	plans, err := s.store.ListPlans()
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(plans)
 }
 
 // GetPlanByName retrieves a plan by its name from the plan store.
 // It returns a pointer to the plan and an error if one occurs.
 func (s *PlanService) GetPlanByName(name string) (*models.Plan, error) {
-	return s.store.GetPlanByName(name)
+	plan, err := s.store.GetPlanByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(plan, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return plan, nil
 }
 
 // GetPlansByGoal retrieves a list of plans associated with a specific goal.
@@ -79,5 +200,23 @@ func (s *PlanService) GetPlanByName(name string) (*models.Plan, error) {
 // - plans: A list of plans that are associated with the specified goal.
 // - error: Any error that occurred during the retrieval process.
 func (s *PlanService) GetPlansByGoal(id string) ([]*models.Plan, error) {
-	return s.store.GetPlansByGoal(id)
+	plans, err := s.store.GetPlansByGoal(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(plans)
+}
+
+// FindPlans retrieves the plans matching the given list options.
+// Filtering, ordering and paging are performed by the plan store.
+func (s *PlanService) FindPlans(opts *store2.ListOptions) ([]*models.Plan, error) {
+	if s.access != nil {
+		ids, err := s.access.GoalIds(s.user)
+		if err != nil {
+			return nil, err
+		}
+		opts = scopeOptions(opts)
+		opts.GoalIds = restrict(opts.GoalIds, ids)
+	}
+	return s.store.FindPlans(opts)
 }
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"fmt"
 	"github.com/ooyeku/flow/pkg/models"
 	store2 "github.com/ooyeku/flow/pkg/store"
 )
 
 // PlannerService is a type that provides operations for managing planners.
+// A service returned by As only lets its user see and change the planners shared with them.
 type PlannerService struct {
-	store store2.PlannerStore
+	store   store2.PlannerStore
+	access  *AccessService
+	user    string
+	events  *EventService
+	journal *JournalService
 }
 
 // NewPlannerService creates a new instance of the PlannerService.
@@ -26,11 +32,69 @@ func NewPlannerService(store store2.PlannerStore) *PlannerService {
 	}
 }
 
+// As returns a copy of the service that acts for the named user: every call is checked against the user's role
+// in the planner it touches, and the lists only contain the planners shared with the user.
+func (s *PlannerService) As(access *AccessService, user string) *PlannerService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every planner it creates, updates or deletes with events.
+func (s *PlannerService) WithEvents(events *EventService) *PlannerService {
+	scoped := *s
+	scoped.events = events
+	return &scoped
+}
+
+// WithJournal returns a copy of the service that records every planner it creates, updates or deletes in the journal,
+// before and after the change, so that its user can undo it.
+func (s *PlannerService) WithJournal(journal *JournalService) *PlannerService {
+	scoped := *s
+	scoped.journal = journal
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who lacks the required role in the planner.
+func (s *PlannerService) check(planner *models.Planner, required string) error {
+	if s.access == nil || models.RoleAllows(planner.RoleOf(s.user), required) {
+		return nil
+	}
+	return forbidden(s.user, required, "planner", planner.Id)
+}
+
+// visible returns the planners the service's user can view.
+func (s *PlannerService) visible(planners []*models.Planner) []*models.Planner {
+	if s.access == nil {
+		return planners
+	}
+	var shared []*models.Planner
+	for _, planner := range planners {
+		if s.check(planner, models.RoleViewer) == nil {
+			shared = append(shared, planner)
+		}
+	}
+	return shared
+}
+
 // CreatePlanner is a method of the PlannerService struct that creates a new planner with the provided data.
 // It takes a pointer to a Planner struct as a parameter and returns an error.
 // The method uses the CreatePlanner method of the PlannerStore interface to create the planner in the data store.
+// A scoped service makes its user an owner of a planner created for someone else, so it stays accessible to them.
 func (s *PlannerService) CreatePlanner(planner *models.Planner) error {
-	return s.store.CreatePlanner(planner)
+	if s.access != nil && planner.RoleOf(s.user) == "" {
+		if planner.Members == nil {
+			planner.Members = map[string]string{}
+		}
+		planner.Members[s.user] = models.RoleOwner
+	}
+	if err := s.store.CreatePlanner(planner); err != nil {
+		return err
+	}
+	s.events.Record(models.EventCreated, planner)
+	s.journal.Record(s.user, models.EventCreated, nil, planner)
+	return nil
 }
 
 // UpdatePlanner updates an existing planner with the provided information.
@@ -39,16 +103,56 @@ func (s *PlannerService) CreatePlanner(planner *models.Planner) error {
 // - Id: string (unique identifier for the planner)
 // - Title: string (new title for the planner)
 // - UserId: string (new user identifier associated with the planner)
+// expectedRevision is the revision the caller last read; store.AnyRevision skips the check.
 // The method calls the UpdatePlanner method of the PlannerStore interface to update the planner in the database.
-func (s *PlannerService) UpdatePlanner(planner *models.Planner) error {
-	return s.store.UpdatePlanner(planner)
+// It returns store.ErrRevisionMismatch if the planner has been changed since expectedRevision.
+// Editors may change the title; changing UserId or Members requires the owner role.
+func (s *PlannerService) UpdatePlanner(planner *models.Planner, expectedRevision int) error {
+	var current *models.Planner
+	if s.access != nil || s.journal != nil {
+		var err error
+		if current, err = s.store.GetPlanner(planner.Id); err != nil {
+			return err
+		}
+		required := models.RoleEditor
+		if current.UserId != planner.UserId || !sameMembers(current.Members, planner.Members) {
+			required = models.RoleOwner
+		}
+		if err := s.check(current, required); err != nil {
+			return err
+		}
+	}
+	if err := s.store.UpdatePlanner(planner, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventUpdated, planner)
+	s.journal.Record(s.user, models.EventUpdated, current, planner)
+	return nil
 }
 
 // DeletePlanner deletes a planner with the given ID.
 // It takes a string parameter 'id' which is the unique identifier of the planner.
-// The method calls the DeletePlanner method of the PlannerStore interface to delete the planner from the database.
-func (s *PlannerService) DeletePlanner(id string) error {
-	return s.store.DeletePlanner(id)
+// expectedRevision is the revision the caller last read; store.AnyRevision skips the check.
+// The method calls the DeletePlanner method of the PlannerStore interface to move the planner to the trash,
+// with its goals, plans and tasks, which TrashService restores together.
+// Only owners may delete a planner.
+func (s *PlannerService) DeletePlanner(id string, expectedRevision int) error {
+	var planner *models.Planner
+	if s.access != nil || s.events != nil || s.journal != nil {
+		var err error
+		if planner, err = s.store.GetPlanner(id); err != nil {
+			return err
+		}
+		if err := s.check(planner, models.RoleOwner); err != nil {
+			return err
+		}
+	}
+	if err := s.store.DeletePlanner(id, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventDeleted, planner)
+	s.journal.Record(s.user, models.EventDeleted, planner, nil)
+	return nil
 }
 
 // GetPlanner retrieves a planner with the given ID.
@@ -74,7 +178,14 @@ func (s *PlannerService) DeletePlanner(id string) error {
 //		}, nil
 //	}
 func (s *PlannerService) GetPlanner(id string) (*models.Planner, error) {
-	return s.store.GetPlanner(id)
+	planner, err := s.store.GetPlanner(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(planner, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return planner, nil
 }
 
 // ListPlanners returns a list of all planners.
@@ -91,11 +202,25 @@ func (s *PlannerService) GetPlanner(id string) (*models.Planner, error) {
 //	  fmt.Println(planner.Title)
 //	}
 func (s *PlannerService) ListPlanners() ([]*models.Planner, error) {
-	return s.store.ListPlanners()
+	planners, err := s.store.ListPlanners()
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(planners), nil
 }
 
+// GetPlannerByTitle retrieves the planner with the given title from the PlannerStore.
+// It returns storm.ErrNotFound if no planner has the title, and ErrForbidden if the service is scoped
+// to a user who lacks the viewer role in the planner.
 func (s *PlannerService) GetPlannerByTitle(title string) (*models.Planner, error) {
-	return s.store.GetPlannerByTitle(title)
+	planner, err := s.store.GetPlannerByTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(planner, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return planner, nil
 }
 
 // GetPlannerByOwner retrieves all planners owned by the given user.
@@ -108,5 +233,75 @@ func (s *PlannerService) GetPlannerByTitle(title string) (*models.Planner, error
 // - Goals: []Goal (list of goals associated with the planner)
 // The method calls the GetPlannerByOwner method of the PlannerStore interface to retrieve the planners from the database.
 func (s *PlannerService) GetPlannerByOwner(id string) ([]*models.Planner, error) {
-	return s.store.GetPlannerByOwner(id)
+	planners, err := s.store.GetPlannerByOwner(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(planners), nil
+}
+
+// FindPlanners retrieves the planners matching the given list options.
+// Filtering, ordering and paging are performed by the planner store.
+func (s *PlannerService) FindPlanners(opts *store2.ListOptions) ([]*models.Planner, error) {
+	if s.access != nil {
+		ids, err := s.access.PlannerIds(s.user)
+		if err != nil {
+			return nil, err
+		}
+		opts = scopeOptions(opts)
+		opts.PlannerIds = restrict(opts.PlannerIds, ids)
+	}
+	return s.store.FindPlanners(opts)
+}
+
+// SharePlanner gives the named user a role in the planner, or removes the user from its members if role is empty.
+// expectedRevision is the revision the caller last read; store.AnyRevision skips the check.
+// Only owners may share a planner. The user the planner belongs to always stays an owner.
+func (s *PlannerService) SharePlanner(id, user, role string, expectedRevision int) (*models.Planner, error) {
+	if role != "" && !models.ValidRole(role) {
+		return nil, fmt.Errorf("%w: role %q must be %s, %s or %s", ErrInvalidMember, role, models.RoleOwner, models.RoleEditor, models.RoleViewer)
+	}
+	planner, err := s.store.GetPlanner(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(planner, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	if user == planner.UserId {
+		return nil, fmt.Errorf("%w: %s owns planner %s", ErrInvalidMember, user, id)
+	}
+	if expectedRevision == store2.AnyRevision {
+		expectedRevision = planner.Revision
+	}
+	before := *planner
+	members := make(map[string]string, len(planner.Members)+1)
+	for name, memberRole := range planner.Members {
+		members[name] = memberRole
+	}
+	if role == "" {
+		delete(members, user)
+	} else {
+		members[user] = role
+	}
+	planner.Members = members
+	if err := s.store.UpdatePlanner(planner, expectedRevision); err != nil {
+		return nil, err
+	}
+	s.events.Record(models.EventUpdated, planner)
+	s.journal.Record(s.user, models.EventUpdated, &before, planner)
+	return planner, nil
+}
+
+// sameMembers reports whether two member maps give the same users the same roles.
+func sameMembers(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for user, role := range a {
+		if b[user] != role {
+			return false
+		}
+	}
+	return true
 }
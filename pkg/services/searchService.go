@@ -0,0 +1,231 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/chat"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/search"
+	"github.com/ooyeku/flow/pkg/store"
+	"log"
+)
+
+// ChatHistory lists the stored chat responses, as chat.StromRepo and chat.HistoryFile do.
+type ChatHistory interface {
+	ListChatResponses() ([]*chat.ChatResponse, error)
+}
+
+// SearchService searches the titles and descriptions of tasks, the objectives of goals, the names and
+// descriptions of plans, and the questions and answers of the chat history in a search.Index.
+//
+// Rebuild fills the index from the stores; Watch keeps it up to date with the changes recorded on an event bus.
+// The chat history has no events: a daemon that serves it to the chat indexes the responses it saves with
+// AddChatResponse, and the responses the chat writes to a database of its own are only read by Rebuild.
+// A service returned by As only finds the tasks, goals and plans of the planners shared with its user,
+// and no chat history, which belongs to whoever runs the chat.
+type SearchService struct {
+	index  *search.Index
+	tasks  store.TaskStore
+	goals  store.GoalStore
+	plans  store.PlanStore
+	chats  ChatHistory
+	access *AccessService
+	user   string
+}
+
+// NewSearchService creates a SearchService with an empty index over the given stores. chats may be nil.
+//
+// Example usage:
+//
+//	searchService := services.NewSearchService(taskStore, goalStore, planStore, chat.HistoryFile(conf.GetChatDBPath()))
+//	if err := searchService.Rebuild(); err != nil {
+//		log.Fatal(err)
+//	}
+//	searchService.Watch(events)
+func NewSearchService(tasks store.TaskStore, goals store.GoalStore, plans store.PlanStore, chats ChatHistory) *SearchService {
+	return &SearchService{
+		index: search.NewIndex(),
+		tasks: tasks,
+		goals: goals,
+		plans: plans,
+		chats: chats,
+	}
+}
+
+// As returns a copy of the service that searches for the named user. The copy shares the index of s.
+func (s *SearchService) As(access *AccessService, user string) *SearchService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// Rebuild replaces the index with one of the records currently in the stores and the chat history.
+// If the chat history cannot be read, it is left out of the index.
+func (s *SearchService) Rebuild() error {
+	var docs []search.Document
+	tasks, err := s.tasks.ListTasks()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		docs = append(docs, taskDocument(task))
+	}
+	goals, err := s.goals.ListGoals()
+	if err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		docs = append(docs, goalDocument(goal))
+	}
+	plans, err := s.plans.ListPlans()
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		docs = append(docs, planDocument(plan))
+	}
+	if s.chats != nil {
+		responses, err := s.chats.ListChatResponses()
+		if err != nil {
+			// the chat may hold its database; the records are still worth searching without it
+			log.Printf("Error reading chat history, searching without it: %s", err)
+		}
+		for _, response := range responses {
+			docs = append(docs, chatDocument(response))
+		}
+	}
+	s.index.Reset(docs)
+	return nil
+}
+
+// Watch updates the index with every task, goal and plan created, updated or deleted through the event bus.
+func (s *SearchService) Watch(events *EventService) {
+	events.Observe(s.observe)
+}
+
+// observe adds the record of an event to the index, or removes it if it was deleted.
+func (s *SearchService) observe(event *models.Event) {
+	if event.Type == models.EventType(event.Resource, models.EventDeleted) {
+		s.index.Remove(event.Resource, event.ResourceId)
+		return
+	}
+	var doc search.Document
+	var err error
+	switch event.Resource {
+	case search.TypeTask:
+		task := &models.Task{}
+		err = json.Unmarshal(event.Data, task)
+		doc = taskDocument(task)
+	case search.TypeGoal:
+		goal := &models.Goal{}
+		err = json.Unmarshal(event.Data, goal)
+		doc = goalDocument(goal)
+	case search.TypePlan:
+		plan := &models.Plan{}
+		err = json.Unmarshal(event.Data, plan)
+		doc = planDocument(plan)
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("Error indexing %s %s: %s", event.Resource, event.ResourceId, err)
+		return
+	}
+	s.index.Add(doc)
+}
+
+// AddChatResponse adds a chat response saved after the index was built to the index.
+func (s *SearchService) AddChatResponse(response *chat.ChatResponse) {
+	s.index.Add(chatDocument(response))
+}
+
+// taskDocument returns the search document of a task.
+func taskDocument(task *models.Task) search.Document {
+	return search.Document{Type: search.TypeTask, ID: task.ID, Title: task.Title, Text: task.Description}
+}
+
+// goalDocument returns the search document of a goal.
+func goalDocument(goal *models.Goal) search.Document {
+	return search.Document{Type: search.TypeGoal, ID: goal.Id, Title: goal.Objective}
+}
+
+// planDocument returns the search document of a plan.
+func planDocument(plan *models.Plan) search.Document {
+	return search.Document{Type: search.TypePlan, ID: plan.Id, Title: plan.PlanName, Text: plan.PlanDescription}
+}
+
+// chatDocument returns the search document of a chat response: its question and answer.
+func chatDocument(response *chat.ChatResponse) search.Document {
+	return search.Document{Type: search.TypeChat, ID: response.ID, Title: response.UserQuery.Query, Text: response.Object}
+}
+
+// Search returns up to limit records of the given types (all types if empty) that match the query, best match first.
+// Every hit is looked up in its store before it is returned, so records deleted without an event,
+// such as by the cli while the server was stopped, are dropped from the index instead.
+func (s *SearchService) Search(query string, types []string, limit int) ([]search.Hit, error) {
+	var searchErr error
+	hits := s.index.Search(query, search.Options{
+		Types: types,
+		Limit: limit,
+		Filter: func(hit search.Hit) bool {
+			if searchErr != nil {
+				return false
+			}
+			record, err := s.lookup(hit)
+			if errors.Is(err, storm.ErrNotFound) {
+				s.index.Remove(hit.Type, hit.ID)
+				return false
+			}
+			if err == nil {
+				err = s.check(hit.Type, record)
+			}
+			switch {
+			case err == nil:
+				return true
+			case errors.Is(err, ErrForbidden), errors.Is(err, storm.ErrNotFound):
+				return false
+			default:
+				searchErr = err
+				return false
+			}
+		},
+	})
+	if searchErr != nil {
+		return nil, searchErr
+	}
+	return hits, nil
+}
+
+// lookup returns the record of a hit from its store, or nil for the chat history.
+func (s *SearchService) lookup(hit search.Hit) (interface{}, error) {
+	switch hit.Type {
+	case search.TypeTask:
+		return s.tasks.GetTask(hit.ID)
+	case search.TypeGoal:
+		return s.goals.GetGoal(hit.ID)
+	case search.TypePlan:
+		return s.plans.GetPlan(hit.ID)
+	}
+	return nil, nil
+}
+
+// check returns ErrForbidden if the service is scoped to a user who cannot view the record of a hit.
+func (s *SearchService) check(typ string, record interface{}) error {
+	if s.access == nil {
+		return nil
+	}
+	switch r := record.(type) {
+	case *models.Task:
+		return s.access.CheckPlan(s.user, r.PlanId, models.RoleViewer)
+	case *models.Goal:
+		if r.PlannerId == "" {
+			return nil
+		}
+		return s.access.CheckPlanner(s.user, r.PlannerId, models.RoleViewer)
+	case *models.Plan:
+		return s.access.CheckGoal(s.user, r.GoalId, models.RoleViewer)
+	}
+	return forbidden(s.user, models.RoleViewer, typ, "history")
+}
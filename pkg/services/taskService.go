@@ -7,8 +7,13 @@ import (
 )
 
 // TaskService represents a service for managing tasks.
+// A service returned by As only lets its user see and change the tasks of the planners shared with them.
 type TaskService struct {
-	Store store.TaskStore
+	Store   store.TaskStore
+	access  *AccessService
+	user    string
+	events  *EventService
+	journal *JournalService
 }
 
 // NewTaskService creates a new instance of TaskService using the provided TaskStore.
@@ -19,22 +24,123 @@ func NewTaskService(store store.TaskStore) *TaskService {
 	}
 }
 
+// As returns a copy of the service that acts for the named user: every call is checked against the user's role
+// in the planner the task's plan belongs to, and the lists only contain the tasks the user can view.
+func (s *TaskService) As(access *AccessService, user string) *TaskService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every task it creates, updates or deletes with events.
+func (s *TaskService) WithEvents(events *EventService) *TaskService {
+	scoped := *s
+	scoped.events = events
+	return &scoped
+}
+
+// WithJournal returns a copy of the service that records every task it creates, updates or deletes in the journal,
+// before and after the change, so that its user can undo it.
+func (s *TaskService) WithJournal(journal *JournalService) *TaskService {
+	scoped := *s
+	scoped.journal = journal
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who lacks the required role in the planner of the task's plan.
+func (s *TaskService) check(task *models.Task, required string) error {
+	if s.access == nil {
+		return nil
+	}
+	return s.access.CheckPlan(s.user, task.PlanId, required)
+}
+
+// visible returns the tasks the service's user can view.
+func (s *TaskService) visible(tasks []*models.Task) ([]*models.Task, error) {
+	if s.access == nil {
+		return tasks, nil
+	}
+	ids, err := s.access.PlanIds(s.user)
+	if err != nil {
+		return nil, err
+	}
+	plans := idSet(ids)
+	var shared []*models.Task
+	for _, task := range tasks {
+		if plans[task.PlanId] {
+			shared = append(shared, task)
+		}
+	}
+	return shared, nil
+}
+
 // CreateTask creates a new task using the provided task data.
+// Adding a task to a plan requires the editor role in the plan's planner.
 // It returns an error if the task creation fails.
 func (s *TaskService) CreateTask(task *models.Task) error {
-	return s.Store.CreateTask(task)
+	if err := s.check(task, models.RoleEditor); err != nil {
+		return err
+	}
+	if err := s.Store.CreateTask(task); err != nil {
+		return err
+	}
+	s.events.Record(models.EventCreated, task)
+	s.journal.Record(s.user, models.EventCreated, nil, task)
+	return nil
 }
 
 // UpdateTask updates the task with the specified ID using the provided task object.
-// It returns an error if there was a problem updating the task.
-func (s *TaskService) UpdateTask(id string, task *models.Task) error {
-	return s.Store.UpdateTask(id, task)
+// Besides the update, completing a task publishes a "task.completed" event.
+// expectedRevision is the revision the caller last read, or store.AnyRevision to overwrite unconditionally.
+// It returns store.ErrRevisionMismatch if the task has been changed since, or an error if there was a problem updating the task.
+// Changing a task requires the editor role in its planner, and in the new planner if the task is moved to another plan.
+func (s *TaskService) UpdateTask(id string, task *models.Task, expectedRevision int) error {
+	var current *models.Task
+	if s.access != nil || s.events != nil || s.journal != nil {
+		var err error
+		if current, err = s.Store.GetTask(id); err != nil {
+			return err
+		}
+		if err := s.check(current, models.RoleEditor); err != nil {
+			return err
+		}
+		if err := s.check(task, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.Store.UpdateTask(id, task, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventUpdated, task)
+	s.journal.Record(s.user, models.EventUpdated, current, task)
+	if current != nil && !current.Completed && task.Completed {
+		s.events.Record(models.EventCompleted, task)
+	}
+	return nil
 }
 
-// DeleteTask deletes a task with the given ID.
+// DeleteTask deletes a task with the given ID, which moves it to the trash until it is restored or purged with TrashService.
+// expectedRevision is the revision the caller last read, or store.AnyRevision to delete unconditionally.
 // It returns an error if there was a problem deleting the task.
-func (s *TaskService) DeleteTask(id string) error {
-	return s.Store.DeleteTask(id)
+// Deleting a task requires the editor role in its planner.
+func (s *TaskService) DeleteTask(id string, expectedRevision int) error {
+	var task *models.Task
+	if s.access != nil || s.events != nil || s.journal != nil {
+		var err error
+		if task, err = s.Store.GetTask(id); err != nil {
+			return err
+		}
+		if err := s.check(task, models.RoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := s.Store.DeleteTask(id, expectedRevision); err != nil {
+		return err
+	}
+	s.events.Record(models.EventDeleted, task)
+	s.journal.Record(s.user, models.EventDeleted, task, nil)
+	return nil
 }
 
 // GetTask retrieves a task with the specified ID from the task store.
@@ -42,7 +148,14 @@ func (s *TaskService) DeleteTask(id string) error {
 // It returns a pointer to a Task object and an error.
 // The Task object contains the details of the retrieved task, including the ID, title, description, owner, started status, completed status, creation timestamp, and update timestamp
 func (s *TaskService) GetTask(id string) (*models.Task, error) {
-	return s.Store.GetTask(id)
+	task, err := s.Store.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(task, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
 // ListTasks retrieves a list of tasks from the TaskService.
@@ -53,7 +166,7 @@ func (s *TaskService) ListTasks() ([]*models.Task, error) {
 		log.Printf("Error in TaskService.ListTasks: %s", err)
 		return nil, err
 	}
-	return tasks, nil
+	return s.visible(tasks)
 }
 
 // GetTaskByTitle retrieves a task by its title.
@@ -82,12 +195,37 @@ func (s *TaskService) ListTasks() ([]*models.Task, error) {
 // fmt.Println(task.CreatedAt)
 // fmt.Println(task.UpdatedAt)
 func (s *TaskService) GetTaskByTitle(title string) (*models.Task, error) {
-	return s.Store.GetTaskByTitle(title)
+	task, err := s.Store.GetTaskByTitle(title)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(task, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
 // GetTaskByOwner returns a task owned by the given owner.
 // It takes an owner string as a parameter and returns the task associated with that owner.
 // If there is no task found or an error occurs, it returns nil and an error respectively.
 func (s *TaskService) GetTaskByOwner(owner string) ([]*models.Task, error) {
-	return s.Store.GetTaskByOwner(owner)
+	tasks, err := s.Store.GetTaskByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(tasks)
+}
+
+// FindTasks retrieves the tasks matching the given list options.
+// Filtering, ordering and paging are performed by the task store.
+func (s *TaskService) FindTasks(opts *store.ListOptions) ([]*models.Task, error) {
+	if s.access != nil {
+		ids, err := s.access.PlanIds(s.user)
+		if err != nil {
+			return nil, err
+		}
+		opts = scopeOptions(opts)
+		opts.PlanIds = restrict(opts.PlanIds, ids)
+	}
+	return s.Store.FindTasks(opts)
 }
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"time"
+)
+
+// TrashService lists, restores and purges the tasks, goals, plans and planners in the trash: the records the other
+// services deleted, together with the records below them that were deleted along with them.
+// A service returned by As only lets its user see and change the records they could have deleted themselves:
+// planners they own, and goals, plans and tasks of planners they can edit.
+type TrashService struct {
+	store   store.TrashStore
+	access  *AccessService
+	user    string
+	events  *EventService
+	journal *JournalService
+}
+
+// NewTrashService creates a TrashService over the given store.
+//
+// Example usage:
+//
+//	trashService := services.NewTrashService(inmemory.NewInMemoryTrashStore(db)).WithEvents(events)
+//	items, err := trashService.ListTrash()
+func NewTrashService(store store.TrashStore) *TrashService {
+	return &TrashService{
+		store: store,
+	}
+}
+
+// As returns a copy of the service that acts for the named user, as TrashService describes.
+func (s *TrashService) As(access *AccessService, user string) *TrashService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every record it restores with events.
+func (s *TrashService) WithEvents(events *EventService) *TrashService {
+	scoped := *s
+	scoped.events = events
+	return &scoped
+}
+
+// WithJournal returns a copy of the service that records every restore in the journal, so that its user can undo it.
+func (s *TrashService) WithJournal(journal *JournalService) *TrashService {
+	scoped := *s
+	scoped.journal = journal
+	return &scoped
+}
+
+// ListTrash returns the records in the trash the service's user may restore, most recently deleted first.
+func (s *TrashService) ListTrash() ([]*models.TrashItem, error) {
+	items, err := s.store.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	if s.access == nil {
+		return items, nil
+	}
+	allowed := []*models.TrashItem{}
+	for _, item := range items {
+		err := s.check(item, items)
+		switch {
+		case err == nil:
+			allowed = append(allowed, item)
+		case errors.Is(err, ErrForbidden), errors.Is(err, storm.ErrNotFound):
+		default:
+			return nil, err
+		}
+	}
+	return allowed, nil
+}
+
+// RestoreTrash brings back the record with the id from the trash, together with the records that were deleted along
+// with it; given a record that was deleted along with another one, it brings back that other one and everything
+// deleted with it. It returns the restored records, from the top of the hierarchy down.
+// storm.ErrNotFound is returned if no record with the id is in the trash.
+func (s *TrashService) RestoreTrash(id string) ([]*models.TrashItem, error) {
+	if s.access != nil {
+		items, err := s.store.ListTrash()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Id != id {
+				continue
+			}
+			root := item
+			for _, other := range items {
+				if other.Id == item.Root() && other.DeletedWith == "" {
+					root = other
+				}
+			}
+			if err := s.check(root, items); err != nil {
+				return nil, err
+			}
+		}
+	}
+	restored, err := s.store.RestoreTrash(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range restored {
+		s.events.Record(models.EventRestored, item.Record)
+		if item.DeletedWith == "" {
+			s.journal.Record(s.user, models.EventRestored, nil, item.Record)
+		}
+	}
+	return restored, nil
+}
+
+// EmptyTrash purges the records the service's user may restore that were deleted before the given time for good,
+// with the records deleted along with them, and returns them.
+func (s *TrashService) EmptyTrash(before time.Time) ([]*models.TrashItem, error) {
+	items, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	purged := []*models.TrashItem{}
+	for _, item := range items {
+		if item.DeletedWith != "" || !item.DeletedAt.Before(before) {
+			continue
+		}
+		group, err := s.store.PurgeTrash(item.Id)
+		if err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return nil, err
+		}
+		purged = append(purged, group...)
+	}
+	return purged, nil
+}
+
+// check returns ErrForbidden if the service is scoped to a user who could not have deleted the record of the item:
+// the owner role is required in a planner, and the editor role in the planner of a goal, plan or task.
+// The planner is looked up among the records in the trash, items, if it is not found in the stores.
+func (s *TrashService) check(item *models.TrashItem, items []*models.TrashItem) error {
+	if s.access == nil {
+		return nil
+	}
+	required := models.RoleEditor
+	if item.Resource == "planner" {
+		required = models.RoleOwner
+	}
+	// walk up from the record to its planner, through the trash where the records above were deleted too
+	resource, id := item.Resource, item.Id
+	for resource != "planner" {
+		if trashed := findItem(items, resource, id); trashed != nil {
+			id = trashed.ParentId
+		} else if resource == "plan" {
+			plan, err := s.access.plans.GetPlan(id)
+			if err != nil {
+				return err
+			}
+			id = plan.GoalId
+		} else {
+			var err error
+			if id, err = s.access.PlannerOfGoal(id); err != nil {
+				return err
+			}
+		}
+		if id == "" {
+			return nil
+		}
+		resource = parentOf(resource)
+	}
+	if trashed := findItem(items, "planner", id); trashed != nil {
+		planner := trashed.Record.(*models.Planner)
+		if !models.RoleAllows(planner.RoleOf(s.user), required) {
+			return forbidden(s.user, required, "planner", id)
+		}
+		return nil
+	}
+	return s.access.CheckPlanner(s.user, id, required)
+}
+
+// parentOf returns the kind of record above records of the given kind.
+func parentOf(resource string) string {
+	switch resource {
+	case "task":
+		return "plan"
+	case "plan":
+		return "goal"
+	}
+	return "planner"
+}
+
+// findItem returns the item of the record of the given kind and id, or nil if it is not in items.
+func findItem(items []*models.TrashItem, resource, id string) *models.TrashItem {
+	for _, item := range items {
+		if item.Resource == resource && item.Id == id {
+			return item
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/ooyeku/flow/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// setupTrash creates a planner of alice shared with bob as an editor, with a goal, a plan and a task below it.
+func setupTrash(t *testing.T) *journaled {
+	j := setupJournal(t)
+	planner := &models.Planner{Id: "planner1", Title: "Team", UserId: "alice",
+		Members: map[string]string{"bob": models.RoleEditor}}
+	if err := j.planners.CreatePlanner(planner); err != nil {
+		t.Fatalf("failed to create planner: %v", err)
+	}
+	if err := j.goals.CreateGoal(&models.Goal{Id: "goal1", Objective: "Ship", PlannerId: "planner1"}); err != nil {
+		t.Fatalf("failed to create goal: %v", err)
+	}
+	if err := j.plans.CreatePlan(&models.Plan{Id: "plan1", PlanName: "Release", GoalId: "goal1"}); err != nil {
+		t.Fatalf("failed to create plan: %v", err)
+	}
+	if err := j.tasks.CreateTask(&models.Task{ID: "task1", Title: "Tag", PlanId: "plan1"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	return j
+}
+
+func TestTrashService_Restore(t *testing.T) {
+	j := setupTrash(t)
+	if err := j.planners.As(j.access, "alice").DeletePlanner("planner1", store.AnyRevision); err != nil {
+		t.Fatalf("failed to delete planner: %v", err)
+	}
+	_, err := j.tasks.GetTask("task1")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+
+	// bob may see the goal, plan and task but not restore the planner, which only its owner may delete
+	items, err := j.trash.As(j.access, "bob").ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	if assert.Len(t, items, 3) {
+		assert.Equal(t, "goal1", items[0].Id)
+		assert.Equal(t, "planner1", items[0].DeletedWith)
+	}
+	_, err = j.trash.As(j.access, "bob").RestoreTrash("task1")
+	assert.ErrorIs(t, err, services.ErrForbidden)
+	items, err = j.trash.As(j.access, "carol").ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	assert.Empty(t, items)
+
+	restored, err := j.trash.As(j.access, "alice").RestoreTrash("task1")
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	assert.Len(t, restored, 4)
+	task, err := j.tasks.As(j.access, "bob").GetTask("task1")
+	if err != nil {
+		t.Fatalf("failed to get restored task: %v", err)
+	}
+	assert.Equal(t, "Tag", task.Title)
+
+	// the restore is journaled: undoing it moves the planner and everything below it to the trash again
+	res, err := j.journal.As(j.access, "alice").Undo(1)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, models.EventRestored, res.Applied[0].Action)
+	}
+	_, err = j.plans.GetPlan("plan1")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+
+	// and undoing the deletion brings it all back from the trash
+	res, err = j.journal.As(j.access, "alice").Undo(1)
+	if err != nil {
+		t.Fatalf("failed to undo: %v", err)
+	}
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, models.EventDeleted, res.Applied[0].Action)
+	}
+	_, err = j.plans.GetPlan("plan1")
+	assert.NoError(t, err)
+	items, err = j.trash.ListTrash()
+	if err != nil {
+		t.Fatalf("failed to list trash: %v", err)
+	}
+	assert.Empty(t, items)
+}
+
+func TestTrashService_EmptyTrash(t *testing.T) {
+	j := setupTrash(t)
+	if err := j.goals.DeleteGoal("goal1", store.AnyRevision); err != nil {
+		t.Fatalf("failed to delete goal: %v", err)
+	}
+	if err := j.tasks.CreateTask(&models.Task{ID: "task2", Title: "Unplanned"}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if err := j.tasks.DeleteTask("task2", store.AnyRevision); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+
+	purged, err := j.trash.EmptyTrash(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to empty trash: %v", err)
+	}
+	assert.Empty(t, purged)
+
+	// carol cannot edit the planner of the goal, but the task without a plan is shared with everyone
+	purged, err = j.trash.As(j.access, "carol").EmptyTrash(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to empty trash: %v", err)
+	}
+	if assert.Len(t, purged, 1) {
+		assert.Equal(t, "task2", purged[0].Id)
+	}
+	purged, err = j.trash.EmptyTrash(time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to empty trash: %v", err)
+	}
+	assert.Len(t, purged, 3, "the goal is purged with its plan and task")
+	_, err = j.trash.RestoreTrash("goal1")
+	assert.ErrorIs(t, err, storm.ErrNotFound)
+}
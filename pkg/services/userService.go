@@ -0,0 +1,63 @@
+package services
+
+import (
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+)
+
+// UserService provides operations for managing users and their API tokens.
+type UserService struct {
+	Store store.UserStore
+}
+
+// NewUserService creates a new instance of the UserService with the provided UserStore.
+//
+// Example usage:
+//
+//	userStore := inmemory.NewInMemoryUserStore(db)
+//	userService := services.NewUserService(userStore)
+func NewUserService(store store.UserStore) *UserService {
+	return &UserService{
+		Store: store,
+	}
+}
+
+// CreateUser creates a new user.
+func (s *UserService) CreateUser(user *models.User) error {
+	return s.Store.CreateUser(user)
+}
+
+// GetUser retrieves the user with the specified ID.
+func (s *UserService) GetUser(id string) (*models.User, error) {
+	return s.Store.GetUser(id)
+}
+
+// GetUserByName retrieves the user with the specified name.
+func (s *UserService) GetUserByName(name string) (*models.User, error) {
+	return s.Store.GetUserByName(name)
+}
+
+// ListUsers returns every user.
+func (s *UserService) ListUsers() ([]*models.User, error) {
+	return s.Store.ListUsers()
+}
+
+// CreateToken stores a new API token.
+func (s *UserService) CreateToken(token *models.Token) error {
+	return s.Store.CreateToken(token)
+}
+
+// GetTokenByHash retrieves the token with the specified hash.
+func (s *UserService) GetTokenByHash(hash string) (*models.Token, error) {
+	return s.Store.GetTokenByHash(hash)
+}
+
+// ListTokens returns the tokens of the user with the specified ID.
+func (s *UserService) ListTokens(userId string) ([]*models.Token, error) {
+	return s.Store.ListTokens(userId)
+}
+
+// DeleteToken deletes the token with the specified ID.
+func (s *UserService) DeleteToken(id string) error {
+	return s.Store.DeleteToken(id)
+}
@@ -1,12 +1,19 @@
 package services
 
 import (
+	"errors"
+	"github.com/asdine/storm"
 	"github.com/ooyeku/flow/pkg/models"
 	"github.com/ooyeku/flow/pkg/store"
 )
 
+// VersionService records and renumbers the versions of goals, plans and tasks.
+// A service returned by As only reaches the versions of the records in the planners shared with its user.
 type VersionService struct {
 	versionStore store.VersionStore
+	events       *EventService
+	access       *AccessService
+	user         string
 }
 
 func NewVersionService(versionStore store.VersionStore) *VersionService {
@@ -15,23 +22,117 @@ func NewVersionService(versionStore store.VersionStore) *VersionService {
 	}
 }
 
+// As returns a copy of the service that acts for the named user: reading a version requires the viewer role in the
+// planner of the goal, plan or task it was taken of, and creating or renumbering one the editor role.
+// Versions of records that belong to no planner are shared with every user.
+func (service *VersionService) As(access *AccessService, user string) *VersionService {
+	scoped := *service
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// WithEvents returns a copy of the service that records every version it creates or updates with events.
+func (service *VersionService) WithEvents(events *EventService) *VersionService {
+	scoped := *service
+	scoped.events = events
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who lacks the required role in the planner of the version.
+func (service *VersionService) check(version *models.Version, required string) error {
+	if service.access == nil {
+		return nil
+	}
+	plannerId, err := service.access.PlannerOfVersion(version)
+	if err != nil || plannerId == "" {
+		return err
+	}
+	return service.access.CheckPlanner(service.user, plannerId, required)
+}
+
 func (service *VersionService) CreateVersion(version *models.Version) error {
-	return service.versionStore.CreateVersion(version)
+	if err := service.check(version, models.RoleEditor); err != nil {
+		return err
+	}
+	if err := service.versionStore.CreateVersion(version); err != nil {
+		return err
+	}
+	service.events.Record(models.EventCreated, version)
+	return nil
 }
 
+// UpdateVersion replaces the version with the id. A scoped service requires the editor role
+// in the planner of the version both before and after the change.
 func (service *VersionService) UpdateVersion(id string, version *models.Version) error {
+	if service.access != nil {
+		existing, err := service.versionStore.GetVersion(id)
+		if err != nil {
+			return err
+		}
+		if err := service.check(existing, models.RoleEditor); err != nil {
+			return err
+		}
+		if err := service.check(version, models.RoleEditor); err != nil {
+			return err
+		}
+	}
 	// create version image
-	return service.versionStore.UpdateVersion(id, version)
+	if err := service.versionStore.UpdateVersion(id, version); err != nil {
+		return err
+	}
+	service.events.Record(models.EventUpdated, version)
+	return nil
 }
 
+// GetVersion returns the version with the id, or ErrForbidden if the service's user cannot view it.
 func (service *VersionService) GetVersion(id string) (*models.Version, error) {
-	return service.versionStore.GetVersion(id)
+	version, err := service.versionStore.GetVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.check(version, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return version, nil
 }
 
+// ListVersions returns the versions the service's user can view. Versions of records that no longer exist
+// are left out of the list of a scoped service, since their planner cannot be told.
 func (service *VersionService) ListVersions() ([]*models.Version, error) {
-	return service.versionStore.ListVersions()
+	versions, err := service.versionStore.ListVersions()
+	if err != nil {
+		return nil, err
+	}
+	if service.access == nil {
+		return versions, nil
+	}
+	visible := []*models.Version{}
+	for _, version := range versions {
+		err := service.check(version, models.RoleViewer)
+		switch {
+		case err == nil:
+			visible = append(visible, version)
+		case errors.Is(err, ErrForbidden), errors.Is(err, storm.ErrNotFound):
+		default:
+			return nil, err
+		}
+	}
+	return visible, nil
 }
 
+// GetPreviousVersion returns the version the version with the id follows, or ErrForbidden if the service's user
+// cannot view it.
 func (service *VersionService) GetPreviousVersion(id string) (*models.Version, error) {
-	return service.versionStore.GetPreviousVersion(id)
+	version, err := service.versionStore.GetPreviousVersion(id)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, nil
+	}
+	if err := service.check(version, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return version, nil
 }
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/store"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the delivery settings of a WebhookService.
+const (
+	DefaultWebhookAttempts = 6
+	DefaultWebhookBackoff  = 10 * time.Second
+	DefaultWebhookTimeout  = 10 * time.Second
+	DefaultDeliveryLogSize = 1000
+)
+
+// Headers of every webhook request. SignatureHeader carries "sha256=" followed by the hex encoded
+// HMAC-SHA256 of the body, keyed with the secret of the webhook; see SignPayload.
+const (
+	EventHeader     = "X-Flow-Event"
+	DeliveryHeader  = "X-Flow-Delivery"
+	SignatureHeader = "X-Flow-Signature"
+)
+
+// ErrInvalidWebhook is returned by WebhookService.CreateWebhook for a webhook without an http(s) URL or a secret.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// SignPayload returns the value of the SignatureHeader of a webhook request with the given body.
+// Receivers compute it over the raw body with their copy of the secret and compare it with hmac.Equal.
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookService manages webhooks and delivers the events of the event bus to them.
+// A service returned by As only lets its user see and remove the webhooks they created.
+//
+// Delivery settings:
+// - Client: the HTTP client used to send the events.
+// - Attempts: how often an event is sent before giving up.
+// - Backoff: the wait before the first retry, doubled on every further retry.
+// - KeepDeliveries: the number of delivery attempts kept in the delivery log.
+type WebhookService struct {
+	Client         *http.Client
+	Attempts       int
+	Backoff        time.Duration
+	KeepDeliveries int
+
+	store  store.WebhookStore
+	events *EventService
+	access *AccessService
+	user   string
+}
+
+// NewWebhookService creates a WebhookService that stores the webhooks in store and delivers the events of events,
+// checking with access that the owner of each webhook may see the event.
+//
+// Example usage:
+//
+//	webhooks := services.NewWebhookService(inmemory.NewInMemoryWebhookStore(db), events, access)
+//	go webhooks.Run(ctx)
+func NewWebhookService(store store.WebhookStore, events *EventService, access *AccessService) *WebhookService {
+	return &WebhookService{
+		Client:         &http.Client{Timeout: DefaultWebhookTimeout},
+		Attempts:       DefaultWebhookAttempts,
+		Backoff:        DefaultWebhookBackoff,
+		KeepDeliveries: DefaultDeliveryLogSize,
+		store:          store,
+		events:         events,
+		access:         access,
+	}
+}
+
+// As returns a copy of the service that acts for the named user: the webhooks it creates belong to the user,
+// and it only lists, returns and deletes the webhooks of the user.
+func (s *WebhookService) As(access *AccessService, user string) *WebhookService {
+	scoped := *s
+	scoped.access = access
+	scoped.user = user
+	return &scoped
+}
+
+// check returns ErrForbidden if the service is scoped to a user who did not create the webhook.
+func (s *WebhookService) check(webhook *models.Webhook) error {
+	if s.user == "" || webhook.Owner == s.user {
+		return nil
+	}
+	return fmt.Errorf("%w: webhook %s belongs to another user", ErrForbidden, webhook.Id)
+}
+
+// CreateWebhook stores a new webhook. A scoped service makes its user the owner,
+// who needs the viewer role in the planner the webhook is restricted to.
+func (s *WebhookService) CreateWebhook(webhook *models.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q is not an http or https URL", ErrInvalidWebhook, webhook.URL)
+	}
+	if webhook.Secret == "" {
+		return fmt.Errorf("%w: missing secret", ErrInvalidWebhook)
+	}
+	if s.user != "" {
+		webhook.Owner = s.user
+		if webhook.PlannerId != "" && s.access != nil {
+			if err := s.access.CheckPlanner(s.user, webhook.PlannerId, models.RoleViewer); err != nil {
+				return err
+			}
+		}
+	}
+	return s.store.CreateWebhook(webhook)
+}
+
+// GetWebhook retrieves the webhook with the specified ID.
+func (s *WebhookService) GetWebhook(id string) (*models.Webhook, error) {
+	webhook, err := s.store.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.check(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ListWebhooks returns the webhooks of the service's user, or every webhook for an unscoped service.
+func (s *WebhookService) ListWebhooks() ([]*models.Webhook, error) {
+	webhooks, err := s.store.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	owned := []*models.Webhook{}
+	for _, webhook := range webhooks {
+		if s.check(webhook) == nil {
+			owned = append(owned, webhook)
+		}
+	}
+	return owned, nil
+}
+
+// DeleteWebhook deletes the webhook with the specified ID and its delivery log.
+func (s *WebhookService) DeleteWebhook(id string) error {
+	if _, err := s.GetWebhook(id); err != nil {
+		return err
+	}
+	return s.store.DeleteWebhook(id)
+}
+
+// ListDeliveries returns up to limit delivery attempts of the webhook with the specified ID, the most recent first.
+func (s *WebhookService) ListDeliveries(webhookId string, limit int) ([]*models.Delivery, error) {
+	if _, err := s.GetWebhook(webhookId); err != nil {
+		return nil, err
+	}
+	return s.store.ListDeliveries(webhookId, limit)
+}
+
+// Run delivers the events published on the event bus to the matching webhooks until ctx is done,
+// and then waits for the deliveries in progress to stop. Failed deliveries are retried with exponential backoff.
+// If Run falls behind the event bus, it catches up from the event log.
+//
+// What the delivery has reached is kept in the store, so that Run picks up where the last run stopped:
+// it resumes the retries that were pending and delivers the events logged in the meantime, as far back as the
+// event log reaches. The first run starts with the events published after it. An event may thus be sent twice
+// around a restart; receivers can tell by the DeliveryHeader.
+func (s *WebhookService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	last, err := s.start()
+	if err != nil {
+		log.Printf("Error reading the last event delivered to the webhooks: %s", err)
+	}
+	s.resume(ctx, &wg)
+	for ctx.Err() == nil {
+		sub := s.events.Subscribe(EventFilter{})
+		missed, err := s.events.ListEvents(last, 0, EventFilter{})
+		if err != nil {
+			log.Printf("Error catching up with events after %d: %s", last, err)
+		}
+		for _, event := range missed {
+			s.dispatch(ctx, &wg, event)
+			last = event.Id
+		}
+		last = s.forward(ctx, &wg, sub, last)
+		sub.Close()
+	}
+}
+
+// start returns the id of the last event the webhooks were handed, or, before the first run,
+// the id of the last event in the log, so that the events of the past are not sent.
+func (s *WebhookService) start() (int, error) {
+	last, err := s.store.GetDispatched()
+	if err != nil || last > 0 {
+		return last, err
+	}
+	events, err := s.events.ListEvents(0, 0, EventFilter{})
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	last = events[len(events)-1].Id
+	return last, s.store.SetDispatched(last)
+}
+
+// resume restarts the deliveries that were pending when the last run stopped. Those of webhooks that were deleted
+// since, or of events that are no longer in the event log, are dropped.
+func (s *WebhookService) resume(ctx context.Context, wg *sync.WaitGroup) {
+	pending, err := s.store.ListPending()
+	if err != nil {
+		log.Printf("Error listing pending webhook deliveries: %s", err)
+		return
+	}
+	for _, p := range pending {
+		webhook, err := s.store.GetWebhook(p.WebhookId)
+		var events []*models.Event
+		if err == nil {
+			events, err = s.events.ListEvents(p.EventId-1, 1, EventFilter{})
+		}
+		if err != nil || len(events) == 0 || events[0].Id != p.EventId {
+			log.Printf("Dropping the pending delivery of event %d to webhook %s", p.EventId, p.WebhookId)
+			if err := s.store.DeletePending(p.WebhookId, p.EventId); err != nil {
+				log.Printf("Error dropping the pending delivery of event %d to webhook %s: %s", p.EventId, p.WebhookId, err)
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(p *models.PendingDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, webhook, events[0], p)
+		}(p)
+	}
+}
+
+// forward dispatches the events of sub with an id greater than last until ctx is done or sub is dropped,
+// and returns the id of the last event dispatched.
+func (s *WebhookService) forward(ctx context.Context, wg *sync.WaitGroup, sub *Subscription, last int) int {
+	for {
+		select {
+		case <-ctx.Done():
+			return last
+		case event, ok := <-sub.C:
+			if !ok {
+				return last
+			}
+			if event.Id <= last {
+				continue
+			}
+			s.dispatch(ctx, wg, event)
+			last = event.Id
+		}
+	}
+}
+
+// dispatch starts the delivery of an event to every webhook that matches it and whose owner may see it.
+// The deliveries are stored as pending before the event is recorded as dispatched, so that none is lost on a restart.
+func (s *WebhookService) dispatch(ctx context.Context, wg *sync.WaitGroup, event *models.Event) {
+	webhooks, err := s.store.ListWebhooks()
+	if err != nil {
+		log.Printf("Error listing webhooks for event %d: %s", event.Id, err)
+		return
+	}
+	for _, webhook := range webhooks {
+		if !(EventFilter{PlannerId: webhook.PlannerId, Types: webhook.Types}).Match(event) {
+			continue
+		}
+		if webhook.Owner != "" && s.access != nil && !s.events.As(s.access, webhook.Owner).visible(event) {
+			continue
+		}
+		pending := &models.PendingDelivery{WebhookId: webhook.Id, EventId: event.Id, Attempt: 1, Due: time.Now()}
+		if err := s.store.SavePending(pending); err != nil {
+			log.Printf("Error saving the delivery of event %d to webhook %s: %s", event.Id, webhook.Id, err)
+		}
+		wg.Add(1)
+		go func(webhook *models.Webhook) {
+			defer wg.Done()
+			s.deliver(ctx, webhook, event, pending)
+		}(webhook)
+	}
+	if err := s.store.SetDispatched(event.Id); err != nil {
+		log.Printf("Error recording event %d as dispatched: %s", event.Id, err)
+	}
+}
+
+// deliver sends an event to a webhook from the attempt of pending on, once it is due, retrying with exponential
+// backoff until it succeeds, the attempts are used up or ctx is done. Every attempt is recorded in the delivery log,
+// and the next one in the pending delivery, which is deleted once the delivery succeeds or gives up.
+// An attempt interrupted by ctx is left pending, to be made again by the next run.
+func (s *WebhookService) deliver(ctx context.Context, webhook *models.Webhook, event *models.Event, pending *models.PendingDelivery) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error encoding event %d: %s", event.Id, err)
+		return
+	}
+	for {
+		if wait := time.Until(pending.Due); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		delivery := &models.Delivery{
+			WebhookId: webhook.Id,
+			EventId:   event.Id,
+			EventType: event.Type,
+			Attempt:   pending.Attempt,
+			Time:      time.Now(),
+		}
+		delivery.StatusCode, err = s.send(ctx, webhook, event, body)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && !delivery.Succeeded() {
+			err = fmt.Errorf("unexpected status %d", delivery.StatusCode)
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if err := s.store.AppendDelivery(delivery, s.KeepDeliveries); err != nil {
+			log.Printf("Error logging delivery of event %d to webhook %s: %s", event.Id, webhook.Id, err)
+		}
+		if delivery.Succeeded() || pending.Attempt >= s.Attempts {
+			if err := s.store.DeletePending(webhook.Id, event.Id); err != nil {
+				log.Printf("Error clearing the delivery of event %d to webhook %s: %s", event.Id, webhook.Id, err)
+			}
+			return
+		}
+		// the wait before the next attempt doubles with every attempt
+		pending.Due = time.Now().Add(s.Backoff << (pending.Attempt - 1))
+		pending.Attempt++
+		if err := s.store.SavePending(pending); err != nil {
+			log.Printf("Error saving the delivery of event %d to webhook %s: %s", event.Id, webhook.Id, err)
+		}
+	}
+}
+
+// send POSTs the signed body of an event to a webhook and returns the status code of the response.
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, event *models.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "flow-webhooks")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(event.Id))
+	req.Header.Set(SignatureHeader, SignPayload(webhook.Secret, body))
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	return res.StatusCode, nil
+}
@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"github.com/asdine/storm"
+	"github.com/ooyeku/flow/internal/inmemory"
+	"github.com/ooyeku/flow/pkg/models"
+	"github.com/ooyeku/flow/pkg/services"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookService_Run(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	goalStore := inmemory.NewInMemoryGoalStore(db)
+	access := services.NewAccessService(inmemory.NewInMemoryPlannerStore(db), goalStore, inmemory.NewInMemoryPlanStore(db),
+		inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	taskService := services.NewTaskService(inmemory.NewInMemoryTaskStore(db)).WithEvents(events)
+	webhookStore := inmemory.NewInMemoryWebhookStore(db)
+	webhooks := services.NewWebhookService(webhookStore, events, access)
+	webhooks.Backoff = time.Millisecond
+
+	// the receiver fails the first request, so the event is delivered on the second attempt
+	var mu sync.Mutex
+	var received []string
+	requests := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !hmac.Equal([]byte(r.Header.Get(services.SignatureHeader)), []byte(services.SignPayload("s3cret", body))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = append(received, r.Header.Get(services.EventHeader))
+	}))
+	defer receiver.Close()
+
+	webhook := &models.Webhook{Id: "hook1", URL: receiver.URL, Types: []string{"task.completed"}, Secret: "s3cret"}
+	if err := webhooks.CreateWebhook(webhook); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	assert.ErrorIs(t, webhooks.CreateWebhook(&models.Webhook{Id: "hook2", URL: "ftp://example.com", Secret: "s"}), services.ErrInvalidWebhook)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		webhooks.Run(ctx)
+		close(done)
+	}()
+	// give Run time to subscribe before the task changes
+	time.Sleep(50 * time.Millisecond)
+
+	task := &models.Task{ID: "task1", Title: "Release", Owner: "alice"}
+	if err := taskService.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	task.Completed = true
+	if err := taskService.UpdateTask(task.ID, task, task.Revision); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		deliveries, err := webhooks.ListDeliveries(webhook.Id, 0)
+		return err == nil && len(deliveries) == 2
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	assert.Equal(t, []string{"task.completed"}, received)
+	mu.Unlock()
+	deliveries, err := webhooks.ListDeliveries(webhook.Id, 0)
+	if err != nil {
+		t.Fatalf("failed to list deliveries: %v", err)
+	}
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, 2, deliveries[0].Attempt)
+		assert.True(t, deliveries[0].Succeeded())
+		assert.Equal(t, 1, deliveries[1].Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[1].StatusCode)
+		assert.NotEmpty(t, deliveries[1].Error)
+	}
+
+	// other users cannot see the webhooks added from the command line
+	_, err = webhooks.As(access, "bob").GetWebhook(webhook.Id)
+	assert.ErrorIs(t, err, services.ErrForbidden)
+}
+
+func TestWebhookService_Resume(t *testing.T) {
+	db, err := storm.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	access := services.NewAccessService(inmemory.NewInMemoryPlannerStore(db), inmemory.NewInMemoryGoalStore(db),
+		inmemory.NewInMemoryPlanStore(db), inmemory.NewInMemoryTaskStore(db))
+	events := services.NewEventService(inmemory.NewInMemoryEventStore(db), access, services.DefaultEventLogSize)
+	taskService := services.NewTaskService(inmemory.NewInMemoryTaskStore(db)).WithEvents(events)
+	webhookStore := inmemory.NewInMemoryWebhookStore(db)
+
+	// the receiver is down until the server restarts
+	var mu sync.Mutex
+	up := false
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, r.Header.Get(services.DeliveryHeader))
+	}))
+	defer receiver.Close()
+	webhook := &models.Webhook{Id: "hook1", URL: receiver.URL, Types: []string{"task.completed"}, Secret: "s3cret"}
+	if err := webhookStore.CreateWebhook(webhook); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	complete := func(id string) int {
+		task := &models.Task{ID: id, Title: id}
+		if err := taskService.CreateTask(task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+		task.Completed = true
+		if err := taskService.UpdateTask(task.ID, task, task.Revision); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+		completed, err := events.ListEvents(0, 0, services.EventFilter{Types: []string{"task.completed"}})
+		if err != nil || len(completed) == 0 {
+			t.Fatalf("failed to list events: %v", err)
+		}
+		return completed[len(completed)-1].Id
+	}
+	run := func(ctx context.Context) chan struct{} {
+		webhooks := services.NewWebhookService(webhookStore, events, access)
+		webhooks.Backoff = time.Hour
+		done := make(chan struct{})
+		go func() {
+			webhooks.Run(ctx)
+			close(done)
+		}()
+		// give Run time to subscribe before the tasks change
+		time.Sleep(50 * time.Millisecond)
+		return done
+	}
+
+	// the first attempt fails and the retry is due in an hour when the server stops
+	ctx, cancel := context.WithCancel(context.Background())
+	done := run(ctx)
+	first := complete("task1")
+	assert.Eventually(t, func() bool {
+		pending, err := webhookStore.ListPending()
+		return err == nil && len(pending) == 1 && pending[0].Attempt == 2
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	// a task completed while the server is down is delivered after the restart, with the retry once it is due
+	second := complete("task2")
+	pending, err := webhookStore.ListPending()
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected the retry to be pending, got %v, %v", pending, err)
+	}
+	pending[0].Due = time.Now()
+	if err := webhookStore.SavePending(pending[0]); err != nil {
+		t.Fatalf("failed to save pending delivery: %v", err)
+	}
+	mu.Lock()
+	up = true
+	mu.Unlock()
+	ctx, cancel = context.WithCancel(context.Background())
+	done = run(ctx)
+	assert.Eventually(t, func() bool {
+		pending, err := webhookStore.ListPending()
+		return err == nil && len(pending) == 0
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	assert.ElementsMatch(t, []string{strconv.Itoa(first), strconv.Itoa(second)}, received)
+	mu.Unlock()
+	deliveries, err := webhookStore.ListDeliveries(webhook.Id, 0)
+	if err != nil {
+		t.Fatalf("failed to list deliveries: %v", err)
+	}
+	assert.Len(t, deliveries, 3)
+}
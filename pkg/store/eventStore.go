@@ -0,0 +1,11 @@
+package store
+
+import "github.com/ooyeku/flow/pkg/models"
+
+// EventStore is an interface that defines the methods of a bounded log of events.
+// AppendEvent assigns the next id to the event and then drops the oldest events so that at most keep remain.
+// ListEvents returns up to limit events with an id greater than after, oldest first; a limit of 0 returns them all.
+type EventStore interface {
+	AppendEvent(event *models.Event, keep int) error
+	ListEvents(after, limit int) ([]*models.Event, error)
+}
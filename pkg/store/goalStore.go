@@ -4,12 +4,14 @@ import "github.com/ooyeku/flow/pkg/models"
 
 // GoalStore is an interface that defines methods for creating, updating, deleting, retrieving, and listing goals.
 // CreateGoal creates a new goal.
+// Update and delete return ErrRevisionMismatch when expectedRevision is not AnyRevision and differs from the stored revision.
 type GoalStore interface {
 	CreateGoal(goal *models.Goal) error
-	UpdateGoal(goal *models.Goal) error
-	DeleteGoal(id string) error
+	UpdateGoal(goal *models.Goal, expectedRevision int) error
+	DeleteGoal(id string, expectedRevision int) error
 	GetGoal(id string) (*models.Goal, error)
 	ListGoals() ([]*models.Goal, error)
 	GetGoalByObjective(objective string) (*models.Goal, error)
 	GetGoalsByPlannerId(id string) ([]*models.Goal, error)
+	FindGoals(opts *ListOptions) ([]*models.Goal, error)
 }
@@ -0,0 +1,15 @@
+package store
+
+import "github.com/ooyeku/flow/pkg/models"
+
+// JournalStore is an interface that defines the methods of the journal of the changes each user made, kept for undo and redo.
+// AppendEntry assigns the next id to the entry, drops the undone entries of its user, which can no longer be redone
+// after a new change, and then drops the oldest entries of the user so that at most keep remain.
+// ListEntries returns the entries of a user, oldest first.
+// UpdateEntry replaces the entry with the same id, and DeleteEntry removes the entry with the id.
+type JournalStore interface {
+	AppendEntry(entry *models.JournalEntry, keep int) error
+	ListEntries(user string) ([]*models.JournalEntry, error)
+	UpdateEntry(entry *models.JournalEntry) error
+	DeleteEntry(id int) error
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidSort is returned by the Find* methods when ListOptions.Sort names a field that cannot be sorted on.
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// ListOptions describes the paging, filtering and ordering applied by the Find* methods of the stores.
+// Zero values mean "no constraint", so an empty ListOptions returns every record.
+//
+// Fields:
+// - Limit: the maximum number of records to return.
+// - Offset: the number of matching records to skip.
+// - Sort: the json name of the field to order by, prefixed with "-" for descending order.
+// - Owner: only return records owned by this user (Task.Owner, Planner.UserId).
+// - Status: only return records with this status (NotStarted, InProgress, Completed, Fail).
+// - DeadlineBefore: only return goals whose deadline is before this time.
+// - PlannerId: only return goals that belong to this planner.
+// - GoalId: only return plans that belong to this goal.
+// - PlannerIds: if not nil, only return planners with one of these ids, or goals that belong to one of these planners.
+// - GoalIds: if not nil, only return plans that belong to one of these goals.
+// - PlanIds: if not nil, only return tasks that belong to one of these plans.
+// - Ids: if not nil, only return records with one of these ids.
+//
+// The id lists restrict the results to the records a user has access to; an empty id in a list matches
+// the records that do not belong to a planner, goal or plan.
+type ListOptions struct {
+	Limit          int
+	Offset         int
+	Sort           string
+	Owner          string
+	Status         string
+	DeadlineBefore time.Time
+	PlannerId      string
+	GoalId         string
+	PlannerIds     []string
+	GoalIds        []string
+	PlanIds        []string
+	Ids            []string
+}
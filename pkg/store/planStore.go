@@ -4,12 +4,14 @@ import "github.com/ooyeku/flow/pkg/models"
 
 // PlanStore is an interface that defines the methods to interact with plans.
 // CreatePlan creates a new plan.
+// Update and delete return ErrRevisionMismatch when expectedRevision is not AnyRevision and differs from the stored revision.
 type PlanStore interface {
 	CreatePlan(plan *models.Plan) error
-	UpdatePlan(plan *models.Plan) error
-	DeletePlan(id string) error
+	UpdatePlan(plan *models.Plan, expectedRevision int) error
+	DeletePlan(id string, expectedRevision int) error
 	GetPlan(id string) (*models.Plan, error)
 	ListPlans() ([]*models.Plan, error)
 	GetPlanByName(name string) (*models.Plan, error)
 	GetPlansByGoal(id string) ([]*models.Plan, error)
+	FindPlans(opts *ListOptions) ([]*models.Plan, error)
 }
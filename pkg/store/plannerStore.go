@@ -4,12 +4,14 @@ import "github.com/ooyeku/flow/pkg/models"
 
 // PlannerStore is an interface that defines the methods for managing planners in a store.
 // It provides functionality for creating, updating, deleting, retrieving, and listing planners.
+// Update and delete return ErrRevisionMismatch when expectedRevision is not AnyRevision and differs from the stored revision.
 type PlannerStore interface {
 	CreatePlanner(planner *models.Planner) error
-	UpdatePlanner(planner *models.Planner) error
-	DeletePlanner(id string) error
+	UpdatePlanner(planner *models.Planner, expectedRevision int) error
+	DeletePlanner(id string, expectedRevision int) error
 	GetPlanner(id string) (*models.Planner, error)
 	ListPlanners() ([]*models.Planner, error)
 	GetPlannerByTitle(title string) (*models.Planner, error)
 	GetPlannerByOwner(id string) ([]*models.Planner, error)
+	FindPlanners(opts *ListOptions) ([]*models.Planner, error)
 }
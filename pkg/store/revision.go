@@ -0,0 +1,11 @@
+package store
+
+import "errors"
+
+// AnyRevision can be passed as the expected revision of an update or delete to skip the revision check.
+// Records are created with revision 1, so a zero expected revision never matches a real conflict.
+const AnyRevision = 0
+
+// ErrRevisionMismatch is returned by the Update* and Delete* methods when the stored record
+// has been changed since the expected revision was read.
+var ErrRevisionMismatch = errors.New("revision mismatch")
@@ -6,12 +6,14 @@ import (
 
 // TaskStore represents an interface for managing tasks
 // Implementations of this interface should provide methods to create, update, delete, get, and list tasks
+// Update and delete return ErrRevisionMismatch when expectedRevision is not AnyRevision and differs from the stored revision.
 type TaskStore interface {
 	CreateTask(task *models.Task) error
-	UpdateTask(id string, task *models.Task) error
-	DeleteTask(id string) error
+	UpdateTask(id string, task *models.Task, expectedRevision int) error
+	DeleteTask(id string, expectedRevision int) error
 	GetTask(id string) (*models.Task, error)
 	ListTasks() ([]*models.Task, error)
 	GetTaskByTitle(title string) (*models.Task, error)
 	GetTaskByOwner(owner string) ([]*models.Task, error)
+	FindTasks(opts *ListOptions) ([]*models.Task, error)
 }
@@ -0,0 +1,16 @@
+package store
+
+// Stores groups the stores of the records that can be changed together in one transaction.
+type Stores struct {
+	Task    TaskStore
+	Goal    GoalStore
+	Plan    PlanStore
+	Planner PlannerStore
+}
+
+// Transactor runs a function with stores that share one transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise, so the changes made by fn
+// are stored all together or not at all. Other writers wait until the transaction ends.
+type Transactor interface {
+	Transaction(fn func(stores *Stores) error) error
+}
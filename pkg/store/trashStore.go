@@ -0,0 +1,25 @@
+package store
+
+import (
+	"errors"
+	"github.com/ooyeku/flow/pkg/models"
+)
+
+// ErrParentInTrash is returned by RestoreTrash when the goal, plan or planner above the records to restore
+// is still in the trash, deleted on its own, so the records would come back under a deleted parent.
+var ErrParentInTrash = errors.New("parent is in the trash")
+
+// TrashStore is an interface that defines the methods of the trash, which holds the tasks, goals, plans and planners
+// deleted with the Delete* methods of their stores until they are restored or purged.
+// ListTrash returns the deleted records, most recently deleted first.
+// RestoreTrash brings back the record with the id together with the records deleted along with it; given a record
+// that was deleted along with another, it brings back that other record and everything deleted with it.
+// The restored records keep the revision they were deleted at and are returned from the top of the hierarchy down.
+// PurgeTrash removes the same records for good and returns them.
+// Both return storm.ErrNotFound if no record with the id is in the trash, and RestoreTrash returns ErrParentInTrash,
+// restoring nothing, if the record above the restored records is in the trash too.
+type TrashStore interface {
+	ListTrash() ([]*models.TrashItem, error)
+	RestoreTrash(id string) ([]*models.TrashItem, error)
+	PurgeTrash(id string) ([]*models.TrashItem, error)
+}
@@ -0,0 +1,16 @@
+package store
+
+import "github.com/ooyeku/flow/pkg/models"
+
+// UserStore is an interface that defines the methods for managing users and their API tokens in a store.
+// GetUserByName and GetTokenByHash return storm.ErrNotFound if there is no match.
+type UserStore interface {
+	CreateUser(user *models.User) error
+	GetUser(id string) (*models.User, error)
+	GetUserByName(name string) (*models.User, error)
+	ListUsers() ([]*models.User, error)
+	CreateToken(token *models.Token) error
+	GetTokenByHash(hash string) (*models.Token, error)
+	ListTokens(userId string) ([]*models.Token, error)
+	DeleteToken(id string) error
+}
@@ -0,0 +1,28 @@
+package store
+
+import "github.com/ooyeku/flow/pkg/models"
+
+// WebhookStore is an interface that defines the methods for managing webhooks and their delivery log in a store.
+// GetWebhook and DeleteWebhook return storm.ErrNotFound if there is no webhook with the id.
+// DeleteWebhook also deletes the deliveries of the webhook.
+// AppendDelivery assigns the next id to the delivery and then drops the oldest deliveries so that at most keep remain.
+// ListDeliveries returns up to limit deliveries of a webhook, newest first; a limit of 0 returns them all.
+//
+// The store also keeps what the delivery of the events has reached, so that it survives a restart:
+// SavePending stores or replaces the pending delivery of an event to a webhook, DeletePending removes it
+// (and does nothing if there is none), and ListPending returns them all. GetDispatched returns the id of the last
+// event handed to the webhooks, which SetDispatched records, or 0 if none was. DeleteWebhook also deletes the
+// pending deliveries of the webhook.
+type WebhookStore interface {
+	CreateWebhook(webhook *models.Webhook) error
+	GetWebhook(id string) (*models.Webhook, error)
+	ListWebhooks() ([]*models.Webhook, error)
+	DeleteWebhook(id string) error
+	AppendDelivery(delivery *models.Delivery, keep int) error
+	ListDeliveries(webhookId string, limit int) ([]*models.Delivery, error)
+	SavePending(pending *models.PendingDelivery) error
+	DeletePending(webhookId string, eventId int) error
+	ListPending() ([]*models.PendingDelivery, error)
+	GetDispatched() (int, error)
+	SetDispatched(eventId int) error
+}